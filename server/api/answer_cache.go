@@ -0,0 +1,136 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oseducation/knowledge-graph/app"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultCachedAnswersPage    = 0
+	defaultCachedAnswersPerPage = 50
+)
+
+func (apiObj *API) initAnswerCache() {
+	apiObj.AnswerCache = apiObj.APIRoot.Group("/answer-cache")
+
+	apiObj.AnswerCache.GET("/", authMiddleware(), requireNodePermissions(), getCachedAnswers)
+	apiObj.AnswerCache.DELETE("/", authMiddleware(), requireNodePermissions(), invalidateAnswerCache)
+	apiObj.AnswerCache.GET("/:answerID", authMiddleware(), requireNodePermissions(), getCachedAnswer)
+	apiObj.AnswerCache.PUT("/:answerID", authMiddleware(), requireNodePermissions(), updateCachedAnswer)
+	apiObj.AnswerCache.DELETE("/:answerID", authMiddleware(), requireNodePermissions(), deleteCachedAnswer)
+}
+
+func getCachedAnswers(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", strconv.Itoa(defaultCachedAnswersPage)))
+	if err != nil || page < 0 {
+		page = defaultCachedAnswersPage
+	}
+	perPage, err := strconv.Atoi(c.DefaultQuery("per_page", strconv.Itoa(defaultCachedAnswersPerPage)))
+	if err != nil || perPage <= 0 {
+		perPage = defaultCachedAnswersPerPage
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	answers, err := a.GetCachedAnswers(&model.CachedAnswerGetOptions{
+		NodeID:  c.Query("node_id"),
+		Page:    page,
+		PerPage: perPage,
+	})
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, answers)
+}
+
+// invalidateAnswerCache invalidates cached answers of the node, pinned ones only with pinned=true
+func invalidateAnswerCache(c *gin.Context) {
+	nodeID := c.Query("node_id")
+	if !model.IsValidID(nodeID) {
+		responseFormat(c, http.StatusBadRequest, "`node_id` not valid")
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := a.InvalidateAnswerCache(nodeID, c.Query("pinned") == "true"); err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, "cached answers invalidated")
+}
+
+func getCachedAnswer(c *gin.Context) {
+	_, answer, ok := getCachedAnswerFromPath(c)
+	if !ok {
+		return
+	}
+	responseFormat(c, http.StatusOK, answer)
+}
+
+func updateCachedAnswer(c *gin.Context) {
+	updated, err := model.CachedAnswerFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "Invalid or missing `answer` in the request body")
+		return
+	}
+
+	a, answer, ok := getCachedAnswerFromPath(c)
+	if !ok {
+		return
+	}
+	updated.ID = answer.ID
+
+	answer, err = a.UpdateCachedAnswer(updated)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, answer)
+}
+
+func deleteCachedAnswer(c *gin.Context) {
+	a, answer, ok := getCachedAnswerFromPath(c)
+	if !ok {
+		return
+	}
+
+	if err := a.DeleteCachedAnswer(answer.ID); err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, "cached answer deleted")
+}
+
+func getCachedAnswerFromPath(c *gin.Context) (*app.App, *model.CachedAnswer, bool) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+
+	answer, err := a.GetCachedAnswer(c.Param("answerID"))
+	if errors.Is(err, sql.ErrNoRows) {
+		responseFormat(c, http.StatusNotFound, "cached answer not found")
+		return nil, nil, false
+	} else if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+	return a, answer, true
+}
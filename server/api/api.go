@@ -34,6 +34,16 @@ type API struct {
 	Subscriptions      *gin.RouterGroup // 'api/v1/subscriptions'
 	TutorPersonalities *gin.RouterGroup // 'api/v1/tutor-personalities'
 	NodeNotes          *gin.RouterGroup // 'api/v1/notes'
+	Plan               *gin.RouterGroup // 'api/v1/plan'
+	Certificates       *gin.RouterGroup // 'api/v1/certificates'
+	Leaderboards       *gin.RouterGroup // 'api/v1/leaderboards'
+	Classrooms         *gin.RouterGroup // 'api/v1/classrooms'
+	StudyGroups        *gin.RouterGroup // 'api/v1/groups'
+	Quizzes            *gin.RouterGroup // 'api/v1/quizzes'
+	Exams              *gin.RouterGroup // 'api/v1/exams'
+	ExamAttempts       *gin.RouterGroup // 'api/v1/exam-attempts'
+	AnswerCache        *gin.RouterGroup // 'api/v1/answer-cache'
+	Moderation         *gin.RouterGroup // 'api/v1/moderation'
 }
 
 // Init initializes api
@@ -63,6 +73,15 @@ func Init(router *gin.Engine, application *app.App) error {
 	apiObj.initSubscriptions()
 	apiObj.initTutorPersonality()
 	apiObj.initNodeNote()
+	apiObj.initPlan()
+	apiObj.initCertificate()
+	apiObj.initLeaderboard()
+	apiObj.initClassroom()
+	apiObj.initStudyGroup()
+	apiObj.initQuiz()
+	apiObj.initExam()
+	apiObj.initAnswerCache()
+	apiObj.initModeration()
 
 	apiObj.Root.NoRoute(func(c *gin.Context) {
 		c.JSON(http.StatusNotFound, "Page not found")
@@ -74,6 +74,24 @@ func requireUserPermissions() gin.HandlerFunc {
 	}
 }
 
+func requireClassroomPermissions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, err := getSession(c)
+		if err != nil {
+			responseFormat(c, http.StatusUnauthorized, err.Error())
+			c.Abort()
+			return
+		}
+		if !session.CanManageClassrooms() {
+			responseFormat(c, http.StatusForbidden, "No permission for this action")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 func splitAuthMiddleware(withSession gin.HandlerFunc, withoutSession gin.HandlerFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		a, err := getApp(c)
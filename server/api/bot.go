@@ -27,6 +27,8 @@ func (apiObj *API) initBot() {
 	apiObj.Bots.POST("/next", authMiddleware(), getBotPosts)
 	apiObj.Bots.POST("/correct_answer", authMiddleware(), getResponseToCorrectAnswer)
 	apiObj.Bots.POST("/incorrect_answer", authMiddleware(), getResponseToIncorrectAnswer)
+	apiObj.Bots.POST("/review_quiz", authMiddleware(), startReviewQuiz)
+	apiObj.Bots.POST("/hint", authMiddleware(), getAssignmentHint)
 
 }
 
@@ -65,19 +67,34 @@ func askQuestion(c *gin.Context) {
 		return
 	}
 
-	if !a.CheckLimit(session.UserID) {
+	if !a.CheckLimit(session.UserID, session.Role) {
 		responseFormat(c, http.StatusBadRequest, "Monthly limit exceeded")
 		return
 	}
-	gptModel := services.FreeGPT
+	tier := services.LLMTierFree
 	if session.Role != model.UserRole {
-		gptModel = services.PremiumGPT
+		tier = services.LLMTierPremium
 	}
 
+	// the message may be redacted, blocked messages don't reach the language model
+	message, blocked := a.ModerateInput(post.Message, nodeID, post.UserID)
+
 	isStream := c.DefaultQuery("stream", "")
 
+	if isStream != "true" && blocked {
+		answer, blockedErr := a.AnswerBlockedQuestion(nodeID, post.UserID)
+		if blockedErr != nil {
+			responseFormat(c, http.StatusInternalServerError, "Error while answering a blocked question")
+			a.Log.Error(blockedErr.Error())
+			return
+		}
+
+		responseFormat(c, http.StatusCreated, answer)
+		return
+	}
+
 	if isStream != "true" {
-		answer, gptErr := a.AskQuestionToChatGPT(post.Message, nodeID, post.UserID, gptModel)
+		answer, gptErr := a.AskQuestionToChatGPT(message, nodeID, post.UserID, tier)
 		if gptErr != nil {
 			responseFormat(c, http.StatusInternalServerError, "Error while asking a question")
 			a.Log.Error(gptErr.Error())
@@ -88,27 +105,33 @@ func askQuestion(c *gin.Context) {
 		return
 	}
 
-	userIntent, err := a.GetUserIntent(post.Message, post.UserID, nodeID)
-	if err != nil {
-		responseFormat(c, http.StatusInternalServerError, "Error getting user intent")
-		a.Log.Error(err.Error())
-		return
+	userIntent := app.UserIntent{}
+	if !blocked {
+		userIntent, err = a.GetUserIntent(message, post.UserID, nodeID)
+		if err != nil {
+			responseFormat(c, http.StatusInternalServerError, "Error getting user intent")
+			a.Log.Error(err.Error())
+			return
+		}
 	}
 
 	var chatStream services.ChatStream
+	var citations []*model.Citation
 	var chatStreamErr error
 
-	if userIntent.Intent == app.QuestionOnCurrentTopicIntent {
+	if blocked {
+		chatStream = a.BlockedQuestionStream()
+	} else if userIntent.Intent == app.QuestionOnCurrentTopicIntent {
 		// a question about the current topic
-		chatStream, chatStreamErr = a.AskQuestionToChatGPTSteam(post.Message, nodeID, post.UserID, gptModel)
+		chatStream, citations, chatStreamErr = a.AskQuestionToChatGPTSteam(message, nodeID, post.UserID, tier)
 	} else if userIntent.Intent == app.QuestionOnOffTopicIntent {
 		// an off-topic question
 		chatStream, chatStreamErr = a.AskQuestionToChatGPTSteamOffTopic()
 	} else if userIntent.Intent == app.QuestionOnDifferentTopicIntent {
 		// some other topic from the course
-		chatStream, chatStreamErr = a.AskQuestionToChatGPTSteamOnDifferentTopic(post.Message, userIntent.TopicID, post.UserID, gptModel)
+		chatStream, citations, chatStreamErr = a.AskQuestionToChatGPTSteamOnDifferentTopic(message, userIntent.TopicID, post.UserID, tier)
 	} else if userIntent.Intent == app.DialogueIntent {
-		chatStream, chatStreamErr = a.AskQuestionToChatGPTSteamOnTopicDialogue(post.Message, nodeID, post.UserID, gptModel, userIntent.PrevPosts)
+		chatStream, citations, chatStreamErr = a.AskQuestionToChatGPTSteamOnTopicDialogue(message, nodeID, post.UserID, tier, userIntent.PrevPosts)
 	} else if userIntent.Intent != "" {
 		// show text or show video
 		chatStream = services.CreateStringStream(fmt.Sprintf("{intent: %s}", userIntent.Intent))
@@ -131,6 +154,11 @@ func askQuestion(c *gin.Context) {
 	for {
 		resp, err := chatStream.Recv()
 		if errors.Is(err, io.EOF) {
+			if len(citations) > 0 {
+				// the client stores the citations in the props of the saved answer
+				citationsJSON, _ := json.Marshal(citations)
+				fmt.Fprintf(c.Writer, "data: {citations: %s}\n\n", citationsJSON)
+			}
 			fmt.Fprintf(c.Writer, "data: {%s}\n\n", "[DONE]")
 			c.Writer.Flush()
 			return // stream finished
@@ -170,12 +198,16 @@ func getResponseToCorrectAnswer(c *gin.Context) {
 		return
 	}
 
-	gptModel := services.FreeGPT
+	if !a.CheckLimit(session.UserID, session.Role) {
+		responseFormat(c, http.StatusBadRequest, "Monthly limit exceeded")
+		return
+	}
+	tier := services.LLMTierFree
 	if session.Role != model.UserRole {
-		gptModel = services.PremiumGPT
+		tier = services.LLMTierPremium
 	}
 
-	chatStream, err := a.GetResponseToCorrectAnswerStream(question.Explanation, session.UserID, gptModel)
+	chatStream, err := a.GetResponseToCorrectAnswerStream(question.Explanation, session.UserID, tier)
 	if err != nil {
 		responseFormat(c, http.StatusInternalServerError, "Error while getting response to correct answer stream")
 		a.Log.Error(err.Error())
@@ -237,12 +269,16 @@ func getResponseToIncorrectAnswer(c *gin.Context) {
 		return
 	}
 
-	gptModel := services.FreeGPT
+	if !a.CheckLimit(session.UserID, session.Role) {
+		responseFormat(c, http.StatusBadRequest, "Monthly limit exceeded")
+		return
+	}
+	tier := services.LLMTierFree
 	if session.Role != model.UserRole {
-		gptModel = services.PremiumGPT
+		tier = services.LLMTierPremium
 	}
 
-	chatStream, err := a.GetResponseToIncorrectAnswerStream(question, data.IncorrectAnswer, session.UserID, gptModel)
+	chatStream, err := a.GetResponseToIncorrectAnswerStream(question, data.IncorrectAnswer, session.UserID, tier)
 	if err != nil {
 		responseFormat(c, http.StatusInternalServerError, "Error while getting response to correct answer stream")
 		a.Log.Error(err.Error())
@@ -275,6 +311,71 @@ func getResponseToIncorrectAnswer(c *gin.Context) {
 	}
 }
 
+func getAssignmentHint(c *gin.Context) {
+	type HintRequest struct {
+		NodeID string `json:"node_id"`
+	}
+	var data HintRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&data); err != nil || !model.IsValidID(data.NodeID) {
+		responseFormat(c, http.StatusBadRequest, "Invalid or missing `node_id` in the request body")
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if !a.CheckLimit(session.UserID, session.Role) {
+		responseFormat(c, http.StatusBadRequest, "Monthly limit exceeded")
+		return
+	}
+	tier := services.LLMTierFree
+	if session.Role != model.UserRole {
+		tier = services.LLMTierPremium
+	}
+
+	chatStream, level, err := a.GetAssignmentHintStream(data.NodeID, session.UserID, tier)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer chatStream.Close()
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+	c.Writer.Header().Set("Content-Encoding", "none")
+	c.Writer.WriteHeaderNow()
+
+	for {
+		resp, err := chatStream.Recv()
+		if errors.Is(err, io.EOF) {
+			// the client stores the hint level in the props of the saved hint
+			fmt.Fprintf(c.Writer, "data: {hint_level: %d}\n\n", level)
+			fmt.Fprintf(c.Writer, "data: {%s}\n\n", "[DONE]")
+			c.Writer.Flush()
+			return // stream finished
+		}
+		if err != nil {
+			responseFormat(c, http.StatusInternalServerError, "Error while reading stream")
+			a.Log.Error(err.Error())
+			return
+		}
+		fmt.Fprintf(c.Writer, "data: {%s}\n\n", resp)
+		c.Writer.Flush()
+	}
+}
+
 type OldAndNewPosts struct {
 	NewPost  *model.PostWithUser `json:"new_post"`
 	OldPosts []*model.Post       `json:"old_posts"`
@@ -326,3 +427,24 @@ func getBotPosts(c *gin.Context) {
 
 	responseFormat(c, http.StatusOK, posts)
 }
+
+func startReviewQuiz(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	post, err := a.PostReviewQuiz(session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusCreated, post)
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+func (apiObj *API) initCertificate() {
+	apiObj.Certificates = apiObj.APIRoot.Group("/certificates")
+
+	apiObj.Certificates.GET("/", authMiddleware(), getCertificates)
+	apiObj.Certificates.GET("/:id/pdf", authMiddleware(), getCertificatePDF)
+	apiObj.Certificates.GET("/:id/verify", verifyCertificate)
+	apiObj.Certificates.POST("/:id/revoke", authMiddleware(), requireUserPermissions(), revokeCertificate)
+}
+
+func getCertificates(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	certificates, err := a.GetCertificates(session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, certificates)
+}
+
+func getCertificatePDF(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		responseFormat(c, http.StatusBadRequest, "missing certificate id")
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	certificate, err := a.GetCertificate(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responseFormat(c, http.StatusNotFound, "certificate not found")
+			return
+		}
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if certificate.UserID != session.UserID && !session.CanManageUsers() {
+		responseFormat(c, http.StatusForbidden, "No permission for this action")
+		return
+	}
+
+	pdf, err := a.GetCertificatePDF(certificate)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=certificate-%s.pdf", certificate.ID))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+func verifyCertificate(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		responseFormat(c, http.StatusBadRequest, "missing certificate id")
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	verification, err := a.VerifyCertificate(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responseFormat(c, http.StatusNotFound, "certificate not found")
+			return
+		}
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, verification)
+}
+
+func revokeCertificate(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		responseFormat(c, http.StatusBadRequest, "missing certificate id")
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := a.RevokeCertificate(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responseFormat(c, http.StatusNotFound, "certificate not found")
+			return
+		}
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, "")
+}
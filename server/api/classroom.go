@@ -0,0 +1,259 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oseducation/knowledge-graph/app"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+func (apiObj *API) initClassroom() {
+	apiObj.Classrooms = apiObj.APIRoot.Group("/classrooms")
+
+	apiObj.Classrooms.POST("/", authMiddleware(), requireClassroomPermissions(), createClassroom)
+	apiObj.Classrooms.GET("/", authMiddleware(), requireClassroomPermissions(), getTeacherClassrooms)
+	apiObj.Classrooms.GET("/joined", authMiddleware(), getJoinedClassrooms)
+	apiObj.Classrooms.POST("/join", authMiddleware(), joinClassroom)
+	apiObj.Classrooms.DELETE("/:classroomID", authMiddleware(), requireClassroomPermissions(), deleteClassroom)
+	apiObj.Classrooms.GET("/:classroomID/dashboard", authMiddleware(), requireClassroomPermissions(), getClassroomDashboard)
+	apiObj.Classrooms.PUT("/:classroomID/goals/:nodeID", authMiddleware(), requireClassroomPermissions(), assignClassroomGoal)
+	apiObj.Classrooms.DELETE("/:classroomID/goals/:nodeID", authMiddleware(), requireClassroomPermissions(), unassignClassroomGoal)
+	apiObj.Classrooms.DELETE("/:classroomID/students/:userID", authMiddleware(), removeStudent)
+}
+
+func createClassroom(c *gin.Context) {
+	classroom, err := model.ClassroomFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "invalid classroom")
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	classroom, err = a.CreateClassroom(session.UserID, classroom.Name)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusCreated, classroom)
+}
+
+func getTeacherClassrooms(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	classrooms, err := a.GetTeacherClassrooms(session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, classrooms)
+}
+
+func getJoinedClassrooms(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	classrooms, err := a.GetStudentClassrooms(session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, classrooms)
+}
+
+func joinClassroom(c *gin.Context) {
+	request, err := model.ClassroomFromJSON(c.Request.Body)
+	if err != nil || request.JoinCode == "" {
+		responseFormat(c, http.StatusBadRequest, "missing join code")
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	classroom, err := a.JoinClassroom(session.UserID, request.JoinCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		responseFormat(c, http.StatusNotFound, "invalid join code")
+		return
+	} else if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, classroom)
+}
+
+func deleteClassroom(c *gin.Context) {
+	a, classroom, ok := getManagedClassroom(c)
+	if !ok {
+		return
+	}
+
+	if err := a.DeleteClassroom(classroom.ID); err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, "deleted")
+}
+
+func getClassroomDashboard(c *gin.Context) {
+	a, classroom, ok := getManagedClassroom(c)
+	if !ok {
+		return
+	}
+
+	dashboard, err := a.GetClassroomDashboard(classroom)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, dashboard)
+}
+
+func assignClassroomGoal(c *gin.Context) {
+	goal, err := model.ClassroomGoalFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "invalid classroom goal")
+		return
+	}
+
+	a, classroom, ok := getManagedClassroom(c)
+	if !ok {
+		return
+	}
+
+	goal.ClassroomID = classroom.ID
+	goal.NodeID = c.Param("nodeID")
+	if err := a.AssignClassroomGoal(goal); err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, goal)
+}
+
+func unassignClassroomGoal(c *gin.Context) {
+	a, classroom, ok := getManagedClassroom(c)
+	if !ok {
+		return
+	}
+
+	if err := a.UnassignClassroomGoal(classroom.ID, c.Param("nodeID")); err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, "unassigned")
+}
+
+// removeStudent removes the student from the classroom, students can leave classrooms themselves
+func removeStudent(c *gin.Context) {
+	classroomID := c.Param("classroomID")
+	userID := c.Param("userID")
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	classroom, err := a.GetClassroom(classroomID)
+	if errors.Is(err, sql.ErrNoRows) {
+		responseFormat(c, http.StatusNotFound, "classroom not found")
+		return
+	} else if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if session.UserID != userID && !canManageClassroom(session, classroom) {
+		responseFormat(c, http.StatusForbidden, "No permission for this action")
+		return
+	}
+
+	if err := a.RemoveStudent(classroom.ID, userID); err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, "removed")
+}
+
+// getManagedClassroom gets the classroom from the path and checks that the user is its teacher.
+// It writes the error response and returns false if the classroom can't be managed.
+func getManagedClassroom(c *gin.Context) (*app.App, *model.Classroom, bool) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+
+	classroom, err := a.GetClassroom(c.Param("classroomID"))
+	if errors.Is(err, sql.ErrNoRows) {
+		responseFormat(c, http.StatusNotFound, "classroom not found")
+		return nil, nil, false
+	} else if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+
+	if !canManageClassroom(session, classroom) {
+		responseFormat(c, http.StatusForbidden, "No permission for this action")
+		return nil, nil, false
+	}
+	return a, classroom, true
+}
+
+// canManageClassroom reports whether the session belongs to the classroom's teacher or an admin
+func canManageClassroom(session *model.Session, classroom *model.Classroom) bool {
+	return session.CanManageUsers() || (session.CanManageClassrooms() && classroom.TeacherID == session.UserID)
+}
@@ -9,6 +9,13 @@ import (
 	"github.com/oseducation/knowledge-graph/model"
 )
 
+const (
+	defaultTimelinePage    = 0
+	defaultTimelinePerPage = 50
+	defaultXPPage          = 0
+	defaultXPPerPage       = 50
+)
+
 func (apiObj *API) initDashboard() {
 	apiObj.Dashboard = apiObj.APIRoot.Group("/dashboard")
 
@@ -17,7 +24,13 @@ func (apiObj *API) initDashboard() {
 	apiObj.Dashboard.GET("/progress", authMiddleware(), progress)
 	apiObj.Dashboard.GET("/performers", authMiddleware(), performers)
 	apiObj.Dashboard.GET("/steak", authMiddleware(), steak)
+	apiObj.Dashboard.POST("/steak/freeze", authMiddleware(), spendStreakFreezes)
 	apiObj.Dashboard.GET("/number_of_bot_posts_monthly", authMiddleware(), getNumberOfBotPostsForUser)
+	apiObj.Dashboard.GET("/llm_usage", authMiddleware(), getLLMUsageForUser)
+	apiObj.Dashboard.GET("/timeline", authMiddleware(), timeline)
+	apiObj.Dashboard.GET("/goal_estimates", authMiddleware(), goalEstimates)
+	apiObj.Dashboard.GET("/xp", authMiddleware(), xpHistory)
+	apiObj.Dashboard.GET("/achievements", authMiddleware(), getAchievements)
 }
 
 func topics(c *gin.Context) {
@@ -156,20 +169,134 @@ func steak(c *gin.Context) {
 		return
 	}
 
-	steak, maxSteak, today, err := a.Store.Node().LearningSteak(session.UserID)
+	streak, today, err := a.GetStreak(session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responseFormat(c, http.StatusOK, streakResponse(streak, today))
+}
+
+func spendStreakFreezes(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	streak, today, err := a.SpendStreakFreezes(session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	responseFormat(c, http.StatusOK, streakResponse(streak, today))
+}
+
+func streakResponse(streak *model.Streak, today string) map[string]interface{} {
+	missedDays := streak.MissedDays(today)
+	if missedDays < 0 {
+		missedDays = 0
+	}
+	return map[string]interface{}{
+		"current_steak": streak.Current(today),
+		"max_steak":     streak.MaxStreak,
+		"today":         streak.ActiveOn(today),
+		"freezes":       streak.Freezes,
+		"freezes_used":  streak.FreezesUsed,
+		"missed_days":   missedDays,
+	}
+}
+
+func getNumberOfBotPostsForUser(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	budget, err := a.GetBudgetStatus(session.UserID, session.Role)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// the usage is shown in the tokens the budget is enforced in
+	weekAgo := time.Now().AddDate(0, 0, -7).UnixNano() / int64(time.Millisecond)
+	usageThisWeek, err := a.GetLLMUsage(session.UserID, weekAgo)
 	if err != nil {
 		responseFormat(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	responseFormat(c, http.StatusOK, map[string]interface{}{
-		"current_steak": steak,
-		"max_steak":     maxSteak,
-		"today":         today,
+		"tokens_month": budget.Usage.TotalTokens(),
+		"tokens_week":  usageThisWeek.TotalTokens(),
+		"usage":        budget,
 	})
 }
 
-func getNumberOfBotPostsForUser(c *gin.Context) {
+func timeline(c *gin.Context) {
+	since, err := strconv.ParseInt(c.DefaultQuery("since", "0"), 10, 64)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	until, err := strconv.ParseInt(c.DefaultQuery("until", "0"), 10, 64)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", strconv.Itoa(defaultTimelinePage)))
+	if err != nil {
+		page = defaultTimelinePage
+	}
+	perPage, err := strconv.Atoi(c.DefaultQuery("per_page", strconv.Itoa(defaultTimelinePerPage)))
+	if err != nil {
+		perPage = defaultTimelinePerPage
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	options := &model.TimelineGetOptions{}
+	model.ComposeTimelineOptions(
+		model.TimelineSince(since),
+		model.TimelineUntil(until),
+		model.TimelinePage(page),
+		model.TimelinePerPage(perPage))(options)
+	transitions, err := a.GetTimeline(session.UserID, options)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responseFormat(c, http.StatusOK, transitions)
+}
+
+func goalEstimates(c *gin.Context) {
 	a, err := getApp(c)
 	if err != nil {
 		responseFormat(c, http.StatusInternalServerError, err.Error())
@@ -182,24 +309,91 @@ func getNumberOfBotPostsForUser(c *gin.Context) {
 		return
 	}
 
-	now := time.Now()
-	beginningOfTheMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).UnixNano() / int64(time.Millisecond)
-	countThisMonth, err := a.CountChatGPTPosts(session.UserID, beginningOfTheMonth)
+	pace, goals, err := a.GetGoalEstimates(session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responseFormat(c, http.StatusOK, map[string]interface{}{
+		"pace":  pace,
+		"goals": goals,
+	})
+}
+
+func xpHistory(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", strconv.Itoa(defaultXPPage)))
+	if err != nil || page < 0 {
+		page = defaultXPPage
+	}
+	perPage, err := strconv.Atoi(c.DefaultQuery("per_page", strconv.Itoa(defaultXPPerPage)))
+	if err != nil || perPage <= 0 {
+		perPage = defaultXPPerPage
+	}
+
+	a, err := getApp(c)
 	if err != nil {
 		responseFormat(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	weekAgo := time.Now().AddDate(0, -1, 0).UnixNano() / int64(time.Millisecond)
-	countThisWeek, err := a.CountChatGPTPosts(session.UserID, weekAgo)
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	level, events, err := a.GetXPHistory(session.UserID, page, perPage)
 	if err != nil {
 		responseFormat(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	responseFormat(c, http.StatusOK, map[string]interface{}{
-		"bot_posts_month": countThisMonth,
-		"bot_posts_week":  countThisWeek,
-		"max_posts":       a.Config.ChatSettings.ChatGPTMonthlyLimit,
+		"level":  level,
+		"events": events,
 	})
 }
+
+func getAchievements(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	achievements, err := a.GetUserAchievements(session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responseFormat(c, http.StatusOK, achievements)
+}
+
+func getLLMUsageForUser(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	budget, err := a.GetBudgetStatus(session.UserID, session.Role)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, budget)
+}
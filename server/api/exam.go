@@ -0,0 +1,241 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oseducation/knowledge-graph/app"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+func (apiObj *API) initExam() {
+	apiObj.Exams = apiObj.APIRoot.Group("/exams")
+
+	apiObj.Exams.GET("/", authMiddleware(), getExams)
+	apiObj.Exams.POST("/", authMiddleware(), requireNodePermissions(), createExam)
+	apiObj.Exams.GET("/:examID", authMiddleware(), getExam)
+	apiObj.Exams.PUT("/:examID", authMiddleware(), requireNodePermissions(), updateExam)
+	apiObj.Exams.DELETE("/:examID", authMiddleware(), requireNodePermissions(), deleteExam)
+	apiObj.Exams.GET("/:examID/attempts", authMiddleware(), getExamAttempts)
+	apiObj.Exams.POST("/:examID/attempts", authMiddleware(), startExamAttempt)
+
+	apiObj.ExamAttempts = apiObj.APIRoot.Group("/exam-attempts")
+
+	apiObj.ExamAttempts.GET("/:attemptID", authMiddleware(), getExamAttempt)
+	apiObj.ExamAttempts.GET("/:attemptID/question", authMiddleware(), getNextExamQuestion)
+	apiObj.ExamAttempts.POST("/:attemptID/answers", authMiddleware(), answerExamQuestion)
+}
+
+func getExams(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	exams, err := a.GetExams(c.Query("parent_node_id"))
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, exams)
+}
+
+func createExam(c *gin.Context) {
+	exam, err := model.ExamFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "Invalid or missing `exam` in the request body")
+		return
+	}
+	exam.ID = ""
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	exam, err = a.CreateExam(exam)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusCreated, exam)
+}
+
+func getExam(c *gin.Context) {
+	_, exam, ok := getExamFromPath(c)
+	if !ok {
+		return
+	}
+	responseFormat(c, http.StatusOK, exam)
+}
+
+func updateExam(c *gin.Context) {
+	exam, err := model.ExamFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "Invalid or missing `exam` in the request body")
+		return
+	}
+
+	a, oldExam, ok := getExamFromPath(c)
+	if !ok {
+		return
+	}
+	exam.ID = oldExam.ID
+
+	exam, err = a.UpdateExam(exam)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, exam)
+}
+
+func deleteExam(c *gin.Context) {
+	a, exam, ok := getExamFromPath(c)
+	if !ok {
+		return
+	}
+
+	if err := a.DeleteExam(exam.ID); err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, "exam deleted")
+}
+
+func getExamAttempts(c *gin.Context) {
+	a, exam, ok := getExamFromPath(c)
+	if !ok {
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	attempts, err := a.GetExamAttempts(exam.ID, session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, attempts)
+}
+
+func startExamAttempt(c *gin.Context) {
+	a, exam, ok := getExamFromPath(c)
+	if !ok {
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	attempt, err := a.StartExamAttempt(exam, session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusCreated, attempt.WithoutResults())
+}
+
+func getExamAttempt(c *gin.Context) {
+	_, attempt, ok := getMyExamAttempt(c)
+	if !ok {
+		return
+	}
+	responseFormat(c, http.StatusOK, attempt.WithoutResults())
+}
+
+func getNextExamQuestion(c *gin.Context) {
+	a, attempt, ok := getMyExamAttempt(c)
+	if !ok {
+		return
+	}
+
+	question, err := a.GetNextExamQuestion(attempt)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, question)
+}
+
+func answerExamQuestion(c *gin.Context) {
+	answer, err := model.QuizAnswerFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "invalid exam answer")
+		return
+	}
+
+	a, attempt, ok := getMyExamAttempt(c)
+	if !ok {
+		return
+	}
+
+	result, err := a.AnswerExamQuestion(attempt, answer.QuestionID, answer.ChoiceID)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, result)
+}
+
+// getExamFromPath gets the exam from the path.
+// It writes the error response and returns false if the exam can't be found.
+func getExamFromPath(c *gin.Context) (*app.App, *model.Exam, bool) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+
+	exam, err := a.GetExam(c.Param("examID"))
+	if errors.Is(err, sql.ErrNoRows) {
+		responseFormat(c, http.StatusNotFound, "exam not found")
+		return nil, nil, false
+	} else if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+	return a, exam, true
+}
+
+// getMyExamAttempt gets the attempt from the path and checks that it belongs to the user.
+// It writes the error response and returns false if the attempt can't be accessed.
+func getMyExamAttempt(c *gin.Context) (*app.App, *model.ExamAttempt, bool) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+
+	attempt, err := a.GetExamAttempt(c.Param("attemptID"))
+	if errors.Is(err, sql.ErrNoRows) {
+		responseFormat(c, http.StatusNotFound, "attempt not found")
+		return nil, nil, false
+	} else if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+
+	if attempt.UserID != session.UserID {
+		responseFormat(c, http.StatusNotFound, "attempt not found")
+		return nil, nil, false
+	}
+	return a, attempt, true
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/oseducation/knowledge-graph/model"
 )
 
 func (apiObj *API) initGoal() {
@@ -11,6 +12,7 @@ func (apiObj *API) initGoal() {
 
 	apiObj.Goals.GET("/:userID", authMiddleware(), getGoals)
 	apiObj.Goals.POST("/:userID/nodes/:nodeID", authMiddleware(), addGoal)
+	apiObj.Goals.PUT("/:userID/nodes/:nodeID", authMiddleware(), updateGoalSettings)
 	apiObj.Goals.DELETE("/:userID/nodes/:nodeID", authMiddleware(), deleteGoal)
 }
 
@@ -41,6 +43,53 @@ func addGoal(c *gin.Context) {
 	responseFormat(c, http.StatusCreated, "")
 }
 
+func updateGoalSettings(c *gin.Context) {
+	userID := c.Param("userID")
+	if userID == "" {
+		responseFormat(c, http.StatusBadRequest, "missing user_id")
+		return
+	}
+
+	nodeID := c.Param("nodeID")
+	if nodeID == "" {
+		responseFormat(c, http.StatusBadRequest, "missing node_id")
+		return
+	}
+
+	settings, err := model.GoalSettingsFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "invalid goal settings")
+		return
+	}
+	if err := settings.IsValid(); err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if userID != session.UserID {
+		responseFormat(c, http.StatusBadRequest, "user mismatch")
+		return
+	}
+
+	if err := a.UpdateGoalSettings(userID, nodeID, settings); err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, "")
+}
+
 func deleteGoal(c *gin.Context) {
 	userID := c.Param("userID")
 	if userID == "" {
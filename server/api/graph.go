@@ -11,6 +11,8 @@ func (apiObj *API) initGraph() {
 	apiObj.Nodes = apiObj.APIRoot.Group("/graph")
 
 	apiObj.Nodes.GET("/", splitAuthMiddleware(getMyGraph, getGraph))
+	apiObj.Nodes.GET("/recommendations", authMiddleware(), getRecommendations)
+	apiObj.Nodes.GET("/next", authMiddleware(), getNextTopic)
 }
 
 func getMyGraph(c *gin.Context) {
@@ -44,3 +46,47 @@ func getGraph(c *gin.Context) {
 	gr := a.GetFrontEndGraph(model.LanguageEnglish)
 	responseFormat(c, http.StatusOK, gr)
 }
+
+func getRecommendations(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	recommendations, err := a.GetRecommendations(session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responseFormat(c, http.StatusOK, recommendations)
+}
+
+func getNextTopic(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	recommendation, err := a.GetNextTopic(session.UserID, c.Query("goal_id"))
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responseFormat(c, http.StatusOK, recommendation)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oseducation/knowledge-graph/model"
+)
+
+const defaultLeaderboardSize = 10
+
+func (apiObj *API) initLeaderboard() {
+	apiObj.Leaderboards = apiObj.APIRoot.Group("/leaderboards")
+
+	apiObj.Leaderboards.GET("/", authMiddleware(), getLeaderboard)
+	apiObj.Leaderboards.GET("/friends", authMiddleware(), getFriends)
+	apiObj.Leaderboards.GET("/friend_requests", authMiddleware(), getFriendRequests)
+	apiObj.Leaderboards.POST("/friends/:friendID", authMiddleware(), addFriend)
+	apiObj.Leaderboards.DELETE("/friends/:friendID", authMiddleware(), removeFriend)
+}
+
+func getLeaderboard(c *gin.Context) {
+	scope := c.DefaultQuery("scope", model.LeaderboardScopeGlobal)
+	scopeID := c.Query("scope_id")
+	window := c.DefaultQuery("window", model.LeaderboardWindowWeekly)
+	metric := c.DefaultQuery("metric", model.LeaderboardMetricXP)
+	n, err := strconv.Atoi(c.DefaultQuery("n", strconv.Itoa(defaultLeaderboardSize)))
+	if err != nil || n <= 0 {
+		n = defaultLeaderboardSize
+	}
+	if err := model.IsValidLeaderboardRequest(scope, window, metric); err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	leaderboard, err := a.GetLeaderboard(session.UserID, scope, scopeID, window, metric, n)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, leaderboard)
+}
+
+func getFriends(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	friends, err := a.GetFriends(session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, friends)
+}
+
+func getFriendRequests(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	users, err := a.GetFriendRequests(session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, users)
+}
+
+func addFriend(c *gin.Context) {
+	friendID := c.Param("friendID")
+	if friendID == "" {
+		responseFormat(c, http.StatusBadRequest, "missing friend_id")
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := a.AddFriend(session.UserID, friendID); err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusCreated, "")
+}
+
+func removeFriend(c *gin.Context) {
+	friendID := c.Param("friendID")
+	if friendID == "" {
+		responseFormat(c, http.StatusBadRequest, "missing friend_id")
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := a.RemoveFriend(session.UserID, friendID); err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, "")
+}
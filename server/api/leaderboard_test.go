@@ -0,0 +1,72 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/oseducation/knowledge-graph/functionaltesting"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFriends(t *testing.T) {
+	th := functionaltesting.Setup(t)
+	defer th.TearDown()
+
+	a := th.Server.App
+	user, friend := th.BasicUser.ID, th.AdminUser.ID
+	ids := func(users []*model.User) []string {
+		result := []string{}
+		for _, u := range users {
+			result = append(result, u.ID)
+		}
+		return result
+	}
+
+	t.Run("friend request isn't a friendship", func(t *testing.T) {
+		require.NoError(t, a.AddFriend(user, friend))
+
+		friends, err := a.GetFriends(user)
+		require.NoError(t, err)
+		require.Empty(t, friends)
+
+		requests, err := a.GetFriendRequests(friend)
+		require.NoError(t, err)
+		require.Equal(t, []string{user}, ids(requests))
+
+		requests, err = a.GetFriendRequests(user)
+		require.NoError(t, err)
+		require.Empty(t, requests)
+	})
+
+	t.Run("accepted request is a friendship", func(t *testing.T) {
+		require.NoError(t, a.AddFriend(friend, user))
+
+		for userID, friendID := range map[string]string{user: friend, friend: user} {
+			friends, err := a.GetFriends(userID)
+			require.NoError(t, err)
+			require.Equal(t, []string{friendID}, ids(friends))
+
+			requests, err := a.GetFriendRequests(userID)
+			require.NoError(t, err)
+			require.Empty(t, requests)
+		}
+	})
+
+	t.Run("removed friendship is removed for both", func(t *testing.T) {
+		require.NoError(t, a.RemoveFriend(friend, user))
+
+		for _, userID := range []string{user, friend} {
+			friends, err := a.GetFriends(userID)
+			require.NoError(t, err)
+			require.Empty(t, friends)
+
+			requests, err := a.GetFriendRequests(userID)
+			require.NoError(t, err)
+			require.Empty(t, requests)
+		}
+	})
+
+	t.Run("can't add yourself", func(t *testing.T) {
+		require.Error(t, a.AddFriend(user, user))
+	})
+}
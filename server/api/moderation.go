@@ -0,0 +1,109 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oseducation/knowledge-graph/app"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultModerationFlagsPage    = 0
+	defaultModerationFlagsPerPage = 50
+)
+
+func (apiObj *API) initModeration() {
+	apiObj.Moderation = apiObj.APIRoot.Group("/moderation")
+
+	apiObj.Moderation.GET("/flags", authMiddleware(), requireUserPermissions(), getModerationFlags)
+	apiObj.Moderation.GET("/flags/:flagID", authMiddleware(), requireUserPermissions(), getModerationFlag)
+	apiObj.Moderation.PUT("/flags/:flagID", authMiddleware(), requireUserPermissions(), reviewModerationFlag)
+}
+
+// getModerationFlags returns the review queue, pending flags by default
+func getModerationFlags(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", strconv.Itoa(defaultModerationFlagsPage)))
+	if err != nil || page < 0 {
+		page = defaultModerationFlagsPage
+	}
+	perPage, err := strconv.Atoi(c.DefaultQuery("per_page", strconv.Itoa(defaultModerationFlagsPerPage)))
+	if err != nil || perPage <= 0 {
+		perPage = defaultModerationFlagsPerPage
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	flags, err := a.GetModerationFlags(&model.ModerationFlagGetOptions{
+		Status:    c.DefaultQuery("status", model.ModerationFlagStatusPending),
+		Direction: c.Query("direction"),
+		UserID:    c.Query("user_id"),
+		Page:      page,
+		PerPage:   perPage,
+	})
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, flags)
+}
+
+func getModerationFlag(c *gin.Context) {
+	_, flag, ok := getModerationFlagFromPath(c)
+	if !ok {
+		return
+	}
+	responseFormat(c, http.StatusOK, flag)
+}
+
+func reviewModerationFlag(c *gin.Context) {
+	review, err := model.ModerationFlagFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "Invalid or missing `flag` in the request body")
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	a, flag, ok := getModerationFlagFromPath(c)
+	if !ok {
+		return
+	}
+	review.ID = flag.ID
+
+	flag, err = a.ReviewModerationFlag(review, session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, flag)
+}
+
+func getModerationFlagFromPath(c *gin.Context) (*app.App, *model.ModerationFlag, bool) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+
+	flag, err := a.GetModerationFlag(c.Param("flagID"))
+	if errors.Is(err, sql.ErrNoRows) {
+		responseFormat(c, http.StatusNotFound, "moderation flag not found")
+		return nil, nil, false
+	} else if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+	return a, flag, true
+}
@@ -1,12 +1,14 @@
 package api
 
 import (
+	"database/sql"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -26,6 +28,10 @@ func (apiObj *API) initNode() {
 	apiObj.Nodes.POST("/:nodeID/video/:videoID", authMiddleware(), addVideo)
 
 	apiObj.Nodes.PUT("/:nodeID/status", authMiddleware(), updateNodeStatus)
+
+	apiObj.Nodes.GET("/:nodeID/policy", authMiddleware(), requireNodePermissions(), getCompletionPolicy)
+	apiObj.Nodes.PUT("/:nodeID/policy", authMiddleware(), requireNodePermissions(), saveCompletionPolicy)
+	apiObj.Nodes.DELETE("/:nodeID/policy", authMiddleware(), requireNodePermissions(), deleteCompletionPolicy)
 }
 
 func createNode(c *gin.Context) {
@@ -245,8 +251,90 @@ func updateNodeStatus(c *gin.Context) {
 	}
 
 	if err := a.UpdateStatus(status); err != nil {
+		var unmetErr *model.UnmetRequirementsError
+		if errors.As(err, &unmetErr) {
+			responseFormat(c, http.StatusBadRequest, unmetErr)
+			return
+		}
 		responseFormat(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 	responseFormat(c, http.StatusOK, "status updated")
 }
+
+func getCompletionPolicy(c *gin.Context) {
+	nodeID := c.Param("nodeID")
+	if nodeID == "" {
+		responseFormat(c, http.StatusBadRequest, "missing node_id")
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	policy, err := a.GetCompletionPolicy(nodeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			responseFormat(c, http.StatusNotFound, "no completion policy for the node")
+			return
+		}
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, policy)
+}
+
+func saveCompletionPolicy(c *gin.Context) {
+	nodeID := c.Param("nodeID")
+	if nodeID == "" {
+		responseFormat(c, http.StatusBadRequest, "missing node_id")
+		return
+	}
+
+	policy, err := model.CompletionPolicyFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "Invalid or missing `policy` in the request body")
+		return
+	}
+	policy.NodeID = nodeID
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	rpolicy, err := a.SaveCompletionPolicy(policy)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid completion policy error") {
+			responseFormat(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, rpolicy)
+}
+
+func deleteCompletionPolicy(c *gin.Context) {
+	nodeID := c.Param("nodeID")
+	if nodeID == "" {
+		responseFormat(c, http.StatusBadRequest, "missing node_id")
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := a.DeleteCompletionPolicy(nodeID); err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, "policy deleted")
+}
@@ -1,6 +1,7 @@
 package api_test
 
 import (
+	"encoding/json"
 	"strconv"
 	"testing"
 
@@ -279,3 +280,64 @@ func TestUpdateNodeStatus(t *testing.T) {
 		functionaltesting.CheckBadRequestStatus(t, resp)
 	})
 }
+
+func TestUpdateNodeStatusWithCompletionPolicy(t *testing.T) {
+	th := functionaltesting.Setup(t)
+	defer th.TearDown()
+	node := testNode
+	node.Name = "Node1"
+	node.Description = "Description1"
+	createdNode, resp, err := th.AdminClient.CreateNode(&node)
+	require.NoError(t, err)
+	functionaltesting.CheckCreatedStatus(t, resp)
+
+	_, resp, err = th.AdminClient.SaveCompletionPolicy(&model.CompletionPolicy{
+		NodeID:           createdNode.ID,
+		MinVideosWatched: 1,
+	})
+	require.NoError(t, err)
+	functionaltesting.CheckOKStatus(t, resp)
+
+	status := model.NodeStatusForUser{
+		NodeID: createdNode.ID,
+		UserID: th.AdminUser.ID,
+		Status: "finished",
+	}
+
+	t.Run("can't finish node without meeting completion policy", func(t *testing.T) {
+		resp, err := th.AdminClient.UpdateNodeStatus(createdNode.ID, &status)
+		require.Error(t, err)
+		functionaltesting.CheckBadRequestStatus(t, resp)
+
+		var unmetErr model.UnmetRequirementsError
+		require.NoError(t, json.Unmarshal([]byte(err.Error()), &unmetErr))
+		require.Equal(t, createdNode.ID, unmetErr.NodeID)
+		require.Equal(t, []model.UnmetRequirement{{
+			Requirement: model.RequirementVideosWatched,
+			Required:    1,
+			Actual:      0,
+		}}, unmetErr.Requirements)
+
+		nodeWithResources, _, err := th.AdminClient.GetNode(createdNode.ID)
+		require.NoError(t, err)
+		require.NotEqual(t, "finished", nodeWithResources.Status)
+	})
+
+	t.Run("can start node without meeting completion policy", func(t *testing.T) {
+		started := status
+		started.Status = "started"
+		resp, err := th.AdminClient.UpdateNodeStatus(createdNode.ID, &started)
+		require.NoError(t, err)
+		functionaltesting.CheckOKStatus(t, resp)
+	})
+
+	t.Run("can finish node after completion policy is removed", func(t *testing.T) {
+		resp, err := th.AdminClient.DeleteCompletionPolicy(createdNode.ID)
+		require.NoError(t, err)
+		functionaltesting.CheckOKStatus(t, resp)
+
+		resp, err = th.AdminClient.UpdateNodeStatus(createdNode.ID, &status)
+		require.NoError(t, err)
+		functionaltesting.CheckOKStatus(t, resp)
+	})
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (apiObj *API) initPlan() {
+	apiObj.Plan = apiObj.APIRoot.Group("/plan")
+
+	apiObj.Plan.GET("/today", authMiddleware(), getTodayPlan)
+}
+
+func getTodayPlan(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	plan, today, err := a.GetTodayPlan(session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responseFormat(c, http.StatusOK, map[string]interface{}{
+		"status": plan.Status,
+		"today":  today,
+		"days":   plan.Days,
+	})
+}
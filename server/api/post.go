@@ -25,6 +25,11 @@ func createPost(c *gin.Context) {
 		return
 	}
 
+	if model.IsStudyGroupLocationID(post.LocationID) {
+		responseFormat(c, http.StatusForbidden, "post to the study group feed instead")
+		return
+	}
+
 	a, err := getApp(c)
 	if err != nil {
 		responseFormat(c, http.StatusInternalServerError, err.Error())
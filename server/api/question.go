@@ -1,9 +1,22 @@
 package api
 
 import (
+	"database/sql"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/oseducation/knowledge-graph/app"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultQuestionStatsPage    = 0
+	defaultQuestionStatsPerPage = 50
+
+	defaultQuestionReviewPage    = 0
+	defaultQuestionReviewPerPage = 50
 )
 
 func (apiObj *API) initQuestion() {
@@ -11,6 +24,15 @@ func (apiObj *API) initQuestion() {
 
 	apiObj.Questions.GET("/:questionID", authMiddleware(), getQuestion)
 	apiObj.Questions.GET("/onboarding/:courseID", getOnboardingQuestions)
+	apiObj.Questions.POST("/:questionID/answer", authMiddleware(), answerQuestion)
+	apiObj.Questions.GET("/stats", authMiddleware(), requireNodePermissions(), getQuestionStats)
+	apiObj.Questions.POST("/stats", authMiddleware(), requireNodePermissions(), computeQuestionStats)
+	apiObj.Questions.GET("/:questionID/stats", authMiddleware(), requireNodePermissions(), getStatsForQuestion)
+	apiObj.Questions.POST("/generate", authMiddleware(), requireNodePermissions(), generateQuestions)
+	apiObj.Questions.GET("/review", authMiddleware(), requireNodePermissions(), getQuestionsForReview)
+	apiObj.Questions.PUT("/:questionID", authMiddleware(), requireNodePermissions(), updateQuestion)
+	apiObj.Questions.POST("/:questionID/approve", authMiddleware(), requireNodePermissions(), reviewQuestion(model.QuestionStatusApproved))
+	apiObj.Questions.POST("/:questionID/reject", authMiddleware(), requireNodePermissions(), reviewQuestion(model.QuestionStatusRejected))
 }
 
 func getQuestion(c *gin.Context) {
@@ -59,3 +81,220 @@ func getOnboardingQuestions(c *gin.Context) {
 	}
 	responseFormat(c, http.StatusOK, questions)
 }
+
+func answerQuestion(c *gin.Context) {
+	questionID := c.Param("questionID")
+	if questionID == "" {
+		responseFormat(c, http.StatusBadRequest, "missing question_id")
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	answer, err := model.QuestionAnswerFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "Invalid or missing `answer` in the request body")
+		return
+	}
+
+	ranswer, err := a.AnswerQuestion(session.UserID, questionID, answer.ChoiceID, answer.TimeSpent)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, ranswer)
+}
+
+func getQuestionStats(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", strconv.Itoa(defaultQuestionStatsPage)))
+	if err != nil || page < 0 {
+		page = defaultQuestionStatsPage
+	}
+	perPage, err := strconv.Atoi(c.DefaultQuery("per_page", strconv.Itoa(defaultQuestionStatsPerPage)))
+	if err != nil || perPage <= 0 {
+		perPage = defaultQuestionStatsPerPage
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	stats, err := a.GetQuestionStats(&model.QuestionStatsGetOptions{
+		NodeID:      c.Query("node_id"),
+		FlaggedOnly: c.Query("flagged") == "true",
+		Page:        page,
+		PerPage:     perPage,
+	})
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, stats)
+}
+
+func computeQuestionStats(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	stats, err := a.ComputeQuestionStats()
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	flagged := 0
+	for _, s := range stats {
+		if s.Flagged {
+			flagged++
+		}
+	}
+	responseFormat(c, http.StatusOK, map[string]interface{}{
+		"questions": len(stats),
+		"flagged":   flagged,
+	})
+}
+
+func getStatsForQuestion(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	stats, err := a.GetStatsForQuestion(c.Param("questionID"))
+	if errors.Is(err, sql.ErrNoRows) {
+		responseFormat(c, http.StatusNotFound, "stats not computed for the question")
+		return
+	} else if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, stats)
+}
+
+func generateQuestions(c *gin.Context) {
+	nodeID := c.Query("node_id")
+	if nodeID == "" {
+		responseFormat(c, http.StatusBadRequest, "missing node_id")
+		return
+	}
+	count, err := strconv.Atoi(c.DefaultQuery("count", strconv.Itoa(app.DefaultNumberOfGeneratedQuestions)))
+	if err != nil || count <= 0 || count > app.MaxNumberOfGeneratedQuestions {
+		responseFormat(c, http.StatusBadRequest, "invalid count")
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	questions, err := a.GenerateQuestions(nodeID, session.UserID, count, c.Query("exam_only") == "true")
+	if errors.Is(err, sql.ErrNoRows) {
+		responseFormat(c, http.StatusNotFound, "node not found")
+		return
+	} else if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusCreated, questions)
+}
+
+func getQuestionsForReview(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", strconv.Itoa(defaultQuestionReviewPage)))
+	if err != nil || page < 0 {
+		page = defaultQuestionReviewPage
+	}
+	perPage, err := strconv.Atoi(c.DefaultQuery("per_page", strconv.Itoa(defaultQuestionReviewPerPage)))
+	if err != nil || perPage <= 0 {
+		perPage = defaultQuestionReviewPerPage
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	options := &model.QuestionGetOptions{}
+	model.ComposeQuestionOptions(
+		model.QuestionNodeID(c.Query("node_id")),
+		model.QuestionStatus(c.DefaultQuery("status", model.QuestionStatusDraft)),
+		model.QuestionExamOnly(c.Query("exam_only") == "true"),
+		model.QuestionPage(page),
+		model.QuestionPerPage(perPage),
+	)(options)
+	questions, err := a.GetQuestionsForReview(options)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, questions)
+}
+
+func updateQuestion(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	question, err := model.QuestionFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "Invalid or missing `question` in the request body")
+		return
+	}
+	question.ID = c.Param("questionID")
+
+	updated, err := a.UpdateQuestion(question)
+	if errors.Is(err, sql.ErrNoRows) {
+		responseFormat(c, http.StatusNotFound, "question not found")
+		return
+	} else if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, updated)
+}
+
+func reviewQuestion(status string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		a, err := getApp(c)
+		if err != nil {
+			responseFormat(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		question, err := a.ReviewQuestion(c.Param("questionID"), status)
+		if errors.Is(err, sql.ErrNoRows) {
+			responseFormat(c, http.StatusNotFound, "question not found")
+			return
+		} else if err != nil {
+			responseFormat(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		responseFormat(c, http.StatusOK, question)
+	}
+}
@@ -0,0 +1,86 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/oseducation/knowledge-graph/functionaltesting"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNodeQuestions(t *testing.T) {
+	th := functionaltesting.Setup(t)
+	defer th.TearDown()
+
+	node := testNode
+	createdNode, _, err := th.AdminClient.CreateNode(&node)
+	require.NoError(t, err)
+
+	saveQuestion := func(name, status string, examOnly bool) *model.Question {
+		question, err := th.Server.App.Store.Question().Save(&model.Question{
+			Name:         name,
+			Question:     name + "?",
+			QuestionType: model.QuestionTypeMultipleChoice,
+			NodeID:       createdNode.ID,
+			Explanation:  "explanation",
+			Status:       status,
+			ExamOnly:     examOnly,
+			Choices: []model.QuestionChoice{
+				{Choice: "right", IsRightChoice: true},
+				{Choice: "wrong"},
+			},
+		})
+		require.NoError(t, err)
+		return question
+	}
+	approved := saveQuestion("approved", model.QuestionStatusApproved, false)
+	legacy := saveQuestion("legacy", "", false)
+	saveQuestion("draft", model.QuestionStatusDraft, false)
+	saveQuestion("rejected", model.QuestionStatusRejected, false)
+	exam := saveQuestion("exam", model.QuestionStatusApproved, true)
+
+	t.Run("learners get only approved questions", func(t *testing.T) {
+		nodeWithResources, resp, err := th.UserClient.GetNode(createdNode.ID)
+		require.NoError(t, err)
+		functionaltesting.CheckOKStatus(t, resp)
+
+		ids := []string{}
+		for _, question := range nodeWithResources.Questions {
+			require.Equal(t, model.QuestionStatusApproved, question.Status)
+			ids = append(ids, question.ID)
+		}
+		require.ElementsMatch(t, []string{approved.ID, legacy.ID}, ids)
+	})
+
+	t.Run("questions are filtered by the status", func(t *testing.T) {
+		for _, status := range []string{model.QuestionStatusDraft, model.QuestionStatusRejected} {
+			questions, err := th.Server.App.Store.Question().GetQuestions(&model.QuestionGetOptions{NodeID: createdNode.ID, Status: status})
+			require.NoError(t, err)
+			require.Len(t, questions, 1)
+			require.Equal(t, status, questions[0].Status)
+			require.Equal(t, status, questions[0].Name)
+		}
+	})
+
+	t.Run("exam questions are kept apart from the practice ones", func(t *testing.T) {
+		questions, err := th.Server.App.Store.Question().GetQuestions(&model.QuestionGetOptions{NodeID: createdNode.ID, ExamOnly: true})
+		require.NoError(t, err)
+		require.Len(t, questions, 1)
+		require.Equal(t, exam.ID, questions[0].ID)
+
+		_, err = th.Server.App.GetQuestion(exam.ID)
+		require.Error(t, err)
+		examQuestion, err := th.Server.App.Store.Question().Get(exam.ID)
+		require.NoError(t, err)
+		_, err = th.Server.App.AnswerQuestion(model.NewID(), exam.ID, examQuestion.Choices[0].ID, 0)
+		require.Error(t, err)
+
+		question, err := th.Server.App.GetQuestion(approved.ID)
+		require.NoError(t, err)
+		for _, choice := range question.Choices {
+			answer, err := th.Server.App.AnswerQuestion(model.NewID(), question.ID, choice.ID, 0)
+			require.NoError(t, err)
+			require.Equal(t, choice.IsRightChoice, answer.IsRight)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oseducation/knowledge-graph/app"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultQuizPage    = 0
+	defaultQuizPerPage = 20
+)
+
+func (apiObj *API) initQuiz() {
+	apiObj.Quizzes = apiObj.APIRoot.Group("/quizzes")
+
+	apiObj.Quizzes.GET("/", authMiddleware(), getReviewQuizzes)
+	apiObj.Quizzes.POST("/", authMiddleware(), createReviewQuiz)
+	apiObj.Quizzes.GET("/:quizID", authMiddleware(), getReviewQuiz)
+	apiObj.Quizzes.POST("/:quizID/answers", authMiddleware(), answerQuizQuestion)
+}
+
+func getReviewQuizzes(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", strconv.Itoa(defaultQuizPage)))
+	if err != nil || page < 0 {
+		page = defaultQuizPage
+	}
+	perPage, err := strconv.Atoi(c.DefaultQuery("per_page", strconv.Itoa(defaultQuizPerPage)))
+	if err != nil || perPage <= 0 {
+		perPage = defaultQuizPerPage
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	quizzes, err := a.GetReviewQuizzes(session.UserID, page, perPage)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, quizzes)
+}
+
+func createReviewQuiz(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	quiz, err := a.CreateReviewQuiz(session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusCreated, quiz)
+}
+
+func getReviewQuiz(c *gin.Context) {
+	_, quiz, ok := getMyReviewQuiz(c)
+	if !ok {
+		return
+	}
+	responseFormat(c, http.StatusOK, quiz)
+}
+
+func answerQuizQuestion(c *gin.Context) {
+	answer, err := model.QuizAnswerFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "invalid quiz answer")
+		return
+	}
+
+	a, quiz, ok := getMyReviewQuiz(c)
+	if !ok {
+		return
+	}
+
+	result, err := a.AnswerQuizQuestion(quiz, answer.QuestionID, answer.ChoiceID)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, result)
+}
+
+// getMyReviewQuiz gets the quiz from the path and checks that it belongs to the user.
+// It writes the error response and returns false if the quiz can't be accessed.
+func getMyReviewQuiz(c *gin.Context) (*app.App, *model.ReviewQuiz, bool) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+
+	quiz, err := a.GetReviewQuiz(c.Param("quizID"))
+	if errors.Is(err, sql.ErrNoRows) {
+		responseFormat(c, http.StatusNotFound, "quiz not found")
+		return nil, nil, false
+	} else if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+
+	if quiz.UserID != session.UserID {
+		responseFormat(c, http.StatusNotFound, "quiz not found")
+		return nil, nil, false
+	}
+	return a, quiz, true
+}
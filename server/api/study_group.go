@@ -0,0 +1,261 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oseducation/knowledge-graph/app"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+func (apiObj *API) initStudyGroup() {
+	apiObj.StudyGroups = apiObj.APIRoot.Group("/groups")
+
+	apiObj.StudyGroups.GET("/", authMiddleware(), getStudyGroups)
+	apiObj.StudyGroups.POST("/", authMiddleware(), createStudyGroup)
+	apiObj.StudyGroups.GET("/my", authMiddleware(), getMyStudyGroups)
+	apiObj.StudyGroups.GET("/:groupID", authMiddleware(), getStudyGroup)
+	apiObj.StudyGroups.DELETE("/:groupID", authMiddleware(), deleteStudyGroup)
+	apiObj.StudyGroups.POST("/:groupID/join", authMiddleware(), joinStudyGroup)
+	apiObj.StudyGroups.POST("/:groupID/leave", authMiddleware(), leaveStudyGroup)
+	apiObj.StudyGroups.GET("/:groupID/posts", authMiddleware(), getStudyGroupPosts)
+	apiObj.StudyGroups.POST("/:groupID/posts", authMiddleware(), createStudyGroupPost)
+	apiObj.StudyGroups.GET("/:groupID/progress", authMiddleware(), getStudyGroupProgress)
+	apiObj.StudyGroups.GET("/:groupID/presence", authMiddleware(), getStudyGroupPresence)
+}
+
+func getStudyGroups(c *gin.Context) {
+	goalNodeID := c.Query("goal_node_id")
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	groups, err := a.GetStudyGroups(goalNodeID)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, groups)
+}
+
+func createStudyGroup(c *gin.Context) {
+	group, err := model.StudyGroupFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "invalid study group")
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	group.ID = ""
+	group.CreatorID = session.UserID
+	group, err = a.CreateStudyGroup(group)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusCreated, group)
+}
+
+func getMyStudyGroups(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	groups, err := a.GetUserStudyGroups(session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, groups)
+}
+
+func getStudyGroup(c *gin.Context) {
+	_, _, group, ok := getStudyGroupFromPath(c)
+	if !ok {
+		return
+	}
+	responseFormat(c, http.StatusOK, group)
+}
+
+func deleteStudyGroup(c *gin.Context) {
+	a, session, group, ok := getStudyGroupFromPath(c)
+	if !ok {
+		return
+	}
+
+	if group.CreatorID != session.UserID && !session.CanManageUsers() {
+		responseFormat(c, http.StatusForbidden, "No permission for this action")
+		return
+	}
+
+	if err := a.DeleteStudyGroup(group.ID); err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, "deleted")
+}
+
+func joinStudyGroup(c *gin.Context) {
+	a, session, group, ok := getStudyGroupFromPath(c)
+	if !ok {
+		return
+	}
+
+	if err := a.JoinStudyGroup(group, session.UserID); err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, "joined")
+}
+
+func leaveStudyGroup(c *gin.Context) {
+	a, session, group, ok := getStudyGroupFromPath(c)
+	if !ok {
+		return
+	}
+
+	if err := a.LeaveStudyGroup(group.ID, session.UserID); err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, "left")
+}
+
+func getStudyGroupPosts(c *gin.Context) {
+	a, group, ok := getMemberStudyGroup(c)
+	if !ok {
+		return
+	}
+
+	posts, err := a.GetStudyGroupPosts(group)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, posts)
+}
+
+func createStudyGroupPost(c *gin.Context) {
+	post, err := model.PostFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "Invalid or missing `post` in the request body")
+		return
+	}
+
+	a, group, ok := getMemberStudyGroup(c)
+	if !ok {
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	rpost, err := a.CreateStudyGroupPost(group, session.UserID, post.Message)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, "Error while creating post")
+		a.Log.Error(err.Error())
+		return
+	}
+	responseFormat(c, http.StatusCreated, rpost)
+}
+
+func getStudyGroupProgress(c *gin.Context) {
+	a, group, ok := getMemberStudyGroup(c)
+	if !ok {
+		return
+	}
+
+	progress, err := a.GetStudyGroupProgress(group)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, progress)
+}
+
+func getStudyGroupPresence(c *gin.Context) {
+	a, group, ok := getMemberStudyGroup(c)
+	if !ok {
+		return
+	}
+
+	users, err := a.GetStudyingNow(group)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, users)
+}
+
+// getStudyGroupFromPath gets the study group from the path.
+// It writes the error response and returns false if the group can't be found.
+func getStudyGroupFromPath(c *gin.Context) (*app.App, *model.Session, *model.StudyGroup, bool) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, nil, false
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, nil, false
+	}
+
+	group, err := a.GetStudyGroup(c.Param("groupID"))
+	if errors.Is(err, sql.ErrNoRows) {
+		responseFormat(c, http.StatusNotFound, "study group not found")
+		return nil, nil, nil, false
+	} else if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, nil, false
+	}
+	return a, session, group, true
+}
+
+// getMemberStudyGroup gets the study group from the path and checks that the user is its member.
+// It writes the error response and returns false if the user can't access the group.
+func getMemberStudyGroup(c *gin.Context) (*app.App, *model.StudyGroup, bool) {
+	a, session, group, ok := getStudyGroupFromPath(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	isMember, err := a.IsStudyGroupMember(group.ID, session.UserID)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+	if !isMember {
+		responseFormat(c, http.StatusForbidden, "join the study group first")
+		return nil, nil, false
+	}
+	return a, group, true
+}
@@ -1,18 +1,140 @@
 package api
 
 import (
+	"database/sql"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/oseducation/knowledge-graph/app"
 	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
 )
 
 func (apiObj *API) initTutorPersonality() {
 	apiObj.TutorPersonalities = apiObj.APIRoot.Group("/tutor-personalities")
 
 	apiObj.TutorPersonalities.GET("", authMiddleware(), getTutorPersonalities)
+	apiObj.TutorPersonalities.GET("/all", authMiddleware(), requireNodePermissions(), getAllTutorPersonalities)
+	apiObj.TutorPersonalities.POST("", authMiddleware(), requireNodePermissions(), createTutorPersonality)
+	apiObj.TutorPersonalities.GET("/:personalityID", authMiddleware(), requireNodePermissions(), getTutorPersonality)
+	apiObj.TutorPersonalities.PUT("/:personalityID", authMiddleware(), requireNodePermissions(), updateTutorPersonality)
+	apiObj.TutorPersonalities.DELETE("/:personalityID", authMiddleware(), requireNodePermissions(), deleteTutorPersonality)
 }
 
+// getTutorPersonalities returns the enabled personalities available to the caller's plan
 func getTutorPersonalities(c *gin.Context) {
-	responseFormat(c, http.StatusOK, model.DefaultTutorPersonalities)
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	session, err := getSession(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	personalities, err := a.GetTutorPersonalities(session.Role)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, personalities)
+}
+
+func getAllTutorPersonalities(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	personalities, err := a.GetAllTutorPersonalities()
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, personalities)
+}
+
+func createTutorPersonality(c *gin.Context) {
+	personality, err := model.TutorPersonalityFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "Invalid or missing `personality` in the request body")
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	personality, err = a.CreateTutorPersonality(personality)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusCreated, personality)
+}
+
+func getTutorPersonality(c *gin.Context) {
+	_, personality, ok := getTutorPersonalityFromPath(c)
+	if !ok {
+		return
+	}
+	responseFormat(c, http.StatusOK, personality)
+}
+
+func updateTutorPersonality(c *gin.Context) {
+	updated, err := model.TutorPersonalityFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "Invalid or missing `personality` in the request body")
+		return
+	}
+
+	a, personality, ok := getTutorPersonalityFromPath(c)
+	if !ok {
+		return
+	}
+	updated.ID = personality.ID
+
+	personality, err = a.UpdateTutorPersonality(updated)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, personality)
+}
+
+func deleteTutorPersonality(c *gin.Context) {
+	a, personality, ok := getTutorPersonalityFromPath(c)
+	if !ok {
+		return
+	}
+
+	if err := a.DeleteTutorPersonality(personality.ID); err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, "tutor personality deleted")
+}
+
+func getTutorPersonalityFromPath(c *gin.Context) (*app.App, *model.TutorPersonality, bool) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+
+	personality, err := a.GetTutorPersonality(c.Param("personalityID"))
+	if errors.Is(err, sql.ErrNoRows) {
+		responseFormat(c, http.StatusNotFound, "tutor personality not found")
+		return nil, nil, false
+	} else if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+	return a, personality, true
 }
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/oseducation/knowledge-graph/log"
@@ -14,6 +15,9 @@ import (
 const (
 	defaultUserPage    = -1
 	defaultUserPerPage = -1
+
+	defaultLLMUsagePage    = 0
+	defaultLLMUsagePerPage = 50
 )
 
 const (
@@ -41,6 +45,7 @@ func (apiObj *API) initUser() {
 	apiObj.Users.PUT("/", authMiddleware(), requireUserPermissions(), updateUser)
 	apiObj.Users.DELETE("/", authMiddleware(), requireUserPermissions(), deleteUser)
 	apiObj.Users.GET("/graph", authMiddleware(), requireUserPermissions(), getUserGraph)
+	apiObj.Users.GET("/llm_usage", authMiddleware(), requireUserPermissions(), getLLMUsageReport)
 
 	apiObj.Users.GET("/code", authMiddleware(), getMyCodes)
 	apiObj.Users.PUT("/code", authMiddleware(), updateMyCode)
@@ -561,3 +566,48 @@ func resetPassword(c *gin.Context) {
 
 	responseFormat(c, http.StatusOK, "password was reset")
 }
+
+// getLLMUsageReport returns usage of the language model per user and per model, the current month by default
+func getLLMUsageReport(c *gin.Context) {
+	since, err := strconv.ParseInt(c.DefaultQuery("since", "0"), 10, 64)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	until, err := strconv.ParseInt(c.DefaultQuery("until", "0"), 10, 64)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if since == 0 && until == 0 {
+		now := time.Now()
+		since = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).UnixMilli()
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", strconv.Itoa(defaultLLMUsagePage)))
+	if err != nil || page < 0 {
+		page = defaultLLMUsagePage
+	}
+	perPage, err := strconv.Atoi(c.DefaultQuery("per_page", strconv.Itoa(defaultLLMUsagePerPage)))
+	if err != nil || perPage <= 0 {
+		perPage = defaultLLMUsagePerPage
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	report, err := a.GetLLMUsageReport(&model.LLMUsageGetOptions{
+		UserID:  c.Query("user_id"),
+		Since:   since,
+		Until:   until,
+		Page:    page,
+		PerPage: perPage,
+	})
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, report)
+}
@@ -13,6 +13,8 @@ func (apiObj *API) initVideo() {
 	apiObj.Videos.GET("/next", authMiddleware(), getNextVideo)
 	apiObj.Videos.GET("/view/:videoID", authMiddleware(), getVideo)
 	apiObj.Videos.POST("/engage/:videoID", authMiddleware(), addUserEngagement)
+	apiObj.Videos.GET("/:videoID/transcript", authMiddleware(), requireNodePermissions(), getTranscript)
+	apiObj.Videos.PUT("/:videoID/transcript", authMiddleware(), requireNodePermissions(), saveTranscript)
 }
 
 func getVideo(c *gin.Context) {
@@ -94,3 +96,44 @@ func getNextVideo(c *gin.Context) {
 
 	responseFormat(c, http.StatusOK, videoID)
 }
+
+func getTranscript(c *gin.Context) {
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	segments, err := a.GetTranscript(c.Param("videoID"))
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, segments)
+}
+
+func saveTranscript(c *gin.Context) {
+	segments, err := model.TranscriptFromJSON(c.Request.Body)
+	if err != nil {
+		responseFormat(c, http.StatusBadRequest, "Invalid or missing transcript in the request body")
+		return
+	}
+
+	a, err := getApp(c)
+	if err != nil {
+		responseFormat(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	videoID := c.Param("videoID")
+	if _, err := a.GetVideo(videoID); err != nil {
+		responseFormat(c, http.StatusNotFound, "video not found")
+		return
+	}
+
+	if err := a.SaveTranscript(videoID, segments); err != nil {
+		responseFormat(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responseFormat(c, http.StatusOK, segments)
+}
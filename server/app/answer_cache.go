@@ -0,0 +1,166 @@
+package app
+
+import (
+	"io"
+	"strings"
+
+	"github.com/oseducation/knowledge-graph/log"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/oseducation/knowledge-graph/services"
+	"github.com/pkg/errors"
+)
+
+// answerCachingStream saves the streamed answer to the cache when the stream is finished
+type answerCachingStream struct {
+	services.ChatStream
+
+	app       *App
+	key       *model.CachedAnswer
+	citations []*model.Citation
+	answer    strings.Builder
+	saved     bool
+}
+
+// getAnswerCacheKey returns the cache key of the question: node, tutor personality, language, LLM tier and the question's embedding.
+// Returns nil if the cache is disabled or embeddings aren't configured.
+func (a *App) getAnswerCacheKey(message, nodeID, userID string, tier services.LLMTier) *model.CachedAnswer {
+	if a.Config.ChatSettings.AnswerCacheSimilarity <= 0 {
+		return nil
+	}
+	user, err := a.Store.User().Get(userID)
+	if err != nil {
+		a.Log.Error("can't get user for the answer cache", log.String("userID", userID), log.Err(err))
+		return nil
+	}
+	vector, err := a.Services.LLMService.GetEmbedding(message, userID)
+	if err != nil {
+		a.Log.Error("can't get embedding for the answer cache", log.Err(err))
+		return nil
+	}
+	if len(vector) == 0 {
+		return nil
+	}
+	return &model.CachedAnswer{
+		NodeID:           nodeID,
+		TutorPersonality: a.getTutorPersonality(user).ID,
+		Language:         user.Lang,
+		Tier:             string(tier),
+		Question:         message,
+		Vector:           vector,
+	}
+}
+
+// getCachedAnswer returns the cached answer to the most similar question, nil if there is none.
+// Answers cached before the node's content was changed are expired unless they are pinned.
+func (a *App) getCachedAnswer(key *model.CachedAnswer) *model.CachedAnswer {
+	if key == nil {
+		return nil
+	}
+	cached, err := a.Store.AnswerCache().FindSimilar(key.Vector, key.NodeID, key.TutorPersonality, key.Language, key.Tier, a.Config.ChatSettings.AnswerCacheSimilarity)
+	if err != nil {
+		a.Log.Error("can't search the answer cache", log.Err(err))
+		return nil
+	}
+	if cached == nil {
+		return nil
+	}
+
+	if !cached.Pinned {
+		contentUpdatedAt, err := a.Store.Node().GetContentUpdatedAt(key.NodeID)
+		if err != nil {
+			a.Log.Error("can't get content update time", log.String("nodeID", key.NodeID), log.Err(err))
+			return nil
+		}
+		if contentUpdatedAt > cached.CreatedAt {
+			if err := a.Store.AnswerCache().DeleteExpired(key.NodeID, contentUpdatedAt); err != nil {
+				a.Log.Error("can't delete expired answers", log.String("nodeID", key.NodeID), log.Err(err))
+				return nil
+			}
+			// a pinned or a fresh answer may still match
+			return a.getCachedAnswer(key)
+		}
+	}
+
+	if err := a.Store.AnswerCache().IncrementHits(cached.ID); err != nil {
+		a.Log.Error("can't count the cache hit", log.String("cachedAnswerID", cached.ID), log.Err(err))
+	}
+	return cached
+}
+
+func (a *App) cacheAnswer(key *model.CachedAnswer, answer string, citations []*model.Citation) {
+	if key == nil || answer == "" {
+		return
+	}
+	key.Answer = answer
+	key.Citations = citations
+	if _, err := a.Store.AnswerCache().Save(key); err != nil {
+		a.Log.Error("can't cache the answer", log.String("nodeID", key.NodeID), log.Err(err))
+	}
+}
+
+func (a *App) cacheAnswerStream(stream services.ChatStream, key *model.CachedAnswer, citations []*model.Citation) services.ChatStream {
+	if key == nil {
+		return stream
+	}
+	return &answerCachingStream{
+		ChatStream: stream,
+		app:        a,
+		key:        key,
+		citations:  citations,
+	}
+}
+
+// Recv caches the answer only if the stream finished successfully and the answer wasn't flagged by moderation
+func (s *answerCachingStream) Recv() (string, error) {
+	text, err := s.ChatStream.Recv()
+	s.answer.WriteString(text)
+	if errors.Is(err, io.EOF) && !s.saved {
+		s.saved = true
+		if moderated, ok := s.ChatStream.(*moderatedStream); ok && moderated.flagged {
+			return text, err
+		}
+		s.app.cacheAnswer(s.key, s.answer.String(), s.citations)
+	}
+	return text, err
+}
+
+// GetCachedAnswers returns cached answers for admins
+func (a *App) GetCachedAnswers(options *model.CachedAnswerGetOptions) ([]*model.CachedAnswer, error) {
+	answers, err := a.Store.AnswerCache().GetAnswers(options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "options = %v", options)
+	}
+	return answers, nil
+}
+
+// GetCachedAnswer gets the cached answer by id
+func (a *App) GetCachedAnswer(id string) (*model.CachedAnswer, error) {
+	return a.Store.AnswerCache().Get(id)
+}
+
+// UpdateCachedAnswer edits the answer, its citations and pinning of the cached answer
+func (a *App) UpdateCachedAnswer(updated *model.CachedAnswer) (*model.CachedAnswer, error) {
+	cached, err := a.Store.AnswerCache().Get(updated.ID)
+	if err != nil {
+		return nil, err
+	}
+	cached.Answer = updated.Answer
+	cached.Pinned = updated.Pinned
+	if updated.Citations != nil {
+		cached.Citations = updated.Citations
+	}
+	if err := a.Store.AnswerCache().Update(cached); err != nil {
+		return nil, err
+	}
+	return cached, nil
+}
+
+// DeleteCachedAnswer invalidates the cached answer
+func (a *App) DeleteCachedAnswer(id string) error {
+	return a.Store.AnswerCache().Delete(id)
+}
+
+// InvalidateAnswerCache invalidates the cached answers of the node, pinned answers are kept unless withPinned is set
+func (a *App) InvalidateAnswerCache(nodeID string, withPinned bool) error {
+	return a.Store.AnswerCache().DeleteForNode(nodeID, withPinned)
+}
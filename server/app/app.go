@@ -22,6 +22,8 @@ type App struct {
 	Graph    *model.Graph
 	Services *services.Services
 	Random   *rand.Rand
+	// Achievements are the badges users can unlock
+	Achievements []*model.Achievement
 }
 
 // NewApp creates new App
@@ -32,14 +34,23 @@ func NewApp(logger *log.Logger, store store.Store, config *config.Config) (*App,
 	}
 	logger.Info("graph constructed", log.String("nodes", strconv.Itoa(len(graph.Nodes))), log.String("prerequisites", strconv.Itoa(len(graph.Prerequisites))))
 
-	services, err := services.NewServices(store, config.EmailSettings, logger)
+	services, err := services.NewServices(store, config.EmailSettings, config.LLMSettings, config.ModerationSettings, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "can't create services")
 	}
 
+	if config.ChatSettings.ChatGPTMonthlyLimit > 0 {
+		logger.Warn("ChatSettings.ChatGPTMonthlyLimit isn't enforced anymore, the language model usage is limited by ChatSettings.Budgets and DefaultBudget")
+	}
+
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 
-	return &App{logger, store, config, graph, services, r}, nil
+	achievements, err := loadAchievements()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't read achievements")
+	}
+
+	return &App{logger, store, config, graph, services, r, achievements}, nil
 }
 
 // GetSiteURL returns site url from config
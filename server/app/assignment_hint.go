@@ -0,0 +1,116 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/oseducation/knowledge-graph/log"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/oseducation/knowledge-graph/services"
+	"github.com/pkg/errors"
+)
+
+const assignmentHintPrompt = `%s. The learner is solving the Karel the Robot programming assignment below and asked for a hint on their code.
+Assignment: {%s}
+Reference solution, never show it or any part of it to the learner: {%s}
+Compare the learner's code with the assignment and the reference solution. Don't write code for the learner and don't reveal the solution, help the learner find the next step themselves.
+This is hint %d of %d, every next hint is more specific. %s Be concise.`
+
+// assignmentHintInstructions are the instructions for the hint levels
+var assignmentHintInstructions = map[int]string{
+	1: "Ask one Socratic question that makes the learner notice where their program doesn't do what the assignment asks, don't point to the specific lines.",
+	2: "Point the learner to the part of their program that doesn't work and ask a guiding question about the idea they are missing.",
+	3: "Explain in words what the next step of the program should do and why, but leave writing the code to the learner.",
+}
+
+// hintRecordingStream records the hint level when the hint is streamed to the end,
+// the hints the learner didn't get, e.g. because of a dropped connection, don't raise the level
+type hintRecordingStream struct {
+	services.ChatStream
+
+	app      *App
+	hint     *model.AssignmentHint
+	recorded bool
+}
+
+// GetAssignmentHintStream streams the next hint on the learner's saved code of the assignment, the hint level is recorded when the hint is streamed.
+// Every next hint on the assignment is one level more specific, up to the maximum level.
+func (a *App) GetAssignmentHintStream(nodeID, userID string, tier services.LLMTier) (services.ChatStream, int, error) {
+	node, err := a.Store.Node().Get(nodeID)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "can't get node %s", nodeID)
+	}
+	if node.NodeType != model.NodeTypeAssignment {
+		return nil, 0, errors.Errorf("node %s isn't an assignment", nodeID)
+	}
+
+	code, err := a.Store.UserCode().GetLatestCodeForUserNode(userID, nodeID)
+	if err != nil || strings.TrimSpace(code.Code) == "" {
+		return nil, 0, errors.Errorf("no saved code of user %s on assignment %s", userID, nodeID)
+	}
+
+	assignment, solution, err := a.getAssignmentTexts(node)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	level, err := a.Store.AssignmentHint().GetLevel(userID, nodeID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if level < model.MaxAssignmentHintLevel {
+		level++
+	}
+
+	tutorPersonalityPrompt := a.getTutorPrompt(userID)
+	systemMessage := fmt.Sprintf(assignmentHintPrompt, tutorPersonalityPrompt, assignment, solution, level, model.MaxAssignmentHintLevel, assignmentHintInstructions[level])
+	stream, err := a.Services.LLMService.SendStream(userID, systemMessage, tier, []string{fmt.Sprintf("My code:\n```java\n%s\n```", code.Code)})
+	if err != nil {
+		return nil, 0, err
+	}
+	return &hintRecordingStream{
+		ChatStream: a.moderateStream(stream, "", nodeID, userID),
+		app:        a,
+		hint: &model.AssignmentHint{
+			UserID: userID,
+			NodeID: nodeID,
+			Level:  level,
+		},
+	}, level, nil
+}
+
+// Recv records the hint level only if the stream finished successfully
+func (s *hintRecordingStream) Recv() (string, error) {
+	text, err := s.ChatStream.Recv()
+	if errors.Is(err, io.EOF) && !s.recorded {
+		s.recorded = true
+		if err2 := s.app.Store.AssignmentHint().Save(s.hint); err2 != nil {
+			s.app.Log.Error("can't save assignment hint level", log.String("userID", s.hint.UserID), log.String("nodeID", s.hint.NodeID), log.Err(err2))
+		}
+	}
+	return text, err
+}
+
+// getAssignmentTexts returns the text of the assignment and the reference solution code imported for the node
+func (a *App) getAssignmentTexts(node *model.Node) (string, string, error) {
+	solutionName := solutionCodeTextName(node.Name, node.Lang)
+	texts, err := a.Store.Text().GetTexts(&model.TextGetOptions{NodeID: node.ID})
+	if err != nil {
+		return "", "", errors.Wrapf(err, "can't get texts of node %s", node.ID)
+	}
+
+	var assignment strings.Builder
+	solution := ""
+	for _, text := range texts {
+		if text.Name == solutionName {
+			solution = text.Text
+			continue
+		}
+		fmt.Fprintf(&assignment, "%s\n", text.Text)
+	}
+	if assignment.Len() == 0 {
+		assignment.WriteString(node.Description)
+	}
+	return assignment.String(), solution, nil
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"io"
+	"testing"
+
+	"github.com/oseducation/knowledge-graph/log"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/oseducation/knowledge-graph/store"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type assignmentHintStoreMock struct {
+	store.AssignmentHintStore
+	hints []*model.AssignmentHint
+}
+
+func (hs *assignmentHintStoreMock) Save(hint *model.AssignmentHint) error {
+	hs.hints = append(hs.hints, hint)
+	return nil
+}
+
+type assignmentHintTestStore struct {
+	store.Store
+	hints *assignmentHintStoreMock
+}
+
+func (s *assignmentHintTestStore) AssignmentHint() store.AssignmentHintStore {
+	return s.hints
+}
+
+// failingStream fails after streaming the chunks
+type failingStream struct {
+	chunkStream
+}
+
+func (fs *failingStream) Recv() (string, error) {
+	text, err := fs.chunkStream.Recv()
+	if errors.Is(err, io.EOF) {
+		return "", errors.New("connection is lost")
+	}
+	return text, err
+}
+
+func TestHintRecordingStream(t *testing.T) {
+	newStream := func(stream *chunkStream, failing bool) (*hintRecordingStream, *assignmentHintStoreMock) {
+		hints := &assignmentHintStoreMock{}
+		a := &App{
+			Log:   log.NewLogger(&log.LoggerConfiguration{NonLogger: true}),
+			Store: &assignmentHintTestStore{hints: hints},
+		}
+		hint := &model.AssignmentHint{UserID: model.NewID(), NodeID: model.NewID(), Level: 2}
+		if failing {
+			return &hintRecordingStream{ChatStream: &failingStream{*stream}, app: a, hint: hint}, hints
+		}
+		return &hintRecordingStream{ChatStream: stream, app: a, hint: hint}, hints
+	}
+
+	t.Run("level is recorded once the hint is streamed", func(t *testing.T) {
+		stream, hints := newStream(&chunkStream{chunks: []string{"What does ", "the robot do?"}}, false)
+		require.Equal(t, "What does the robot do?", readStream(t, stream))
+		require.Len(t, hints.hints, 1)
+		require.Equal(t, 2, hints.hints[0].Level)
+
+		_, err := stream.Recv()
+		require.ErrorIs(t, err, io.EOF)
+		require.Len(t, hints.hints, 1)
+	})
+
+	t.Run("level isn't recorded before the end of the hint", func(t *testing.T) {
+		stream, hints := newStream(&chunkStream{chunks: []string{"What does ", "the robot do?"}}, false)
+		_, err := stream.Recv()
+		require.NoError(t, err)
+		require.Empty(t, hints.hints)
+	})
+
+	t.Run("level isn't recorded if the stream fails", func(t *testing.T) {
+		stream, hints := newStream(&chunkStream{chunks: []string{"What does "}}, true)
+		_, err := stream.Recv()
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.Error(t, err)
+		require.Empty(t, hints.hints)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/oseducation/knowledge-graph/log"
 	"github.com/oseducation/knowledge-graph/model"
 	"github.com/oseducation/knowledge-graph/services"
 	"github.com/pkg/errors"
@@ -13,7 +14,8 @@ const (
 	minimalEmbeddingScore = 0.5
 	numberOfSimilarTopics = 3
 
-	numberOfDialoguePosts = 10
+	// numberOfDialoguePosts are passed to the dialogue, the turns not fitting in the context window are summarized
+	numberOfDialoguePosts = 50
 
 	ShowVideoIntent                = "show_video"
 	ShotTextIntent                 = "show_text"
@@ -115,6 +117,12 @@ You're on the right track, and I'm here to help you keep the momentum going. Whe
 		}
 	}
 
+	if len(oldPosts) != 0 && isFirstPostOfTheDay(oldPosts[len(oldPosts)-1].UpdatedAt) {
+		refresher, refresherOptions := a.getRefresher(userID, len(options)+1)
+		message += refresher
+		options = append(options, refresherOptions...)
+	}
+
 	post, err := a.CreatePost(&model.Post{
 		LocationID: fmt.Sprintf("%s_%s", userID, model.BotID),
 		UserID:     model.BotID,
@@ -131,16 +139,46 @@ You're on the right track, and I'm here to help you keep the momentum going. Whe
 	return post, nil
 }
 
-func (a *App) CheckLimit(userID string) bool {
+func isFirstPostOfTheDay(lastPostDate int64) bool {
 	now := time.Now()
-	beginningOfTheMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).UnixNano() / int64(time.Millisecond)
-	count, err := a.CountChatGPTPosts(userID, beginningOfTheMonth)
+	beginningOfTheDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).UnixNano() / int64(time.Millisecond)
+	return lastPostDate < beginningOfTheDay
+}
+
+// getRefresher suggests to refresh the most decayed prerequisites of the current goal
+func (a *App) getRefresher(userID string, firstOptionID int) (string, []model.Option) {
+	nodes, err := a.getNodesToRefresh(userID)
+	if err != nil {
+		a.Log.Error("can't get nodes to refresh", log.Err(err))
+		return "", nil
+	}
+	if len(nodes) == 0 {
+		return "", nil
+	}
+
+	message := "\n\n🧠 Before we move on, a short refresher might help. It's been a while since you've seen these topics:\n"
+	options := make([]model.Option, 0, len(nodes))
+	for i, node := range nodes {
+		message += fmt.Sprintf("- **%s**\n", node.Name)
+		options = append(options, model.Option{
+			ID:                fmt.Sprintf("%d", firstOptionID+i),
+			TextOnButton:      fmt.Sprintf("Refresh %s", node.Name),
+			MessageAfterClick: fmt.Sprintf("Let's refresh %s!", node.Name),
+			Action:            model.PostActionTypeLink,
+			Link:              fmt.Sprintf("/nodes/%s", node.ID),
+		})
+	}
+	return message, options
+}
+
+// CheckLimit checks whether the user has budget left for the language model this month
+func (a *App) CheckLimit(userID string, plan model.RoleType) bool {
+	status, err := a.GetBudgetStatus(userID, plan)
 	if err != nil {
 		a.Log.Error(err.Error())
 		return false
 	}
-
-	return count < a.Config.ChatSettings.ChatGPTMonthlyLimit
+	return !status.Exceeded
 }
 
 func (a *App) CountChatGPTPosts(userID string, after int64) (int, error) {
@@ -161,14 +199,26 @@ func (a *App) CountChatGPTPosts(userID string, after int64) (int, error) {
 	return count, nil
 }
 
-func (a *App) AskQuestionToChatGPT(message, nodeID, userID string, gptModel services.ChatGPTModel) (*model.Post, error) {
-	systemMessage, err := a.getSystemMessage(nodeID, userID)
-	if err != nil {
-		return nil, err
-	}
-	answer, err := a.Services.ChatGPTService.Send(userID, systemMessage, gptModel, []string{message})
-	if err != nil {
-		return nil, errors.Wrapf(err, "can't send message to chatGPT")
+func (a *App) AskQuestionToChatGPT(message, nodeID, userID string, tier services.LLMTier) (*model.Post, error) {
+	key := a.getAnswerCacheKey(message, nodeID, userID, tier)
+	answer, citations := "", []*model.Citation{}
+	if cached := a.getCachedAnswer(key); cached != nil {
+		answer, citations = cached.Answer, cached.Citations
+	} else {
+		systemMessage, messageCitations, err := a.getSystemMessage(message, nodeID, userID)
+		if err != nil {
+			return nil, err
+		}
+		answer, err = a.Services.LLMService.Send(userID, systemMessage, tier, []string{message})
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't send message to chatGPT")
+		}
+		citations = messageCitations
+		moderated, flagged := a.moderateOutput(answer, message, nodeID, userID)
+		answer = moderated
+		if !flagged {
+			a.cacheAnswer(key, answer, citations)
+		}
 	}
 
 	post, err := a.Store.Post().Save(&model.Post{
@@ -176,7 +226,7 @@ func (a *App) AskQuestionToChatGPT(message, nodeID, userID string, gptModel serv
 		UserID:     model.BotID,
 		Message:    answer,
 		PostType:   model.PostTypeChatGPT,
-		Props:      map[string]interface{}{"node_id": nodeID},
+		Props:      map[string]interface{}{"node_id": nodeID, "citations": citations},
 	})
 
 	return post, err
@@ -195,20 +245,46 @@ func (a *App) getPrerequisiteNodes(nodeID string) ([]*model.Node, error) {
 	return nodes, nil
 }
 
-func (a *App) getSystemMessage(nodeID, userID string) (string, error) {
+// getSystemMessage composes the tutor's prompt with the passages of the node and its prerequisites most relevant
+// to the message and returns the citations of the passages. Until the content is indexed the node's first text is used.
+func (a *App) getSystemMessage(message, nodeID, userID string) (string, []*model.Citation, error) {
 	nodes, err := a.getPrerequisiteNodes(nodeID)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	node, err := a.Store.Node().Get(nodeID) // TODO: include nodeID in prerequisites to reduce db fetch
 	if err != nil {
-		return "", errors.Wrapf(err, "can't get node with id = %v", nodeID)
+		return "", nil, errors.Wrapf(err, "can't get node with id = %v", nodeID)
 	}
 
 	topics := ""
+	nodeIDs := []string{nodeID}
 	for _, node := range nodes {
 		topics += fmt.Sprintf("Topic: %s\nDescription: %s\n", node.Name, node.Description)
+		nodeIDs = append(nodeIDs, node.ID)
+	}
+
+	tutorPersonalityPrompt := a.getTutorPrompt(userID)
+
+	chunks, err := a.getRelevantContent(message, nodeIDs)
+	if err != nil {
+		a.Log.Error("can't get relevant content", log.Err(err))
+	}
+	if len(chunks) > 0 {
+		passages := ""
+		citations := make([]*model.Citation, 0, len(chunks))
+		for i, chunk := range chunks {
+			passages += fmt.Sprintf("[%d] %s\n%s\n\n", i+1, chunk.Title, chunk.Content)
+			citations = append(citations, chunk.Citation(i+1))
+		}
+		systemMessage := fmt.Sprintf(`%s. Assume that students knows all the topics listed in braces:
+{%s}
+The learner is studying the topic: %s. %s
+Use the numbered passages from the course below to answer the question and cite the passages you used by their numbers in square brackets, e.g. [1]:
+{%s}
+`, tutorPersonalityPrompt, topics, node.Name, node.Description, passages)
+		return systemMessage, citations, nil
 	}
 
 	textOptions := &model.TextGetOptions{}
@@ -219,7 +295,7 @@ func (a *App) getSystemMessage(nodeID, userID string) (string, error) {
 	)(textOptions)
 	texts, err := a.Store.Text().GetTexts(textOptions) // TODO: include in a single db fetch
 	if err != nil {
-		return "", errors.Wrap(err, "can't get texts")
+		return "", nil, errors.Wrap(err, "can't get texts")
 	}
 
 	text := ""
@@ -228,31 +304,45 @@ func (a *App) getSystemMessage(nodeID, userID string) (string, error) {
 	}
 	content := fmt.Sprintf("Topic: %s\nDescription: %s\nContent: %s", node.Name, node.Description, text)
 
-	tutorPersonalityPrompt := a.getTutorPrompt(userID)
 	systemMessage := fmt.Sprintf(`%s. Assume that students knows all the topics listed in braces:
 {%s}
 Use the content below to answer the question:
 {%s}
 `, tutorPersonalityPrompt, topics, content)
-	return systemMessage, nil
+	return systemMessage, []*model.Citation{}, nil
 }
 
-func (a *App) AskQuestionToChatGPTSteam(message, nodeID, userID string, gptModel services.ChatGPTModel) (services.ChatStream, error) {
-	systemMessage, err := a.getSystemMessage(nodeID, userID)
+// AskQuestionToChatGPTSteam streams the answer and returns the citations of the passages it's based on.
+// Answers to similar questions are served from the cache.
+func (a *App) AskQuestionToChatGPTSteam(message, nodeID, userID string, tier services.LLMTier) (services.ChatStream, []*model.Citation, error) {
+	key := a.getAnswerCacheKey(message, nodeID, userID, tier)
+	if cached := a.getCachedAnswer(key); cached != nil {
+		return services.CreateStringStream(cached.Answer), cached.Citations, nil
+	}
+
+	systemMessage, citations, err := a.getSystemMessage(message, nodeID, userID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	stream, err := a.Services.LLMService.SendStream(userID, systemMessage, tier, []string{message})
+	if err != nil {
+		return nil, nil, err
 	}
-	return a.Services.ChatGPTService.SendStream(userID, systemMessage, gptModel, []string{message})
+	return a.cacheAnswerStream(a.moderateStream(stream, message, nodeID, userID), key, citations), citations, nil
 }
 
-func (a *App) GetResponseToCorrectAnswerStream(explanation string, userID string, gptModel services.ChatGPTModel) (services.ChatStream, error) {
+func (a *App) GetResponseToCorrectAnswerStream(explanation string, userID string, tier services.LLMTier) (services.ChatStream, error) {
 	tutorPersonalityPrompt := a.getTutorPrompt(userID)
 	systemMessage := fmt.Sprintf("%s. Learner correctly answered to the multiple choice question. Here is the explanation of the question: {%s}. Congratulate and explain the correct answer to the learner.", tutorPersonalityPrompt, explanation)
-	message := ""
-	return a.Services.ChatGPTService.SendStream(userID, systemMessage, gptModel, []string{message})
+	message := "I answered the question correctly."
+	stream, err := a.Services.LLMService.SendStream(userID, systemMessage, tier, []string{message})
+	if err != nil {
+		return nil, err
+	}
+	return a.moderateStream(stream, "", "", userID), nil
 }
 
-func (a *App) GetResponseToIncorrectAnswerStream(question *model.Question, incorrectAnswer, userID string, gptModel services.ChatGPTModel) (services.ChatStream, error) {
+func (a *App) GetResponseToIncorrectAnswerStream(question *model.Question, incorrectAnswer, userID string, tier services.LLMTier) (services.ChatStream, error) {
 	tutorPersonalityPrompt := a.getTutorPrompt(userID)
 	correctAnswer := ""
 	for _, choice := range question.Choices {
@@ -262,48 +352,48 @@ func (a *App) GetResponseToIncorrectAnswerStream(question *model.Question, incor
 		}
 	}
 	systemMessage := fmt.Sprintf("%s. Learner incorrectly answered to the multiple choice question. They question was: {%s}. They answered: {%s}. They should have answered: {%s}. Here is the explanation of the correct answer: {%s}. State that the learner answered incorrectly, explain to the learner why they might have answered incorrectly, state the correct answer and explain why it is the correct answer. Be concise.", tutorPersonalityPrompt, question.Question, incorrectAnswer, correctAnswer, question.Explanation)
-	message := ""
-	return a.Services.ChatGPTService.SendStream(userID, systemMessage, gptModel, []string{message})
+	message := fmt.Sprintf("I answered: %s", incorrectAnswer)
+	stream, err := a.Services.LLMService.SendStream(userID, systemMessage, tier, []string{message})
+	if err != nil {
+		return nil, err
+	}
+	return a.moderateStream(stream, "", "", userID), nil
 }
 
-func (a *App) AskQuestionToChatGPTSteamOnTopicDialogue(message, nodeID, userID string, gptModel services.ChatGPTModel, prevPosts []*model.Post) (services.ChatStream, error) {
-	systemMessage, err := a.getSystemMessage(nodeID, userID)
+func (a *App) AskQuestionToChatGPTSteamOnTopicDialogue(message, nodeID, userID string, tier services.LLMTier, prevPosts []*model.Post) (services.ChatStream, []*model.Citation, error) {
+	systemMessage, citations, err := a.getSystemMessage(message, nodeID, userID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	chatMessages := []services.ChatMessage{{
-		Role:    services.ChatGPTModelRoleSystem,
-		Content: systemMessage,
-	}}
-	for _, post := range prevPosts {
-		if post.UserID == userID {
-			chatMessages = append(chatMessages, services.ChatMessage{Content: post.Message, Role: services.ChatGPTModelRoleUser})
-		} else if post.UserID == model.BotID {
-			chatMessages = append(chatMessages, services.ChatMessage{Content: post.Message, Role: services.ChatGPTModelRoleAssistant})
-		}
+	chatMessages, err := a.getDialogueMessages(systemMessage, message, nodeID, userID, tier, prevPosts)
+	if err != nil {
+		return nil, nil, err
+	}
+	stream, err := a.Services.LLMService.SendStreamWithChatMessages(userID, tier, chatMessages)
+	if err != nil {
+		return nil, nil, err
 	}
-	chatMessages = append(chatMessages, services.ChatMessage{Content: message, Role: services.ChatGPTModelRoleUser})
-	return a.Services.ChatGPTService.SendStreamWithChatMessages(userID, gptModel, chatMessages)
+	return a.moderateStream(stream, message, nodeID, userID), citations, nil
 }
 
-func (a *App) AskQuestionToChatGPTSteamOnDifferentTopic(message, nodeID, userID string, gptModel services.ChatGPTModel) (services.ChatStream, error) {
+func (a *App) AskQuestionToChatGPTSteamOnDifferentTopic(message, nodeID, userID string, tier services.LLMTier) (services.ChatStream, []*model.Citation, error) {
 	statuses, err := a.Store.Node().GetNodesForUser(userID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	for _, status := range statuses {
 		if status.NodeID == nodeID && (status.Status == model.NodeStatusFinished || status.Status == model.NodeStatusStarted || status.Status == model.NodeStatusWatched) {
-			return a.AskQuestionToChatGPTSteam(message, nodeID, userID, gptModel)
+			return a.AskQuestionToChatGPTSteam(message, nodeID, userID, tier)
 		}
 	}
 
 	node, err := a.Store.Node().Get(nodeID)
 	if err != nil {
-		return nil, errors.Wrapf(err, "can't get node with id = %v", nodeID)
+		return nil, nil, errors.Wrapf(err, "can't get node with id = %v", nodeID)
 	}
 	answer := fmt.Sprintf("It seems like you're asking a question about a different topic. You will cover this when we reach the topic named **`%s`**. Meanwhile, you can ask any question on the topic in progress!", node.Name)
 
-	return services.CreateStringStream(answer), nil
+	return services.CreateStringStream(answer), []*model.Citation{}, nil
 }
 
 func (a *App) AskQuestionToChatGPTSteamOffTopic() (services.ChatStream, error) {
@@ -316,14 +406,14 @@ func (a *App) GetUserIntent(text, userID string, currentNodeID string) (UserInte
 		return UserIntent{}, errors.Wrap(err, "can't get current node")
 	}
 
-	vector, err := a.Services.ChatGPTService.GetEmbedding(text, userID)
+	vector, err := a.Services.LLMService.GetEmbedding(text, userID)
 	if err != nil {
 		return UserIntent{}, errors.Wrap(err, "can't get embedding")
 	}
 
 	topics, err := a.Services.PineconeService.Query(numberOfSimilarTopics, vector)
-	if err != nil || len(topics) == 0 {
-		return UserIntent{}, errors.Wrap(err, "can't get pinecone response")
+	if err != nil {
+		return UserIntent{}, errors.Wrap(err, "can't get similar topics")
 	}
 
 	for _, topic := range topics {
@@ -332,8 +422,8 @@ func (a *App) GetUserIntent(text, userID string, currentNodeID string) (UserInte
 		}
 	}
 
-	// Do we have user intent?
-	if topics[0].Score >= minimalEmbeddingScore {
+	// Do we have user intent? Without indexed embeddings the message is treated as a dialogue
+	if len(topics) > 0 && topics[0].Score >= minimalEmbeddingScore {
 		if topics[0].Intent == ShowVideoIntent || topics[0].Intent == ShotTextIntent {
 			return UserIntent{Intent: topics[0].Intent}, nil
 		} else if topics[0].Intent != "" {
@@ -356,7 +446,7 @@ func (a *App) GetUserIntent(text, userID string, currentNodeID string) (UserInte
 	// 	return UserIntent{Intent: QuestionOnOffTopicIntent}, nil
 	// }
 	// gptMessages := a.getGPTMessages(posts)
-	// messagesEmbedding, err := a.Services.ChatGPTService.GetEmbedding(gptMessages, userID)
+	// messagesEmbedding, err := a.Services.LLMService.GetEmbedding(gptMessages, userID)
 	// if err != nil {
 	// 	return UserIntent{Intent: QuestionOnOffTopicIntent, PrevPosts: posts}, nil
 	// }
@@ -396,19 +486,6 @@ func (a *App) getLastNDialoguePosts(userID string, n int) ([]*model.Post, error)
 // 	return message
 // }
 
-func (a *App) getTutorPrompt(userID string) string {
-	tutorPersonality, err := a.Store.Preferences().Get(userID, "tutor_personality")
-	if err != nil {
-		return model.StandardTutorPersonality.Prompt
-	}
-	for _, personality := range model.DefaultTutorPersonalities {
-		if personality.ID == tutorPersonality {
-			return personality.Prompt
-		}
-	}
-	return model.StandardTutorPersonality.Prompt
-}
-
 // // Function to calculate the dot product of two vectors
 // func dotProduct(vectorA, vectorB []float32) float32 {
 // 	sum := float32(0.0)
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// GetCertificates returns all the certificates of the user
+func (a *App) GetCertificates(userID string) ([]*model.Certificate, error) {
+	certificates, err := a.Store.Certificate().GetForUser(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "userID = %s", userID)
+	}
+	return certificates, nil
+}
+
+// GetCertificate gets certificate by id
+func (a *App) GetCertificate(id string) (*model.Certificate, error) {
+	certificate, err := a.Store.Certificate().Get(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "id = %s", id)
+	}
+	return certificate, nil
+}
+
+// GetCertificatePDF renders the certificate as PDF
+func (a *App) GetCertificatePDF(certificate *model.Certificate) ([]byte, error) {
+	verifyURL := fmt.Sprintf("%s/api/v1/certificates/%s/verify", a.GetSiteURL(), certificate.ID)
+	pdf, err := a.Services.CertificateService.RenderPDF(certificate, verifyURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't render certificate %s", certificate.ID)
+	}
+	return pdf, nil
+}
+
+// VerifyCertificate returns public information about the certificate
+func (a *App) VerifyCertificate(id string) (*model.CertificateVerification, error) {
+	certificate, err := a.GetCertificate(id)
+	if err != nil {
+		return nil, err
+	}
+	return certificate.Verification(), nil
+}
+
+// RevokeCertificate revokes the certificate, revoked certificates fail verification
+func (a *App) RevokeCertificate(id string) error {
+	if _, err := a.GetCertificate(id); err != nil {
+		return err
+	}
+	if err := a.Store.Certificate().Revoke(id); err != nil {
+		return errors.Wrapf(err, "id = %s", id)
+	}
+	return nil
+}
+
+// issueCertificate issues a certificate for the reached goal, the goal's prerequisites are listed as covered topics
+func (a *App) issueCertificate(userID, nodeID string) error {
+	certificates, err := a.Store.Certificate().GetForUser(userID)
+	if err != nil {
+		return errors.Wrapf(err, "can't get certificates for user %s", userID)
+	}
+	for _, certificate := range certificates {
+		if certificate.NodeID == nodeID {
+			return nil
+		}
+	}
+
+	user, err := a.Store.User().Get(userID)
+	if err != nil {
+		return errors.Wrapf(err, "can't get user %s", userID)
+	}
+	learnerName := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if learnerName == "" {
+		learnerName = user.Username
+	}
+
+	goal, ok := a.Graph.Nodes[nodeID]
+	if !ok {
+		return errors.Errorf("unknown node %s", nodeID)
+	}
+	topics := []string{}
+	for _, prereq := range a.getAllPrerequisiteNodes(nodeID) {
+		if node, ok := a.Graph.Nodes[prereq]; ok && prereq != nodeID {
+			topics = append(topics, node.Name)
+		}
+	}
+
+	if err := a.Store.Certificate().Save(&model.Certificate{
+		UserID:      userID,
+		NodeID:      nodeID,
+		LearnerName: learnerName,
+		GoalName:    goal.Name,
+		Topics:      topics,
+	}); err != nil {
+		return errors.Wrapf(err, "can't save certificate for user %s, node %s", userID, nodeID)
+	}
+	return nil
+}
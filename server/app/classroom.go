@@ -0,0 +1,296 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	// classroomStuckAfter is the time after which a started node without progress is considered stuck
+	classroomStuckAfter = 7 * 24 * time.Hour
+	// classroomBotUsagePeriod is the period bot usage is counted for on the dashboard
+	classroomBotUsagePeriod = 30 * 24 * time.Hour
+)
+
+// CreateClassroom creates a new classroom for the teacher
+func (a *App) CreateClassroom(teacherID, name string) (*model.Classroom, error) {
+	classroom, err := a.Store.Classroom().Save(&model.Classroom{
+		TeacherID: teacherID,
+		Name:      name,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "teacherID = %s", teacherID)
+	}
+	return classroom, nil
+}
+
+// GetClassroom gets classroom by id
+func (a *App) GetClassroom(id string) (*model.Classroom, error) {
+	classroom, err := a.Store.Classroom().Get(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "id = %s", id)
+	}
+	return classroom, nil
+}
+
+// GetTeacherClassrooms returns classrooms created by the teacher
+func (a *App) GetTeacherClassrooms(teacherID string) ([]*model.Classroom, error) {
+	classrooms, err := a.Store.Classroom().GetForTeacher(teacherID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "teacherID = %s", teacherID)
+	}
+	return classrooms, nil
+}
+
+// GetStudentClassrooms returns classrooms joined by the user, join codes are known only to teachers
+func (a *App) GetStudentClassrooms(userID string) ([]*model.Classroom, error) {
+	classrooms, err := a.Store.Classroom().GetForStudent(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "userID = %s", userID)
+	}
+	for _, classroom := range classrooms {
+		classroom.JoinCode = ""
+	}
+	return classrooms, nil
+}
+
+// DeleteClassroom deletes the classroom, goals already assigned to students stay with them
+func (a *App) DeleteClassroom(id string) error {
+	if err := a.Store.Classroom().Delete(id); err != nil {
+		return errors.Wrapf(err, "id = %s", id)
+	}
+	return nil
+}
+
+// JoinClassroom adds the user to the classroom with the join code and assigns classroom's goals to the user
+func (a *App) JoinClassroom(userID, joinCode string) (*model.Classroom, error) {
+	classroom, err := a.Store.Classroom().GetByJoinCode(strings.ToUpper(strings.TrimSpace(joinCode)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "joinCode = %s", joinCode)
+	}
+	if classroom.TeacherID == userID {
+		return nil, errors.New("teacher can't join own classroom")
+	}
+
+	joined, err := a.Store.Classroom().AddStudent(classroom.ID, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "userID = %s", userID)
+	}
+	if joined {
+		goals, err := a.Store.Classroom().GetGoals(classroom.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't get goals of classroom %s", classroom.ID)
+		}
+		for _, goal := range goals {
+			if err := a.assignGoalToStudent(userID, goal); err != nil {
+				return nil, err
+			}
+		}
+	}
+	classroom.JoinCode = ""
+	return classroom, nil
+}
+
+// RemoveStudent removes the student from the classroom
+func (a *App) RemoveStudent(classroomID, userID string) error {
+	if err := a.Store.Classroom().RemoveStudent(classroomID, userID); err != nil {
+		return errors.Wrapf(err, "classroomID = %s, userID = %s", classroomID, userID)
+	}
+	return nil
+}
+
+// GetClassroomGoals returns goals assigned to the classroom
+func (a *App) GetClassroomGoals(classroomID string) ([]*model.ClassroomGoal, error) {
+	goals, err := a.Store.Classroom().GetGoals(classroomID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "classroomID = %s", classroomID)
+	}
+	return goals, nil
+}
+
+// AssignClassroomGoal assigns the goal to the classroom and sets it as a goal of every student with due date as target date
+func (a *App) AssignClassroomGoal(goal *model.ClassroomGoal) error {
+	if _, ok := a.Graph.Nodes[goal.NodeID]; !ok {
+		return errors.Errorf("unknown node %s", goal.NodeID)
+	}
+	if goal.DueDate < 0 {
+		return errors.Errorf("invalid due date %d", goal.DueDate)
+	}
+	if err := a.Store.Classroom().SaveGoal(goal); err != nil {
+		return errors.Wrapf(err, "classroomID = %s, nodeID = %s", goal.ClassroomID, goal.NodeID)
+	}
+
+	students, err := a.Store.Classroom().GetStudents(goal.ClassroomID)
+	if err != nil {
+		return errors.Wrapf(err, "can't get students of classroom %s", goal.ClassroomID)
+	}
+	for _, student := range students {
+		if err := a.assignGoalToStudent(student.ID, goal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnassignClassroomGoal removes the goal from the classroom, students keep it as their own goal
+func (a *App) UnassignClassroomGoal(classroomID, nodeID string) error {
+	if err := a.Store.Classroom().DeleteGoal(classroomID, nodeID); err != nil {
+		return errors.Wrapf(err, "classroomID = %s, nodeID = %s", classroomID, nodeID)
+	}
+	return nil
+}
+
+// assignGoalToStudent creates the goal for the student and sets its target date to the due date,
+// goals the student has already reached are left untouched
+func (a *App) assignGoalToStudent(userID string, classroomGoal *model.ClassroomGoal) error {
+	if err := a.CreateGoal(userID, classroomGoal.NodeID); err != nil {
+		return errors.Wrapf(err, "can't assign goal %s to student %s", classroomGoal.NodeID, userID)
+	}
+	goal, err := a.Store.Goal().Get(userID, classroomGoal.NodeID)
+	if err != nil {
+		return errors.Wrapf(err, "can't get goal %s of student %s", classroomGoal.NodeID, userID)
+	}
+	if goal.FinishedAt != 0 || goal.TargetDate == classroomGoal.DueDate {
+		return nil
+	}
+	settings := goal.GoalSettings
+	settings.TargetDate = classroomGoal.DueDate
+	return a.UpdateGoalSettings(userID, classroomGoal.NodeID, &settings)
+}
+
+// GetClassroomDashboard returns progress of every student of the classroom on the assigned paths
+func (a *App) GetClassroomDashboard(classroom *model.Classroom) (*model.ClassroomDashboard, error) {
+	goals, err := a.Store.Classroom().GetGoals(classroom.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get goals of classroom %s", classroom.ID)
+	}
+	students, err := a.Store.Classroom().GetStudents(classroom.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get students of classroom %s", classroom.ID)
+	}
+
+	paths := make(map[string][]string, len(goals))
+	pathNodes := map[string]bool{}
+	for _, goal := range goals {
+		paths[goal.NodeID] = a.getAllPrerequisiteNodes(goal.NodeID)
+		for _, nodeID := range paths[goal.NodeID] {
+			pathNodes[nodeID] = true
+		}
+	}
+	questionIDs, err := a.getQuestionIDs(pathNodes)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &model.ClassroomDashboard{
+		Classroom: classroom,
+		Goals:     goals,
+		Students:  make([]*model.StudentProgress, 0, len(students)),
+	}
+	for _, student := range students {
+		progress, err := a.getStudentProgress(student, goals, paths, pathNodes, questionIDs)
+		if err != nil {
+			return nil, err
+		}
+		dashboard.Students = append(dashboard.Students, progress)
+	}
+	return dashboard, nil
+}
+
+func (a *App) getStudentProgress(student *model.User, goals []*model.ClassroomGoal, paths map[string][]string, pathNodes map[string]bool, questionIDs []string) (*model.StudentProgress, error) {
+	statuses, err := a.Store.Node().GetNodesForUser(student.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get statuses of student %s", student.ID)
+	}
+	stuckBefore := time.Now().Add(-classroomStuckAfter).UnixMilli()
+	statusMap := make(map[string]string, len(statuses))
+	stuckNodes := []string{}
+	for _, status := range statuses {
+		statusMap[status.NodeID] = status.Status
+		if pathNodes[status.NodeID] && status.UpdatedAt < stuckBefore &&
+			(status.Status == model.NodeStatusStarted || status.Status == model.NodeStatusWatched) {
+			stuckNodes = append(stuckNodes, status.NodeID)
+		}
+	}
+
+	progress := &model.StudentProgress{
+		User:       student,
+		Goals:      make([]*model.StudentGoalProgress, 0, len(goals)),
+		StuckNodes: stuckNodes,
+	}
+	for _, goal := range goals {
+		goalProgress := &model.StudentGoalProgress{
+			NodeID:   goal.NodeID,
+			Total:    len(paths[goal.NodeID]),
+			Statuses: map[string]string{},
+		}
+		for _, nodeID := range paths[goal.NodeID] {
+			status, ok := statusMap[nodeID]
+			if !ok {
+				continue
+			}
+			goalProgress.Statuses[nodeID] = status
+			if status == model.NodeStatusFinished {
+				goalProgress.Finished++
+			}
+		}
+		progress.Goals = append(progress.Goals, goalProgress)
+	}
+
+	if len(questionIDs) > 0 {
+		answers, err := a.Store.Question().GetAnswers(student.ID, questionIDs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't get answers of student %s", student.ID)
+		}
+		right := 0
+		for _, answer := range answers {
+			if answer.IsRight {
+				right++
+			}
+		}
+		progress.QuestionsAnswered = len(answers)
+		if len(answers) > 0 {
+			progress.QuestionAccuracy = float64(right) / float64(len(answers))
+		}
+	}
+
+	progress.BotMessages, err = a.CountChatGPTPosts(student.ID, time.Now().Add(-classroomBotUsagePeriod).UnixMilli())
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't count bot messages of student %s", student.ID)
+	}
+
+	hints, err := a.Store.AssignmentHint().GetLevelsForUser(student.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get hint levels of student %s", student.ID)
+	}
+	progress.HintLevels = map[string]int{}
+	for _, hint := range hints {
+		if pathNodes[hint.NodeID] {
+			progress.HintLevels[hint.NodeID] = hint.Level
+		}
+	}
+	return progress, nil
+}
+
+// getQuestionIDs returns ids of all the questions of the nodes
+func (a *App) getQuestionIDs(nodeIDs map[string]bool) ([]string, error) {
+	questionIDs := []string{}
+	for nodeID := range nodeIDs {
+		options := &model.QuestionGetOptions{}
+		model.ComposeQuestionOptions(
+			model.QuestionNodeID(nodeID),
+			model.QuestionPage(0),
+			model.QuestionPerPage(-1))(options)
+		questions, err := a.Store.Question().GetQuestions(options)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't get questions of node %s", nodeID)
+		}
+		for _, question := range questions {
+			questionIDs = append(questionIDs, question.ID)
+		}
+	}
+	return questionIDs, nil
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"database/sql"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// GetCompletionPolicy gets policy set directly on the node
+func (a *App) GetCompletionPolicy(nodeID string) (*model.CompletionPolicy, error) {
+	policy, err := a.Store.CompletionPolicy().Get(nodeID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "nodeID = %s", nodeID)
+	}
+	return policy, nil
+}
+
+// SaveCompletionPolicy creates or updates policy of the node
+func (a *App) SaveCompletionPolicy(policy *model.CompletionPolicy) (*model.CompletionPolicy, error) {
+	if _, err := a.Store.Node().Get(policy.NodeID); err != nil {
+		return nil, errors.Wrapf(err, "can't get node %s", policy.NodeID)
+	}
+	rpolicy, err := a.Store.CompletionPolicy().Save(policy)
+	if err != nil {
+		return nil, errors.Wrapf(err, "nodeID = %s", policy.NodeID)
+	}
+	return rpolicy, nil
+}
+
+// DeleteCompletionPolicy removes policy of the node
+func (a *App) DeleteCompletionPolicy(nodeID string) error {
+	if err := a.Store.CompletionPolicy().Delete(nodeID); err != nil {
+		return errors.Wrapf(err, "nodeID = %s", nodeID)
+	}
+	return nil
+}
+
+// GetNodeProgress gets user's progress on the node, which is used to evaluate completion policies.
+// Only the latest answer on each question is taken into account.
+func (a *App) GetNodeProgress(userID, nodeID string) (*model.NodeProgress, error) {
+	videosWatched, err := a.Store.Video().GetNumberOfFinishedVideos(userID, nodeID)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get number of finished videos")
+	}
+
+	questions, err := a.Store.Question().GetQuestions(&model.QuestionGetOptions{NodeID: nodeID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get questions for node %s", nodeID)
+	}
+	questionIDs := make([]string, 0, len(questions))
+	for _, question := range questions {
+		questionIDs = append(questionIDs, question.ID)
+	}
+
+	latestAnswers := map[string]bool{}
+	if len(questionIDs) > 0 {
+		answers, err := a.Store.Question().GetAnswers(userID, questionIDs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't get answers for node %s", nodeID)
+		}
+		for _, answer := range answers {
+			latestAnswers[answer.QuestionID] = answer.IsRight
+		}
+	}
+
+	progress := &model.NodeProgress{
+		VideosWatched:     videosWatched,
+		QuestionsTotal:    len(questionIDs),
+		QuestionsAnswered: len(latestAnswers),
+	}
+	for _, isRight := range latestAnswers {
+		if isRight {
+			progress.QuestionsCorrect++
+		}
+	}
+	return progress, nil
+}
+
+// checkCompletionPolicy returns UnmetRequirementsError if user hasn't met the policy of the node.
+// If the node has no policy of its own, policy of its parent (course) is used.
+func (a *App) checkCompletionPolicy(userID, nodeID string) error {
+	policy, err := a.getEffectiveCompletionPolicy(nodeID)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		return nil
+	}
+
+	progress, err := a.GetNodeProgress(userID, nodeID)
+	if err != nil {
+		return err
+	}
+	if unmet := policy.Check(progress); len(unmet) > 0 {
+		return &model.UnmetRequirementsError{
+			NodeID:       nodeID,
+			Requirements: unmet,
+		}
+	}
+	return nil
+}
+
+func (a *App) getEffectiveCompletionPolicy(nodeID string) (*model.CompletionPolicy, error) {
+	policy, err := a.Store.CompletionPolicy().Get(nodeID)
+	if err == nil {
+		return policy, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrapf(err, "can't get completion policy for node %s", nodeID)
+	}
+
+	node, err := a.Store.Node().Get(nodeID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get node %s", nodeID)
+	}
+	if node.ParentID == "" {
+		return nil, nil
+	}
+
+	policy, err = a.Store.CompletionPolicy().Get(node.ParentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "can't get completion policy for node %s", node.ParentID)
+	}
+	return policy, nil
+}
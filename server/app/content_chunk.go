@@ -0,0 +1,218 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxChunkLength is the length of a passage in characters, longer sections are split by paragraphs
+	maxChunkLength = 1500
+	// transcriptWindowSeconds is the length of a video passage
+	transcriptWindowSeconds = 60
+	// numberOfRetrievedChunks is the number of passages added to the tutor's prompt
+	numberOfRetrievedChunks = 5
+)
+
+// IndexContent splits texts, question explanations and video transcripts of all the nodes into passages,
+// embeds them and stores them for the tutor to retrieve. The reference solution codes aren't indexed,
+// the tutor must never show them to the learners.
+func (a *App) IndexContent() (int, error) {
+	count := 0
+	for nodeID, node := range a.Graph.Nodes {
+		chunks, err := a.getNodeContentChunks(nodeID, solutionCodeTextName(node.Name, node.Lang))
+		if err != nil {
+			return count, err
+		}
+		for _, chunk := range chunks {
+			vector, err := a.Services.LLMService.GetEmbedding(chunk.Content, "")
+			if err != nil {
+				return count, errors.Wrapf(err, "can't get embedding of %s %s", chunk.SourceType, chunk.SourceID)
+			}
+			if len(vector) == 0 {
+				return count, errors.New("embeddings aren't configured, set EmbeddingBaseURL or an OpenAI compatible provider in LLMSettings")
+			}
+			chunk.Vector = vector
+		}
+		if err := a.Store.ContentChunk().SaveForNode(nodeID, chunks); err != nil {
+			return count, errors.Wrapf(err, "can't save chunks of node %s", nodeID)
+		}
+		count += len(chunks)
+	}
+	return count, nil
+}
+
+func (a *App) getNodeContentChunks(nodeID, solutionName string) ([]*model.ContentChunk, error) {
+	chunks := []*model.ContentChunk{}
+
+	texts, err := a.Store.Text().GetTexts(&model.TextGetOptions{NodeID: nodeID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get texts of node %s", nodeID)
+	}
+	for _, text := range texts {
+		if text.Name == solutionName {
+			continue
+		}
+		for _, section := range splitMarkdown(text.Text) {
+			chunks = append(chunks, &model.ContentChunk{
+				ID:         model.NewID(),
+				SourceType: model.ContentSourceText,
+				SourceID:   text.ID,
+				Title:      text.Name,
+				Anchor:     section.anchor,
+				Content:    section.content,
+			})
+		}
+	}
+
+	questions, err := a.Store.Question().GetQuestions(&model.QuestionGetOptions{NodeID: nodeID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get questions of node %s", nodeID)
+	}
+	for _, question := range questions {
+		if question.Explanation == "" {
+			continue
+		}
+		chunks = append(chunks, &model.ContentChunk{
+			ID:         model.NewID(),
+			SourceType: model.ContentSourceQuestion,
+			SourceID:   question.ID,
+			Title:      question.Name,
+			Content:    fmt.Sprintf("%s\n%s", question.Question, question.Explanation),
+		})
+	}
+
+	videos, err := a.Store.Video().GetVideos(&model.VideoGetOptions{NodeID: nodeID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get videos of node %s", nodeID)
+	}
+	for _, video := range videos {
+		segments, err := a.Store.Video().GetTranscript(video.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, window := range splitTranscript(segments) {
+			chunks = append(chunks, &model.ContentChunk{
+				ID:           model.NewID(),
+				SourceType:   model.ContentSourceVideo,
+				SourceID:     video.ID,
+				Title:        video.Name,
+				StartSeconds: window.start,
+				Content:      window.content,
+			})
+		}
+	}
+	return chunks, nil
+}
+
+// getRelevantContent returns the passages of the node and its prerequisites most similar to the message
+func (a *App) getRelevantContent(message string, nodeIDs []string) ([]*model.ContentChunk, error) {
+	vector, err := a.Services.LLMService.GetEmbedding(message, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get embedding of the message")
+	}
+	chunks, err := a.Store.ContentChunk().Search(vector, nodeIDs, numberOfRetrievedChunks)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't search content chunks")
+	}
+	return chunks, nil
+}
+
+type markdownSection struct {
+	anchor  string
+	content string
+}
+
+// splitMarkdown splits the text by headings, the anchor of the section is the slug of its heading.
+// Sections longer than maxChunkLength are split by paragraphs.
+func splitMarkdown(text string) []markdownSection {
+	sections := []markdownSection{}
+	anchor := ""
+	lines := []string{}
+	flush := func() {
+		for _, part := range splitByParagraphs(strings.TrimSpace(strings.Join(lines, "\n"))) {
+			sections = append(sections, markdownSection{anchor: anchor, content: part})
+		}
+		lines = []string{}
+	}
+
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+		}
+		if !inCode && strings.HasPrefix(trimmed, "#") {
+			heading := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			if heading != "" {
+				flush()
+				anchor = slugify(heading)
+			}
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return sections
+}
+
+func splitByParagraphs(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	if len(text) <= maxChunkLength {
+		return []string{text}
+	}
+	parts := []string{}
+	current := ""
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		if current != "" && len(current)+len(paragraph) > maxChunkLength {
+			parts = append(parts, current)
+			current = ""
+		}
+		if current != "" {
+			current += "\n\n"
+		}
+		current += paragraph
+	}
+	if strings.TrimSpace(current) != "" {
+		parts = append(parts, current)
+	}
+	return parts
+}
+
+// slugify returns the anchor of the heading the way markdown renderers generate it
+func slugify(heading string) string {
+	slug := strings.Builder{}
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			slug.WriteRune(r)
+		case unicode.IsSpace(r):
+			slug.WriteRune('-')
+		}
+	}
+	return slug.String()
+}
+
+type transcriptWindow struct {
+	start   int64
+	content string
+}
+
+// splitTranscript groups the transcript segments into windows of transcriptWindowSeconds
+func splitTranscript(segments []*model.TranscriptSegment) []transcriptWindow {
+	windows := []transcriptWindow{}
+	for _, segment := range segments {
+		last := len(windows) - 1
+		if last >= 0 && segment.StartSeconds-windows[last].start < transcriptWindowSeconds {
+			windows[last].content += " " + segment.Text
+			continue
+		}
+		windows = append(windows, transcriptWindow{start: segment.StartSeconds, content: segment.Text})
+	}
+	return windows
+}
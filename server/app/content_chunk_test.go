@@ -0,0 +1,172 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/oseducation/knowledge-graph/store"
+	"github.com/stretchr/testify/require"
+)
+
+// contentTestStore returns the texts of a node without questions and videos
+type contentTestStore struct {
+	store.Store
+	texts []*model.Text
+}
+
+func (s *contentTestStore) Text() store.TextStore         { return &textStoreMock{texts: s.texts} }
+func (s *contentTestStore) Question() store.QuestionStore { return &questionStoreMock{} }
+func (s *contentTestStore) Video() store.VideoStore       { return &videoStoreMock{} }
+
+type textStoreMock struct {
+	store.TextStore
+	texts []*model.Text
+}
+
+func (ts *textStoreMock) GetTexts(options *model.TextGetOptions) ([]*model.Text, error) {
+	return ts.texts, nil
+}
+
+type questionStoreMock struct {
+	store.QuestionStore
+}
+
+func (qs *questionStoreMock) GetQuestions(options *model.QuestionGetOptions) ([]*model.Question, error) {
+	return nil, nil
+}
+
+type videoStoreMock struct {
+	store.VideoStore
+}
+
+func (vs *videoStoreMock) GetVideos(options *model.VideoGetOptions) ([]*model.Video, error) {
+	return nil, nil
+}
+
+func TestSplitMarkdown(t *testing.T) {
+	t.Run("text without headings is a single section", func(t *testing.T) {
+		sections := splitMarkdown("First paragraph.\n\nSecond paragraph.")
+		require.Equal(t, []markdownSection{{anchor: "", content: "First paragraph.\n\nSecond paragraph."}}, sections)
+	})
+
+	t.Run("sections are split by headings", func(t *testing.T) {
+		text := "Intro\n# First Heading\nfirst\n## Second, heading!\nsecond"
+		sections := splitMarkdown(text)
+		require.Equal(t, []markdownSection{
+			{anchor: "", content: "Intro"},
+			{anchor: "first-heading", content: "# First Heading\nfirst"},
+			{anchor: "second-heading", content: "## Second, heading!\nsecond"},
+		}, sections)
+	})
+
+	t.Run("comments in code blocks aren't headings", func(t *testing.T) {
+		text := "# Code\n```python\n# not a heading\nprint(1)\n```\nafter"
+		sections := splitMarkdown(text)
+		require.Len(t, sections, 1)
+		require.Equal(t, "code", sections[0].anchor)
+		require.Contains(t, sections[0].content, "# not a heading")
+	})
+
+	t.Run("empty headings and sections are skipped", func(t *testing.T) {
+		sections := splitMarkdown("#\n\n# Title\n")
+		require.Equal(t, []markdownSection{
+			{anchor: "", content: "#"},
+			{anchor: "title", content: "# Title"},
+		}, sections)
+		require.Empty(t, splitMarkdown(""))
+	})
+
+	t.Run("long sections are split by paragraphs", func(t *testing.T) {
+		paragraph := strings.Repeat("a", maxChunkLength/2+1)
+		sections := splitMarkdown("# Long\n" + paragraph + "\n\n" + paragraph)
+		require.Len(t, sections, 2)
+		for _, section := range sections {
+			require.Equal(t, "long", section.anchor)
+		}
+	})
+}
+
+func TestSplitByParagraphs(t *testing.T) {
+	short := strings.Repeat("a", maxChunkLength/4)
+	long := strings.Repeat("b", maxChunkLength+1)
+
+	for name, tc := range map[string]struct {
+		text     string
+		expected []string
+	}{
+		"empty text":               {text: "", expected: []string{}},
+		"short text is kept":       {text: "one\n\ntwo", expected: []string{"one\n\ntwo"}},
+		"paragraphs are grouped":   {text: strings.Join([]string{short, short, short, short}, "\n\n"), expected: []string{strings.Join([]string{short, short, short}, "\n\n"), short}},
+		"long paragraph isn't cut": {text: short + "\n\n" + long, expected: []string{short, long}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, splitByParagraphs(tc.text))
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	for heading, expected := range map[string]string{
+		"Hello World":         "hello-world",
+		"What's next?":        "whats-next",
+		"snake_case and-dash": "snake_case-and-dash",
+		"Step 2: Loops":       "step-2-loops",
+		"ცვლადები და ტიპები": "ცვლადები-და-ტიპები",
+		"": "",
+	} {
+		t.Run(heading, func(t *testing.T) {
+			require.Equal(t, expected, slugify(heading))
+		})
+	}
+}
+
+func TestSplitTranscript(t *testing.T) {
+	segment := func(start int64, text string) *model.TranscriptSegment {
+		return &model.TranscriptSegment{StartSeconds: start, EndSeconds: start + 5, Text: text}
+	}
+
+	t.Run("empty transcript", func(t *testing.T) {
+		require.Empty(t, splitTranscript(nil))
+	})
+
+	t.Run("segments are grouped into windows", func(t *testing.T) {
+		windows := splitTranscript([]*model.TranscriptSegment{
+			segment(0, "one"),
+			segment(30, "two"),
+			segment(59, "three"),
+			segment(60, "four"),
+			segment(200, "five"),
+		})
+		require.Equal(t, []transcriptWindow{
+			{start: 0, content: "one two three"},
+			{start: 60, content: "four"},
+			{start: 200, content: "five"},
+		}, windows)
+	})
+
+	t.Run("window starts at its first segment", func(t *testing.T) {
+		windows := splitTranscript([]*model.TranscriptSegment{
+			segment(10, "one"),
+			segment(65, "two"),
+			segment(71, "three"),
+		})
+		require.Equal(t, []transcriptWindow{
+			{start: 10, content: "one two"},
+			{start: 71, content: "three"},
+		}, windows)
+	})
+}
+
+func TestGetNodeContentChunks(t *testing.T) {
+	node := model.Node{ID: model.NewID(), Name: "Collect Beepers", Lang: model.LanguageEnglish}
+	assignment := &model.Text{ID: model.NewID(), Name: node.Name, Text: "Collect all the beepers."}
+	solution := &model.Text{ID: model.NewID(), Name: solutionCodeTextName(node.Name, node.Lang), Text: "```java\npickBeeper();\n```"}
+	a := &App{Store: &contentTestStore{texts: []*model.Text{assignment, solution}}}
+
+	chunks, err := a.getNodeContentChunks(node.ID, solutionCodeTextName(node.Name, node.Lang))
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+	require.Equal(t, assignment.ID, chunks[0].SourceID)
+	require.Equal(t, assignment.Text, chunks[0].Content)
+}
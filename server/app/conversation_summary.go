@@ -0,0 +1,188 @@
+package app
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/oseducation/knowledge-graph/log"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/oseducation/knowledge-graph/services"
+	"github.com/pkg/errors"
+)
+
+const (
+	// numberOfHistoryPosts are checked for the earlier dialogues on the node not summarized yet
+	numberOfHistoryPosts = 100
+	// summaryTokens are reserved in the context window for the summary of the node's dialogue
+	summaryTokens = 300
+	// maxSummarizedPostLength limits long messages, e.g. pasted code, in the summarization prompt
+	maxSummarizedPostLength = 2000
+	// numberOfRecentSummaries of the learner's other topics are added to the dialogue
+	numberOfRecentSummaries = 3
+	recentSummariesPeriod   = 7 * 24 * time.Hour
+
+	summarizePrompt = "You keep the memory of a tutor about the dialogue with a learner. Update the summary of the conversation with the new turns. Keep what the learner struggled with, their mistakes and misconceptions, the questions they asked and what was already explained to them. Use at most 150 words and answer only with the summary."
+)
+
+// getDialogueMessages returns the chat messages of the dialogue on the node: the system message with the summaries
+// of the earlier conversations and the latest turns that fit in the context window of the tier's model.
+// Turns that don't fit and the earlier dialogues on the node are added to the node's summary.
+func (a *App) getDialogueMessages(systemMessage, message, nodeID, userID string, tier services.LLMTier, prevPosts []*model.Post) ([]services.ChatMessage, error) {
+	summary, err := a.Store.ConversationSummary().Get(userID, nodeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		summary = &model.ConversationSummary{UserID: userID, NodeID: nodeID}
+	} else if err != nil {
+		return nil, err
+	}
+
+	systemMessage += a.getRecentSummariesMessage(userID, nodeID)
+	budget := int64(a.Services.LLMService.PromptTokens(tier)) - services.EstimateTokens(systemMessage) - services.EstimateTokens(message) - summaryTokens
+
+	// the latest turns are kept while they fit, the older ones are summarized
+	turns := []*model.Post{}
+	unsummarized := []*model.Post{}
+	for i := len(prevPosts) - 1; i >= 0; i-- {
+		post := prevPosts[i]
+		if post.CreatedAt <= summary.LastPostAt {
+			break
+		}
+		tokens := services.EstimateTokens(post.Message)
+		if len(unsummarized) == 0 && tokens <= budget {
+			budget -= tokens
+			turns = append([]*model.Post{post}, turns...)
+			continue
+		}
+		unsummarized = append([]*model.Post{post}, unsummarized...)
+	}
+	unsummarized = append(a.getEarlierDialoguePosts(userID, nodeID, summary.LastPostAt, prevPosts), unsummarized...)
+
+	if len(unsummarized) > 0 {
+		if err := a.summarizeDialogue(summary, unsummarized); err != nil {
+			// the tutor can answer without the memory
+			a.Log.Error("can't summarize dialogue", log.String("userID", userID), log.String("nodeID", nodeID), log.Err(err))
+		}
+	}
+	if summary.Summary != "" {
+		systemMessage += fmt.Sprintf("\n\nSummary of the earlier conversation with the learner on this topic: {%s}", summary.Summary)
+	}
+
+	chatMessages := []services.ChatMessage{{
+		Role:    services.ChatRoleSystem,
+		Content: systemMessage,
+	}}
+	for _, post := range turns {
+		if post.UserID == userID {
+			chatMessages = append(chatMessages, services.ChatMessage{Content: post.Message, Role: services.ChatRoleUser})
+		} else if post.UserID == model.BotID {
+			chatMessages = append(chatMessages, services.ChatMessage{Content: post.Message, Role: services.ChatRoleAssistant})
+		}
+	}
+	return append(chatMessages, services.ChatMessage{Content: message, Role: services.ChatRoleUser}), nil
+}
+
+// getEarlierDialoguePosts returns the posts of the learner's earlier dialogues on the node created after the time
+// and the posts of the current dialogue older than the turns passed to the tutor.
+// Dialogues are the posts following the topic post of the node.
+func (a *App) getEarlierDialoguePosts(userID, nodeID string, after int64, prevPosts []*model.Post) []*model.Post {
+	posts, err := a.Store.Post().GetLastNPostsForLocation(numberOfHistoryPosts, fmt.Sprintf("%s_%s", userID, model.BotID))
+	if err != nil {
+		a.Log.Error("can't get dialogue history", log.String("userID", userID), log.Err(err))
+		return []*model.Post{}
+	}
+	before := model.GetMillis()
+	if len(prevPosts) > 0 {
+		before = prevPosts[0].CreatedAt
+	}
+
+	earlierPosts := []*model.Post{}
+	dialogue := []*model.Post{}
+	isCurrentDialogue := true
+	for _, post := range posts {
+		if post.CreatedAt <= after {
+			break
+		}
+		if post.PostType != model.PostTypeTopic {
+			if post.Message != offTopicString && (!isCurrentDialogue || post.CreatedAt < before) {
+				dialogue = append([]*model.Post{post}, dialogue...)
+			}
+			continue
+		}
+		if isCurrentDialogue || post.Props["node_id"] == nodeID {
+			earlierPosts = append(dialogue, earlierPosts...)
+		}
+		isCurrentDialogue = false
+		dialogue = []*model.Post{}
+	}
+	if isCurrentDialogue {
+		earlierPosts = dialogue
+	}
+	return earlierPosts
+}
+
+// summarizeDialogue adds the posts to the summary with the language model and saves it
+func (a *App) summarizeDialogue(summary *model.ConversationSummary, posts []*model.Post) error {
+	var dialogue strings.Builder
+	for _, post := range posts {
+		text := post.Message
+		if len(text) > maxSummarizedPostLength {
+			text = truncateOnRuneBoundary(text, maxSummarizedPostLength) + "..."
+		}
+		if post.UserID == summary.UserID {
+			fmt.Fprintf(&dialogue, "Learner: %s\n", text)
+		} else if post.UserID == model.BotID {
+			fmt.Fprintf(&dialogue, "Tutor: %s\n", text)
+		}
+	}
+
+	message := fmt.Sprintf("Summary so far: {%s}\n\nNew turns:\n%s", summary.Summary, dialogue.String())
+	answer, err := a.Services.LLMService.Send(summary.UserID, summarizePrompt, services.LLMTierFree, []string{message})
+	if err != nil {
+		return errors.Wrap(err, "can't summarize the dialogue")
+	}
+
+	summary.Summary = strings.TrimSpace(answer)
+	summary.LastPostAt = posts[len(posts)-1].CreatedAt
+	return a.Store.ConversationSummary().Save(summary)
+}
+
+// getRecentSummariesMessage returns the summaries of the learner's dialogues on other topics during the last week
+func (a *App) getRecentSummariesMessage(userID, nodeID string) string {
+	summaries, err := a.Store.ConversationSummary().GetForUser(userID, time.Now().Add(-recentSummariesPeriod).UnixMilli())
+	if err != nil {
+		a.Log.Error("can't get recent conversation summaries", log.String("userID", userID), log.Err(err))
+		return ""
+	}
+
+	message := ""
+	count := 0
+	for _, summary := range summaries {
+		node, ok := a.Graph.Nodes[summary.NodeID]
+		if summary.NodeID == nodeID || !ok {
+			continue
+		}
+		message += fmt.Sprintf("\n- %s: {%s}", node.Name, summary.Summary)
+		count++
+		if count == numberOfRecentSummaries {
+			break
+		}
+	}
+	if message == "" {
+		return ""
+	}
+	return "\n\nEarlier this week the learner also discussed these topics:" + message
+}
+
+// truncateOnRuneBoundary cuts the text to at most maxLength bytes without splitting a multi-byte rune
+func truncateOnRuneBoundary(text string, maxLength int) string {
+	if len(text) <= maxLength {
+		return text
+	}
+	end := maxLength
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return text[:end]
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// defaultIntentPhrases are indexed when the content repo doesn't provide intents.json
+var defaultIntentPhrases = map[string][]string{
+	ShowVideoIntent: {
+		"show me the video",
+		"can I watch a video about this",
+		"play the video lesson",
+	},
+	ShotTextIntent: {
+		"show me the text",
+		"can I read about this",
+		"show me the lesson to read",
+	},
+}
+
+// IndexEmbeddings embeds names and descriptions of all the nodes and the intent phrases and stores them
+// in the vector store used for intent routing. Intent phrases are read from intents.json of the content repo
+// at the url, e.g. {"show_video": ["show me the video"]}, built-in phrases are used if url is empty.
+func (a *App) IndexEmbeddings(url string) (int, error) {
+	intentPhrases := defaultIntentPhrases
+	if url != "" {
+		content, err := getFileContent(fmt.Sprintf("%s/intents.json", url))
+		if err != nil {
+			return 0, errors.Wrapf(err, "can't get intents.json file\n%s", content)
+		}
+		if err := json.Unmarshal([]byte(content), &intentPhrases); err != nil {
+			return 0, errors.Wrap(err, "can't unmarshal intents.json file")
+		}
+	}
+
+	if err := a.Store.Embedding().DeleteAll(); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, node := range a.Graph.Nodes {
+		text := node.Name
+		if node.Description != "" {
+			text = fmt.Sprintf("%s. %s", node.Name, node.Description)
+		}
+		if err := a.saveEmbedding(&model.Embedding{
+			ID:   model.NodeEmbeddingID(node.ID),
+			Name: node.Name,
+		}, text); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	for intent, phrases := range intentPhrases {
+		if intent != ShowVideoIntent && intent != ShotTextIntent {
+			return count, errors.Errorf("unknown intent %s", intent)
+		}
+		for i, phrase := range phrases {
+			if err := a.saveEmbedding(&model.Embedding{
+				ID:     model.IntentEmbeddingID(intent, i),
+				Intent: intent,
+			}, phrase); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (a *App) saveEmbedding(embedding *model.Embedding, text string) error {
+	vector, err := a.Services.LLMService.GetEmbedding(text, "")
+	if err != nil {
+		return errors.Wrapf(err, "can't get embedding of %s", embedding.ID)
+	}
+	if len(vector) == 0 {
+		return errors.New("embeddings aren't configured, set EmbeddingBaseURL or an OpenAI compatible provider in LLMSettings")
+	}
+	embedding.Vector = vector
+	if err := a.Store.Embedding().Save(embedding); err != nil {
+		return errors.Wrapf(err, "can't save embedding %s", embedding.ID)
+	}
+	return nil
+}
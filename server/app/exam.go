@@ -0,0 +1,292 @@
+package app
+
+import (
+	"time"
+
+	"github.com/oseducation/knowledge-graph/log"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// CreateExam creates an exam for the parent node
+func (a *App) CreateExam(exam *model.Exam) (*model.Exam, error) {
+	if err := a.checkExamParent(exam.ParentNodeID); err != nil {
+		return nil, err
+	}
+	exam, err := a.Store.Exam().Save(exam)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't save exam for node %s", exam.ParentNodeID)
+	}
+	return exam, nil
+}
+
+// UpdateExam updates exam settings, parent node of the exam can't be changed
+func (a *App) UpdateExam(exam *model.Exam) (*model.Exam, error) {
+	oldExam, err := a.Store.Exam().Get(exam.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "examID = %s", exam.ID)
+	}
+	exam.ParentNodeID = oldExam.ParentNodeID
+	exam.CreatedAt = oldExam.CreatedAt
+	if err := a.Store.Exam().Update(exam); err != nil {
+		return nil, errors.Wrapf(err, "can't update exam %s", exam.ID)
+	}
+	return exam, nil
+}
+
+// GetExam returns the exam
+func (a *App) GetExam(id string) (*model.Exam, error) {
+	exam, err := a.Store.Exam().Get(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "examID = %s", id)
+	}
+	return exam, nil
+}
+
+// GetExams returns exams, of the parent node if parentNodeID is set
+func (a *App) GetExams(parentNodeID string) ([]*model.Exam, error) {
+	exams, err := a.Store.Exam().GetExams(parentNodeID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parentNodeID = %s", parentNodeID)
+	}
+	return exams, nil
+}
+
+// DeleteExam deletes the exam, past attempts are kept
+func (a *App) DeleteExam(id string) error {
+	if err := a.Store.Exam().Delete(id); err != nil {
+		return errors.Wrapf(err, "examID = %s", id)
+	}
+	return nil
+}
+
+// StartExamAttempt starts a new attempt with questions drawn randomly from the children of the exam's parent node.
+// If the user already has an attempt in progress, it's returned instead.
+func (a *App) StartExamAttempt(exam *model.Exam, userID string) (*model.ExamAttempt, error) {
+	attempts, err := a.Store.Exam().GetAttempts(exam.ID, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get attempts of exam %s for user %s", exam.ID, userID)
+	}
+	now := model.GetMillis()
+	for _, attempt := range attempts {
+		if attempt.Status != model.ExamAttemptStatusInProgress {
+			continue
+		}
+		if !attempt.IsExpired(now) {
+			return a.GetExamAttempt(attempt.ID)
+		}
+		if _, err := a.finishExpiredAttempt(attempt.ID); err != nil {
+			return nil, err
+		}
+	}
+	if exam.MaxAttempts > 0 && len(attempts) >= exam.MaxAttempts {
+		return nil, errors.Errorf("no attempts left for exam %s, allowed %d", exam.ID, exam.MaxAttempts)
+	}
+
+	pool, err := a.getExamQuestionPool(exam.ParentNodeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(pool) == 0 {
+		return nil, errors.Errorf("exam %s has no questions", exam.ID)
+	}
+	a.Random.Shuffle(len(pool), func(i, j int) {
+		pool[i], pool[j] = pool[j], pool[i]
+	})
+	if len(pool) > exam.NumQuestions {
+		pool = pool[:exam.NumQuestions]
+	}
+
+	attempt := &model.ExamAttempt{
+		ID:        model.NewID(),
+		ExamID:    exam.ID,
+		UserID:    userID,
+		StartedAt: now,
+		Deadline:  now + (time.Duration(exam.TimeLimitMinutes) * time.Minute).Milliseconds(),
+		Status:    model.ExamAttemptStatusInProgress,
+	}
+	for i, question := range pool {
+		attempt.Questions = append(attempt.Questions, &model.ExamAttemptQuestion{
+			AttemptID:  attempt.ID,
+			QuestionID: question.ID,
+			Position:   i,
+		})
+	}
+	if err := a.Store.Exam().SaveAttempt(attempt); err != nil {
+		return nil, errors.Wrapf(err, "can't save attempt of exam %s for user %s", exam.ID, userID)
+	}
+	return attempt, nil
+}
+
+// GetExamAttempt returns the attempt with its questions, the attempt is finished if its deadline has passed
+func (a *App) GetExamAttempt(id string) (*model.ExamAttempt, error) {
+	attempt, err := a.Store.Exam().GetAttempt(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "attemptID = %s", id)
+	}
+	if attempt.IsExpired(model.GetMillis()) {
+		return a.finishExpiredAttempt(id)
+	}
+	return attempt, nil
+}
+
+// GetExamAttempts returns user's attempts of the exam
+func (a *App) GetExamAttempts(examID, userID string) ([]*model.ExamAttempt, error) {
+	attempts, err := a.Store.Exam().GetAttempts(examID, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "examID = %s, userID = %s", examID, userID)
+	}
+	return attempts, nil
+}
+
+// GetNextExamQuestion returns the next unanswered question of the attempt without revealing the right choice
+func (a *App) GetNextExamQuestion(attempt *model.ExamAttempt) (*model.ExamQuestion, error) {
+	examQuestion := &model.ExamQuestion{
+		Attempt: attempt.WithoutResults(),
+		Total:   len(attempt.Questions),
+	}
+	if attempt.Status != model.ExamAttemptStatusInProgress {
+		return examQuestion, nil
+	}
+	next := attempt.NextQuestion()
+	if next == nil {
+		return examQuestion, nil
+	}
+	question, err := a.Store.Question().Get(next.QuestionID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get question %s", next.QuestionID)
+	}
+	examQuestion.Position = next.Position
+	examQuestion.Question = question.WithoutAnswers()
+	return examQuestion, nil
+}
+
+// AnswerExamQuestion records the answer on the current question of the attempt.
+// Answers after the deadline are rejected. When the last question is answered, the attempt is graded
+// and passing the exam finishes its parent node if the exam is configured so.
+func (a *App) AnswerExamQuestion(attempt *model.ExamAttempt, questionID, choiceID string) (*model.ExamAttempt, error) {
+	if attempt.Status != model.ExamAttemptStatusInProgress {
+		return nil, errors.Errorf("attempt %s is already finished", attempt.ID)
+	}
+	now := model.GetMillis()
+	if attempt.IsExpired(now) {
+		if _, err := a.finishExpiredAttempt(attempt.ID); err != nil {
+			return nil, err
+		}
+		return nil, errors.Errorf("time is up for attempt %s", attempt.ID)
+	}
+	next := attempt.NextQuestion()
+	if next == nil || next.QuestionID != questionID {
+		return nil, errors.Errorf("question %s isn't the current question of attempt %s", questionID, attempt.ID)
+	}
+
+	// questions are served one at a time, so the time is counted from the previous answer
+	shownAt := attempt.StartedAt
+	for _, question := range attempt.Questions {
+		if question.AnsweredAt > shownAt {
+			shownAt = question.AnsweredAt
+		}
+	}
+	question, err := a.Store.Question().Get(questionID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get question %s", questionID)
+	}
+	answer, err := a.saveAnswer(attempt.UserID, question, choiceID, now-shownAt)
+	if err != nil {
+		return nil, err
+	}
+	next.ChoiceID = choiceID
+	next.IsRight = answer.IsRight
+	next.AnsweredAt = now
+	saved, err := a.Store.Exam().SaveAnswer(next)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't save answer for attempt %s", attempt.ID)
+	}
+	if !saved {
+		return nil, errors.Errorf("question %s is already answered", questionID)
+	}
+
+	if attempt.NextQuestion() != nil {
+		return attempt.WithoutResults(), nil
+	}
+
+	exam, err := a.Store.Exam().Get(attempt.ExamID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "examID = %s", attempt.ExamID)
+	}
+	if err := a.finishExamAttempt(exam, attempt); err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// finishExpiredAttempt grades the attempt whose deadline has passed, unanswered questions count as wrong
+func (a *App) finishExpiredAttempt(id string) (*model.ExamAttempt, error) {
+	attempt, err := a.Store.Exam().GetAttempt(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "attemptID = %s", id)
+	}
+	exam, err := a.Store.Exam().Get(attempt.ExamID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "examID = %s", attempt.ExamID)
+	}
+	if err := a.finishExamAttempt(exam, attempt); err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+func (a *App) finishExamAttempt(exam *model.Exam, attempt *model.ExamAttempt) error {
+	attempt.Grade(exam.PassingScore)
+	attempt.FinishedAt = model.GetMillis()
+	if err := a.Store.Exam().FinishAttempt(attempt); err != nil {
+		return errors.Wrapf(err, "can't finish attempt %s", attempt.ID)
+	}
+	if attempt.Status != model.ExamAttemptStatusPassed || !exam.FinishesParent {
+		return nil
+	}
+
+	// the exam replaces completion policy of the parent node
+	if err := a.finishNode(&model.NodeStatusForUser{
+		NodeID: exam.ParentNodeID,
+		UserID: attempt.UserID,
+		Status: model.NodeStatusFinished,
+	}); err != nil {
+		a.Log.Error("can't finish parent node of the exam", log.String("examID", exam.ID), log.Err(err))
+	}
+	return nil
+}
+
+// getExamQuestionPool returns the exam questions of all the children of the parent node,
+// the practice questions aren't used as the learners have seen their answers
+func (a *App) getExamQuestionPool(parentNodeID string) ([]*model.Question, error) {
+	pool := []*model.Question{}
+	for _, node := range a.Graph.Nodes {
+		if node.ParentID != parentNodeID {
+			continue
+		}
+		options := &model.QuestionGetOptions{}
+		model.ComposeQuestionOptions(
+			model.QuestionNodeID(node.ID),
+			model.QuestionExamOnly(true),
+			model.QuestionPage(0),
+			model.QuestionPerPage(-1))(options)
+		questions, err := a.Store.Question().GetQuestions(options)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't get exam questions of node %s", node.ID)
+		}
+		pool = append(pool, questions...)
+	}
+	return pool, nil
+}
+
+func (a *App) checkExamParent(parentNodeID string) error {
+	node, ok := a.Graph.Nodes[parentNodeID]
+	if !ok {
+		return errors.Errorf("unknown node %s", parentNodeID)
+	}
+	if node.NodeType != model.NodeTypeParent {
+		return errors.Errorf("node %s isn't a parent node", parentNodeID)
+	}
+	return nil
+}
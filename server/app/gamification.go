@@ -0,0 +1,151 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/oseducation/knowledge-graph/config"
+	"github.com/oseducation/knowledge-graph/log"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+func loadAchievements() ([]*model.Achievement, error) {
+	return model.AchievementsFromJSON(bytes.NewReader(config.AchievementsJSON))
+}
+
+// GetXPHistory returns user's level and XP history
+func (a *App) GetXPHistory(userID string, page, perPage int) (*model.Level, []*model.XPEvent, error) {
+	counts, err := a.Store.Gamification().GetXPEventCounts(userID)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "userID = %s", userID)
+	}
+	xp := 0
+	for _, count := range counts {
+		xp += count.XP
+	}
+	events, err := a.Store.Gamification().GetXPEvents(userID, page, perPage)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "userID = %s", userID)
+	}
+	return model.LevelForXP(xp), events, nil
+}
+
+// GetUserAchievements returns achievements unlocked by the user
+func (a *App) GetUserAchievements(userID string) ([]*model.UserAchievement, error) {
+	userAchievements, err := a.Store.Gamification().GetAchievements(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "userID = %s", userID)
+	}
+	achievements := make([]*model.UserAchievement, 0, len(userAchievements))
+	for _, userAchievement := range userAchievements {
+		// achievements removed from the data file are not shown
+		if achievement := a.getAchievement(userAchievement.AchievementID); achievement != nil {
+			userAchievement.Achievement = achievement
+			achievements = append(achievements, userAchievement)
+		}
+	}
+	return achievements, nil
+}
+
+// trackXPEvent awards XP for the event and unlocks achievements triggered by it.
+// Gamification shouldn't break learning, so errors are only logged.
+func (a *App) trackXPEvent(userID, eventType, refID string) {
+	event := &model.XPEvent{
+		UserID:    userID,
+		EventType: eventType,
+		RefID:     refID,
+	}
+	awarded, err := a.Store.Gamification().SaveXPEvent(event)
+	if err != nil {
+		a.Log.Error("can't save xp event", log.String("userID", userID), log.String("event", eventType), log.Err(err))
+		return
+	}
+	if !awarded {
+		return
+	}
+	if err := a.addLeaderboardScores(event); err != nil {
+		a.Log.Error("can't add leaderboard scores", log.String("userID", userID), log.String("event", eventType), log.Err(err))
+	}
+	if err := a.evaluateAchievements(userID, eventType); err != nil {
+		a.Log.Error("can't evaluate achievements", log.String("userID", userID), log.String("event", eventType), log.Err(err))
+	}
+}
+
+// evaluateAchievements unlocks achievements triggered by the event if their conditions are met
+func (a *App) evaluateAchievements(userID, eventType string) error {
+	candidates := []*model.Achievement{}
+	for _, achievement := range a.Achievements {
+		if achievement.TriggeredBy(eventType) {
+			candidates = append(candidates, achievement)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	unlocked, err := a.Store.Gamification().GetAchievements(userID)
+	if err != nil {
+		return errors.Wrap(err, "can't get unlocked achievements")
+	}
+	unlockedIDs := make(map[string]bool, len(unlocked))
+	for _, achievement := range unlocked {
+		unlockedIDs[achievement.AchievementID] = true
+	}
+
+	counts, err := a.Store.Gamification().GetXPEventCounts(userID)
+	if err != nil {
+		return errors.Wrap(err, "can't get xp event counts")
+	}
+	streak, today, err := a.GetStreak(userID)
+	if err != nil {
+		return errors.Wrap(err, "can't get streak")
+	}
+	metrics := model.NewUserMetrics(counts, streak.Current(today))
+
+	for _, achievement := range candidates {
+		if unlockedIDs[achievement.ID] || !achievement.IsMet(metrics) {
+			continue
+		}
+		saved, err := a.Store.Gamification().SaveAchievement(&model.UserAchievement{
+			UserID:        userID,
+			AchievementID: achievement.ID,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "can't save achievement %s", achievement.ID)
+		}
+		if !saved {
+			continue
+		}
+		if err := a.postAchievementUnlocked(userID, achievement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postAchievementUnlocked celebrates the unlocked achievement in the bot chat
+func (a *App) postAchievementUnlocked(userID string, achievement *model.Achievement) error {
+	_, err := a.CreatePost(&model.Post{
+		LocationID: fmt.Sprintf("%s_%s", userID, model.BotID),
+		UserID:     model.BotID,
+		Message:    fmt.Sprintf("%s Congratulations! You've unlocked the **%s** badge: %s 🎉", achievement.Icon, achievement.Name, achievement.Description),
+		PostType:   model.PostTypeAchievementUnlocked,
+		Props: map[string]interface{}{
+			"achievement_id": achievement.ID,
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "can't create post for achievement %s", achievement.ID)
+	}
+	return nil
+}
+
+func (a *App) getAchievement(achievementID string) *model.Achievement {
+	for _, achievement := range a.Achievements {
+		if achievement.ID == achievementID {
+			return achievement
+		}
+	}
+	return nil
+}
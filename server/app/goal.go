@@ -7,6 +7,8 @@ import (
 	"github.com/pkg/errors"
 )
 
+const maxGoals = 3
+
 // CreateGoal creates new goal for a user
 func (a *App) CreateGoal(userID, nodeID string) error {
 	goal, err := a.Store.Goal().Get(userID, nodeID)
@@ -24,7 +26,7 @@ func (a *App) CreateGoal(userID, nodeID string) error {
 		if err2 := a.Store.Goal().Reset(userID, nodeID); err != nil {
 			return errors.Wrap(err2, "can't reset goal")
 		}
-		return nil
+		return a.invalidatePlan(userID)
 	}
 
 	// no goal is set, create new one
@@ -32,44 +34,70 @@ func (a *App) CreateGoal(userID, nodeID string) error {
 	if err != nil {
 		return errors.Wrapf(err, "userID = %s, nodeID = %s", userID, nodeID)
 	}
-	return nil
+	return a.invalidatePlan(userID)
 }
 
 // FinishGoal finishes a goal for a user
 func (a *App) FinishGoal(userID, nodeID string) error {
-	err := a.Store.Goal().Finish(userID, nodeID)
-	if err != nil {
-		return errors.Wrapf(err, "userID = %s, nodeID = %s", userID, nodeID)
+	if err := a.finishGoal(userID, nodeID); err != nil {
+		return err
+	}
+	return a.invalidatePlan(userID)
+}
+
+// finishGoal finishes the user's goal on the node, if any
+func (a *App) finishGoal(userID, nodeID string) error {
+	if err := a.Store.Goal().Finish(userID, nodeID); err != nil {
+		return errors.Wrapf(err, "can't finish goal for user %s, node %s", userID, nodeID)
 	}
 	return nil
 }
 
+// isActiveGoal returns true if the node is user's goal which is neither finished nor deleted
+func (a *App) isActiveGoal(userID, nodeID string) (bool, error) {
+	goal, err := a.Store.Goal().Get(userID, nodeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "userID = %s, nodeID = %s", userID, nodeID)
+	}
+	return goal.FinishedAt == 0 && goal.DeletedAt == 0, nil
+}
+
 // DeleteGoal deletes a goal for a user
 func (a *App) DeleteGoal(userID, nodeID string) error {
 	err := a.Store.Goal().Delete(userID, nodeID)
 	if err != nil {
 		return errors.Wrapf(err, "userID = %s, nodeID = %s", userID, nodeID)
 	}
-	return nil
+	return a.invalidatePlan(userID)
 }
 
-// DeleteGoal deletes a goal for a user
+// GetGoals returns at most maxGoals of user's active goals ordered by priority,
+// the rest is filled with the default goals suggested to the user
 func (a *App) GetGoals(userID string) ([]*model.GoalWithData, error) {
-	maxGoals := 3
 	goals, err := a.Store.Goal().GetAllWithData(userID)
 	if err != nil {
 		return nil, errors.Wrapf(err, "userID = %s", userID)
 	}
 
 	if len(goals) >= maxGoals {
-		return goals[:maxGoals], nil
+		return a.withEstimates(userID, goals[:maxGoals])
 	}
 	defaultGoals, err := a.Store.Goal().DefaultGoalsForUser(userID)
 	if err != nil {
 		return nil, errors.Wrap(err, "can't get default goals")
 	}
 
+	isGoal := make(map[string]bool, len(goals))
+	for _, goal := range goals {
+		isGoal[goal.NodeID] = true
+	}
 	for i := 0; len(goals) < maxGoals && i < len(defaultGoals); i++ {
+		if isGoal[defaultGoals[i]] {
+			continue
+		}
 		node, err := a.Store.Node().Get(defaultGoals[i])
 		if err != nil {
 			return nil, errors.Wrapf(err, "can't get node = %s", defaultGoals[i])
@@ -81,9 +109,30 @@ func (a *App) GetGoals(userID string) ([]*model.GoalWithData, error) {
 		})
 	}
 
-	if maxGoals > len(goals) {
-		maxGoals = len(goals)
+	return a.withEstimates(userID, goals)
+}
+
+// UpdateGoalSettings updates planner settings of the goal, user's plan is rebuilt on the next request
+func (a *App) UpdateGoalSettings(userID, nodeID string, settings *model.GoalSettings) error {
+	if _, err := a.Store.Goal().Get(userID, nodeID); err != nil {
+		return errors.Wrapf(err, "userID = %s, nodeID = %s", userID, nodeID)
 	}
+	if err := a.Store.Goal().UpdateSettings(userID, nodeID, settings); err != nil {
+		return errors.Wrapf(err, "userID = %s, nodeID = %s", userID, nodeID)
+	}
+	return a.invalidatePlan(userID)
+}
 
-	return goals[:maxGoals], nil
+func (a *App) withEstimates(userID string, goals []*model.GoalWithData) ([]*model.GoalWithData, error) {
+	if len(goals) == 0 {
+		return goals, nil
+	}
+	pace, err := a.GetStudyPace(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get study pace for user %s", userID)
+	}
+	if err := a.addGoalEstimates(userID, pace, goals); err != nil {
+		return nil, errors.Wrap(err, "can't estimate goals")
+	}
+	return goals, nil
 }
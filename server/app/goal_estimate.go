@@ -0,0 +1,166 @@
+package app
+
+import (
+	"math"
+	"time"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	paceWindowDays = 30
+	// interactions longer than this are most likely tabs left open
+	maxInteractionMinutes = 120.0
+	defaultVideoFactor    = 1.2
+	maxVideoFactor        = 3.0
+)
+
+// GetStudyPace builds user's pace model from the last month of activity
+func (a *App) GetStudyPace(userID string) (*model.StudyPace, error) {
+	pace := &model.StudyPace{
+		MinutesPerDay:  model.DefaultStudyMinutesPerDay,
+		MinutesPerNode: model.DefaultMinutesPerNode,
+		VideoFactor:    defaultVideoFactor,
+	}
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -paceWindowDays).UnixNano() / int64(time.Millisecond)
+
+	stats, err := a.Store.Video().GetWatchStats(userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get watch stats")
+	}
+	if stats.TimesFinished > 0 {
+		pace.VideoFactor = math.Min(math.Max(float64(stats.TimesStarted)/float64(stats.TimesFinished), 1), maxVideoFactor)
+	}
+
+	interactions, err := a.Store.UserInteraction().GetInteractions(userID, since)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get interactions")
+	}
+	minutesPerDay := map[string]float64{}
+	totalMinutes := 0.0
+	for _, interaction := range interactions {
+		if interaction.EndDate <= interaction.StartDate {
+			continue
+		}
+		minutes := math.Min(float64(interaction.EndDate-interaction.StartDate)/float64(time.Minute.Milliseconds()), maxInteractionMinutes)
+		day := time.UnixMilli(interaction.StartDate).Format("2006-01-02")
+		minutesPerDay[day] += minutes
+		totalMinutes += minutes
+	}
+	if totalMinutes == 0 {
+		return pace, nil
+	}
+
+	mean := totalMinutes / paceWindowDays
+	variance := 0.0
+	for day := 0; day < paceWindowDays; day++ {
+		d := now.AddDate(0, 0, -day).Format("2006-01-02")
+		variance += (minutesPerDay[d] - mean) * (minutesPerDay[d] - mean)
+	}
+	pace.MinutesPerDay = mean
+	pace.MinutesPerDayStdDev = math.Sqrt(variance / paceWindowDays)
+	pace.HasHistory = true
+
+	transitions, err := a.Store.Node().GetTimeline(userID, &model.TimelineGetOptions{Since: since, Page: -1})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get timeline")
+	}
+	finished := map[string]bool{}
+	for _, transition := range transitions {
+		if transition.ToStatus == model.NodeStatusFinished {
+			finished[transition.NodeID] = true
+		}
+	}
+	if len(finished) == 0 {
+		return pace, nil
+	}
+	finishedIDs := make([]string, 0, len(finished))
+	for nodeID := range finished {
+		finishedIDs = append(finishedIDs, nodeID)
+	}
+	videoLength, err := a.getTotalVideoLength(finishedIDs)
+	if err != nil {
+		return nil, err
+	}
+	videoMinutes := float64(videoLength) / 60 * pace.VideoFactor
+	pace.MinutesPerNode = math.Max((totalMinutes-videoMinutes)/float64(len(finished)), model.DefaultMinutesPerNode/2)
+	return pace, nil
+}
+
+// EstimateGoal estimates when user will reach the goal with the pace
+func (a *App) EstimateGoal(userID, nodeID string, pace *model.StudyPace, statuses map[string]*model.NodeStatusForUser) (*model.GoalEstimate, error) {
+	remaining := []string{}
+	for _, prereq := range a.getAllPrerequisiteNodes(nodeID) {
+		if status, ok := statuses[prereq]; ok && status.Status == model.NodeStatusFinished {
+			continue
+		}
+		remaining = append(remaining, prereq)
+	}
+	if len(remaining) == 0 {
+		return pace.Estimate(0, 0, model.GetMillis()), nil
+	}
+
+	videoLength, err := a.getTotalVideoLength(remaining)
+	if err != nil {
+		return nil, err
+	}
+	return pace.Estimate(len(remaining), videoLength, model.GetMillis()), nil
+}
+
+// GetGoalEstimates estimates time to each of the user's active goals
+func (a *App) GetGoalEstimates(userID string) (*model.StudyPace, []*model.GoalWithData, error) {
+	goals, err := a.Store.Goal().GetAllWithData(userID)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "userID = %s", userID)
+	}
+	pace, err := a.GetStudyPace(userID)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "can't get study pace for user %s", userID)
+	}
+	if err := a.addGoalEstimates(userID, pace, goals); err != nil {
+		return nil, nil, err
+	}
+	return pace, goals, nil
+}
+
+func (a *App) addGoalEstimates(userID string, pace *model.StudyPace, goals []*model.GoalWithData) error {
+	statusMap, err := a.getStatusMap(userID)
+	if err != nil {
+		return err
+	}
+	for _, goal := range goals {
+		estimate, err := a.EstimateGoal(userID, goal.NodeID, pace, statusMap)
+		if err != nil {
+			return errors.Wrapf(err, "can't estimate goal %s", goal.NodeID)
+		}
+		goal.Estimate = estimate
+	}
+	return nil
+}
+
+func (a *App) getStatusMap(userID string) (map[string]*model.NodeStatusForUser, error) {
+	statuses, err := a.GetStatusesForUser(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get statuses for user %s", userID)
+	}
+	statusMap := make(map[string]*model.NodeStatusForUser, len(statuses))
+	for _, status := range statuses {
+		statusMap[status.NodeID] = status
+	}
+	return statusMap, nil
+}
+
+func (a *App) getTotalVideoLength(nodeIDs []string) (int64, error) {
+	lengths, err := a.Store.Video().GetVideoLengths(nodeIDs)
+	if err != nil {
+		return 0, errors.Wrap(err, "can't get video lengths")
+	}
+	var total int64
+	for _, length := range lengths {
+		total += length.Length
+	}
+	return total, nil
+}
@@ -89,11 +89,20 @@ func (a *App) GetGraphForUser(userID string) (*model.FrontendGraph, error) {
 		return nil, errors.Wrap(err, "can't get user")
 	}
 
+	retentions, err := a.GetRetentions(userID, statusMap)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get retentions")
+	}
+
 	gr := a.GetFrontEndGraph(user.Lang)
 	for i, node := range gr.Nodes {
 		if status, ok := statusMap[node.ID]; ok {
 			gr.Nodes[i].Status = status.Status
 		}
+		if retention, ok := retentions[node.ID]; ok {
+			gr.Nodes[i].Retention = retention
+			gr.Nodes[i].NeedsRefresh = retention < model.RetentionRefreshThreshold
+		}
 	}
 
 	return gr, nil
@@ -117,7 +126,7 @@ func (a *App) populateUserKnowledge(answers map[string]bool, userID string) erro
 			Status:    model.NodeStatusFinished,
 			UpdatedAt: model.GetMillis(),
 		}
-		if err := a.UpdateStatus(status); err != nil {
+		if err := a.setStatus(status); err != nil {
 			return errors.Wrapf(err, "can't update status for user %s, node %s", userID, nodeID)
 		}
 	}
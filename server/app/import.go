@@ -26,10 +26,11 @@ type VideoPart struct {
 
 type ExtendedNode struct {
 	model.Node
-	VideoKeys         []string    `json:"videos"`
-	VideoParts        []VideoPart `json:"video_parts"`
-	TextFileNames     []string    `json:"texts"`
-	QuestionFileNames []string    `json:"questions"`
+	VideoKeys         []string                `json:"videos"`
+	VideoParts        []VideoPart             `json:"video_parts"`
+	TextFileNames     []string                `json:"texts"`
+	QuestionFileNames []string                `json:"questions"`
+	CompletionPolicy  *model.CompletionPolicy `json:"completion_policy"`
 }
 
 var (
@@ -160,6 +161,10 @@ func (a *App) importLeafNodes(url string, parentNodes map[string]ExtendedNode) (
 			Node: *updatedNode,
 		}
 
+		if err := a.importCompletionPolicy(node.CompletionPolicy, updatedNode.ID); err != nil {
+			return "", errors.Wrap(err, "can't import completion policy")
+		}
+
 		if err := a.importVideos(node.VideoKeys, node.VideoParts, updatedNode.ID, user.ID); err != nil {
 			return "", errors.Wrap(err, "can't import videos")
 		}
@@ -231,6 +236,10 @@ func (a *App) importParentNodes(url string) ([]string, map[string]ExtendedNode,
 		nodes[id] = ExtendedNode{
 			Node: *updatedNode,
 		}
+
+		if err3 := a.importCompletionPolicy(node.CompletionPolicy, updatedNode.ID); err3 != nil {
+			return nil, nil, errors.Wrap(err3, "can't import completion policy")
+		}
 	}
 
 	if err4 := a.importGraph(url, nodes); err4 != nil {
@@ -642,12 +651,8 @@ func (a *App) saveSolutionCode(url, name, lang, solutionCode, nodeID, userID str
 			return nil
 		}
 	}
-	sName := fmt.Sprintf("Code: %s", name)
-	if lang == model.LanguageGeorgian {
-		sName = fmt.Sprintf("კოდი: %s", name)
-	}
 	if _, err := a.Store.Text().Save(&model.Text{
-		Name:     sName,
+		Name:     solutionCodeTextName(name, lang),
 		Text:     fmt.Sprintf("```java\n%s```", codeContent),
 		NodeID:   nodeID,
 		AuthorID: userID,
@@ -657,6 +662,25 @@ func (a *App) saveSolutionCode(url, name, lang, solutionCode, nodeID, userID str
 	return nil
 }
 
+// solutionCodeTextName returns the name of the text with the reference solution code of the problem
+func solutionCodeTextName(name, lang string) string {
+	if lang == model.LanguageGeorgian {
+		return fmt.Sprintf("კოდი: %s", name)
+	}
+	return fmt.Sprintf("Code: %s", name)
+}
+
+func (a *App) importCompletionPolicy(policy *model.CompletionPolicy, nodeID string) error {
+	if policy == nil {
+		return nil
+	}
+	policy.NodeID = nodeID
+	if _, err := a.Store.CompletionPolicy().Save(policy); err != nil {
+		return errors.Wrapf(err, "can't save completion policy for node %s", nodeID)
+	}
+	return nil
+}
+
 func (a *App) importNode(node *model.Node) (*model.Node, error) {
 	var updatedNode *model.Node
 	oldNode, err := a.Store.Node().GetByName(node.Name)
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// GetLeaderboard returns top n users of the scope ranked by the metric in the window.
+// scopeID is the course id for the course scope, the study group id for the group scope and is ignored by the other scopes.
+func (a *App) GetLeaderboard(viewerID, scope, scopeID, window, metric string, n int) (*model.Leaderboard, error) {
+	if err := model.IsValidLeaderboardRequest(scope, window, metric); err != nil {
+		return nil, err
+	}
+
+	options := &model.LeaderboardGetOptions{
+		Metric:   metric,
+		Period:   model.LeaderboardPeriod(window, time.Now()),
+		ViewerID: viewerID,
+		Limit:    n,
+	}
+	switch scope {
+	case model.LeaderboardScopeCohort:
+		// cohort is the users registered in the same month
+		viewer, err := a.Store.User().Get(viewerID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't get user %s", viewerID)
+		}
+		registeredAt := time.UnixMilli(viewer.CreatedAt).UTC()
+		from := time.Date(registeredAt.Year(), registeredAt.Month(), 1, 0, 0, 0, 0, time.UTC)
+		options.CohortFrom = from.UnixMilli()
+		options.CohortTo = from.AddDate(0, 1, 0).UnixMilli()
+	case model.LeaderboardScopeCourse:
+		if _, ok := a.Graph.Nodes[scopeID]; !ok {
+			return nil, errors.Errorf("unknown course %s", scopeID)
+		}
+		options.CourseID = scopeID
+	case model.LeaderboardScopeFriends:
+		options.FriendsOf = viewerID
+	case model.LeaderboardScopeGroup:
+		isMember, err := a.IsStudyGroupMember(scopeID, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		if !isMember {
+			return nil, errors.Errorf("user %s is not a member of study group %s", viewerID, scopeID)
+		}
+		options.StudyGroupID = scopeID
+	}
+
+	entries, err := a.Store.Leaderboard().GetLeaderboard(options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get %s leaderboard", scope)
+	}
+	leaderboard := &model.Leaderboard{
+		Scope:   scope,
+		Window:  window,
+		Metric:  metric,
+		Entries: entries,
+	}
+
+	me, err := a.Store.Leaderboard().GetEntry(viewerID, options)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrapf(err, "can't get leaderboard entry for user %s", viewerID)
+	}
+	leaderboard.Me = me
+	return leaderboard, nil
+}
+
+// GetFriends returns user's friends, the users who added each other
+func (a *App) GetFriends(userID string) ([]*model.User, error) {
+	friends, err := a.Store.Leaderboard().GetFriends(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "userID = %s", userID)
+	}
+	return friends, nil
+}
+
+// GetFriendRequests returns the users waiting for the user to add them back
+func (a *App) GetFriendRequests(userID string) ([]*model.User, error) {
+	users, err := a.Store.Leaderboard().GetFriendRequests(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "userID = %s", userID)
+	}
+	return users, nil
+}
+
+// AddFriend sends the friend request or accepts the one sent by the friend.
+// Users see each other on the friends leaderboard only after both of them added each other.
+func (a *App) AddFriend(userID, friendID string) error {
+	if userID == friendID {
+		return errors.New("can't add yourself as a friend")
+	}
+	if _, err := a.Store.User().Get(friendID); err != nil {
+		return errors.Wrapf(err, "can't get user %s", friendID)
+	}
+	if err := a.Store.Leaderboard().AddFriend(userID, friendID); err != nil {
+		return errors.Wrapf(err, "userID = %s, friendID = %s", userID, friendID)
+	}
+	return nil
+}
+
+// RemoveFriend removes friend from the user's friends, declines or cancels the friend request
+func (a *App) RemoveFriend(userID, friendID string) error {
+	if err := a.Store.Leaderboard().RemoveFriend(userID, friendID); err != nil {
+		return errors.Wrapf(err, "userID = %s, friendID = %s", userID, friendID)
+	}
+	return nil
+}
+
+// addLeaderboardScores updates cached leaderboard scores with the awarded XP event
+func (a *App) addLeaderboardScores(event *model.XPEvent) error {
+	periods := model.LeaderboardPeriods(time.UnixMilli(event.CreatedAt))
+	if err := a.Store.Leaderboard().AddScore(event.UserID, model.LeaderboardMetricXP, event.XP, periods); err != nil {
+		return err
+	}
+	if event.EventType == model.XPEventNodeFinished {
+		if err := a.Store.Leaderboard().AddScore(event.UserID, model.LeaderboardMetricNodes, 1, periods); err != nil {
+			return err
+		}
+	}
+	return nil
+}
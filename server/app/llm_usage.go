@@ -0,0 +1,92 @@
+package app
+
+import (
+	"math"
+	"time"
+
+	"github.com/oseducation/knowledge-graph/config"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// defaultLLMBudget limits the plans without a configured budget
+var defaultLLMBudget = config.LLMBudget{MonthlyTokens: 300000}
+
+// GetBudgetStatus returns the user's usage of the language model this month against the budget of the plan.
+// The plan is the user's role, so subscribers (customers) get their own budget.
+func (a *App) GetBudgetStatus(userID string, plan model.RoleType) (*model.LLMBudgetStatus, error) {
+	options := &model.LLMUsageGetOptions{
+		UserID: userID,
+		Since:  beginningOfTheMonth(time.Now()),
+	}
+	total, err := a.Store.LLMUsage().GetTotal(options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get llm usage of user %s", userID)
+	}
+	models, err := a.Store.LLMUsage().GetTotalsByModel(options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get llm usage by model of user %s", userID)
+	}
+
+	status := &model.LLMBudgetStatus{
+		Plan:   plan,
+		Usage:  total,
+		Models: models,
+	}
+	budget := a.getBudget(plan)
+	status.TokenLimit = budget.MonthlyTokens
+	status.CostLimit = budget.MonthlyCost
+	if budget.MonthlyTokens > 0 {
+		status.PercentUsed = float64(total.TotalTokens()) * 100 / float64(budget.MonthlyTokens)
+	}
+	if budget.MonthlyCost > 0 {
+		status.PercentUsed = math.Max(status.PercentUsed, total.Cost*100/budget.MonthlyCost)
+	}
+	status.Exceeded = status.PercentUsed >= 100
+	return status, nil
+}
+
+// getBudget returns the budget of the plan, the plans missing in the configured budgets get the default budget
+func (a *App) getBudget(plan model.RoleType) config.LLMBudget {
+	if budget, ok := a.Config.ChatSettings.Budgets[string(plan)]; ok {
+		return budget
+	}
+	if a.Config.ChatSettings.DefaultBudget != nil {
+		return *a.Config.ChatSettings.DefaultBudget
+	}
+	return defaultLLMBudget
+}
+
+// GetLLMUsage returns the user's usage of the language model since the time in millis
+func (a *App) GetLLMUsage(userID string, since int64) (*model.LLMUsageTotal, error) {
+	total, err := a.Store.LLMUsage().GetTotal(&model.LLMUsageGetOptions{UserID: userID, Since: since})
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get llm usage of user %s", userID)
+	}
+	return total, nil
+}
+
+// GetLLMUsageReport returns the usage of all the users for the period
+func (a *App) GetLLMUsageReport(options *model.LLMUsageGetOptions) (*model.LLMUsageReport, error) {
+	total, err := a.Store.LLMUsage().GetTotal(options)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get llm usage")
+	}
+	users, err := a.Store.LLMUsage().GetTotalsByUser(options)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get llm usage by user")
+	}
+	models, err := a.Store.LLMUsage().GetTotalsByModel(options)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get llm usage by model")
+	}
+	return &model.LLMUsageReport{
+		Total:  total,
+		Users:  users,
+		Models: models,
+	}, nil
+}
+
+func beginningOfTheMonth(now time.Time) int64 {
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).UnixNano() / int64(time.Millisecond)
+}
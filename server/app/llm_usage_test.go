@@ -0,0 +1,32 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/oseducation/knowledge-graph/config"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBudget(t *testing.T) {
+	budgets := map[string]config.LLMBudget{
+		string(model.UserRole):  {MonthlyTokens: 1000},
+		string(model.AdminRole): {},
+	}
+
+	for name, tc := range map[string]struct {
+		defaultBudget *config.LLMBudget
+		plan          model.RoleType
+		expected      config.LLMBudget
+	}{
+		"configured budget":                   {plan: model.UserRole, expected: config.LLMBudget{MonthlyTokens: 1000}},
+		"explicit zero budget isn't limited":  {plan: model.AdminRole, expected: config.LLMBudget{}},
+		"built-in default for missing plans":  {plan: model.TeacherRole, expected: defaultLLMBudget},
+		"configured default for missing plan": {defaultBudget: &config.LLMBudget{MonthlyCost: 2}, plan: model.TeacherRole, expected: config.LLMBudget{MonthlyCost: 2}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			a := &App{Config: &config.Config{ChatSettings: config.ChatSettings{Budgets: budgets, DefaultBudget: tc.defaultBudget}}}
+			require.Equal(t, tc.expected, a.getBudget(tc.plan))
+		})
+	}
+}
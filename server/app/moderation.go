@@ -0,0 +1,286 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/oseducation/knowledge-graph/log"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/oseducation/knowledge-graph/services"
+	"github.com/pkg/errors"
+)
+
+const (
+	blockedInputMessage  = "I can't help with that. Let's keep our conversation about learning!"
+	blockedOutputMessage = "Sorry, I can't continue this answer. Let's get back to the topic!"
+	redactedText         = "[removed]"
+
+	// the streamed answer is checked when a sentence ends after minModerationChunkLength characters,
+	// or at maxModerationChunkLength characters, nothing is flushed to the learner before it's checked
+	minModerationChunkLength = 200
+	maxModerationChunkLength = 1000
+)
+
+var moderationPolicyStrictness = map[string]int{
+	model.ModerationPolicyWarn:   1,
+	model.ModerationPolicyRedact: 2,
+	model.ModerationPolicyBlock:  3,
+}
+
+// moderatedStream checks the answer incrementally and passes on only the moderated text
+type moderatedStream struct {
+	services.ChatStream
+
+	app      *App
+	question string
+	nodeID   string
+	userID   string
+	pending  string
+	finished bool
+	// flagged is set if a policy was applied to the answer, e.g. the answer isn't cached
+	flagged bool
+}
+
+// ModerateInput moderates the learner's message to the tutor.
+// Returns the message passed to the tutor, redacted if needed, and whether the message is blocked.
+func (a *App) ModerateInput(message, nodeID, userID string) (string, bool) {
+	moderated, policy := a.moderate(message, "", model.ModerationDirectionInput, nodeID, userID)
+	return moderated, policy == model.ModerationPolicyBlock
+}
+
+// AnswerBlockedQuestion saves the tutor's answer to the blocked message of the learner
+func (a *App) AnswerBlockedQuestion(nodeID, userID string) (*model.Post, error) {
+	return a.Store.Post().Save(&model.Post{
+		LocationID: fmt.Sprintf("%s_%s", userID, model.BotID),
+		UserID:     model.BotID,
+		Message:    blockedInputMessage,
+		PostType:   model.PostTypeChatGPT,
+		Props:      map[string]interface{}{"node_id": nodeID},
+	})
+}
+
+// BlockedQuestionStream streams the tutor's answer to the blocked message of the learner
+func (a *App) BlockedQuestionStream() services.ChatStream {
+	return services.CreateStringStream(blockedInputMessage)
+}
+
+// moderateOutput moderates the tutor's answer to the question, returns the moderated answer and whether it was flagged
+func (a *App) moderateOutput(answer, question, nodeID, userID string) (string, bool) {
+	moderated, policy := a.moderate(answer, question, model.ModerationDirectionOutput, nodeID, userID)
+	if policy == model.ModerationPolicyBlock {
+		return blockedOutputMessage, true
+	}
+	return moderated, policy != ""
+}
+
+// moderateStream checks the streamed answer before it's flushed to the learner, the stream isn't wrapped if moderation is disabled
+func (a *App) moderateStream(stream services.ChatStream, question, nodeID, userID string) services.ChatStream {
+	if a.Config.ModerationSettings.Provider == "" {
+		return stream
+	}
+	return &moderatedStream{
+		ChatStream: stream,
+		app:        a,
+		question:   question,
+		nodeID:     nodeID,
+		userID:     userID,
+	}
+}
+
+// moderate classifies the text and applies the strictest policy of the flagged categories.
+// Returns the text to pass on and the applied policy, empty if the text isn't flagged.
+// Flagged exchanges are added to the review queue.
+func (a *App) moderate(text, question, direction, nodeID, userID string) (string, string) {
+	if strings.TrimSpace(text) == "" {
+		return text, ""
+	}
+	flag := &model.ModerationFlag{
+		UserID:    userID,
+		NodeID:    nodeID,
+		Direction: direction,
+		Question:  question,
+		Text:      text,
+	}
+	result, err := a.Services.ModerationService.Moderate(text)
+	if err != nil {
+		a.Log.Error("can't moderate text", log.String("userID", userID), log.String("direction", direction), log.Err(err))
+		// the text is blocked unless the tutor is set to keep working while the classifier isn't available,
+		// passed on text is flagged with the warn policy, so e.g. the answer isn't cached
+		flag.Categories = []string{model.ModerationCategoryUnmoderated}
+		flag.Policy = model.ModerationPolicyBlock
+		if a.Config.ModerationSettings.FailOpen {
+			flag.Policy = model.ModerationPolicyWarn
+		}
+		a.saveModerationFlag(flag)
+		if flag.Policy == model.ModerationPolicyBlock {
+			return "", flag.Policy
+		}
+		return text, flag.Policy
+	}
+	if !result.Flagged {
+		return text, ""
+	}
+
+	policy := a.getModerationPolicy(result.Categories)
+	a.Log.Warn("text flagged by moderation", log.String("userID", userID), log.String("direction", direction), log.String("policy", policy), log.String("categories", strings.Join(result.Categories, ",")))
+	flag.Categories = result.Categories
+	flag.Policy = policy
+	a.saveModerationFlag(flag)
+
+	switch policy {
+	case model.ModerationPolicyBlock:
+		return "", policy
+	case model.ModerationPolicyRedact:
+		return redact(text, result.Spans), policy
+	}
+	return text, policy
+}
+
+// saveModerationFlag adds the flagged exchange to the review queue
+func (a *App) saveModerationFlag(flag *model.ModerationFlag) {
+	if _, err := a.Store.Moderation().SaveFlag(flag); err != nil {
+		a.Log.Error("can't save moderation flag", log.String("userID", flag.UserID), log.Err(err))
+	}
+}
+
+// getModerationPolicy returns the strictest policy configured for the categories, texts are blocked by default
+func (a *App) getModerationPolicy(categories []string) string {
+	settings := a.Config.ModerationSettings
+	defaultPolicy := settings.DefaultPolicy
+	if moderationPolicyStrictness[defaultPolicy] == 0 {
+		defaultPolicy = model.ModerationPolicyBlock
+	}
+	if len(categories) == 0 {
+		return defaultPolicy
+	}
+
+	policy := ""
+	for _, category := range categories {
+		categoryPolicy, ok := settings.Policies[category]
+		if !ok || moderationPolicyStrictness[categoryPolicy] == 0 {
+			categoryPolicy = defaultPolicy
+		}
+		if moderationPolicyStrictness[categoryPolicy] > moderationPolicyStrictness[policy] {
+			policy = categoryPolicy
+		}
+	}
+	return policy
+}
+
+// redact replaces the flagged spans of the text, the whole text if the classifier didn't return spans
+func redact(text string, spans [][2]int) string {
+	if len(spans) == 0 {
+		return redactedText
+	}
+	var redacted strings.Builder
+	last := 0
+	for _, span := range spans {
+		if span[0] < last {
+			// overlapping spans of different rules
+			if span[1] > last {
+				last = span[1]
+			}
+			continue
+		}
+		redacted.WriteString(text[last:span[0]])
+		redacted.WriteString(redactedText)
+		last = span[1]
+	}
+	redacted.WriteString(text[last:])
+	return redacted.String()
+}
+
+// moderationChunkEnd returns the length of the text's prefix ready to be checked, 0 if more text is needed
+func moderationChunkEnd(text string) int {
+	if len(text) < minModerationChunkLength {
+		return 0
+	}
+	end := strings.LastIndexAny(text, ".!?\n")
+	if end >= minModerationChunkLength-1 {
+		return end + 1
+	}
+	if len(text) < maxModerationChunkLength {
+		return 0
+	}
+	// without a sentence break the text is cut after the last whitespace, so a word isn't split between the checks
+	if space := strings.LastIndexFunc(text, unicode.IsSpace); space >= minModerationChunkLength-1 {
+		_, size := utf8.DecodeRuneInString(text[space:])
+		return space + size
+	}
+	// or after the last complete rune, e.g. in a long URL
+	end = len(text)
+	start := end - 1
+	for start > 0 && end-start < utf8.UTFMax && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	if !utf8.FullRuneInString(text[start:]) {
+		end = start
+	}
+	return end
+}
+
+// Recv returns the next moderated part of the answer, the answer is cut off if a part is blocked
+func (s *moderatedStream) Recv() (string, error) {
+	for !s.finished {
+		text, err := s.ChatStream.Recv()
+		if errors.Is(err, io.EOF) {
+			s.finished = true
+		} else if err != nil {
+			return "", err
+		}
+		s.pending += text
+
+		end := len(s.pending)
+		if !s.finished {
+			end = moderationChunkEnd(s.pending)
+		}
+		if end == 0 {
+			continue
+		}
+		checked := s.pending[:end]
+		s.pending = s.pending[end:]
+
+		moderated, policy := s.app.moderate(checked, s.question, model.ModerationDirectionOutput, s.nodeID, s.userID)
+		if policy != "" {
+			s.flagged = true
+		}
+		if policy == model.ModerationPolicyBlock {
+			s.finished = true
+			return "\n\n" + blockedOutputMessage, nil
+		}
+		return moderated, nil
+	}
+	return "", io.EOF
+}
+
+// GetModerationFlags returns the flagged exchanges of the review queue
+func (a *App) GetModerationFlags(options *model.ModerationFlagGetOptions) ([]*model.ModerationFlag, error) {
+	flags, err := a.Store.Moderation().GetFlags(options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "options = %v", options)
+	}
+	return flags, nil
+}
+
+// GetModerationFlag returns the flagged exchange
+func (a *App) GetModerationFlag(flagID string) (*model.ModerationFlag, error) {
+	return a.Store.Moderation().GetFlag(flagID)
+}
+
+// ReviewModerationFlag sets the status and the note of the reviewer on the flagged exchange
+func (a *App) ReviewModerationFlag(review *model.ModerationFlag, reviewerID string) (*model.ModerationFlag, error) {
+	flag, err := a.Store.Moderation().GetFlag(review.ID)
+	if err != nil {
+		return nil, err
+	}
+	flag.Status = review.Status
+	flag.Note = review.Note
+	flag.ReviewedBy = reviewerID
+	if err := a.Store.Moderation().UpdateFlag(flag); err != nil {
+		return nil, err
+	}
+	return flag, nil
+}
@@ -0,0 +1,260 @@
+package app
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/oseducation/knowledge-graph/config"
+	"github.com/oseducation/knowledge-graph/log"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/oseducation/knowledge-graph/services"
+	"github.com/oseducation/knowledge-graph/store"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// moderationServiceMock flags the texts containing "bad" with the "test" category
+type moderationServiceMock struct {
+	err error
+}
+
+func (ms *moderationServiceMock) Moderate(text string) (*model.ModerationResult, error) {
+	if ms.err != nil {
+		return nil, ms.err
+	}
+	result := &model.ModerationResult{}
+	offset := 0
+	for {
+		index := strings.Index(text[offset:], "bad")
+		if index == -1 {
+			break
+		}
+		result.Spans = append(result.Spans, [2]int{offset + index, offset + index + 3})
+		offset += index + 3
+	}
+	if len(result.Spans) > 0 {
+		result.Flagged = true
+		result.Categories = []string{"test"}
+	}
+	return result, nil
+}
+
+type moderationStoreMock struct {
+	store.ModerationStore
+	flags []*model.ModerationFlag
+}
+
+func (ms *moderationStoreMock) SaveFlag(flag *model.ModerationFlag) (*model.ModerationFlag, error) {
+	ms.flags = append(ms.flags, flag)
+	return flag, nil
+}
+
+type moderationTestStore struct {
+	store.Store
+	moderation *moderationStoreMock
+}
+
+func (s *moderationTestStore) Moderation() store.ModerationStore {
+	return s.moderation
+}
+
+// chunkStream streams the chunks one by one
+type chunkStream struct {
+	chunks []string
+}
+
+func (cs *chunkStream) Recv() (string, error) {
+	if len(cs.chunks) == 0 {
+		return "", io.EOF
+	}
+	chunk := cs.chunks[0]
+	cs.chunks = cs.chunks[1:]
+	return chunk, nil
+}
+
+func (cs *chunkStream) Close() {}
+
+func newModerationTestApp(settings config.ModerationSettings, moderationService services.ModerationServiceInterface) (*App, *moderationStoreMock) {
+	moderationStore := &moderationStoreMock{}
+	return &App{
+		Log:      log.NewLogger(&log.LoggerConfiguration{NonLogger: true}),
+		Store:    &moderationTestStore{moderation: moderationStore},
+		Config:   &config.Config{ModerationSettings: settings},
+		Services: &services.Services{ModerationService: moderationService},
+	}, moderationStore
+}
+
+func readStream(t *testing.T, stream services.ChatStream) string {
+	var text strings.Builder
+	for {
+		part, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return text.String()
+		}
+		require.NoError(t, err)
+		text.WriteString(part)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	for name, tc := range map[string]struct {
+		text     string
+		spans    [][2]int
+		expected string
+	}{
+		"whole text without spans": {text: "some text", spans: nil, expected: redactedText},
+		"single span":              {text: "my mail is a@b.c, hi", spans: [][2]int{{11, 16}}, expected: "my mail is [removed], hi"},
+		"spans at the edges":       {text: "bad and bad", spans: [][2]int{{0, 3}, {8, 11}}, expected: "[removed] and [removed]"},
+		"overlapping spans":        {text: "one two three", spans: [][2]int{{4, 7}, {5, 13}}, expected: "one [removed]"},
+		"nested spans":             {text: "one two three", spans: [][2]int{{0, 7}, {4, 7}}, expected: "[removed] three"},
+		"multi-byte runes":         {text: "ეს ცუდი სიტყვაა", spans: [][2]int{{7, 19}}, expected: "ეს [removed] სიტყვაა"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, redact(tc.text, tc.spans))
+		})
+	}
+}
+
+func TestModerationChunkEnd(t *testing.T) {
+	sentence := strings.Repeat("a", minModerationChunkLength) + "."
+	georgian := strings.Repeat("ა", maxModerationChunkLength/3+1)
+
+	for name, tc := range map[string]struct {
+		text     string
+		expected int
+	}{
+		"short text waits":                  {text: "Short. Text.", expected: 0},
+		"text is cut after the sentence":    {text: sentence + " next", expected: len(sentence)},
+		"text is cut after the last break":  {text: sentence + " two.\nthree", expected: len(sentence) + 6},
+		"early sentence break waits":        {text: "One. " + strings.Repeat("a", minModerationChunkLength), expected: 0},
+		"long text is cut after whitespace": {text: strings.Repeat("a", maxModerationChunkLength) + " word", expected: maxModerationChunkLength + 1},
+		"long word is cut at its end":       {text: strings.Repeat("a", maxModerationChunkLength), expected: maxModerationChunkLength},
+		"incomplete rune isn't cut":         {text: georgian + "ა"[:2], expected: len(georgian)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			end := moderationChunkEnd(tc.text)
+			require.Equal(t, tc.expected, end)
+			require.True(t, utf8.ValidString(tc.text[:end]))
+		})
+	}
+}
+
+func TestGetModerationPolicy(t *testing.T) {
+	policies := map[string]string{
+		"personal_information": model.ModerationPolicyRedact,
+		"self_harm":            model.ModerationPolicyWarn,
+		"invalid":              "unknown",
+	}
+
+	for name, tc := range map[string]struct {
+		defaultPolicy string
+		categories    []string
+		expected      string
+	}{
+		"configured policy":                 {defaultPolicy: model.ModerationPolicyBlock, categories: []string{"self_harm"}, expected: model.ModerationPolicyWarn},
+		"strictest of the categories":       {defaultPolicy: model.ModerationPolicyBlock, categories: []string{"self_harm", "personal_information"}, expected: model.ModerationPolicyRedact},
+		"default policy for unknown":        {defaultPolicy: model.ModerationPolicyWarn, categories: []string{"violence"}, expected: model.ModerationPolicyWarn},
+		"default policy for invalid":        {defaultPolicy: model.ModerationPolicyRedact, categories: []string{"invalid"}, expected: model.ModerationPolicyRedact},
+		"default policy without categories": {defaultPolicy: model.ModerationPolicyWarn, categories: nil, expected: model.ModerationPolicyWarn},
+		"block without default policy":      {defaultPolicy: "", categories: []string{"violence", "self_harm"}, expected: model.ModerationPolicyBlock},
+		"block for invalid default policy":  {defaultPolicy: "unknown", categories: nil, expected: model.ModerationPolicyBlock},
+	} {
+		t.Run(name, func(t *testing.T) {
+			a, _ := newModerationTestApp(config.ModerationSettings{
+				Provider:      "test",
+				Policies:      policies,
+				DefaultPolicy: tc.defaultPolicy,
+			}, &moderationServiceMock{})
+			require.Equal(t, tc.expected, a.getModerationPolicy(tc.categories))
+		})
+	}
+}
+
+func TestModeratedStreamRecv(t *testing.T) {
+	first := strings.Repeat("a", minModerationChunkLength) + "."
+	second := " The second sentence is bad."
+	clean := " The second sentence is fine."
+
+	for name, tc := range map[string]struct {
+		chunks   []string
+		policy   string
+		failOpen bool
+		err      error
+		expected string
+		flagged  bool
+		flags    []string
+	}{
+		"not flagged answer is passed on": {
+			chunks:   []string{first[:100], first[100:], clean[:10], clean[10:]},
+			policy:   model.ModerationPolicyBlock,
+			expected: first + clean,
+		},
+		"blocked part cuts the answer off": {
+			chunks:   []string{first, second, " " + first},
+			policy:   model.ModerationPolicyBlock,
+			expected: first + "\n\n" + blockedOutputMessage,
+			flagged:  true,
+			flags:    []string{"test"},
+		},
+		"flagged spans are redacted": {
+			chunks:   []string{first, second},
+			policy:   model.ModerationPolicyRedact,
+			expected: first + " The second sentence is [removed].",
+			flagged:  true,
+			flags:    []string{"test"},
+		},
+		"warned answer is passed on": {
+			chunks:   []string{second},
+			policy:   model.ModerationPolicyWarn,
+			expected: second,
+			flagged:  true,
+			flags:    []string{"test"},
+		},
+		"answer is blocked if the classifier fails": {
+			chunks:   []string{first, second},
+			err:      errors.New("classifier isn't available"),
+			expected: "\n\n" + blockedOutputMessage,
+			flagged:  true,
+			flags:    []string{model.ModerationCategoryUnmoderated},
+		},
+		"answer is passed on if the classifier fails open": {
+			chunks:   []string{first, second},
+			failOpen: true,
+			err:      errors.New("classifier isn't available"),
+			expected: first + second,
+			flagged:  true,
+			flags:    []string{model.ModerationCategoryUnmoderated, model.ModerationCategoryUnmoderated},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			a, moderationStore := newModerationTestApp(config.ModerationSettings{
+				Provider:      "test",
+				DefaultPolicy: tc.policy,
+				FailOpen:      tc.failOpen,
+			}, &moderationServiceMock{err: tc.err})
+
+			stream := a.moderateStream(&chunkStream{chunks: tc.chunks}, "question", "", model.NewID())
+			require.Equal(t, tc.expected, readStream(t, stream))
+			require.Equal(t, tc.flagged, stream.(*moderatedStream).flagged)
+
+			categories := []string{}
+			for _, flag := range moderationStore.flags {
+				require.Equal(t, "question", flag.Question)
+				require.Equal(t, model.ModerationDirectionOutput, flag.Direction)
+				categories = append(categories, flag.Categories...)
+			}
+			if tc.flags == nil {
+				tc.flags = []string{}
+			}
+			require.Equal(t, tc.flags, categories)
+		})
+	}
+
+	t.Run("stream isn't wrapped if moderation is disabled", func(t *testing.T) {
+		a, _ := newModerationTestApp(config.ModerationSettings{}, &moderationServiceMock{})
+		stream := &chunkStream{chunks: []string{"bad"}}
+		require.Equal(t, stream, a.moderateStream(stream, "", "", model.NewID()))
+	})
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"github.com/oseducation/knowledge-graph/log"
 	"github.com/oseducation/knowledge-graph/model"
 	"github.com/pkg/errors"
 )
@@ -106,16 +107,68 @@ func (a *App) GetStatusesForUser(userID string) ([]*model.NodeStatusForUser, err
 	return a.Store.Node().GetNodesForUser(userID)
 }
 
+// GetTimeline gets user's history of node status changes
+func (a *App) GetTimeline(userID string, options *model.TimelineGetOptions) ([]*model.NodeStatusTransition, error) {
+	transitions, err := a.Store.Node().GetTimeline(userID, options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "userID = %s", userID)
+	}
+	return transitions, nil
+}
+
+// UpdateStatus updates status of the node for the user.
+// Node can be finished only if user has met its completion policy.
 func (a *App) UpdateStatus(status *model.NodeStatusForUser) error {
 	if err := status.IsValid(); err != nil {
 		return errors.Wrap(err, "status not valid")
 	}
-	if status.Status == model.NodeStatusFinished {
-		if err := a.Store.Goal().Finish(status.UserID, status.NodeID); err != nil {
-			return errors.Wrapf(err, "can't finish goal for user %s, node %s", status.UserID, status.NodeID)
+	if status.Status != model.NodeStatusFinished {
+		return a.setStatus(status)
+	}
+
+	if err := a.checkCompletionPolicy(status.UserID, status.NodeID); err != nil {
+		return err
+	}
+	return a.finishNode(status)
+}
+
+// finishNode marks the node as finished and rewards the user, completion policy must be checked by the caller
+func (a *App) finishNode(status *model.NodeStatusForUser) error {
+	isGoal, err := a.isActiveGoal(status.UserID, status.NodeID)
+	if err != nil {
+		return err
+	}
+	if err := a.setStatus(status); err != nil {
+		return err
+	}
+	if err := a.recordStreakActivity(status.UserID); err != nil {
+		a.Log.Error("can't record streak activity", log.Err(err))
+	}
+	a.trackXPEvent(status.UserID, model.XPEventNodeFinished, status.NodeID)
+	if isGoal {
+		a.trackXPEvent(status.UserID, model.XPEventGoalFinished, status.NodeID)
+		// certificates are issued only for the goals finished under the completion policies
+		if err := a.issueCertificate(status.UserID, status.NodeID); err != nil {
+			a.Log.Error("can't issue certificate", log.String("userID", status.UserID), log.String("nodeID", status.NodeID), log.Err(err))
 		}
 	}
-	return a.Store.Node().UpdateStatus(status)
+	return nil
+}
+
+// setStatus updates status without checking completion policies,
+// e.g. when user's prior knowledge is populated during onboarding
+func (a *App) setStatus(status *model.NodeStatusForUser) error {
+	if err := status.IsValid(); err != nil {
+		return errors.Wrap(err, "status not valid")
+	}
+	if err := a.Store.Node().UpdateStatus(status); err != nil {
+		return err
+	}
+	// the goal is finished only after the status is saved
+	if status.Status == model.NodeStatusFinished {
+		return a.finishGoal(status.UserID, status.NodeID)
+	}
+	return nil
 }
 
 func (a *App) AddVideoToNode(nodeID, videoID, authorID string) (*model.Video, error) {
@@ -0,0 +1,239 @@
+package app
+
+import (
+	"database/sql"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	planHorizonDays = 30
+	minDailyMinutes = 10
+)
+
+// plannedNode is a node waiting to be scheduled during planning
+type plannedNode struct {
+	item      *model.PlanItem
+	remaining int
+}
+
+// GetTodayPlan returns user's plan for today.
+// Plan is rebuilt when it's missing, user fell behind or got ahead of it.
+func (a *App) GetTodayPlan(userID string) (*model.Plan, *model.DayPlan, error) {
+	statusMap, err := a.getStatusMap(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	today := time.Now().In(a.getUserLocation(userID)).Format(model.PlanDateFormat)
+	status := model.PlanStatusOnTrack
+	plan, err := a.Store.Plan().Get(userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, errors.Wrapf(err, "can't get plan for user %s", userID)
+	}
+	if plan != nil {
+		markFinishedItems(plan, statusMap)
+		status = planStatus(plan, today)
+		if status == model.PlanStatusOnTrack && plan.Day(today) != nil {
+			plan.Status = status
+			if err := a.Store.Plan().Save(plan); err != nil {
+				return nil, nil, errors.Wrapf(err, "can't save plan for user %s", userID)
+			}
+			return plan, plan.Day(today), nil
+		}
+	}
+
+	plan, err = a.BuildPlan(userID, statusMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	plan.Status = status
+	if err := a.Store.Plan().Save(plan); err != nil {
+		return nil, nil, errors.Wrapf(err, "can't save plan for user %s", userID)
+	}
+	day := plan.Day(today)
+	if day == nil {
+		day = &model.DayPlan{Date: today, Items: []*model.PlanItem{}}
+	}
+	return plan, day, nil
+}
+
+// BuildPlan spreads remaining paths to the user's goals across the next days.
+// Goals with higher priority and earlier target dates are scheduled first,
+// each goal gets daily study time from its weekly budget, its deadline or user's pace.
+// Days of the plan are in user's timezone.
+func (a *App) BuildPlan(userID string, statusMap map[string]*model.NodeStatusForUser) (*model.Plan, error) {
+	now := time.Now().In(a.getUserLocation(userID))
+	plan := &model.Plan{
+		UserID:    userID,
+		CreatedAt: model.GetMillis(),
+		Status:    model.PlanStatusOnTrack,
+		Days:      []*model.DayPlan{},
+	}
+
+	goals, err := a.Store.Goal().GetAll(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get goals for user %s", userID)
+	}
+	if len(goals) == 0 {
+		return plan, nil
+	}
+	sort.SliceStable(goals, func(i, j int) bool {
+		if goals[i].Priority != goals[j].Priority {
+			return goals[i].Priority > goals[j].Priority
+		}
+		if (goals[i].TargetDate == 0) != (goals[j].TargetDate == 0) {
+			return goals[i].TargetDate != 0
+		}
+		return goals[i].TargetDate < goals[j].TargetDate
+	})
+
+	pace, err := a.GetStudyPace(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get study pace for user %s", userID)
+	}
+
+	scheduled := map[string]bool{}
+	queues := make([][]*plannedNode, len(goals))
+	allNodeIDs := []string{}
+	for i, goal := range goals {
+		for _, nodeID := range a.getAllPrerequisiteNodes(goal.NodeID) {
+			if status, ok := statusMap[nodeID]; ok && status.Status == model.NodeStatusFinished {
+				continue
+			}
+			if scheduled[nodeID] {
+				continue
+			}
+			scheduled[nodeID] = true
+			allNodeIDs = append(allNodeIDs, nodeID)
+			queues[i] = append(queues[i], &plannedNode{
+				item: &model.PlanItem{
+					NodeID:     nodeID,
+					NodeName:   a.Graph.Nodes[nodeID].Name,
+					GoalNodeID: goal.NodeID,
+				},
+			})
+		}
+	}
+	if len(allNodeIDs) == 0 {
+		return plan, nil
+	}
+
+	lengths, err := a.Store.Video().GetVideoLengths(allNodeIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get video lengths")
+	}
+	lengthMap := make(map[string]int64, len(lengths))
+	for _, length := range lengths {
+		lengthMap[length.NodeID] = length.Length
+	}
+
+	// daily budget of the goals without weekly budget and deadline is shared equally
+	unbudgeted := 0
+	for _, goal := range goals {
+		if goal.WeeklyMinutes == 0 && goal.TargetDate == 0 {
+			unbudgeted++
+		}
+	}
+
+	dailyMinutes := make([]int, len(goals))
+	for i, goal := range goals {
+		total := 0
+		for _, node := range queues[i] {
+			node.remaining = int(math.Ceil(pace.RemainingMinutes(1, lengthMap[node.item.NodeID])))
+			total += node.remaining
+		}
+
+		daily := 0.0
+		if goal.WeeklyMinutes > 0 {
+			daily = float64(goal.WeeklyMinutes) / 7
+		}
+		if goal.TargetDate > 0 {
+			daysLeft := math.Max(math.Ceil(float64(goal.TargetDate-model.GetMillis())/float64(24*time.Hour.Milliseconds())), 1)
+			daily = math.Max(daily, float64(total)/daysLeft)
+		}
+		if goal.WeeklyMinutes == 0 && goal.TargetDate == 0 {
+			daily = pace.MinutesPerDay / float64(unbudgeted)
+		}
+		dailyMinutes[i] = int(math.Max(math.Ceil(daily), minDailyMinutes))
+	}
+
+	for d := 0; d < planHorizonDays; d++ {
+		day := &model.DayPlan{
+			Date:  now.AddDate(0, 0, d).Format(model.PlanDateFormat),
+			Items: []*model.PlanItem{},
+		}
+		for i := range goals {
+			budget := dailyMinutes[i]
+			for budget > 0 && len(queues[i]) > 0 {
+				node := queues[i][0]
+				spend := node.remaining
+				if spend > budget {
+					spend = budget
+				}
+				item := *node.item
+				item.Minutes = spend
+				day.Items = append(day.Items, &item)
+				day.Minutes += spend
+				budget -= spend
+				node.remaining -= spend
+				if node.remaining == 0 {
+					queues[i] = queues[i][1:]
+				}
+			}
+		}
+		if len(day.Items) == 0 {
+			break
+		}
+		plan.Days = append(plan.Days, day)
+	}
+	return plan, nil
+}
+
+func (a *App) invalidatePlan(userID string) error {
+	if err := a.Store.Plan().Delete(userID); err != nil {
+		return errors.Wrapf(err, "can't delete plan for user %s", userID)
+	}
+	return nil
+}
+
+func markFinishedItems(plan *model.Plan, statusMap map[string]*model.NodeStatusForUser) {
+	for _, day := range plan.Days {
+		for _, item := range day.Items {
+			if status, ok := statusMap[item.NodeID]; ok && status.Status == model.NodeStatusFinished {
+				item.Finished = true
+			}
+		}
+	}
+}
+
+// planStatus compares user's progress with the plan.
+// User is behind when some items of the past days are not finished,
+// and ahead when all items of today are already finished.
+func planStatus(plan *model.Plan, today string) string {
+	for _, day := range plan.Days {
+		if day.Date >= today {
+			break
+		}
+		for _, item := range day.Items {
+			if !item.Finished {
+				return model.PlanStatusBehind
+			}
+		}
+	}
+
+	todayPlan := plan.Day(today)
+	if todayPlan == nil || len(todayPlan.Items) == 0 {
+		return model.PlanStatusOnTrack
+	}
+	for _, item := range todayPlan.Items {
+		if !item.Finished {
+			return model.PlanStatusOnTrack
+		}
+	}
+	return model.PlanStatusAhead
+}
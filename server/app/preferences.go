@@ -1,6 +1,8 @@
 package app
 
 import (
+	"time"
+
 	"github.com/oseducation/knowledge-graph/model"
 	"github.com/pkg/errors"
 )
@@ -19,6 +21,11 @@ func (a *App) UpdatePreferencesForUser(userID string, preferences []model.Prefer
 				return errors.New("can't update user language")
 			}
 		}
+		if preference.Key == model.TimezonePreferenceKey {
+			if _, err := time.LoadLocation(preference.Value); err != nil {
+				return errors.Wrapf(err, "invalid timezone %s", preference.Value)
+			}
+		}
 	}
 	return a.Store.Preferences().Save(preferences)
 }
@@ -5,15 +5,61 @@ import (
 	"github.com/pkg/errors"
 )
 
-// GetQuestion gets question by id
+// GetQuestion gets the practice question by id, exam questions are served only during the exam attempts
 func (a *App) GetQuestion(id string) (*model.Question, error) {
-	text, err := a.Store.Question().Get(id)
+	question, err := a.Store.Question().Get(id)
 	if err != nil {
 		return nil, errors.Wrapf(err, "id = %s", id)
 	}
-	return text, nil
+	if question.ExamOnly {
+		return nil, errors.Errorf("question %s is an exam question", id)
+	}
+	return question, nil
 }
 
 func (a *App) GetOnboardingQuestions(courseID string) ([][]*model.Question, error) {
 	return a.Store.Question().GetOnboardingQuestions(courseID)
 }
+
+// AnswerQuestion saves user's answer on the practice question and returns whether it was right.
+// Exam questions are answered only during the exam attempts, otherwise their answers would be revealed.
+func (a *App) AnswerQuestion(userID, questionID, choiceID string, timeSpent int64) (*model.QuestionAnswer, error) {
+	question, err := a.Store.Question().Get(questionID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "questionID = %s", questionID)
+	}
+	if question.ExamOnly {
+		return nil, errors.Errorf("question %s is an exam question", questionID)
+	}
+	return a.saveAnswer(userID, question, choiceID, timeSpent)
+}
+
+func (a *App) saveAnswer(userID string, question *model.Question, choiceID string, timeSpent int64) (*model.QuestionAnswer, error) {
+	if question.Status != model.QuestionStatusApproved {
+		return nil, errors.Errorf("question %s isn't approved", question.ID)
+	}
+	answer := &model.QuestionAnswer{
+		UserID:     userID,
+		QuestionID: question.ID,
+		ChoiceID:   choiceID,
+		TimeSpent:  timeSpent,
+	}
+	found := false
+	for _, choice := range question.Choices {
+		if choice.ID == choiceID {
+			answer.IsRight = choice.IsRightChoice
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, errors.Errorf("choice %s doesn't belong to question %s", choiceID, question.ID)
+	}
+	if err := a.Store.Question().SaveAnswer(answer); err != nil {
+		return nil, errors.Wrapf(err, "can't save answer for question %s", question.ID)
+	}
+	if answer.IsRight {
+		a.trackXPEvent(userID, model.XPEventCorrectAnswer, question.ID)
+	}
+	return answer, nil
+}
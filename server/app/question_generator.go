@@ -0,0 +1,164 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/oseducation/knowledge-graph/log"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/oseducation/knowledge-graph/services"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultNumberOfGeneratedQuestions = 5
+	MaxNumberOfGeneratedQuestions     = 20
+
+	// maxGeneratorContentLength limits the node's texts in the generation prompt
+	maxGeneratorContentLength = 12000
+
+	generateQuestionsPrompt = `You write practice questions for an online course. Write %d multiple choice questions that check the understanding of the topic, not the memorization of the wording. Each question has from 3 to 5 choices, exactly one of them is right, the wrong choices are plausible distractors based on typical misconceptions. The explanation tells why the right choice is right and the distractors are wrong.
+Answer only with a JSON array in the format:
+[{"question": "...", "explanation": "...", "choices": [{"choice": "...", "is_right_choice": true}, {"choice": "...", "is_right_choice": false}]}]`
+)
+
+// generatedQuestion is a question in the format the language model is asked to answer with
+type generatedQuestion struct {
+	Question    string `json:"question"`
+	Explanation string `json:"explanation"`
+	Choices     []struct {
+		Choice        string `json:"choice"`
+		IsRightChoice bool   `json:"is_right_choice"`
+	} `json:"choices"`
+}
+
+// GenerateQuestions generates multiple choice questions from the node's description and texts with the language model.
+// The questions are saved as drafts for admins to review, the invalid ones are skipped.
+// If examOnly is set, the questions are generated for the exams of the node's parent instead of the practice.
+func (a *App) GenerateQuestions(nodeID, userID string, count int, examOnly bool) ([]*model.Question, error) {
+	node, err := a.Store.Node().Get(nodeID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get node %s", nodeID)
+	}
+	texts, err := a.Store.Text().GetTexts(&model.TextGetOptions{NodeID: nodeID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get texts of node %s", nodeID)
+	}
+
+	var content strings.Builder
+	for _, text := range texts {
+		content.WriteString(text.Text)
+		content.WriteString("\n")
+	}
+	contentText := truncateOnRuneBoundary(content.String(), maxGeneratorContentLength)
+	if strings.TrimSpace(node.Description) == "" && strings.TrimSpace(contentText) == "" {
+		return nil, errors.Errorf("node %s has no content to generate questions from", nodeID)
+	}
+
+	message := fmt.Sprintf("Topic: %s\nDescription: %s\nContent:\n%s", node.Name, node.Description, contentText)
+	answer, err := a.Services.LLMService.Send(userID, fmt.Sprintf(generateQuestionsPrompt, count), services.LLMTierPremium, []string{message})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't generate questions")
+	}
+	generated, err := parseGeneratedQuestions(answer)
+	if err != nil {
+		return nil, err
+	}
+
+	questions := []*model.Question{}
+	for _, g := range generated {
+		question := &model.Question{
+			Name:         fmt.Sprintf("generated_%s", model.NewID()),
+			Question:     strings.TrimSpace(g.Question),
+			QuestionType: model.QuestionTypeMultipleChoice,
+			NodeID:       nodeID,
+			Explanation:  strings.TrimSpace(g.Explanation),
+			Status:       model.QuestionStatusDraft,
+			ExamOnly:     examOnly,
+		}
+		for _, c := range g.Choices {
+			question.Choices = append(question.Choices, model.QuestionChoice{
+				Choice:        strings.TrimSpace(c.Choice),
+				IsRightChoice: c.IsRightChoice,
+			})
+		}
+		if err := question.IsValidMultipleChoice(); err != nil {
+			a.Log.Warn("skipping invalid generated question", log.String("nodeID", nodeID), log.Err(err))
+			continue
+		}
+		saved, err := a.Store.Question().Save(question)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't save generated question for node %s", nodeID)
+		}
+		questions = append(questions, saved)
+	}
+	return questions, nil
+}
+
+// parseGeneratedQuestions parses the JSON array of the answer, the models often wrap it in a markdown code block
+func parseGeneratedQuestions(answer string) ([]generatedQuestion, error) {
+	start := strings.Index(answer, "[")
+	end := strings.LastIndex(answer, "]")
+	if start == -1 || end < start {
+		return nil, errors.Errorf("no questions in the answer: %s", answer)
+	}
+	var generated []generatedQuestion
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &generated); err != nil {
+		return nil, errors.Wrap(err, "can't parse generated questions")
+	}
+	return generated, nil
+}
+
+// GetQuestionsForReview returns the questions with the status, e.g. the drafts waiting for the review
+func (a *App) GetQuestionsForReview(options *model.QuestionGetOptions) ([]*model.Question, error) {
+	options.WithAnswers = true
+	questions, err := a.Store.Question().GetQuestions(options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "options = %v", options)
+	}
+	return questions, nil
+}
+
+// UpdateQuestion edits the question before it's approved.
+// Approved questions aren't edited, the learners' answers reference their choices.
+func (a *App) UpdateQuestion(update *model.Question) (*model.Question, error) {
+	question, err := a.Store.Question().Get(update.ID)
+	if err != nil {
+		return nil, err
+	}
+	if question.Status == model.QuestionStatusApproved {
+		return nil, errors.Errorf("approved question %s can't be edited", question.ID)
+	}
+	question.Question = strings.TrimSpace(update.Question)
+	question.Explanation = strings.TrimSpace(update.Explanation)
+	question.Choices = update.Choices
+	if err := question.IsValidMultipleChoice(); err != nil {
+		return nil, err
+	}
+	if err := a.Store.Question().Update(question); err != nil {
+		return nil, err
+	}
+	return a.Store.Question().Get(question.ID)
+}
+
+// ReviewQuestion approves or rejects the question
+func (a *App) ReviewQuestion(questionID, status string) (*model.Question, error) {
+	if status != model.QuestionStatusApproved && status != model.QuestionStatusRejected {
+		return nil, errors.Errorf("invalid review status %s", status)
+	}
+	question, err := a.Store.Question().Get(questionID)
+	if err != nil {
+		return nil, err
+	}
+	if status == model.QuestionStatusApproved {
+		if err := question.IsValidMultipleChoice(); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.Store.Question().UpdateStatus(question.ID, status); err != nil {
+		return nil, err
+	}
+	question.Status = status
+	return question, nil
+}
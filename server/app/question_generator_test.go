@@ -0,0 +1,50 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGeneratedQuestions(t *testing.T) {
+	questionJSON := `[{"question": "Q?", "explanation": "E.", "choices": [{"choice": "A", "is_right_choice": true}, {"choice": "B", "is_right_choice": false}]}]`
+
+	for name, answer := range map[string]string{
+		"plain JSON":            questionJSON,
+		"JSON in a code block":  "```json\n" + questionJSON + "\n```",
+		"JSON inside some text": "Here are the questions:\n" + questionJSON + "\nGood luck!",
+	} {
+		t.Run(name, func(t *testing.T) {
+			generated, err := parseGeneratedQuestions(answer)
+			require.NoError(t, err)
+			require.Len(t, generated, 1)
+			require.Equal(t, "Q?", generated[0].Question)
+			require.Equal(t, "E.", generated[0].Explanation)
+			require.Len(t, generated[0].Choices, 2)
+			require.Equal(t, "A", generated[0].Choices[0].Choice)
+			require.True(t, generated[0].Choices[0].IsRightChoice)
+			require.False(t, generated[0].Choices[1].IsRightChoice)
+		})
+	}
+
+	for name, answer := range map[string]string{
+		"empty answer":       "",
+		"no array":           `{"question": "Q?"}`,
+		"unclosed array":     `[{"question": "Q?"}`,
+		"malformed JSON":     `[{"question": "Q?",}]`,
+		"brackets reversed":  `] not JSON [`,
+		"array of strings":   `["Q?"]`,
+		"refusal of a model": "Sorry, I can't write questions on this topic.",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseGeneratedQuestions(answer)
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("empty array", func(t *testing.T) {
+		generated, err := parseGeneratedQuestions("```json\n[]\n```")
+		require.NoError(t, err)
+		require.Empty(t, generated)
+	})
+}
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"math"
+	"sort"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+type answerKey struct {
+	userID     string
+	questionID string
+}
+
+type learnerAccuracy struct {
+	right int
+	total int
+}
+
+// ComputeQuestionStats computes item analysis of every question from learners' first answers
+// and replaces the stored statistics. Only the first answer of the learner on the question is counted,
+// so retries after seeing the explanation don't inflate the results.
+func (a *App) ComputeQuestionStats() ([]*model.QuestionStats, error) {
+	options := &model.QuestionGetOptions{}
+	model.ComposeQuestionOptions(
+		model.QuestionWithAnswers(),
+		model.QuestionPage(0),
+		model.QuestionPerPage(-1))(options)
+	questions, err := a.Store.Question().GetQuestions(options)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get questions")
+	}
+
+	answers, err := a.Store.QuestionStats().GetAllAnswers()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get answers")
+	}
+
+	// answers are ordered by creation time, so the first one seen is the first attempt
+	seen := map[answerKey]bool{}
+	questionAnswers := map[string][]*model.QuestionAnswer{}
+	learners := map[string]*learnerAccuracy{}
+	for _, answer := range answers {
+		key := answerKey{userID: answer.UserID, questionID: answer.QuestionID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		questionAnswers[answer.QuestionID] = append(questionAnswers[answer.QuestionID], answer)
+
+		learner, ok := learners[answer.UserID]
+		if !ok {
+			learner = &learnerAccuracy{}
+			learners[answer.UserID] = learner
+		}
+		learner.total++
+		if answer.IsRight {
+			learner.right++
+		}
+	}
+
+	now := model.GetMillis()
+	stats := make([]*model.QuestionStats, 0, len(questions))
+	for _, question := range questions {
+		s := computeQuestionStats(question, questionAnswers[question.ID], learners)
+		s.ComputedAt = now
+		stats = append(stats, s)
+	}
+
+	if err := a.Store.QuestionStats().SaveAll(stats); err != nil {
+		return nil, errors.Wrap(err, "can't save question stats")
+	}
+	return stats, nil
+}
+
+// GetQuestionStats returns the last computed statistics
+func (a *App) GetQuestionStats(options *model.QuestionStatsGetOptions) ([]*model.QuestionStats, error) {
+	stats, err := a.Store.QuestionStats().GetStats(options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "options = %v", options)
+	}
+	return stats, nil
+}
+
+// GetStatsForQuestion returns the last computed statistics of the question
+func (a *App) GetStatsForQuestion(questionID string) (*model.QuestionStats, error) {
+	stats, err := a.Store.QuestionStats().Get(questionID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "questionID = %s", questionID)
+	}
+	return stats, nil
+}
+
+func computeQuestionStats(question *model.Question, answers []*model.QuestionAnswer, learners map[string]*learnerAccuracy) *model.QuestionStats {
+	s := &model.QuestionStats{
+		QuestionID: question.ID,
+		NodeID:     question.NodeID,
+		Name:       question.Name,
+		Attempts:   len(answers),
+		Choices:    make([]*model.QuestionChoiceStats, 0, len(question.Choices)),
+	}
+
+	picks := map[string]int{}
+	right := 0
+	times := []int64{}
+	for _, answer := range answers {
+		picks[answer.ChoiceID]++
+		if answer.IsRight {
+			right++
+		}
+		if answer.TimeSpent > 0 {
+			times = append(times, answer.TimeSpent)
+		}
+	}
+	if s.Attempts > 0 {
+		s.PercentCorrect = float64(right) * 100 / float64(s.Attempts)
+	}
+
+	rightPicks, maxWrongPicks := 0, 0
+	for _, choice := range question.Choices {
+		choiceStats := &model.QuestionChoiceStats{
+			ChoiceID: choice.ID,
+			Choice:   choice.Choice,
+			IsRight:  choice.IsRightChoice,
+			Picks:    picks[choice.ID],
+		}
+		if s.Attempts > 0 {
+			choiceStats.PickRate = float64(choiceStats.Picks) / float64(s.Attempts)
+		}
+		if choice.IsRightChoice {
+			rightPicks += choiceStats.Picks
+		} else if choiceStats.Picks > maxWrongPicks {
+			maxWrongPicks = choiceStats.Picks
+		}
+		s.Choices = append(s.Choices, choiceStats)
+	}
+	s.Flagged = maxWrongPicks > rightPicks
+
+	s.Discrimination = pointBiserial(answers, learners)
+	s.MedianTimeSpent = median(times)
+	return s
+}
+
+// pointBiserial correlates answering the question right with learner's accuracy on the other questions.
+// Learners who answered only this question are skipped, the result is 0 if it can't be computed.
+func pointBiserial(answers []*model.QuestionAnswer, learners map[string]*learnerAccuracy) float64 {
+	var sumRight, sumWrong, sum, sumSquares float64
+	var nRight, nWrong int
+	for _, answer := range answers {
+		learner := learners[answer.UserID]
+		if learner.total < 2 {
+			continue
+		}
+		rest := learner.right
+		if answer.IsRight {
+			rest--
+		}
+		accuracy := float64(rest) / float64(learner.total-1)
+		sum += accuracy
+		sumSquares += accuracy * accuracy
+		if answer.IsRight {
+			sumRight += accuracy
+			nRight++
+		} else {
+			sumWrong += accuracy
+			nWrong++
+		}
+	}
+
+	n := nRight + nWrong
+	if nRight == 0 || nWrong == 0 {
+		return 0
+	}
+	mean := sum / float64(n)
+	variance := sumSquares/float64(n) - mean*mean
+	if variance <= 0 {
+		return 0
+	}
+	p := float64(nRight) / float64(n)
+	return (sumRight/float64(nRight) - sumWrong/float64(nWrong)) / math.Sqrt(variance) * math.Sqrt(p*(1-p))
+}
+
+func median(values []int64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return values[mid]
+	}
+	return (values[mid-1] + values[mid]) / 2
+}
@@ -0,0 +1,276 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	reviewQuizSize = 5
+	// maxQuizQuestionsPerNode keeps a single node from taking over the quiz
+	maxQuizQuestionsPerNode = 2
+	// recentQuestionsPeriod is the period during which seen questions aren't repeated
+	recentQuestionsPeriod = 3 * 24 * time.Hour
+
+	quizBaseWeight     = 0.1
+	quizTimeWeight     = 1.0
+	quizAccuracyWeight = 1.0
+	quizGoalWeight     = 2.0
+	// quizTimeHalfDays is the number of days since completion at which the time weight is half of its maximum
+	quizTimeHalfDays = 7.0
+)
+
+type quizCandidate struct {
+	nodeID string
+	weight float64
+}
+
+// CreateReviewQuiz builds a quiz from questions of the finished nodes.
+// Nodes are drawn randomly, weighted by time since completion, past accuracy on their questions
+// and closeness to the user's active goals. Questions seen recently aren't repeated.
+func (a *App) CreateReviewQuiz(userID string) (*model.ReviewQuiz, error) {
+	candidates, err := a.getQuizCandidates(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	since := time.Now().Add(-recentQuestionsPeriod).UnixMilli()
+	recentIDs, err := a.Store.Quiz().GetRecentQuestionIDs(userID, since)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get recent questions for user %s", userID)
+	}
+	recent := make(map[string]bool, len(recentIDs))
+	for _, id := range recentIDs {
+		recent[id] = true
+	}
+
+	quiz := &model.ReviewQuiz{UserID: userID}
+	for len(candidates) > 0 && len(quiz.Questions) < reviewQuizSize {
+		i := a.pickWeighted(candidates)
+		nodeID := candidates[i].nodeID
+		candidates = append(candidates[:i], candidates[i+1:]...)
+
+		questions, err := a.getNodeQuestions(nodeID)
+		if err != nil {
+			return nil, err
+		}
+		a.Random.Shuffle(len(questions), func(i, j int) {
+			questions[i], questions[j] = questions[j], questions[i]
+		})
+		added := 0
+		for _, question := range questions {
+			if recent[question.ID] || added == maxQuizQuestionsPerNode || len(quiz.Questions) == reviewQuizSize {
+				continue
+			}
+			quiz.Questions = append(quiz.Questions, &model.QuizQuestion{
+				QuestionID: question.ID,
+				NodeID:     nodeID,
+				Question:   question,
+			})
+			added++
+		}
+	}
+	if len(quiz.Questions) == 0 {
+		return nil, errors.New("no questions to review yet, finish some topics first")
+	}
+
+	questions := quiz.Questions
+	quiz, err = a.Store.Quiz().Save(quiz)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't save quiz for user %s", userID)
+	}
+	quiz.Questions = questions
+	return quiz, nil
+}
+
+// GetReviewQuiz gets the quiz with its questions
+func (a *App) GetReviewQuiz(id string) (*model.ReviewQuiz, error) {
+	quiz, err := a.Store.Quiz().Get(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "id = %s", id)
+	}
+	for _, quizQuestion := range quiz.Questions {
+		question, err := a.Store.Question().Get(quizQuestion.QuestionID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't get question %s", quizQuestion.QuestionID)
+		}
+		quizQuestion.Question = question
+	}
+	return quiz, nil
+}
+
+// GetReviewQuizzes returns user's quizzes
+func (a *App) GetReviewQuizzes(userID string, page, perPage int) ([]*model.ReviewQuiz, error) {
+	quizzes, err := a.Store.Quiz().GetForUser(userID, page, perPage)
+	if err != nil {
+		return nil, errors.Wrapf(err, "userID = %s", userID)
+	}
+	return quizzes, nil
+}
+
+// AnswerQuizQuestion records the answer on the quiz question and finishes the quiz when all the questions are answered
+func (a *App) AnswerQuizQuestion(quiz *model.ReviewQuiz, questionID, choiceID string) (*model.QuizQuestion, error) {
+	if quiz.FinishedAt != 0 {
+		return nil, errors.Errorf("quiz %s is already finished", quiz.ID)
+	}
+	quizQuestion := quiz.GetQuestion(questionID)
+	if quizQuestion == nil {
+		return nil, errors.Errorf("question %s isn't in quiz %s", questionID, quiz.ID)
+	}
+	if quizQuestion.AnsweredAt != 0 {
+		return nil, errors.Errorf("question %s is already answered", questionID)
+	}
+
+	// quizzes can be answered long after they are offered, so time spent is unknown
+	answer, err := a.AnswerQuestion(quiz.UserID, questionID, choiceID, 0)
+	if err != nil {
+		return nil, err
+	}
+	quizQuestion.ChoiceID = choiceID
+	quizQuestion.IsRight = answer.IsRight
+	quizQuestion.AnsweredAt = model.GetMillis()
+	saved, err := a.Store.Quiz().SaveResult(quizQuestion)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't save result for quiz %s", quiz.ID)
+	}
+	if !saved {
+		return nil, errors.Errorf("question %s is already answered", questionID)
+	}
+
+	if quiz.IsFinished() {
+		score := 0
+		for _, question := range quiz.Questions {
+			if question.IsRight {
+				score++
+			}
+		}
+		if err := a.Store.Quiz().Finish(quiz.ID, score); err != nil {
+			return nil, errors.Wrapf(err, "can't finish quiz %s", quiz.ID)
+		}
+	}
+	return quizQuestion, nil
+}
+
+// PostReviewQuiz creates a review quiz and offers it to the user in the bot chat
+func (a *App) PostReviewQuiz(userID string) (*model.Post, error) {
+	quiz, err := a.CreateReviewQuiz(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// questions are fetched with the quiz, they don't fit in the post props
+	post, err := a.CreatePost(&model.Post{
+		LocationID: fmt.Sprintf("%s_%s", userID, model.BotID),
+		UserID:     model.BotID,
+		Message:    fmt.Sprintf("🧠 Here's a quick review quiz with %d questions from topics you've already finished. Good luck!", len(quiz.Questions)),
+		PostType:   model.PostTypeReviewQuiz,
+		Props: map[string]interface{}{
+			"quiz_id":             quiz.ID,
+			"number_of_questions": len(quiz.Questions),
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't create post for quiz %s", quiz.ID)
+	}
+	return post, nil
+}
+
+// getQuizCandidates returns user's finished nodes with their weights
+func (a *App) getQuizCandidates(userID string) ([]*quizCandidate, error) {
+	statuses, err := a.GetStatusesForUser(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get statuses for user %s", userID)
+	}
+	finished := map[string]bool{}
+	for _, status := range statuses {
+		if status.Status == model.NodeStatusFinished {
+			finished[status.NodeID] = true
+		}
+	}
+
+	reviewData, err := a.Store.Node().GetReviewData(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get review data for user %s", userID)
+	}
+
+	goals, err := a.Store.Goal().GetAll(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get goals for user %s", userID)
+	}
+	goalDistances := make([]map[string]int, 0, len(goals))
+	for _, goal := range goals {
+		if goal.FinishedAt == 0 && goal.DeletedAt == 0 {
+			goalDistances = append(goalDistances, a.distancesToNode(goal.NodeID))
+		}
+	}
+
+	now := model.GetMillis()
+	candidates := make([]*quizCandidate, 0, len(reviewData))
+	for _, data := range reviewData {
+		if !finished[data.NodeID] {
+			continue
+		}
+		candidates = append(candidates, &quizCandidate{
+			nodeID: data.NodeID,
+			weight: quizNodeWeight(data, goalDistances, now),
+		})
+	}
+	return candidates, nil
+}
+
+// quizNodeWeight weights the finished node for the review quiz.
+// Nodes finished long ago, answered poorly and close to the user's goals get higher weights.
+func quizNodeWeight(data *model.NodeReviewData, goalDistances []map[string]int, now int64) float64 {
+	days := float64(now-data.LastFinishedAt) / float64(24*time.Hour.Milliseconds())
+	if days < 0 {
+		days = 0
+	}
+	timeScore := days / (days + quizTimeHalfDays)
+
+	// smoothed accuracy, so nodes without answers are in the middle
+	accuracy := float64(data.CorrectAnswers+1) / float64(data.Answers+2)
+	inaccuracyScore := 1 - accuracy
+
+	goalScore := 0.0
+	for _, distances := range goalDistances {
+		if distance, ok := distances[data.NodeID]; ok && 1/float64(1+distance) > goalScore {
+			goalScore = 1 / float64(1+distance)
+		}
+	}
+
+	return quizBaseWeight + quizTimeWeight*timeScore + quizAccuracyWeight*inaccuracyScore + quizGoalWeight*goalScore
+}
+
+// pickWeighted returns index of the random candidate, candidates with higher weights are picked more often
+func (a *App) pickWeighted(candidates []*quizCandidate) int {
+	total := 0.0
+	for _, candidate := range candidates {
+		total += candidate.weight
+	}
+	r := a.Random.Float64() * total
+	for i, candidate := range candidates {
+		r -= candidate.weight
+		if r < 0 {
+			return i
+		}
+	}
+	return len(candidates) - 1
+}
+
+// getNodeQuestions returns all the questions of the node with their choices
+func (a *App) getNodeQuestions(nodeID string) ([]*model.Question, error) {
+	options := &model.QuestionGetOptions{}
+	model.ComposeQuestionOptions(
+		model.QuestionWithAnswers(),
+		model.QuestionNodeID(nodeID),
+		model.QuestionPage(0),
+		model.QuestionPerPage(-1))(options)
+	questions, err := a.Store.Question().GetQuestions(options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get questions of node %s", nodeID)
+	}
+	return questions, nil
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuizNodeWeight(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC).UnixMilli()
+	daysAgo := func(days int) int64 {
+		return now - int64(days)*24*time.Hour.Milliseconds()
+	}
+
+	for name, tc := range map[string]struct {
+		data          model.NodeReviewData
+		goalDistances []map[string]int
+		expected      float64
+	}{
+		"just finished without answers": {
+			data:     model.NodeReviewData{NodeID: "node", LastFinishedAt: now},
+			expected: quizBaseWeight + 0.5,
+		},
+		"finished a week ago": {
+			data:     model.NodeReviewData{NodeID: "node", LastFinishedAt: daysAgo(7)},
+			expected: quizBaseWeight + 0.5 + 0.5,
+		},
+		"finished in the future": {
+			data:     model.NodeReviewData{NodeID: "node", LastFinishedAt: now + time.Hour.Milliseconds()},
+			expected: quizBaseWeight + 0.5,
+		},
+		"answered correctly": {
+			data:     model.NodeReviewData{NodeID: "node", LastFinishedAt: now, Answers: 8, CorrectAnswers: 8},
+			expected: quizBaseWeight + 0.1,
+		},
+		"answered incorrectly": {
+			data:     model.NodeReviewData{NodeID: "node", LastFinishedAt: now, Answers: 8},
+			expected: quizBaseWeight + 0.9,
+		},
+		"goal node": {
+			data:          model.NodeReviewData{NodeID: "node", LastFinishedAt: now},
+			goalDistances: []map[string]int{{"node": 0}},
+			expected:      quizBaseWeight + 0.5 + quizGoalWeight,
+		},
+		"closest goal counts": {
+			data:          model.NodeReviewData{NodeID: "node", LastFinishedAt: now},
+			goalDistances: []map[string]int{{"node": 3}, {"node": 1}, {"other": 0}},
+			expected:      quizBaseWeight + 0.5 + quizGoalWeight/2,
+		},
+		"node isn't on the way to the goals": {
+			data:          model.NodeReviewData{NodeID: "node", LastFinishedAt: now},
+			goalDistances: []map[string]int{{"other": 0}},
+			expected:      quizBaseWeight + 0.5,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data := tc.data
+			require.InDelta(t, tc.expected, quizNodeWeight(&data, tc.goalDistances, now), 1e-9)
+		})
+	}
+
+	t.Run("older and poorly answered nodes weigh more", func(t *testing.T) {
+		recent := quizNodeWeight(&model.NodeReviewData{LastFinishedAt: daysAgo(1), Answers: 4, CorrectAnswers: 2}, nil, now)
+		old := quizNodeWeight(&model.NodeReviewData{LastFinishedAt: daysAgo(30), Answers: 4, CorrectAnswers: 2}, nil, now)
+		poor := quizNodeWeight(&model.NodeReviewData{LastFinishedAt: daysAgo(1), Answers: 4, CorrectAnswers: 0}, nil, now)
+		require.Greater(t, old, recent)
+		require.Greater(t, poor, recent)
+	})
+}
+
+func TestPickWeighted(t *testing.T) {
+	a := &App{Random: rand.New(rand.NewSource(1))}
+
+	t.Run("single candidate", func(t *testing.T) {
+		require.Equal(t, 0, a.pickWeighted([]*quizCandidate{{nodeID: "node", weight: 1}}))
+	})
+
+	t.Run("candidates are picked proportionally to the weights", func(t *testing.T) {
+		candidates := []*quizCandidate{
+			{nodeID: "zero", weight: 0},
+			{nodeID: "light", weight: 1},
+			{nodeID: "heavy", weight: 3},
+		}
+		picks := make([]int, len(candidates))
+		const n = 40000
+		for i := 0; i < n; i++ {
+			picks[a.pickWeighted(candidates)]++
+		}
+		require.Zero(t, picks[0])
+		require.InDelta(t, 0.25, float64(picks[1])/n, 0.02)
+		require.InDelta(t, 0.75, float64(picks[2])/n, 0.02)
+	})
+}
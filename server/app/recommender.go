@@ -0,0 +1,217 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	goalWeight           = 5.0
+	unlocksWeight        = 1.0
+	inProgressBonus      = 3.0
+	preferredTypeBonus   = 1.0
+	failurePenalty       = 0.5
+	maxFailurePenalty    = 3.0
+	recentFailuresPeriod = 7 * 24 * time.Hour
+)
+
+type recommendationScore struct {
+	score   float64
+	reason  string
+	reasonW float64
+}
+
+func (r *recommendationScore) add(weight float64, reason string) {
+	r.score += weight
+	if weight > r.reasonW {
+		r.reasonW = weight
+		r.reason = reason
+	}
+}
+
+// GetRecommendations returns ranked list of nodes user can work on next.
+// Candidates are nodes in progress and unseen nodes with all the prerequisites finished.
+// They are scored by the distance to the user's active goals, number of nodes they unlock,
+// whether they're already in progress, whether they have resources of the user's preferred type and
+// recent incorrect answers on their questions.
+func (a *App) GetRecommendations(userID string) ([]*model.Recommendation, error) {
+	inProgressNodes, nextNodes, err := a.GetNextNodes(userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get next nodes")
+	}
+	if len(inProgressNodes) == 0 && len(nextNodes) == 0 {
+		return []*model.Recommendation{}, nil
+	}
+
+	candidates := make([]model.Node, 0, len(inProgressNodes)+len(nextNodes))
+	candidates = append(candidates, inProgressNodes...)
+	candidates = append(candidates, nextNodes...)
+	inProgress := make(map[string]bool, len(inProgressNodes))
+	for _, node := range inProgressNodes {
+		inProgress[node.ID] = true
+	}
+	candidateIDs := make([]string, 0, len(candidates))
+	for _, node := range candidates {
+		candidateIDs = append(candidateIDs, node.ID)
+	}
+
+	goals, err := a.Store.Goal().GetAll(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get goals for user %s", userID)
+	}
+	goalDistances := make([]map[string]int, 0, len(goals))
+	goalNames := make([]string, 0, len(goals))
+	for _, goal := range goals {
+		goalDistances = append(goalDistances, a.distancesToNode(goal.NodeID))
+		goalNames = append(goalNames, a.Graph.Nodes[goal.NodeID].Name)
+	}
+
+	preferredType, err := a.Store.Preferences().Get(userID, model.PreferredResourceTypeKey)
+	if err != nil {
+		preferredType = ""
+	}
+	resourceCounts := map[string]*model.NodeResourceCounts{}
+	if preferredType != "" {
+		counts, err2 := a.Store.Node().GetResourceCounts(candidateIDs)
+		if err2 != nil {
+			return nil, errors.Wrap(err2, "can't get resource counts")
+		}
+		for _, c := range counts {
+			resourceCounts[c.NodeID] = c
+		}
+	}
+
+	since := time.Now().Add(-recentFailuresPeriod).UnixNano() / int64(time.Millisecond)
+	failures, err := a.Store.Question().GetFailures(userID, since)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get recent failures")
+	}
+	failuresMap := make(map[string]int, len(failures))
+	for _, f := range failures {
+		failuresMap[f.NodeID] = f.Failures
+	}
+
+	dependents := a.getDependents()
+	recommendations := make([]*model.Recommendation, 0, len(candidates))
+	for _, node := range candidates {
+		score := &recommendationScore{}
+
+		for i, distances := range goalDistances {
+			distance, ok := distances[node.ID]
+			if !ok {
+				continue
+			}
+			if distance == 0 {
+				score.add(goalWeight, fmt.Sprintf("It's your goal: %s", goalNames[i]))
+			} else {
+				score.add(goalWeight/float64(1+distance), fmt.Sprintf("It's on the way to your goal: %s", goalNames[i]))
+			}
+		}
+
+		if unlocks := countDescendants(node.ID, dependents); unlocks > 0 {
+			score.add(unlocksWeight*math.Log2(float64(1+unlocks)), fmt.Sprintf("It unlocks %d more topics", unlocks))
+		}
+
+		if inProgress[node.ID] {
+			score.add(inProgressBonus, "You've already started this topic")
+		}
+
+		if c, ok := resourceCounts[node.ID]; ok && c.Has(preferredType) {
+			score.add(preferredTypeBonus, fmt.Sprintf("It has %ss you prefer", preferredType))
+		}
+
+		if score.reason == "" {
+			score.reason = "All of its prerequisites are finished"
+		}
+		if f := failuresMap[node.ID]; f > 0 {
+			score.score -= math.Min(failurePenalty*float64(f), maxFailurePenalty)
+			score.reason += ". You've struggled with it recently, maybe take a break from it"
+		}
+
+		recommendations = append(recommendations, &model.Recommendation{
+			Node:   node,
+			Score:  score.score,
+			Reason: score.reason,
+		})
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		if recommendations[i].Score == recommendations[j].Score {
+			return recommendations[i].Node.ID < recommendations[j].Node.ID
+		}
+		return recommendations[i].Score > recommendations[j].Score
+	})
+	return recommendations, nil
+}
+
+// GetNextTopic returns the best node to work on next towards the goal, nil if there's nothing left to learn.
+// Without the goal, or when no recommended node leads to it, the top recommendation is returned.
+func (a *App) GetNextTopic(userID, goalID string) (*model.Recommendation, error) {
+	recommendations, err := a.GetRecommendations(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(recommendations) == 0 {
+		return nil, nil
+	}
+	if goalID != "" {
+		distances := a.distancesToNode(goalID)
+		for _, recommendation := range recommendations {
+			if _, ok := distances[recommendation.Node.ID]; ok {
+				return recommendation, nil
+			}
+		}
+	}
+	return recommendations[0], nil
+}
+
+// distancesToNode returns the length of the shortest prerequisite path from every prerequisite of the node to the node
+func (a *App) distancesToNode(nodeID string) map[string]int {
+	distances := map[string]int{nodeID: 0}
+	queue := []string{nodeID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, prereq := range a.Graph.Prerequisites[current] {
+			if _, ok := distances[prereq]; ok {
+				continue
+			}
+			distances[prereq] = distances[current] + 1
+			queue = append(queue, prereq)
+		}
+	}
+	return distances
+}
+
+// getDependents returns map of nodes to the nodes that have them as a prerequisite
+func (a *App) getDependents() map[string][]string {
+	dependents := map[string][]string{}
+	for nodeID, prereqs := range a.Graph.Prerequisites {
+		for _, prereq := range prereqs {
+			dependents[prereq] = append(dependents[prereq], nodeID)
+		}
+	}
+	return dependents
+}
+
+func countDescendants(nodeID string, dependents map[string][]string) int {
+	visited := map[string]bool{nodeID: true}
+	stack := []string{nodeID}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, dependent := range dependents[current] {
+			if visited[dependent] {
+				continue
+			}
+			visited[dependent] = true
+			stack = append(stack, dependent)
+		}
+	}
+	return len(visited) - 1
+}
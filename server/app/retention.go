@@ -0,0 +1,75 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+const numberOfRefresherNodes = 3
+
+// GetRetentions estimates retention of the nodes user has currently finished
+func (a *App) GetRetentions(userID string, statuses map[string]*model.NodeStatusForUser) (map[string]float64, error) {
+	reviewData, err := a.Store.Node().GetReviewData(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get review data for user %s", userID)
+	}
+
+	now := model.GetMillis()
+	retentions := make(map[string]float64, len(reviewData))
+	for _, data := range reviewData {
+		status, ok := statuses[data.NodeID]
+		if !ok || status.Status != model.NodeStatusFinished {
+			continue
+		}
+		retentions[data.NodeID] = data.Retention(now)
+	}
+	return retentions, nil
+}
+
+// getNodesToRefresh returns the most decayed finished prerequisites of the user's current goal
+func (a *App) getNodesToRefresh(userID string) ([]model.Node, error) {
+	// goals are ordered by priority, so the first one is the current goal
+	goals, err := a.Store.Goal().GetAllWithData(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get goals for user %s", userID)
+	}
+	if len(goals) == 0 {
+		return []model.Node{}, nil
+	}
+
+	statuses, err := a.GetStatusesForUser(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get statuses for user %s", userID)
+	}
+	statusMap := map[string]*model.NodeStatusForUser{}
+	for _, status := range statuses {
+		statusMap[status.NodeID] = status
+	}
+
+	retentions, err := a.GetRetentions(userID, statusMap)
+	if err != nil {
+		return nil, err
+	}
+
+	decayed := []model.NodeRetention{}
+	for _, nodeID := range a.getAllPrerequisiteNodes(goals[0].NodeID) {
+		retention, ok := retentions[nodeID]
+		if !ok || retention >= model.RetentionRefreshThreshold {
+			continue
+		}
+		decayed = append(decayed, model.NodeRetention{NodeID: nodeID, Retention: retention})
+	}
+	sort.Slice(decayed, func(i, j int) bool {
+		return decayed[i].Retention < decayed[j].Retention
+	})
+
+	nodes := []model.Node{}
+	for i := 0; i < len(decayed) && i < numberOfRefresherNodes; i++ {
+		if node, ok := a.Graph.Nodes[decayed[i].NodeID]; ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
@@ -51,7 +51,7 @@ func (a *App) UpdateSessionRole(userID string, isActive bool) error {
 		role = model.CustomerRole
 	}
 	for _, session := range sessions {
-		if session.Role == model.AdminRole {
+		if session.Role == model.AdminRole || session.Role == model.TeacherRole {
 			continue
 		}
 		session.Role = model.RoleType(role)
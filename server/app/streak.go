@@ -0,0 +1,111 @@
+package app
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// GetStreak returns user's learning streak and today's date in user's timezone.
+// Streak of the users without stored streak is rebuilt from their status history.
+func (a *App) GetStreak(userID string) (*model.Streak, string, error) {
+	location := a.getUserLocation(userID)
+	today := model.StreakDate(time.Now(), location)
+
+	streak, err := a.Store.Streak().Get(userID)
+	if err == nil {
+		return streak, today, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, "", errors.Wrapf(err, "can't get streak for user %s", userID)
+	}
+
+	streak, err = a.rebuildStreak(userID, location)
+	if err != nil {
+		return nil, "", err
+	}
+	return streak, today, nil
+}
+
+// recordStreakActivity extends user's streak when user finishes a node
+func (a *App) recordStreakActivity(userID string) error {
+	streak, today, err := a.GetStreak(userID)
+	if err != nil {
+		return err
+	}
+	if streak.ActiveOn(today) {
+		return nil
+	}
+	streak.RecordActivity(today)
+	if err := a.Store.Streak().Save(streak); err != nil {
+		return errors.Wrapf(err, "can't save streak for user %s", userID)
+	}
+	for _, milestone := range model.StreakMilestones {
+		if streak.CurrentStreak == milestone {
+			a.trackXPEvent(userID, model.XPEventStreakMilestone, fmt.Sprintf("%d_%s", milestone, today))
+		}
+	}
+	return nil
+}
+
+// SpendStreakFreezes spends user's freezes on the days missed since the last active day
+func (a *App) SpendStreakFreezes(userID string) (*model.Streak, string, error) {
+	streak, today, err := a.GetStreak(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := streak.SpendFreezes(today); err != nil {
+		return nil, "", err
+	}
+	if err := a.Store.Streak().Save(streak); err != nil {
+		return nil, "", errors.Wrapf(err, "can't save streak for user %s", userID)
+	}
+	return streak, today, nil
+}
+
+func (a *App) rebuildStreak(userID string, location *time.Location) (*model.Streak, error) {
+	transitions, err := a.Store.Node().GetTimeline(userID, &model.TimelineGetOptions{Page: -1})
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get timeline for user %s", userID)
+	}
+	days := map[string]bool{}
+	for _, transition := range transitions {
+		if transition.ToStatus == model.NodeStatusFinished {
+			days[model.StreakDate(time.UnixMilli(transition.CreatedAt), location)] = true
+		}
+	}
+	sortedDays := make([]string, 0, len(days))
+	for day := range days {
+		sortedDays = append(sortedDays, day)
+	}
+	sort.Strings(sortedDays)
+
+	streak := &model.Streak{
+		UserID:    userID,
+		UpdatedAt: model.GetMillis(),
+	}
+	for _, day := range sortedDays {
+		streak.RecordActivity(day)
+	}
+	if err := a.Store.Streak().Save(streak); err != nil {
+		return nil, errors.Wrapf(err, "can't save streak for user %s", userID)
+	}
+	return streak, nil
+}
+
+// getUserLocation returns user's timezone, UTC if user hasn't set a valid one
+func (a *App) getUserLocation(userID string) *time.Location {
+	timezone, err := a.Store.Preferences().Get(userID, model.TimezonePreferenceKey)
+	if err != nil || timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
@@ -0,0 +1,193 @@
+package app
+
+import (
+	"time"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// studyGroupPresenceWindow is the time since the last interaction during which a member is considered studying now
+const studyGroupPresenceWindow = 10 * time.Minute
+
+// CreateStudyGroup creates a new study group, creator joins the group and gets its goal
+func (a *App) CreateStudyGroup(group *model.StudyGroup) (*model.StudyGroup, error) {
+	if _, ok := a.Graph.Nodes[group.GoalNodeID]; !ok {
+		return nil, errors.Errorf("unknown node %s", group.GoalNodeID)
+	}
+	saved, err := a.Store.StudyGroup().Save(group)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creatorID = %s", group.CreatorID)
+	}
+	if err := a.JoinStudyGroup(saved, saved.CreatorID); err != nil {
+		return nil, err
+	}
+	return a.GetStudyGroup(saved.ID)
+}
+
+// GetStudyGroup gets study group by id
+func (a *App) GetStudyGroup(id string) (*model.StudyGroup, error) {
+	group, err := a.Store.StudyGroup().Get(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "id = %s", id)
+	}
+	return group, nil
+}
+
+// GetStudyGroups returns all the study groups, filtered by the goal if goalNodeID is set
+func (a *App) GetStudyGroups(goalNodeID string) ([]*model.StudyGroup, error) {
+	groups, err := a.Store.StudyGroup().GetGroups(goalNodeID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "goalNodeID = %s", goalNodeID)
+	}
+	return groups, nil
+}
+
+// GetUserStudyGroups returns study groups the user is a member of
+func (a *App) GetUserStudyGroups(userID string) ([]*model.StudyGroup, error) {
+	groups, err := a.Store.StudyGroup().GetForUser(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "userID = %s", userID)
+	}
+	return groups, nil
+}
+
+// DeleteStudyGroup deletes the study group
+func (a *App) DeleteStudyGroup(id string) error {
+	if err := a.Store.StudyGroup().Delete(id); err != nil {
+		return errors.Wrapf(err, "id = %s", id)
+	}
+	return nil
+}
+
+// JoinStudyGroup adds the user to the group and sets group's goal as user's goal
+func (a *App) JoinStudyGroup(group *model.StudyGroup, userID string) error {
+	joined, err := a.Store.StudyGroup().AddMember(group.ID, userID)
+	if err != nil {
+		return errors.Wrapf(err, "groupID = %s, userID = %s", group.ID, userID)
+	}
+	if !joined {
+		return nil
+	}
+	if err := a.CreateGoal(userID, group.GoalNodeID); err != nil {
+		return errors.Wrapf(err, "can't set goal %s for user %s", group.GoalNodeID, userID)
+	}
+	return nil
+}
+
+// LeaveStudyGroup removes the user from the group, the goal stays with the user
+func (a *App) LeaveStudyGroup(groupID, userID string) error {
+	if err := a.Store.StudyGroup().RemoveMember(groupID, userID); err != nil {
+		return errors.Wrapf(err, "groupID = %s, userID = %s", groupID, userID)
+	}
+	return nil
+}
+
+// IsStudyGroupMember returns true if the user is a member of the group
+func (a *App) IsStudyGroupMember(groupID, userID string) (bool, error) {
+	isMember, err := a.Store.StudyGroup().IsMember(groupID, userID)
+	if err != nil {
+		return false, errors.Wrapf(err, "groupID = %s, userID = %s", groupID, userID)
+	}
+	return isMember, nil
+}
+
+// GetStudyGroupPosts returns posts of the group's feed
+func (a *App) GetStudyGroupPosts(group *model.StudyGroup) ([]*model.PostWithUser, error) {
+	return a.GetPostsWithUserData(group.LocationID())
+}
+
+// CreateStudyGroupPost posts the message to the group's feed
+func (a *App) CreateStudyGroupPost(group *model.StudyGroup, userID, message string) (*model.Post, error) {
+	return a.CreatePost(&model.Post{
+		LocationID: group.LocationID(),
+		UserID:     userID,
+		Message:    message,
+	})
+}
+
+// GetStudyGroupProgress returns progress of the members on the group's target subgraph
+func (a *App) GetStudyGroupProgress(group *model.StudyGroup) (*model.StudyGroupProgress, error) {
+	members, err := a.Store.StudyGroup().GetMembers(group.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get members of study group %s", group.ID)
+	}
+	lastActive, err := a.getLastActive(members)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := a.getAllPrerequisiteNodes(group.GoalNodeID)
+	inSubgraph := make(map[string]bool, len(nodes))
+	for _, nodeID := range nodes {
+		inSubgraph[nodeID] = true
+	}
+
+	progress := &model.StudyGroupProgress{
+		GoalNodeID: group.GoalNodeID,
+		Nodes:      nodes,
+		Members:    make([]*model.StudyGroupMemberProgress, 0, len(members)),
+	}
+	finished := 0
+	for _, member := range members {
+		statuses, err := a.Store.Node().GetNodesForUser(member.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't get statuses of member %s", member.ID)
+		}
+		memberProgress := &model.StudyGroupMemberProgress{
+			User:        member,
+			Statuses:    map[string]string{},
+			StudyingNow: lastActive[member.ID] != 0,
+		}
+		for _, status := range statuses {
+			if !inSubgraph[status.NodeID] {
+				continue
+			}
+			memberProgress.Statuses[status.NodeID] = status.Status
+			if status.Status == model.NodeStatusFinished {
+				memberProgress.Finished++
+			}
+		}
+		finished += memberProgress.Finished
+		progress.Members = append(progress.Members, memberProgress)
+	}
+	if len(members) > 0 && len(nodes) > 0 {
+		progress.Finished = float64(finished) / float64(len(members)*len(nodes))
+	}
+	return progress, nil
+}
+
+// GetStudyingNow returns members of the group who have interacted with the app recently
+func (a *App) GetStudyingNow(group *model.StudyGroup) ([]*model.User, error) {
+	members, err := a.Store.StudyGroup().GetMembers(group.ID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get members of study group %s", group.ID)
+	}
+	lastActive, err := a.getLastActive(members)
+	if err != nil {
+		return nil, err
+	}
+	studying := []*model.User{}
+	for _, member := range members {
+		if lastActive[member.ID] != 0 {
+			studying = append(studying, member)
+		}
+	}
+	return studying, nil
+}
+
+// getLastActive returns time of the last interaction of the users active during the presence window
+func (a *App) getLastActive(users []*model.User) (map[string]int64, error) {
+	if len(users) == 0 {
+		return map[string]int64{}, nil
+	}
+	userIDs := make([]string, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.ID)
+	}
+	lastActive, err := a.Store.UserInteraction().GetLastActive(userIDs, time.Now().Add(-studyGroupPresenceWindow).UnixMilli())
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get last active time")
+	}
+	return lastActive, nil
+}
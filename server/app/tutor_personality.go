@@ -0,0 +1,97 @@
+package app
+
+import (
+	"database/sql"
+
+	"github.com/oseducation/knowledge-graph/log"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+const tutorPersonalityPreference = "tutor_personality"
+
+// GetTutorPersonalities returns the enabled personalities available to the plan
+func (a *App) GetTutorPersonalities(plan model.RoleType) ([]*model.TutorPersonality, error) {
+	personalities, err := a.Store.TutorPersonality().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	available := []*model.TutorPersonality{}
+	for _, personality := range personalities {
+		if personality.IsAvailableTo(plan) {
+			available = append(available, personality)
+		}
+	}
+	return available, nil
+}
+
+// GetAllTutorPersonalities returns all the personalities for admins
+func (a *App) GetAllTutorPersonalities() ([]*model.TutorPersonality, error) {
+	return a.Store.TutorPersonality().GetAll()
+}
+
+func (a *App) GetTutorPersonality(personalityID string) (*model.TutorPersonality, error) {
+	return a.Store.TutorPersonality().Get(personalityID)
+}
+
+func (a *App) CreateTutorPersonality(personality *model.TutorPersonality) (*model.TutorPersonality, error) {
+	return a.Store.TutorPersonality().Save(personality)
+}
+
+func (a *App) UpdateTutorPersonality(personality *model.TutorPersonality) (*model.TutorPersonality, error) {
+	if err := a.Store.TutorPersonality().Update(personality); err != nil {
+		return nil, err
+	}
+	return a.Store.TutorPersonality().Get(personality.ID)
+}
+
+// DeleteTutorPersonality deletes the personality, learners who chose it get the standard tutor
+func (a *App) DeleteTutorPersonality(personalityID string) error {
+	if personalityID == model.StandardTutorPersonality.ID {
+		return errors.New("standard tutor personality can't be deleted")
+	}
+	return a.Store.TutorPersonality().Delete(personalityID)
+}
+
+func (a *App) getTutorPrompt(userID string) string {
+	user, err := a.Store.User().Get(userID)
+	if err != nil {
+		a.Log.Error("can't get user for the tutor prompt", log.String("userID", userID), log.Err(err))
+		return model.StandardTutorPersonality.GetPrompt(model.LanguageEnglish)
+	}
+	personality := a.getTutorPersonality(user)
+	return personality.GetPrompt(user.Lang)
+}
+
+// getTutorPersonality returns the personality chosen by the user,
+// the standard one if the user didn't choose any or the chosen one isn't available to the user's plan
+func (a *App) getTutorPersonality(user *model.User) *model.TutorPersonality {
+	personalityID, err := a.Store.Preferences().Get(user.ID, tutorPersonalityPreference)
+	if err != nil || personalityID == model.StandardTutorPersonality.ID {
+		return a.getStandardTutorPersonality()
+	}
+
+	personality, err := a.Store.TutorPersonality().Get(personalityID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			a.Log.Error("can't get tutor personality", log.String("personalityID", personalityID), log.Err(err))
+		}
+		a.Log.Warn("tutor personality not found, the standard one is used", log.String("userID", user.ID), log.String("personalityID", personalityID))
+		return a.getStandardTutorPersonality()
+	}
+	if !personality.IsAvailableTo(user.Role) {
+		a.Log.Warn("tutor personality not available, the standard one is used", log.String("userID", user.ID), log.String("personalityID", personalityID))
+		return a.getStandardTutorPersonality()
+	}
+	return personality
+}
+
+// getStandardTutorPersonality returns the standard personality edited by admins, the built-in one if it's missing
+func (a *App) getStandardTutorPersonality() *model.TutorPersonality {
+	personality, err := a.Store.TutorPersonality().Get(model.StandardTutorPersonality.ID)
+	if err != nil {
+		standard := model.StandardTutorPersonality
+		return &standard
+	}
+	return personality
+}
@@ -1,6 +1,8 @@
 package app
 
 import (
+	"sort"
+
 	"github.com/oseducation/knowledge-graph/model"
 	"github.com/pkg/errors"
 )
@@ -24,45 +26,83 @@ func (a *App) GetVideos(options *model.VideoGetOptions) ([]*model.Video, error)
 }
 
 func (a *App) AddUserEngagement(userID, videoID string, data *model.UserEngagementData) error {
-	return a.Store.Video().AddUserVideoEngagement(userID, videoID, data)
+	if err := a.Store.Video().AddUserVideoEngagement(userID, videoID, data); err != nil {
+		return err
+	}
+	if data.VideoStatus == model.VideoStatusFinished {
+		a.trackXPEvent(userID, model.XPEventVideoWatched, videoID)
+	}
+	return nil
 }
 
-// GetNextVideo returns video user can watch
+// GetNextVideo returns a random video of the highest ranked recommended node which has videos the user hasn't watched yet.
+// If the user has watched all of them, a random video of the highest ranked node with videos is returned.
 func (a *App) GetNextVideo(userID string) (string, error) {
-	inProgressNodes, nextNodes, err := a.GetNextNodes(userID)
+	recommendations, err := a.GetRecommendations(userID)
 	if err != nil {
-		return "", errors.Wrap(err, "can't get next nodes")
+		return "", errors.Wrap(err, "can't get recommendations")
 	}
 
-	if len(nextNodes) == 0 && len(inProgressNodes) == 0 {
+	if len(recommendations) == 0 {
 		return "", errors.New("you've finished all the nodes")
 	}
 
-	nodeList := [][]model.Node{nextNodes, inProgressNodes}
-	for _, nodes := range nodeList {
-		if len(nodes) != 0 {
-			videoKeys, err := a.getVideosFromNodes(nodes)
-			if err != nil {
-				return "", err
-			}
-			if len(videoKeys) != 0 {
-				index := a.Random.Intn(len(videoKeys))
-				return videoKeys[index], nil
+	nodeIDs := make([]string, 0, len(recommendations))
+	for _, recommendation := range recommendations {
+		nodeIDs = append(nodeIDs, recommendation.Node.ID)
+	}
+	keys, err := a.Store.Video().GetVideoKeysForUser(userID, nodeIDs)
+	if err != nil {
+		return "", errors.Wrap(err, "can't get videos of the recommended nodes")
+	}
+	nodeVideos := map[string][]*model.UserVideoKey{}
+	for _, key := range keys {
+		nodeVideos[key.NodeID] = append(nodeVideos[key.NodeID], key)
+	}
+
+	var watched []*model.UserVideoKey
+	for _, nodeID := range nodeIDs {
+		unwatched := []*model.UserVideoKey{}
+		for _, key := range nodeVideos[nodeID] {
+			if !key.Finished {
+				unwatched = append(unwatched, key)
 			}
 		}
+		if len(unwatched) != 0 {
+			return unwatched[a.Random.Intn(len(unwatched))].Key, nil
+		}
+		if watched == nil && len(nodeVideos[nodeID]) != 0 {
+			watched = nodeVideos[nodeID]
+		}
+	}
+	if len(watched) != 0 {
+		return watched[a.Random.Intn(len(watched))].Key, nil
 	}
 
 	return "", errors.New("no videos in the next or in progress nodes")
 }
 
-func (a *App) getVideosFromNodes(nodes []model.Node) ([]string, error) {
-	nodeIDs := make([]string, 0, len(nodes))
-	for i := range nodes {
-		nodeIDs = append(nodeIDs, nodes[i].ID)
-	}
-	videos, err := a.Store.Video().GetVideosFromNodeIDs(nodeIDs)
+// SaveTranscript replaces the transcript of the video, it's indexed for the tutor by db index-embeddings
+func (a *App) SaveTranscript(videoID string, segments []*model.TranscriptSegment) error {
+	video, err := a.Store.Video().Get(videoID)
 	if err != nil {
-		return nil, errors.Wrap(err, "can't get nodes with videos")
+		return errors.Wrapf(err, "can't get video with id = %s", videoID)
 	}
-	return videos, nil
+	for _, segment := range segments {
+		segment.VideoID = videoID
+		if err := segment.IsValid(); err != nil {
+			return err
+		}
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].StartSeconds < segments[j].StartSeconds })
+	if err := a.Store.Video().SaveTranscript(videoID, segments); err != nil {
+		return err
+	}
+	// transcripts aren't timestamped, so cached answers based on them are invalidated here
+	return a.InvalidateAnswerCache(video.NodeID, false)
+}
+
+// GetTranscript gets the transcript of the video
+func (a *App) GetTranscript(videoID string) ([]*model.TranscriptSegment, error) {
+	return a.Store.Video().GetTranscript(videoID)
 }
@@ -58,6 +58,22 @@ var dbAddNode = &cobra.Command{
 	RunE:    addNodeCmdF,
 }
 
+var dbComputeQuestionStats = &cobra.Command{
+	Use:     "compute-question-stats",
+	Short:   "Compute question statistics",
+	Long:    `Compute per-question statistics from learners' answers and flag questions where a wrong choice is picked more than the right one.`,
+	Example: `  db compute-question-stats`,
+	RunE:    computeQuestionStatsCmdF,
+}
+
+var dbIndexEmbeddings = &cobra.Command{
+	Use:     "index-embeddings",
+	Short:   "Index embeddings",
+	Long:    `Embed node names and descriptions and the intent phrases from intents.json of the content repo into the built-in vector store. Texts, question explanations and video transcripts of the nodes are split into passages and embedded for the tutor's answers.`,
+	Example: `  db index-embeddings --url URL-to-folder`,
+	RunE:    indexEmbeddingsCmdF,
+}
+
 var dbNuke = &cobra.Command{
 	Use:     "nuke",
 	Short:   "Nuke DB",
@@ -87,6 +103,11 @@ func init() {
 	dbAddNode.Flags().String("author", "", "an authorID of the node")
 	dbCmd.AddCommand(dbAddNode)
 
+	dbCmd.AddCommand(dbComputeQuestionStats)
+
+	dbIndexEmbeddings.Flags().String("url", "", "URL to folder with intents.json file, built-in intent phrases are used if empty")
+	dbCmd.AddCommand(dbIndexEmbeddings)
+
 	dbCmd.AddCommand(dbNuke)
 
 	rootCmd.AddCommand(dbCmd)
@@ -134,6 +155,52 @@ func nukeDB(_ *cobra.Command, _ []string) error {
 	return nil
 }
 
+func computeQuestionStatsCmdF(_ *cobra.Command, _ []string) error {
+	srv, err := runServer()
+	if err != nil {
+		return errors.New("can't run server")
+	}
+	defer srv.Shutdown()
+
+	stats, err := srv.App.ComputeQuestionStats()
+	if err != nil {
+		return errors.Wrap(err, "can't compute question stats")
+	}
+	flagged := 0
+	for _, s := range stats {
+		if s.Flagged {
+			flagged++
+		}
+	}
+	println("questions", len(stats), "flagged", flagged)
+	return nil
+}
+
+func indexEmbeddingsCmdF(command *cobra.Command, _ []string) error {
+	url, err := command.Flags().GetString("url")
+	if err != nil {
+		return errors.Wrap(err, "can't read url")
+	}
+	srv, err := runServer()
+	if err != nil {
+		return errors.New("can't run server")
+	}
+	defer srv.Shutdown()
+
+	count, err := srv.App.IndexEmbeddings(url)
+	if err != nil {
+		return errors.Wrap(err, "can't index embeddings")
+	}
+	println("embeddings", count)
+
+	count, err = srv.App.IndexContent()
+	if err != nil {
+		return errors.Wrap(err, "can't index content")
+	}
+	println("content chunks", count)
+	return nil
+}
+
 func importGraphCmdF(command *cobra.Command, _ []string) error {
 	url, err := command.Flags().GetString("url")
 	if err != nil || url == "" {
@@ -37,12 +37,20 @@ func pineconeCmdF(command *cobra.Command, _ []string) error {
 		return errors.New("text is required")
 	}
 
-	services, err := services.NewServices(&store.SQLStore{}, config.EmailSettings{}, log.NewLogger(&log.LoggerConfiguration{NonLogger: true}))
+	conf, err := config.ReadConfig()
+	if err != nil {
+		return errors.Wrap(err, "can't read config")
+	}
+	logger := log.NewLogger(&log.LoggerConfiguration{NonLogger: true})
+	// the store is needed by the built-in vector store when Pinecone isn't configured
+	db := store.CreateStore(&conf.DBSettings, logger)
+
+	services, err := services.NewServices(db, conf.EmailSettings, conf.LLMSettings, conf.ModerationSettings, logger)
 	if err != nil {
 		return errors.Wrap(err, "can't create services")
 	}
 
-	vector, err := services.ChatGPTService.GetEmbedding(text, "test-user-id")
+	vector, err := services.LLMService.GetEmbedding(text, "test-user-id")
 	if err != nil {
 		return errors.Wrap(err, "can't get embedding")
 	}
@@ -0,0 +1,10 @@
+package config
+
+import (
+	_ "embed"
+)
+
+// AchievementsJSON declares achievements users can unlock
+//
+//go:embed achievements.json
+var AchievementsJSON []byte
@@ -48,16 +48,90 @@ type PasswordSettings struct {
 	Symbol        bool
 }
 
+// ChatSettings configures the tutor bot
 type ChatSettings struct {
+	// Budgets maps plans, which are the user roles ("user", "customer", "teacher", "admin"), to monthly budgets.
+	// Plans without a budget get DefaultBudget, a plan is unlimited only with an explicit zero budget.
+	Budgets map[string]LLMBudget
+	// DefaultBudget is the budget of the plans missing in Budgets, the built-in default budget is used if it's unset
+	DefaultBudget *LLMBudget
+	// ChatGPTMonthlyLimit is the limit of the tutor's answers before the budgets, it isn't enforced anymore
 	ChatGPTMonthlyLimit int
+	// AnswerCacheSimilarity is the minimal similarity of the questions to serve the cached answer, 0 disables the cache
+	AnswerCacheSimilarity float32
+}
+
+// LLMBudget limits the monthly usage of the language model, a zero limit isn't enforced
+type LLMBudget struct {
+	MonthlyTokens int64
+	// MonthlyCost in USD
+	MonthlyCost float64
+}
+
+// LLMPrice is the price of the model in USD per million tokens
+type LLMPrice struct {
+	PromptPerMillion     float64
+	CompletionPerMillion float64
+}
+
+// LLMSettings configures the language model provider used by the tutor bot.
+// If Provider is empty, OpenAI is used when CHAT_GPT_API_KEY is set and the dummy service otherwise.
+type LLMSettings struct {
+	// Provider is "openai" for OpenAI compatible APIs (OpenAI, vLLM, llama.cpp server, Ollama) or "anthropic"
+	Provider string
+	// BaseURL of the provider's API, e.g. http://localhost:11434/v1 for Ollama
+	BaseURL string
+	// APIKey is read from LLM_API_KEY environment variable if empty, local servers may not need one
+	APIKey         string
+	OrganizationID string
+	// Models maps tiers ("free", "premium") to model names of the provider
+	Models    map[string]string
+	MaxTokens int
+	// Embeddings are requested from an OpenAI compatible API, the chat API is used if EmbeddingBaseURL is empty and the provider is OpenAI compatible
+	EmbeddingBaseURL string
+	EmbeddingAPIKey  string
+	EmbeddingModel   string
+	// Prices maps model names to their prices, used to compute the cost in the usage ledger
+	Prices map[string]LLMPrice
+	// ContextTokens maps model names to the token budgets of the prompt and the answer, at most the model's context window.
+	// Older turns of the dialogue that don't fit are summarized.
+	ContextTokens map[string]int
+}
+
+// ModerationSettings configures the moderation of the learners' messages to the tutor and of the tutor's answers
+type ModerationSettings struct {
+	// Provider is "openai" for OpenAI compatible moderation APIs, "rules" for the local keyword and regex rules,
+	// the moderation is disabled if empty
+	Provider string
+	// BaseURL of the moderation API, the OpenAI API is used if empty
+	BaseURL string
+	// APIKey is read from LLM_API_KEY environment variable if empty
+	APIKey string
+	Model  string
+	Rules  []ModerationRule
+	// Policies maps flagged categories to "block", "redact" or "warn", DefaultPolicy is used for the others
+	Policies      map[string]string
+	DefaultPolicy string
+	// FailOpen passes the texts on when the classifier fails, they are blocked otherwise.
+	// Either way the unmoderated exchanges are queued for review.
+	FailOpen bool
+}
+
+// ModerationRule flags the text matching any of the keywords or the regular expression with the category
+type ModerationRule struct {
+	Category string
+	Keywords []string
+	Pattern  string
 }
 
 type Config struct {
-	ServerSettings   ServerSettings
-	DBSettings       DBSettings
-	EmailSettings    EmailSettings
-	PasswordSettings PasswordSettings
-	ChatSettings     ChatSettings
+	ServerSettings     ServerSettings
+	DBSettings         DBSettings
+	EmailSettings      EmailSettings
+	PasswordSettings   PasswordSettings
+	ChatSettings       ChatSettings
+	LLMSettings        LLMSettings
+	ModerationSettings ModerationSettings
 }
 
 func ReadConfig() (*Config, error) {
@@ -125,3 +125,32 @@ func (c *Client) UpdateNodeStatus(nodeID string, status *model.NodeStatusForUser
 	return BuildResponse(r), nil
 
 }
+
+// SaveCompletionPolicy creates or updates the completion policy of the node.
+func (c *Client) SaveCompletionPolicy(policy *model.CompletionPolicy) (*model.CompletionPolicy, *Response, error) {
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "can't marshal completion policy")
+	}
+
+	r, err := c.DoAPIPut(c.nodesRoute()+"/"+policy.NodeID+"/policy", string(policyJSON))
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	var p model.CompletionPolicy
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		return nil, nil, errors.Wrap(err, "can't decode completion policy")
+	}
+	return &p, BuildResponse(r), nil
+}
+
+// DeleteCompletionPolicy removes the completion policy of the node.
+func (c *Client) DeleteCompletionPolicy(nodeID string) (*Response, error) {
+	r, err := c.DoAPIDelete(c.nodesRoute()+"/"+nodeID+"/policy", "")
+	if err != nil {
+		return BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return BuildResponse(r), nil
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// CachedAnswer is the tutor's answer served again to similar questions on the same node
+// asked with the same tutor personality and language and answered by the same LLM tier
+type CachedAnswer struct {
+	ID               string      `json:"id" db:"id"`
+	NodeID           string      `json:"node_id" db:"node_id"`
+	TutorPersonality string      `json:"tutor_personality" db:"tutor_personality"`
+	Language         string      `json:"language" db:"language"`
+	Tier             string      `json:"tier" db:"tier"`
+	Question         string      `json:"question" db:"question"`
+	Answer           string      `json:"answer" db:"answer"`
+	Citations        []*Citation `json:"citations" db:"-"`
+	Vector           []float32   `json:"-" db:"-"`
+	// Pinned answers aren't invalidated when the node's content changes
+	Pinned    bool  `json:"pinned" db:"pinned"`
+	Hits      int64 `json:"hits" db:"hits"`
+	CreatedAt int64 `json:"created_at" db:"created_at"`
+	UpdatedAt int64 `json:"updated_at" db:"updated_at"`
+	// Score is the similarity to the question, set only by the search
+	Score float32 `json:"score,omitempty" db:"-"`
+}
+
+// IsValid validates the cached answer and returns an error if it isn't configured correctly.
+func (ca *CachedAnswer) IsValid() error {
+	if !IsValidID(ca.ID) {
+		return invalidCachedAnswerError(ca.ID, "id", ca.ID)
+	}
+	if !IsValidID(ca.NodeID) {
+		return invalidCachedAnswerError(ca.ID, "node_id", ca.NodeID)
+	}
+	if ca.Question == "" {
+		return invalidCachedAnswerError(ca.ID, "question", ca.Question)
+	}
+	if ca.Answer == "" {
+		return invalidCachedAnswerError(ca.ID, "answer", ca.Answer)
+	}
+	return nil
+}
+
+// BeforeSave should be called before storing the cached answer
+func (ca *CachedAnswer) BeforeSave() {
+	ca.ID = NewID()
+	ca.CreatedAt = GetMillis()
+	ca.UpdatedAt = ca.CreatedAt
+	if ca.Citations == nil {
+		ca.Citations = []*Citation{}
+	}
+}
+
+// CachedAnswerFromJSON will decode the input and return a CachedAnswer
+func CachedAnswerFromJSON(data io.Reader) (*CachedAnswer, error) {
+	var cachedAnswer *CachedAnswer
+	if err := json.NewDecoder(data).Decode(&cachedAnswer); err != nil {
+		return nil, errors.Wrap(err, "can't decode cached answer")
+	}
+	return cachedAnswer, nil
+}
+
+func invalidCachedAnswerError(id, fieldName string, fieldValue any) error {
+	return errors.Errorf("invalid cached answer error. id=%s %s=%v", id, fieldName, fieldValue)
+}
+
+// CachedAnswerGetOptions for getting and filtering cached answers
+type CachedAnswerGetOptions struct {
+	// NodeID returns answers of the node
+	NodeID  string
+	Page    int
+	PerPage int
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"github.com/pkg/errors"
+)
+
+// hints are graduated, every next hint on the assignment is more specific, the last one never reveals the solution either
+const (
+	MinAssignmentHintLevel = 1
+	MaxAssignmentHintLevel = 3
+)
+
+// AssignmentHint records the tutor's hint on the learner's code of the assignment
+type AssignmentHint struct {
+	UserID    string `json:"user_id" db:"user_id"`
+	NodeID    string `json:"node_id" db:"node_id"`
+	Level     int    `json:"level" db:"level"`
+	CreatedAt int64  `json:"created_at" db:"created_at"`
+}
+
+// IsValid validates the assignment hint and returns an error if it isn't configured correctly.
+func (ah *AssignmentHint) IsValid() error {
+	if !IsValidID(ah.UserID) {
+		return invalidAssignmentHintError(ah.UserID, ah.NodeID, "user_id", ah.UserID)
+	}
+	if !IsValidID(ah.NodeID) {
+		return invalidAssignmentHintError(ah.UserID, ah.NodeID, "node_id", ah.NodeID)
+	}
+	if ah.Level < MinAssignmentHintLevel || ah.Level > MaxAssignmentHintLevel {
+		return invalidAssignmentHintError(ah.UserID, ah.NodeID, "level", ah.Level)
+	}
+	return nil
+}
+
+// BeforeSave should be called before storing the assignment hint
+func (ah *AssignmentHint) BeforeSave() {
+	if ah.CreatedAt == 0 {
+		ah.CreatedAt = GetMillis()
+	}
+}
+
+func invalidAssignmentHintError(userID, nodeID, fieldName string, fieldValue any) error {
+	return errors.Errorf("invalid assignment hint error. userID=%s nodeID=%s %s=%v", userID, nodeID, fieldName, fieldValue)
+}
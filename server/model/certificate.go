@@ -0,0 +1,100 @@
+package model
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// Certificate is issued to a learner who reached a goal.
+// Certificate stores everything printed on it, so PDF can be rendered at any time.
+// Certificate's random ID printed on the PDF is used to verify it against the DB.
+type Certificate struct {
+	ID          string   `json:"id" db:"id"`
+	UserID      string   `json:"user_id" db:"user_id"`
+	NodeID      string   `json:"node_id" db:"node_id"`
+	LearnerName string   `json:"learner_name" db:"learner_name"`
+	GoalName    string   `json:"goal_name" db:"goal_name"`
+	Topics      []string `json:"topics" db:"-"`
+	TopicsJSON  string   `json:"-" db:"topics"`
+	CreatedAt   int64    `json:"created_at" db:"created_at"`
+	RevokedAt   int64    `json:"revoked_at" db:"revoked_at"`
+}
+
+// CertificateVerification is the public result of the certificate verification
+type CertificateVerification struct {
+	Valid       bool   `json:"valid"`
+	Revoked     bool   `json:"revoked"`
+	ID          string `json:"id"`
+	LearnerName string `json:"learner_name"`
+	GoalName    string `json:"goal_name"`
+	IssuedAt    int64  `json:"issued_at"`
+	RevokedAt   int64  `json:"revoked_at,omitempty"`
+}
+
+// IsValid validates the certificate and returns an error if it isn't configured correctly.
+func (c *Certificate) IsValid() error {
+	if !IsValidID(c.ID) {
+		return invalidCertificateError(c.ID, "id", c.ID)
+	}
+	if !IsValidID(c.UserID) {
+		return invalidCertificateError(c.ID, "user_id", c.UserID)
+	}
+	if !IsValidID(c.NodeID) {
+		return invalidCertificateError(c.ID, "node_id", c.NodeID)
+	}
+	if c.LearnerName == "" {
+		return invalidCertificateError(c.ID, "learner_name", c.LearnerName)
+	}
+	if c.GoalName == "" {
+		return invalidCertificateError(c.ID, "goal_name", c.GoalName)
+	}
+	if c.CreatedAt == 0 {
+		return invalidCertificateError(c.ID, "created_at", c.CreatedAt)
+	}
+	return nil
+}
+
+// BeforeSave should be called before storing the certificate
+func (c *Certificate) BeforeSave() error {
+	if c.ID == "" {
+		c.ID = NewID()
+	}
+	if c.CreatedAt == 0 {
+		c.CreatedAt = GetMillis()
+	}
+	topics, err := json.Marshal(c.Topics)
+	if err != nil {
+		return errors.Wrapf(err, "can't marshal topics of certificate %s", c.ID)
+	}
+	c.TopicsJSON = string(topics)
+	return nil
+}
+
+// AfterLoad should be called after reading the certificate from the DB
+func (c *Certificate) AfterLoad() error {
+	if c.TopicsJSON == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(c.TopicsJSON), &c.Topics); err != nil {
+		return errors.Wrapf(err, "can't unmarshal topics of certificate %s", c.ID)
+	}
+	return nil
+}
+
+// Verification returns public information about the certificate
+func (c *Certificate) Verification() *CertificateVerification {
+	return &CertificateVerification{
+		Valid:       c.RevokedAt == 0,
+		Revoked:     c.RevokedAt != 0,
+		ID:          c.ID,
+		LearnerName: c.LearnerName,
+		GoalName:    c.GoalName,
+		IssuedAt:    c.CreatedAt,
+		RevokedAt:   c.RevokedAt,
+	}
+}
+
+func invalidCertificateError(certificateID, fieldName string, fieldValue any) error {
+	return errors.Errorf("invalid certificate error. certificateID=%s %s=%v", certificateID, fieldName, fieldValue)
+}
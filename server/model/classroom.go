@@ -0,0 +1,131 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ClassroomJoinCodeLength is the length of the code students join the classroom with
+	ClassroomJoinCodeLength = 8
+
+	classroomNameMaxRunes = 128
+)
+
+// Classroom is a class created by a teacher, students join it with the join code
+type Classroom struct {
+	ID        string `json:"id" db:"id"`
+	TeacherID string `json:"teacher_id" db:"teacher_id"`
+	Name      string `json:"name" db:"name"`
+	JoinCode  string `json:"join_code,omitempty" db:"join_code"`
+	CreatedAt int64  `json:"created_at" db:"created_at"`
+	DeletedAt int64  `json:"deleted_at" db:"deleted_at"`
+}
+
+// ClassroomGoal is a goal node assigned to all the students of the classroom
+type ClassroomGoal struct {
+	ClassroomID string `json:"classroom_id" db:"classroom_id"`
+	NodeID      string `json:"node_id" db:"node_id"`
+	NodeName    string `json:"node_name,omitempty" db:"node_name"`
+	// DueDate is the date (in millis) students should reach the goal by, 0 if not set
+	DueDate   int64 `json:"due_date" db:"due_date"`
+	CreatedAt int64 `json:"created_at" db:"created_at"`
+}
+
+// StudentGoalProgress is student's progress on the path to the assigned goal
+type StudentGoalProgress struct {
+	NodeID   string `json:"node_id"`
+	Finished int    `json:"finished"`
+	Total    int    `json:"total"`
+	// Statuses maps nodes of the path to student's statuses, nodes student hasn't seen are omitted
+	Statuses map[string]string `json:"statuses"`
+}
+
+// StudentProgress is a row of the classroom dashboard
+type StudentProgress struct {
+	User  *User                  `json:"user"`
+	Goals []*StudentGoalProgress `json:"goals"`
+	// StuckNodes are nodes of the assigned paths student started but hasn't progressed on for a while
+	StuckNodes        []string `json:"stuck_nodes"`
+	QuestionsAnswered int      `json:"questions_answered"`
+	// QuestionAccuracy is the share of the right answers on the assigned paths, 0 if student answered no questions
+	QuestionAccuracy float64 `json:"question_accuracy"`
+	// BotMessages is the number of messages student got from the bot during the last month
+	BotMessages int `json:"bot_messages"`
+	// HintLevels maps assignments of the assigned paths to the highest level of the hints student needed
+	HintLevels map[string]int `json:"hint_levels"`
+}
+
+// ClassroomDashboard is the teacher's overview of the classroom
+type ClassroomDashboard struct {
+	Classroom *Classroom         `json:"classroom"`
+	Goals     []*ClassroomGoal   `json:"goals"`
+	Students  []*StudentProgress `json:"students"`
+}
+
+// IsValid validates the classroom and returns an error if it isn't configured correctly.
+func (c *Classroom) IsValid() error {
+	if !IsValidID(c.ID) {
+		return invalidClassroomError("", "id", c.ID)
+	}
+
+	if !IsValidID(c.TeacherID) {
+		return invalidClassroomError(c.ID, "teacherID", c.TeacherID)
+	}
+
+	if c.Name == "" || len([]rune(c.Name)) > classroomNameMaxRunes {
+		return invalidClassroomError(c.ID, "name", c.Name)
+	}
+
+	if len(c.JoinCode) != ClassroomJoinCodeLength {
+		return invalidClassroomError(c.ID, "joinCode", c.JoinCode)
+	}
+
+	if c.CreatedAt == 0 {
+		return invalidClassroomError(c.ID, "createdAt", c.CreatedAt)
+	}
+
+	return nil
+}
+
+// BeforeSave should be called before storing the classroom
+func (c *Classroom) BeforeSave() {
+	if c.ID == "" {
+		c.ID = NewID()
+	}
+	if c.JoinCode == "" {
+		c.JoinCode = NewClassroomJoinCode()
+	}
+	c.Name = strings.TrimSpace(c.Name)
+	c.CreatedAt = GetMillis()
+}
+
+// NewClassroomJoinCode returns a random upper case join code
+func NewClassroomJoinCode() string {
+	return strings.ToUpper(NewRandomString(ClassroomJoinCodeLength))
+}
+
+// ClassroomFromJSON will decode the input and return a Classroom
+func ClassroomFromJSON(data io.Reader) (*Classroom, error) {
+	var classroom *Classroom
+	if err := json.NewDecoder(data).Decode(&classroom); err != nil {
+		return nil, errors.Wrap(err, "can't decode classroom")
+	}
+	return classroom, nil
+}
+
+// ClassroomGoalFromJSON will decode the input and return a ClassroomGoal
+func ClassroomGoalFromJSON(data io.Reader) (*ClassroomGoal, error) {
+	var goal *ClassroomGoal
+	if err := json.NewDecoder(data).Decode(&goal); err != nil {
+		return nil, errors.Wrap(err, "can't decode classroom goal")
+	}
+	return goal, nil
+}
+
+func invalidClassroomError(classroomID, fieldName string, fieldValue any) error {
+	return errors.Errorf("invalid classroom error. classroomID=%s %s=%v", classroomID, fieldName, fieldValue)
+}
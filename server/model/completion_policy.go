@@ -0,0 +1,129 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	RequirementVideosWatched     = "videos_watched"
+	RequirementQuestionsAnswered = "questions_answered"
+	RequirementCorrectPercent    = "correct_percent"
+)
+
+// CompletionPolicy defines what a learner has to do before a node can be marked as finished.
+// A policy set on a parent node (course) applies to all of its children which don't have their own policy.
+type CompletionPolicy struct {
+	NodeID               string `json:"node_id" db:"node_id"`
+	MinVideosWatched     int    `json:"min_videos_watched" db:"min_videos_watched"`
+	MinQuestionsAnswered int    `json:"min_questions_answered" db:"min_questions_answered"`
+	MinCorrectPercent    int    `json:"min_correct_percent" db:"min_correct_percent"`
+	CreatedAt            int64  `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt            int64  `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// NodeProgress is the learner's progress on the node used to evaluate a completion policy
+type NodeProgress struct {
+	VideosWatched     int `json:"videos_watched"`
+	QuestionsTotal    int `json:"questions_total"`
+	QuestionsAnswered int `json:"questions_answered"`
+	QuestionsCorrect  int `json:"questions_correct"`
+}
+
+// UnmetRequirement describes a single requirement of the completion policy the learner hasn't met yet
+type UnmetRequirement struct {
+	Requirement string `json:"requirement"`
+	Required    int    `json:"required"`
+	Actual      int    `json:"actual"`
+}
+
+// UnmetRequirementsError is returned when learner tries to finish a node without meeting its completion policy
+type UnmetRequirementsError struct {
+	NodeID       string             `json:"node_id"`
+	Requirements []UnmetRequirement `json:"unmet_requirements"`
+}
+
+func (e *UnmetRequirementsError) Error() string {
+	unmet := make([]string, 0, len(e.Requirements))
+	for _, r := range e.Requirements {
+		unmet = append(unmet, fmt.Sprintf("%s: %d/%d", r.Requirement, r.Actual, r.Required))
+	}
+	return fmt.Sprintf("completion policy not met for node %s. %s", e.NodeID, strings.Join(unmet, ", "))
+}
+
+// IsValid validates the policy and returns an error if it isn't configured correctly.
+func (p *CompletionPolicy) IsValid() error {
+	if !IsValidID(p.NodeID) {
+		return invalidCompletionPolicyError("node_id", p.NodeID)
+	}
+	if p.MinVideosWatched < 0 {
+		return invalidCompletionPolicyError("min_videos_watched", p.MinVideosWatched)
+	}
+	if p.MinQuestionsAnswered < 0 {
+		return invalidCompletionPolicyError("min_questions_answered", p.MinQuestionsAnswered)
+	}
+	if p.MinCorrectPercent < 0 || p.MinCorrectPercent > 100 {
+		return invalidCompletionPolicyError("min_correct_percent", p.MinCorrectPercent)
+	}
+	if p.CreatedAt == 0 {
+		return invalidCompletionPolicyError("created_at", p.CreatedAt)
+	}
+	return nil
+}
+
+// BeforeSave should be called before storing the policy
+func (p *CompletionPolicy) BeforeSave() {
+	if p.CreatedAt == 0 {
+		p.CreatedAt = GetMillis()
+	}
+	p.UpdatedAt = GetMillis()
+}
+
+// Check returns list of requirements which are not met by the progress.
+// Percent of correct answers is calculated over all the questions of the node,
+// so unanswered questions count as incorrect ones.
+func (p *CompletionPolicy) Check(progress *NodeProgress) []UnmetRequirement {
+	unmet := []UnmetRequirement{}
+	if progress.VideosWatched < p.MinVideosWatched {
+		unmet = append(unmet, UnmetRequirement{
+			Requirement: RequirementVideosWatched,
+			Required:    p.MinVideosWatched,
+			Actual:      progress.VideosWatched,
+		})
+	}
+	if progress.QuestionsAnswered < p.MinQuestionsAnswered {
+		unmet = append(unmet, UnmetRequirement{
+			Requirement: RequirementQuestionsAnswered,
+			Required:    p.MinQuestionsAnswered,
+			Actual:      progress.QuestionsAnswered,
+		})
+	}
+	if p.MinCorrectPercent > 0 && progress.QuestionsTotal > 0 {
+		correctPercent := progress.QuestionsCorrect * 100 / progress.QuestionsTotal
+		if correctPercent < p.MinCorrectPercent {
+			unmet = append(unmet, UnmetRequirement{
+				Requirement: RequirementCorrectPercent,
+				Required:    p.MinCorrectPercent,
+				Actual:      correctPercent,
+			})
+		}
+	}
+	return unmet
+}
+
+// CompletionPolicyFromJSON will decode the input and return a CompletionPolicy
+func CompletionPolicyFromJSON(data io.Reader) (*CompletionPolicy, error) {
+	var policy *CompletionPolicy
+	if err := json.NewDecoder(data).Decode(&policy); err != nil {
+		return nil, errors.Wrap(err, "can't decode completion policy")
+	}
+	return policy, nil
+}
+
+func invalidCompletionPolicyError(fieldName string, fieldValue any) error {
+	return errors.Errorf("invalid completion policy error. %s=%v", fieldName, fieldValue)
+}
@@ -0,0 +1,53 @@
+package model
+
+// content sources of the chunks
+const (
+	ContentSourceText     = "text"
+	ContentSourceQuestion = "question"
+	ContentSourceVideo    = "video"
+)
+
+// ContentChunk is a passage of the node's learning material embedded for retrieval
+type ContentChunk struct {
+	ID         string `json:"id" db:"id"`
+	NodeID     string `json:"node_id" db:"node_id"`
+	SourceType string `json:"source_type" db:"source_type"`
+	// SourceID is the id of the text, question or video the passage is taken from
+	SourceID string `json:"source_id" db:"source_id"`
+	// Title is the name of the source, shown to the learner
+	Title string `json:"title" db:"title"`
+	// Anchor is the slug of the text's heading the passage belongs to
+	Anchor string `json:"anchor" db:"anchor"`
+	// StartSeconds is the timestamp of the passage in the video
+	StartSeconds int64     `json:"start_seconds" db:"start_seconds"`
+	Content      string    `json:"content" db:"content"`
+	Vector       []float32 `json:"vector,omitempty" db:"-"`
+	UpdatedAt    int64     `json:"updated_at" db:"updated_at"`
+	// Score is the similarity to the query, set only by the search
+	Score float32 `json:"score,omitempty" db:"score"`
+}
+
+// Citation points the learner to the material the bot's answer is based on.
+// Texts are cited by id and anchor, videos by id and timestamp, questions by id.
+type Citation struct {
+	Index        int    `json:"index"`
+	NodeID       string `json:"node_id"`
+	SourceType   string `json:"source_type"`
+	SourceID     string `json:"source_id"`
+	Title        string `json:"title"`
+	Anchor       string `json:"anchor,omitempty"`
+	StartSeconds int64  `json:"start_seconds,omitempty"`
+}
+
+// Citation returns the citation of the chunk with the index used in the prompt
+func (cc *ContentChunk) Citation(index int) *Citation {
+	return &Citation{
+		Index:        index,
+		NodeID:       cc.NodeID,
+		SourceType:   cc.SourceType,
+		SourceID:     cc.SourceID,
+		Title:        cc.Title,
+		Anchor:       cc.Anchor,
+		StartSeconds: cc.StartSeconds,
+	}
+}
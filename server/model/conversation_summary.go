@@ -0,0 +1,39 @@
+package model
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ConversationSummary is the tutor's rolling memory of the learner's dialogue on the node,
+// older turns are summarized by the language model as they leave the context window
+type ConversationSummary struct {
+	UserID  string `json:"user_id" db:"user_id"`
+	NodeID  string `json:"node_id" db:"node_id"`
+	Summary string `json:"summary" db:"summary"`
+	// LastPostAt is the creation time of the newest post included in the summary
+	LastPostAt int64 `json:"last_post_at" db:"last_post_at"`
+	UpdatedAt  int64 `json:"updated_at" db:"updated_at"`
+}
+
+// IsValid validates the conversation summary and returns an error if it isn't configured correctly.
+func (cs *ConversationSummary) IsValid() error {
+	if !IsValidID(cs.UserID) {
+		return invalidConversationSummaryError(cs.UserID, cs.NodeID, "user_id", cs.UserID)
+	}
+	if !IsValidID(cs.NodeID) {
+		return invalidConversationSummaryError(cs.UserID, cs.NodeID, "node_id", cs.NodeID)
+	}
+	if cs.Summary == "" {
+		return invalidConversationSummaryError(cs.UserID, cs.NodeID, "summary", cs.Summary)
+	}
+	return nil
+}
+
+// BeforeSave should be called before storing the conversation summary
+func (cs *ConversationSummary) BeforeSave() {
+	cs.UpdatedAt = GetMillis()
+}
+
+func invalidConversationSummaryError(userID, nodeID, fieldName string, fieldValue any) error {
+	return errors.Errorf("invalid conversation summary error. userID=%s nodeID=%s %s=%v", userID, nodeID, fieldName, fieldValue)
+}
@@ -0,0 +1,31 @@
+package model
+
+import "fmt"
+
+// Embedding is a vector of the indexed text, used to route learner's messages to topics and intents
+type Embedding struct {
+	ID string `json:"id" db:"id"`
+	// Name is the name of the node the text is about, empty for intent phrases
+	Name string `json:"name" db:"name"`
+	// Intent is the intent the phrase expresses, empty for nodes
+	Intent    string    `json:"intent" db:"intent"`
+	Vector    []float32 `json:"vector" db:"-"`
+	UpdatedAt int64     `json:"updated_at" db:"updated_at"`
+}
+
+// EmbeddingMatch is the indexed text similar to the queried vector
+type EmbeddingMatch struct {
+	Name   string  `json:"name" db:"name"`
+	Intent string  `json:"intent" db:"intent"`
+	Score  float32 `json:"score" db:"score"`
+}
+
+// NodeEmbeddingID returns id of the node's embedding
+func NodeEmbeddingID(nodeID string) string {
+	return fmt.Sprintf("node_%s", nodeID)
+}
+
+// IntentEmbeddingID returns id of the embedding of the intent's i-th phrase
+func IntentEmbeddingID(intent string, i int) string {
+	return fmt.Sprintf("intent_%s_%d", intent, i)
+}
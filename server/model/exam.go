@@ -0,0 +1,180 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ExamAttemptStatusInProgress = "in_progress"
+	ExamAttemptStatusPassed     = "passed"
+	ExamAttemptStatusFailed     = "failed"
+
+	examNameMaxRunes = 128
+	// examMaxTimeLimitMinutes limits exams to a day
+	examMaxTimeLimitMinutes = 24 * 60
+)
+
+// Exam is a timed summative assessment of a parent node, its questions are drawn from the node's children
+type Exam struct {
+	ID           string `json:"id" db:"id"`
+	ParentNodeID string `json:"parent_node_id" db:"parent_node_id"`
+	Name         string `json:"name" db:"name"`
+	// NumQuestions is the number of questions drawn from the pool for every attempt
+	NumQuestions     int `json:"num_questions" db:"num_questions"`
+	TimeLimitMinutes int `json:"time_limit_minutes" db:"time_limit_minutes"`
+	// PassingScore is the percent of right answers needed to pass
+	PassingScore int `json:"passing_score" db:"passing_score"`
+	// MaxAttempts is the number of attempts learner has, 0 if unlimited
+	MaxAttempts int `json:"max_attempts" db:"max_attempts"`
+	// FinishesParent marks the parent node as finished when learner passes the exam
+	FinishesParent bool  `json:"finishes_parent" db:"finishes_parent"`
+	CreatedAt      int64 `json:"created_at" db:"created_at"`
+	UpdatedAt      int64 `json:"updated_at" db:"updated_at"`
+	DeletedAt      int64 `json:"deleted_at" db:"deleted_at"`
+}
+
+// ExamAttempt is learner's attempt to pass the exam
+type ExamAttempt struct {
+	ID        string `json:"id" db:"id"`
+	ExamID    string `json:"exam_id" db:"exam_id"`
+	UserID    string `json:"user_id" db:"user_id"`
+	StartedAt int64  `json:"started_at" db:"started_at"`
+	// Deadline is the time (in millis) after which answers aren't accepted
+	Deadline   int64  `json:"deadline" db:"deadline"`
+	FinishedAt int64  `json:"finished_at" db:"finished_at"`
+	Status     string `json:"status" db:"status"`
+	// Score is the percent of right answers
+	Score     int                    `json:"score" db:"score"`
+	Questions []*ExamAttemptQuestion `json:"questions,omitempty" db:"-"`
+}
+
+// ExamAttemptQuestion is a question of the attempt with learner's answer on it
+type ExamAttemptQuestion struct {
+	AttemptID  string `json:"attempt_id" db:"attempt_id"`
+	QuestionID string `json:"question_id" db:"question_id"`
+	Position   int    `json:"position" db:"position"`
+	ChoiceID   string `json:"choice_id,omitempty" db:"choice_id"`
+	IsRight    bool   `json:"is_right" db:"is_right"`
+	AnsweredAt int64  `json:"answered_at" db:"answered_at"`
+}
+
+// ExamQuestion is the question learner has to answer next
+type ExamQuestion struct {
+	Attempt  *ExamAttempt `json:"attempt"`
+	Position int          `json:"position"`
+	Total    int          `json:"total"`
+	Question *Question    `json:"question,omitempty"`
+}
+
+// IsValid validates the exam and returns an error if it isn't configured correctly.
+func (e *Exam) IsValid() error {
+	if !IsValidID(e.ID) {
+		return invalidExamError("", "id", e.ID)
+	}
+
+	if !IsValidID(e.ParentNodeID) {
+		return invalidExamError(e.ID, "parentNodeID", e.ParentNodeID)
+	}
+
+	if e.Name == "" || utf8.RuneCountInString(e.Name) > examNameMaxRunes {
+		return invalidExamError(e.ID, "name", e.Name)
+	}
+
+	if e.NumQuestions <= 0 {
+		return invalidExamError(e.ID, "numQuestions", e.NumQuestions)
+	}
+
+	if e.TimeLimitMinutes <= 0 || e.TimeLimitMinutes > examMaxTimeLimitMinutes {
+		return invalidExamError(e.ID, "timeLimitMinutes", e.TimeLimitMinutes)
+	}
+
+	if e.PassingScore < 0 || e.PassingScore > 100 {
+		return invalidExamError(e.ID, "passingScore", e.PassingScore)
+	}
+
+	if e.MaxAttempts < 0 {
+		return invalidExamError(e.ID, "maxAttempts", e.MaxAttempts)
+	}
+
+	if e.CreatedAt == 0 {
+		return invalidExamError(e.ID, "createdAt", e.CreatedAt)
+	}
+
+	return nil
+}
+
+// BeforeSave should be called before storing the exam
+func (e *Exam) BeforeSave() {
+	if e.ID == "" {
+		e.ID = NewID()
+	}
+	if e.CreatedAt == 0 {
+		e.CreatedAt = GetMillis()
+	}
+	e.UpdatedAt = GetMillis()
+}
+
+// IsExpired returns true if the attempt is in progress but its deadline has passed
+func (a *ExamAttempt) IsExpired(now int64) bool {
+	return a.Status == ExamAttemptStatusInProgress && now > a.Deadline
+}
+
+// NextQuestion returns the first unanswered question of the attempt, nil if all the questions are answered
+func (a *ExamAttempt) NextQuestion() *ExamAttemptQuestion {
+	for _, question := range a.Questions {
+		if question.AnsweredAt == 0 {
+			return question
+		}
+	}
+	return nil
+}
+
+// Grade computes the score of the attempt and whether it passed the exam
+func (a *ExamAttempt) Grade(passingScore int) {
+	right := 0
+	for _, question := range a.Questions {
+		if question.IsRight {
+			right++
+		}
+	}
+	a.Score = 0
+	if len(a.Questions) > 0 {
+		a.Score = right * 100 / len(a.Questions)
+	}
+	a.Status = ExamAttemptStatusFailed
+	if a.Score >= passingScore {
+		a.Status = ExamAttemptStatusPassed
+	}
+}
+
+// WithoutResults returns a copy of the attempt that doesn't reveal which answers were right while it's in progress
+func (a *ExamAttempt) WithoutResults() *ExamAttempt {
+	if a.Status != ExamAttemptStatusInProgress {
+		return a
+	}
+	attempt := *a
+	attempt.Questions = make([]*ExamAttemptQuestion, 0, len(a.Questions))
+	for _, question := range a.Questions {
+		q := *question
+		q.IsRight = false
+		attempt.Questions = append(attempt.Questions, &q)
+	}
+	return &attempt
+}
+
+// ExamFromJSON will decode the input and return an Exam
+func ExamFromJSON(data io.Reader) (*Exam, error) {
+	var exam *Exam
+	if err := json.NewDecoder(data).Decode(&exam); err != nil {
+		return nil, errors.Wrap(err, "can't decode exam")
+	}
+	return exam, nil
+}
+
+func invalidExamError(examID, fieldName string, fieldValue any) error {
+	return errors.Errorf("invalid exam error. examID=%s %s=%v", examID, fieldName, fieldValue)
+}
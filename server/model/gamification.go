@@ -0,0 +1,224 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	XPEventNodeFinished    = "node_finished"
+	XPEventCorrectAnswer   = "correct_answer"
+	XPEventVideoWatched    = "video_watched"
+	XPEventGoalFinished    = "goal_finished"
+	XPEventStreakMilestone = "streak_milestone"
+
+	MetricXP             = "xp"
+	MetricLevel          = "level"
+	MetricNodesFinished  = "nodes_finished"
+	MetricCorrectAnswers = "correct_answers"
+	MetricVideosWatched  = "videos_watched"
+	MetricGoalsFinished  = "goals_finished"
+	MetricCurrentStreak  = "current_streak"
+
+	// xpPerLevel is the XP needed for the second level, every next level needs xpPerLevel more than the previous one
+	xpPerLevel = 100
+)
+
+// XPForEvent is the number of XP user gets for the event
+var XPForEvent = map[string]int{
+	XPEventNodeFinished:    50,
+	XPEventCorrectAnswer:   5,
+	XPEventVideoWatched:    10,
+	XPEventGoalFinished:    200,
+	XPEventStreakMilestone: 100,
+}
+
+// StreakMilestones are the streak lengths rewarded with XP
+var StreakMilestones = []int{3, 7, 14, 30, 60, 100, 365}
+
+// metricForEvent is the metric counting the events
+var metricForEvent = map[string]string{
+	XPEventNodeFinished:  MetricNodesFinished,
+	XPEventCorrectAnswer: MetricCorrectAnswers,
+	XPEventVideoWatched:  MetricVideosWatched,
+	XPEventGoalFinished:  MetricGoalsFinished,
+}
+
+// XPEvent is a single XP award.
+// User gets XP only once for the same event on the same reference, e.g. for finishing a node.
+type XPEvent struct {
+	ID        string `json:"id" db:"id"`
+	UserID    string `json:"user_id" db:"user_id"`
+	EventType string `json:"event_type" db:"event_type"`
+	// RefID is the id of the node, question, video or the milestone the event is about
+	RefID     string `json:"ref_id" db:"ref_id"`
+	XP        int    `json:"xp" db:"xp"`
+	CreatedAt int64  `json:"created_at" db:"created_at"`
+}
+
+// XPEventCount summarizes user's events of the same type
+type XPEventCount struct {
+	EventType string `json:"event_type" db:"event_type"`
+	Count     int    `json:"count" db:"count"`
+	XP        int    `json:"xp" db:"xp"`
+}
+
+// Level is user's level computed from the total XP
+type Level struct {
+	Level int `json:"level"`
+	XP    int `json:"xp"`
+	// LevelXP and NextLevelXP are the total XP needed for the current and the next levels
+	LevelXP     int `json:"level_xp"`
+	NextLevelXP int `json:"next_level_xp"`
+}
+
+// AchievementCondition requires the metric to be at least Min
+type AchievementCondition struct {
+	Metric string `json:"metric"`
+	Min    int    `json:"min"`
+}
+
+// Achievement is a badge user unlocks when all of its conditions are met.
+// Achievement is evaluated only when one of its events happens.
+type Achievement struct {
+	ID          string                  `json:"id"`
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	Icon        string                  `json:"icon"`
+	Events      []string                `json:"events"`
+	Conditions  []*AchievementCondition `json:"conditions"`
+}
+
+// UserAchievement is the badge unlocked by the user
+type UserAchievement struct {
+	UserID        string       `json:"user_id" db:"user_id"`
+	AchievementID string       `json:"achievement_id" db:"achievement_id"`
+	CreatedAt     int64        `json:"created_at" db:"created_at"`
+	Achievement   *Achievement `json:"achievement,omitempty" db:"-"`
+}
+
+// IsValid validates the XP event and returns an error if it isn't configured correctly.
+func (e *XPEvent) IsValid() error {
+	if !IsValidID(e.ID) {
+		return invalidXPEventError(e.ID, "id", e.ID)
+	}
+	if !IsValidID(e.UserID) {
+		return invalidXPEventError(e.ID, "user_id", e.UserID)
+	}
+	if _, ok := XPForEvent[e.EventType]; !ok {
+		return invalidXPEventError(e.ID, "event_type", e.EventType)
+	}
+	if e.RefID == "" {
+		return invalidXPEventError(e.ID, "ref_id", e.RefID)
+	}
+	if e.CreatedAt == 0 {
+		return invalidXPEventError(e.ID, "created_at", e.CreatedAt)
+	}
+	return nil
+}
+
+// BeforeSave should be called before storing the XP event
+func (e *XPEvent) BeforeSave() {
+	if e.ID == "" {
+		e.ID = NewID()
+	}
+	if e.XP == 0 {
+		e.XP = XPForEvent[e.EventType]
+	}
+	e.CreatedAt = GetMillis()
+}
+
+// LevelForXP computes the level from the total XP, level n needs xpPerLevel*n*(n-1)/2 XP
+func LevelForXP(xp int) *Level {
+	level := &Level{Level: 1, XP: xp}
+	for xpPerLevel*(level.Level+1)*level.Level/2 <= xp {
+		level.Level++
+	}
+	level.LevelXP = xpPerLevel * level.Level * (level.Level - 1) / 2
+	level.NextLevelXP = xpPerLevel * (level.Level + 1) * level.Level / 2
+	return level
+}
+
+// NewUserMetrics builds metrics used by the achievement conditions
+func NewUserMetrics(counts []*XPEventCount, currentStreak int) map[string]int {
+	metrics := map[string]int{
+		MetricCurrentStreak: currentStreak,
+	}
+	xp := 0
+	for _, count := range counts {
+		xp += count.XP
+		if metric, ok := metricForEvent[count.EventType]; ok {
+			metrics[metric] = count.Count
+		}
+	}
+	metrics[MetricXP] = xp
+	metrics[MetricLevel] = LevelForXP(xp).Level
+	return metrics
+}
+
+// TriggeredBy returns true if the achievement should be evaluated on the event
+func (a *Achievement) TriggeredBy(eventType string) bool {
+	for _, event := range a.Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMet returns true if all the conditions are met
+func (a *Achievement) IsMet(metrics map[string]int) bool {
+	for _, condition := range a.Conditions {
+		if metrics[condition.Metric] < condition.Min {
+			return false
+		}
+	}
+	return true
+}
+
+// IsValid validates the achievement and returns an error if it isn't configured correctly.
+func (a *Achievement) IsValid() error {
+	if a.ID == "" {
+		return errors.New("achievement without id")
+	}
+	if a.Name == "" {
+		return errors.Errorf("achievement %s without name", a.ID)
+	}
+	if len(a.Events) == 0 {
+		return errors.Errorf("achievement %s without events", a.ID)
+	}
+	for _, event := range a.Events {
+		if _, ok := XPForEvent[event]; !ok {
+			return errors.Errorf("achievement %s has unknown event %s", a.ID, event)
+		}
+	}
+	if len(a.Conditions) == 0 {
+		return errors.Errorf("achievement %s without conditions", a.ID)
+	}
+	return nil
+}
+
+// AchievementsFromJSON will decode and validate the input and return achievements
+func AchievementsFromJSON(data io.Reader) ([]*Achievement, error) {
+	var achievements []*Achievement
+	if err := json.NewDecoder(data).Decode(&achievements); err != nil {
+		return nil, errors.Wrap(err, "can't decode achievements")
+	}
+	ids := map[string]bool{}
+	for _, achievement := range achievements {
+		if err := achievement.IsValid(); err != nil {
+			return nil, err
+		}
+		if ids[achievement.ID] {
+			return nil, errors.Errorf("duplicate achievement %s", achievement.ID)
+		}
+		ids[achievement.ID] = true
+	}
+	return achievements, nil
+}
+
+func invalidXPEventError(eventID, fieldName string, fieldValue any) error {
+	return errors.Errorf("invalid xp event error. eventID=%s %s=%v", eventID, fieldName, fieldValue)
+}
@@ -14,13 +14,26 @@ type Goal struct {
 	CreatedAt  int64  `json:"created_at,omitempty" db:"created_at"`
 	FinishedAt int64  `json:"finished_at,omitempty" db:"finished_at"`
 	DeletedAt  int64  `json:"deleted_at" db:"deleted_at"`
+	GoalSettings
+}
+
+// GoalSettings are optional settings of the goal used by the planner
+type GoalSettings struct {
+	// TargetDate is the date (in millis) user wants to reach the goal by, 0 if not set
+	TargetDate int64 `json:"target_date" db:"target_date"`
+	// Priority of the goal, goals with higher priority are planned first
+	Priority int `json:"priority" db:"priority"`
+	// WeeklyMinutes is the study time user wants to spend on the goal every week, 0 if not set
+	WeeklyMinutes int `json:"weekly_minutes" db:"weekly_minutes"`
 }
 
 // Goal type defines Knowledge Graph goal
 type GoalWithData struct {
-	NodeID               string `json:"node_id" db:"node_id"`
-	Name                 string `json:"name" db:"name"`
-	ThumbnailRelativeURL string `json:"thumbnail_relative_url" db:"thumbnail_relative_url"`
+	NodeID               string        `json:"node_id" db:"node_id"`
+	Name                 string        `json:"name" db:"name"`
+	ThumbnailRelativeURL string        `json:"thumbnail_relative_url" db:"thumbnail_relative_url"`
+	Estimate             *GoalEstimate `json:"estimate,omitempty" db:"-"`
+	GoalSettings
 }
 
 // IsValid validates the node and returns an error if it isn't configured correctly.
@@ -41,6 +54,10 @@ func (g *Goal) IsValid() error {
 		return invalidGoalError("finishedAt", g.FinishedAt)
 	}
 
+	if err := g.GoalSettings.IsValid(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -51,6 +68,29 @@ func (g *Goal) BeforeSave() {
 	}
 }
 
+// IsValid validates goal settings
+func (gs *GoalSettings) IsValid() error {
+	if gs.TargetDate < 0 {
+		return invalidGoalError("targetDate", gs.TargetDate)
+	}
+	if gs.Priority < 0 {
+		return invalidGoalError("priority", gs.Priority)
+	}
+	if gs.WeeklyMinutes < 0 || gs.WeeklyMinutes > 7*24*60 {
+		return invalidGoalError("weeklyMinutes", gs.WeeklyMinutes)
+	}
+	return nil
+}
+
+// GoalSettingsFromJSON will decode the input and return a GoalSettings
+func GoalSettingsFromJSON(data io.Reader) (*GoalSettings, error) {
+	var settings *GoalSettings
+	if err := json.NewDecoder(data).Decode(&settings); err != nil {
+		return nil, errors.Wrap(err, "can't decode goal settings")
+	}
+	return settings, nil
+}
+
 // GoalFromJSON will decode the input and return a Goal
 func GoalFromJSON(data io.Reader) (*Goal, error) {
 	var goal *Goal
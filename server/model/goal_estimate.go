@@ -0,0 +1,88 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// DefaultStudyMinutesPerDay is used as user's pace until we have enough data about the user
+	DefaultStudyMinutesPerDay = 20.0
+	// DefaultMinutesPerNode is the time user spends on texts and questions of a node besides its videos
+	DefaultMinutesPerNode = 10.0
+)
+
+// StudyPace is the user's pace model built from their past activity
+type StudyPace struct {
+	// MinutesPerDay is the average study time per calendar day
+	MinutesPerDay float64 `json:"minutes_per_day"`
+	// MinutesPerDayStdDev is the standard deviation of the daily study time
+	MinutesPerDayStdDev float64 `json:"minutes_per_day_std_dev"`
+	// MinutesPerNode is time spent on a node besides its videos
+	MinutesPerNode float64 `json:"minutes_per_node"`
+	// VideoFactor is how many times longer than its length user spends on a video, e.g. because of rewatches
+	VideoFactor float64 `json:"video_factor"`
+	// HasHistory is false when pace is based on defaults only
+	HasHistory bool `json:"has_history"`
+}
+
+// NodeVideoLength is the total length of the node's videos in seconds
+type NodeVideoLength struct {
+	NodeID string `json:"node_id" db:"node_id"`
+	Length int64  `json:"length" db:"length"`
+}
+
+// VideoWatchStats summarizes how user watches videos
+type VideoWatchStats struct {
+	TimesStarted  int `json:"times_started" db:"times_started"`
+	TimesFinished int `json:"times_finished" db:"times_finished"`
+}
+
+// GoalEstimate is the estimated time user needs to reach the goal
+type GoalEstimate struct {
+	RemainingNodes   int `json:"remaining_nodes"`
+	RemainingMinutes int `json:"remaining_minutes"`
+	// EstimatedAt is the estimated completion date, EarliestAt and LatestAt define the confidence range
+	EstimatedAt int64 `json:"estimated_at"`
+	EarliestAt  int64 `json:"earliest_at"`
+	LatestAt    int64 `json:"latest_at"`
+}
+
+// RemainingMinutes returns estimated study time for the nodes with total video length in seconds
+func (p *StudyPace) RemainingMinutes(nodes int, videoLength int64) float64 {
+	return float64(nodes)*p.MinutesPerNode + float64(videoLength)/60*p.VideoFactor
+}
+
+// Estimate returns estimated completion date of the remaining work starting from now (in millis).
+// Confidence range is based on the variability of user's daily study time,
+// users without history get a wide range.
+func (p *StudyPace) Estimate(nodes int, videoLength int64, now int64) *GoalEstimate {
+	minutes := p.RemainingMinutes(nodes, videoLength)
+	estimate := &GoalEstimate{
+		RemainingNodes:   nodes,
+		RemainingMinutes: int(math.Ceil(minutes)),
+		EstimatedAt:      now,
+		EarliestAt:       now,
+		LatestAt:         now,
+	}
+	if nodes == 0 {
+		return estimate
+	}
+
+	perDay := p.MinutesPerDay
+	if perDay <= 0 {
+		perDay = DefaultStudyMinutesPerDay
+	}
+	fastPerDay := perDay + p.MinutesPerDayStdDev
+	slowPerDay := math.Max(perDay-p.MinutesPerDayStdDev, perDay/3)
+	if !p.HasHistory {
+		fastPerDay = perDay * 2
+		slowPerDay = perDay / 2
+	}
+
+	day := float64(24 * time.Hour.Milliseconds())
+	estimate.EstimatedAt = now + int64(math.Ceil(minutes/perDay)*day)
+	estimate.EarliestAt = now + int64(math.Ceil(minutes/fastPerDay)*day)
+	estimate.LatestAt = now + int64(math.Ceil(minutes/slowPerDay)*day)
+	return estimate
+}
@@ -22,6 +22,9 @@ type FrontendNodes struct {
 	NodeType    string `json:"node_type"`
 	Status      string `json:"status"`
 	ParentID    string `json:"parent_id"`
+	// Retention and NeedsRefresh are set only for the finished nodes, status of such nodes stays finished
+	Retention    float64 `json:"retention,omitempty"`
+	NeedsRefresh bool    `json:"needs_refresh,omitempty"`
 }
 
 type FrontendLinks struct {
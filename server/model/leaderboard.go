@@ -0,0 +1,104 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	LeaderboardScopeGlobal  = "global"
+	LeaderboardScopeCohort  = "cohort"
+	LeaderboardScopeCourse  = "course"
+	LeaderboardScopeFriends = "friends"
+	LeaderboardScopeGroup   = "group"
+
+	LeaderboardWindowWeekly  = "weekly"
+	LeaderboardWindowMonthly = "monthly"
+	LeaderboardWindowAllTime = "all_time"
+
+	LeaderboardMetricXP    = "xp"
+	LeaderboardMetricNodes = "nodes"
+
+	// HideFromLeaderboardsKey is the preference of users who don't want to appear on leaderboards
+	HideFromLeaderboardsKey = "hide_from_leaderboards"
+
+	leaderboardPeriodAllTime = "all"
+)
+
+// LeaderboardEntry is user's place on the leaderboard
+type LeaderboardEntry struct {
+	Rank      int    `json:"rank" db:"-"`
+	UserID    string `json:"user_id" db:"user_id"`
+	Username  string `json:"username" db:"username"`
+	FirstName string `json:"first_name,omitempty" db:"first_name"`
+	LastName  string `json:"last_name,omitempty" db:"last_name"`
+	Score     int    `json:"score" db:"score"`
+}
+
+// Leaderboard is the ranked list of users
+type Leaderboard struct {
+	Scope   string              `json:"scope"`
+	Window  string              `json:"window"`
+	Metric  string              `json:"metric"`
+	Entries []*LeaderboardEntry `json:"entries"`
+	// Me is the place of the user requesting the leaderboard, nil if user has no score yet
+	Me *LeaderboardEntry `json:"me,omitempty"`
+}
+
+// LeaderboardGetOptions for getting the leaderboard of a scope
+type LeaderboardGetOptions struct {
+	Metric string
+	Period string
+	// ViewerID is the user requesting the leaderboard, viewer always sees themselves
+	ViewerID string
+	// CohortFrom and CohortTo filter users registered in the range (in millis)
+	CohortFrom int64
+	CohortTo   int64
+	// CourseID filters users learning the course
+	CourseID string
+	// FriendsOf filters friends of the user, who added each other, and the user themselves
+	FriendsOf string
+	// StudyGroupID filters members of the study group
+	StudyGroupID string
+	Limit        int
+}
+
+// IsValidLeaderboardRequest validates scope, window and metric of the requested leaderboard
+func IsValidLeaderboardRequest(scope, window, metric string) error {
+	if scope != LeaderboardScopeGlobal && scope != LeaderboardScopeCohort &&
+		scope != LeaderboardScopeCourse && scope != LeaderboardScopeFriends && scope != LeaderboardScopeGroup {
+		return errors.Errorf("invalid leaderboard scope %s", scope)
+	}
+	if window != LeaderboardWindowWeekly && window != LeaderboardWindowMonthly && window != LeaderboardWindowAllTime {
+		return errors.Errorf("invalid leaderboard window %s", window)
+	}
+	if metric != LeaderboardMetricXP && metric != LeaderboardMetricNodes {
+		return errors.Errorf("invalid leaderboard metric %s", metric)
+	}
+	return nil
+}
+
+// LeaderboardPeriod returns the key of the window's period containing the time, periods are in UTC
+func LeaderboardPeriod(window string, t time.Time) string {
+	t = t.UTC()
+	switch window {
+	case LeaderboardWindowWeekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("week:%d-%02d", year, week)
+	case LeaderboardWindowMonthly:
+		return fmt.Sprintf("month:%s", t.Format("2006-01"))
+	default:
+		return leaderboardPeriodAllTime
+	}
+}
+
+// LeaderboardPeriods returns keys of all the periods containing the time, score is added to each of them
+func LeaderboardPeriods(t time.Time) []string {
+	return []string{
+		LeaderboardPeriod(LeaderboardWindowWeekly, t),
+		LeaderboardPeriod(LeaderboardWindowMonthly, t),
+		LeaderboardPeriod(LeaderboardWindowAllTime, t),
+	}
+}
@@ -0,0 +1,79 @@
+package model
+
+// types of the language model calls
+const (
+	LLMCallChat      = "chat"
+	LLMCallStream    = "stream"
+	LLMCallEmbedding = "embedding"
+)
+
+// LLMUsage is an entry of the usage ledger, one per call to the language model
+type LLMUsage struct {
+	ID string `json:"id" db:"id"`
+	// UserID is empty for the calls made by the system, e.g. indexing
+	UserID           string `json:"user_id" db:"user_id"`
+	Model            string `json:"model" db:"model"`
+	CallType         string `json:"call_type" db:"call_type"`
+	PromptTokens     int64  `json:"prompt_tokens" db:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens" db:"completion_tokens"`
+	// Cost in USD computed with the model prices configured at the time of the call
+	Cost float64 `json:"cost" db:"cost"`
+	// Estimated is true if the provider didn't report the usage and tokens were estimated from the text length
+	Estimated bool  `json:"estimated" db:"estimated"`
+	CreatedAt int64 `json:"created_at" db:"created_at"`
+}
+
+// BeforeSave should be called before storing the usage
+func (u *LLMUsage) BeforeSave() {
+	u.ID = NewID()
+	if u.CreatedAt == 0 {
+		u.CreatedAt = GetMillis()
+	}
+}
+
+// LLMUsageTotal is the sum of the usage grouped by user or model
+type LLMUsageTotal struct {
+	UserID           string  `json:"user_id,omitempty" db:"user_id"`
+	Username         string  `json:"username,omitempty" db:"username"`
+	Model            string  `json:"model,omitempty" db:"model"`
+	Calls            int64   `json:"calls" db:"calls"`
+	PromptTokens     int64   `json:"prompt_tokens" db:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens" db:"completion_tokens"`
+	Cost             float64 `json:"cost" db:"cost"`
+}
+
+// TotalTokens returns the sum of prompt and completion tokens
+func (t *LLMUsageTotal) TotalTokens() int64 {
+	return t.PromptTokens + t.CompletionTokens
+}
+
+// LLMUsageGetOptions for getting the usage totals
+type LLMUsageGetOptions struct {
+	// UserID returns usage of the user
+	UserID string
+	// Since and Until are time bounds in millis, 0 means unbounded
+	Since   int64
+	Until   int64
+	Page    int
+	PerPage int
+}
+
+// LLMBudgetStatus is the usage of the current month against the budget of the user's plan
+type LLMBudgetStatus struct {
+	Plan   RoleType         `json:"plan"`
+	Usage  *LLMUsageTotal   `json:"usage"`
+	Models []*LLMUsageTotal `json:"models"`
+	// TokenLimit and CostLimit are 0 if the plan isn't limited by them
+	TokenLimit int64   `json:"token_limit"`
+	CostLimit  float64 `json:"cost_limit"`
+	// PercentUsed is the used part of the tightest limit
+	PercentUsed float64 `json:"percent_used"`
+	Exceeded    bool    `json:"exceeded"`
+}
+
+// LLMUsageReport is the usage of the period for admins
+type LLMUsageReport struct {
+	Total  *LLMUsageTotal   `json:"total"`
+	Users  []*LLMUsageTotal `json:"users"`
+	Models []*LLMUsageTotal `json:"models"`
+}
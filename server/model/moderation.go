@@ -0,0 +1,110 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// moderation policies applied to the flagged text, from the strictest
+const (
+	ModerationPolicyBlock  = "block"
+	ModerationPolicyRedact = "redact"
+	ModerationPolicyWarn   = "warn"
+)
+
+// moderated messages are the learner's input to the tutor or the tutor's output
+const (
+	ModerationDirectionInput  = "input"
+	ModerationDirectionOutput = "output"
+)
+
+// statuses of the flagged exchanges in the review queue
+const (
+	ModerationFlagStatusPending   = "pending"
+	ModerationFlagStatusResolved  = "resolved"
+	ModerationFlagStatusDismissed = "dismissed"
+)
+
+// ModerationCategoryUnmoderated is the category of the exchanges queued for review because the classifier failed
+const ModerationCategoryUnmoderated = "unmoderated"
+
+// ModerationResult is the classifier's verdict on the text
+type ModerationResult struct {
+	Flagged    bool
+	Categories []string
+	// Spans are the byte offsets [start, end) of the flagged parts, empty if the classifier flags the whole text
+	Spans [][2]int
+}
+
+// ModerationFlag is a flagged exchange in the review queue of the admins
+type ModerationFlag struct {
+	ID        string `json:"id" db:"id"`
+	UserID    string `json:"user_id" db:"user_id"`
+	NodeID    string `json:"node_id" db:"node_id"`
+	Direction string `json:"direction" db:"direction"`
+	// Question is the learner's message the flagged output answers, empty for the flagged input
+	Question   string   `json:"question" db:"question"`
+	Text       string   `json:"text" db:"text"`
+	Categories []string `json:"categories" db:"-"`
+	Policy     string   `json:"policy" db:"policy"`
+	Status     string   `json:"status" db:"status"`
+	Note       string   `json:"note" db:"note"`
+	ReviewedBy string   `json:"reviewed_by" db:"reviewed_by"`
+	CreatedAt  int64    `json:"created_at" db:"created_at"`
+	UpdatedAt  int64    `json:"updated_at" db:"updated_at"`
+}
+
+// IsValid validates the moderation flag and returns an error if it isn't configured correctly.
+func (mf *ModerationFlag) IsValid() error {
+	if !IsValidID(mf.ID) {
+		return invalidModerationFlagError(mf.ID, "id", mf.ID)
+	}
+	if !IsValidID(mf.UserID) {
+		return invalidModerationFlagError(mf.ID, "user_id", mf.UserID)
+	}
+	if mf.Direction != ModerationDirectionInput && mf.Direction != ModerationDirectionOutput {
+		return invalidModerationFlagError(mf.ID, "direction", mf.Direction)
+	}
+	if mf.Policy != ModerationPolicyBlock && mf.Policy != ModerationPolicyRedact && mf.Policy != ModerationPolicyWarn {
+		return invalidModerationFlagError(mf.ID, "policy", mf.Policy)
+	}
+	if mf.Status != ModerationFlagStatusPending && mf.Status != ModerationFlagStatusResolved && mf.Status != ModerationFlagStatusDismissed {
+		return invalidModerationFlagError(mf.ID, "status", mf.Status)
+	}
+	return nil
+}
+
+// BeforeSave should be called before storing the moderation flag
+func (mf *ModerationFlag) BeforeSave() {
+	mf.ID = NewID()
+	mf.Status = ModerationFlagStatusPending
+	mf.CreatedAt = GetMillis()
+	mf.UpdatedAt = mf.CreatedAt
+	if mf.Categories == nil {
+		mf.Categories = []string{}
+	}
+}
+
+// ModerationFlagFromJSON will decode the input and return a ModerationFlag
+func ModerationFlagFromJSON(data io.Reader) (*ModerationFlag, error) {
+	var flag *ModerationFlag
+	if err := json.NewDecoder(data).Decode(&flag); err != nil {
+		return nil, errors.Wrap(err, "can't decode moderation flag")
+	}
+	return flag, nil
+}
+
+func invalidModerationFlagError(id, fieldName string, fieldValue any) error {
+	return errors.Errorf("invalid moderation flag error. id=%s %s=%v", id, fieldName, fieldValue)
+}
+
+// ModerationFlagGetOptions for getting and filtering moderation flags
+type ModerationFlagGetOptions struct {
+	Status    string
+	Direction string
+	UserID    string
+	Page      int
+	PerPage   int
+}
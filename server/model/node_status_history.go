@@ -0,0 +1,58 @@
+package model
+
+// NodeStatusTransition is a single record of user's node status change.
+// Transitions are append-only, so they keep the whole learning history of the user.
+type NodeStatusTransition struct {
+	UserID     string `json:"user_id" db:"user_id"`
+	NodeID     string `json:"node_id" db:"node_id"`
+	NodeName   string `json:"node_name,omitempty" db:"node_name"`
+	FromStatus string `json:"from_status" db:"from_status"`
+	ToStatus   string `json:"to_status" db:"to_status"`
+	CreatedAt  int64  `json:"created_at" db:"created_at"`
+}
+
+// TimelineGetOptions for getting and filtering user's status transitions
+type TimelineGetOptions struct {
+	// Since returns transitions created after the time (in millis)
+	Since int64
+	// Until returns transitions created before the time (in millis)
+	Until int64
+	// Page
+	Page int
+	// Page size
+	PerPage int
+}
+
+type TimelineGetOption func(*TimelineGetOptions)
+
+func ComposeTimelineOptions(opts ...TimelineGetOption) TimelineGetOption {
+	return func(options *TimelineGetOptions) {
+		for _, f := range opts {
+			f(options)
+		}
+	}
+}
+
+func TimelineSince(since int64) TimelineGetOption {
+	return func(args *TimelineGetOptions) {
+		args.Since = since
+	}
+}
+
+func TimelineUntil(until int64) TimelineGetOption {
+	return func(args *TimelineGetOptions) {
+		args.Until = until
+	}
+}
+
+func TimelinePage(page int) TimelineGetOption {
+	return func(args *TimelineGetOptions) {
+		args.Page = page
+	}
+}
+
+func TimelinePerPage(perPage int) TimelineGetOption {
+	return func(args *TimelineGetOptions) {
+		args.PerPage = perPage
+	}
+}
@@ -0,0 +1,44 @@
+package model
+
+const (
+	PlanStatusOnTrack = "on_track"
+	PlanStatusBehind  = "behind"
+	PlanStatusAhead   = "ahead"
+
+	// PlanDateFormat is the format of the plan's days
+	PlanDateFormat = "2006-01-02"
+)
+
+// PlanItem is a node user should work on during the day
+type PlanItem struct {
+	NodeID     string `json:"node_id"`
+	NodeName   string `json:"node_name"`
+	GoalNodeID string `json:"goal_node_id"`
+	Minutes    int    `json:"minutes"`
+	Finished   bool   `json:"finished"`
+}
+
+// DayPlan is the plan of a single day
+type DayPlan struct {
+	Date    string      `json:"date"`
+	Minutes int         `json:"minutes"`
+	Items   []*PlanItem `json:"items"`
+}
+
+// Plan is user's study plan spreading remaining paths to the goals across the days
+type Plan struct {
+	UserID    string     `json:"user_id"`
+	CreatedAt int64      `json:"created_at"`
+	Status    string     `json:"status"`
+	Days      []*DayPlan `json:"days"`
+}
+
+// Day returns plan of the day, nil if the day isn't planned
+func (p *Plan) Day(date string) *DayPlan {
+	for _, day := range p.Days {
+		if day.Date == date {
+			return day
+		}
+	}
+	return nil
+}
@@ -22,6 +22,8 @@ const (
 	PostTypeChatGPTCorrectAnswerExplanation   = "chat_gpt_correct_answer_expl"
 	PostTypeChatGPTIncorrectAnswerExplanation = "chat_gpt_incorrect_answer_expl"
 	PostTypeTestAnswer                        = "answer"
+	PostTypeAchievementUnlocked               = "achievement_unlocked"
+	PostTypeReviewQuiz                        = "review_quiz"
 )
 
 const PostMessageMaxRunes = 65536
@@ -77,7 +79,7 @@ func (p *Post) IsValid() error {
 		return invalidPostError(p.ID, "updated_at", p.UpdatedAt)
 	}
 
-	if !IsValidID(p.LocationID) && len(p.LocationID) != len(PostDirectMessageLocationExample) {
+	if !IsValidID(p.LocationID) && len(p.LocationID) != len(PostDirectMessageLocationExample) && !IsStudyGroupLocationID(p.LocationID) {
 		return invalidPostError(p.ID, "location_id", p.LocationID)
 	}
 
@@ -102,7 +104,9 @@ func (p *Post) IsValid() error {
 		p.PostType != PostTypeTopicFinished &&
 		p.PostType != PostTypeChatGPTCorrectAnswerExplanation &&
 		p.PostType != PostTypeChatGPTIncorrectAnswerExplanation &&
-		p.PostType != PostTypeTestAnswer {
+		p.PostType != PostTypeTestAnswer &&
+		p.PostType != PostTypeAchievementUnlocked &&
+		p.PostType != PostTypeReviewQuiz {
 		return invalidPostError(p.ID, "type", p.PostType)
 	}
 
@@ -3,10 +3,20 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"strings"
 
 	"github.com/pkg/errors"
 )
 
+const (
+	QuestionTypeMultipleChoice = "multiple_choice"
+
+	// generated questions are drafts until an admin approves them, only approved questions are shown to learners
+	QuestionStatusDraft    = "draft"
+	QuestionStatusApproved = "approved"
+	QuestionStatusRejected = "rejected"
+)
+
 // Question type defines question/test on a specific node
 type Question struct {
 	ID           string           `json:"id" db:"id"`
@@ -18,6 +28,8 @@ type Question struct {
 	QuestionType string           `json:"question_type" db:"question_type"`
 	NodeID       string           `json:"node_id" db:"node_id"`
 	Explanation  string           `json:"explanation" db:"explanation"`
+	Status       string           `json:"status" db:"status"`
+	ExamOnly     bool             `json:"exam_only" db:"exam_only"`
 	Choices      []QuestionChoice `json:"choices" db:"_"`
 }
 
@@ -41,11 +53,44 @@ func (q *Question) IsValid() error {
 		return invalidQuestionError(q.ID, "create_at", q.CreatedAt)
 	}
 
+	if q.Status != QuestionStatusDraft && q.Status != QuestionStatusApproved && q.Status != QuestionStatusRejected {
+		return invalidQuestionError(q.ID, "status", q.Status)
+	}
+
 	// TODO maybe check if question field is a correct md?
 
 	return nil
 }
 
+// IsValidMultipleChoice validates the structure of the multiple choice question, e.g. the one generated by the language model
+func (q *Question) IsValidMultipleChoice() error {
+	if strings.TrimSpace(q.Question) == "" {
+		return invalidQuestionError(q.ID, "question", q.Question)
+	}
+	if strings.TrimSpace(q.Explanation) == "" {
+		return invalidQuestionError(q.ID, "explanation", q.Explanation)
+	}
+	if len(q.Choices) < 2 {
+		return invalidQuestionError(q.ID, "choices", len(q.Choices))
+	}
+	rightChoices := 0
+	choices := map[string]bool{}
+	for _, choice := range q.Choices {
+		text := strings.ToLower(strings.TrimSpace(choice.Choice))
+		if text == "" || choices[text] {
+			return invalidQuestionError(q.ID, "choice", choice.Choice)
+		}
+		choices[text] = true
+		if choice.IsRightChoice {
+			rightChoices++
+		}
+	}
+	if rightChoices != 1 {
+		return invalidQuestionError(q.ID, "right choices", rightChoices)
+	}
+	return nil
+}
+
 // BeforeSave should be called before storing the question
 func (q *Question) BeforeSave() {
 	q.ID = NewID()
@@ -53,6 +98,9 @@ func (q *Question) BeforeSave() {
 		q.CreatedAt = GetMillis()
 	}
 	q.UpdatedAt = q.CreatedAt
+	if q.Status == "" {
+		q.Status = QuestionStatusApproved
+	}
 }
 
 // BeforeSave should be called before storing the question choice
@@ -60,6 +108,20 @@ func (qc *QuestionChoice) BeforeSave() {
 	qc.ID = NewID()
 }
 
+// WithoutAnswers returns a copy of the question that doesn't reveal the right choice
+func (q *Question) WithoutAnswers() *Question {
+	question := *q
+	question.Explanation = ""
+	question.Choices = make([]QuestionChoice, 0, len(q.Choices))
+	for _, choice := range q.Choices {
+		question.Choices = append(question.Choices, QuestionChoice{
+			ID:     choice.ID,
+			Choice: choice.Choice,
+		})
+	}
+	return &question
+}
+
 // QuestionFromJSON will decode the input and return a Question
 func QuestionFromJSON(data io.Reader) (*Question, error) {
 	var question *Question
@@ -92,6 +154,10 @@ type QuestionGetOptions struct {
 	PerPage int
 	// if true returns questions with answers populated
 	WithAnswers bool
+	// Status returns questions with the status, approved questions if empty
+	Status string
+	// ExamOnly returns the questions of the exams instead of the practice ones
+	ExamOnly bool
 }
 
 type QuestionGetOption func(*QuestionGetOptions)
@@ -127,3 +193,15 @@ func QuestionWithAnswers() QuestionGetOption {
 		args.WithAnswers = true
 	}
 }
+
+func QuestionStatus(status string) QuestionGetOption {
+	return func(args *QuestionGetOptions) {
+		args.Status = status
+	}
+}
+
+func QuestionExamOnly(examOnly bool) QuestionGetOption {
+	return func(args *QuestionGetOptions) {
+		args.ExamOnly = examOnly
+	}
+}
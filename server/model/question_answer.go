@@ -0,0 +1,59 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// QuestionAnswer type defines user's answer on a question
+type QuestionAnswer struct {
+	UserID     string `json:"user_id" db:"user_id"`
+	QuestionID string `json:"question_id" db:"question_id"`
+	ChoiceID   string `json:"choice_id" db:"choice_id"`
+	IsRight    bool   `json:"is_right" db:"is_right"`
+	// TimeSpent is the time (in millis) learner spent on the question before answering, 0 if unknown
+	TimeSpent int64 `json:"time_spent,omitempty" db:"time_spent"`
+	CreatedAt int64 `json:"created_at,omitempty" db:"created_at"`
+}
+
+// IsValid validates the answer and returns an error if it isn't configured correctly.
+func (qa *QuestionAnswer) IsValid() error {
+	if !IsValidID(qa.UserID) {
+		return invalidQuestionAnswerError("user_id", qa.UserID)
+	}
+	if !IsValidID(qa.QuestionID) {
+		return invalidQuestionAnswerError("question_id", qa.QuestionID)
+	}
+	if !IsValidID(qa.ChoiceID) {
+		return invalidQuestionAnswerError("choice_id", qa.ChoiceID)
+	}
+	if qa.TimeSpent < 0 {
+		return invalidQuestionAnswerError("time_spent", qa.TimeSpent)
+	}
+	if qa.CreatedAt == 0 {
+		return invalidQuestionAnswerError("created_at", qa.CreatedAt)
+	}
+	return nil
+}
+
+// BeforeSave should be called before storing the answer
+func (qa *QuestionAnswer) BeforeSave() {
+	if qa.CreatedAt == 0 {
+		qa.CreatedAt = GetMillis()
+	}
+}
+
+// QuestionAnswerFromJSON will decode the input and return a QuestionAnswer
+func QuestionAnswerFromJSON(data io.Reader) (*QuestionAnswer, error) {
+	var answer *QuestionAnswer
+	if err := json.NewDecoder(data).Decode(&answer); err != nil {
+		return nil, errors.Wrap(err, "can't decode question answer")
+	}
+	return answer, nil
+}
+
+func invalidQuestionAnswerError(fieldName string, fieldValue any) error {
+	return errors.Errorf("invalid question answer error. %s=%v", fieldName, fieldValue)
+}
@@ -0,0 +1,39 @@
+package model
+
+// QuestionChoiceStats is how often the choice of the question was picked
+type QuestionChoiceStats struct {
+	ChoiceID string  `json:"choice_id"`
+	Choice   string  `json:"choice"`
+	IsRight  bool    `json:"is_right"`
+	Picks    int     `json:"picks"`
+	PickRate float64 `json:"pick_rate"`
+}
+
+// QuestionStats is item analysis of the question computed from learners' first answers
+type QuestionStats struct {
+	QuestionID string `json:"question_id" db:"question_id"`
+	NodeID     string `json:"node_id" db:"node_id"`
+	Name       string `json:"name" db:"name"`
+	Attempts   int    `json:"attempts" db:"attempts"`
+	// PercentCorrect is the percent of first answers that were right
+	PercentCorrect float64 `json:"percent_correct" db:"percent_correct"`
+	// Discrimination is the point-biserial correlation between answering the question right
+	// and learner's accuracy on the rest of the questions, low values mean the question doesn't tell strong learners from weak ones
+	Discrimination float64 `json:"discrimination" db:"discrimination"`
+	// MedianTimeSpent is the median time (in millis) to answer, 0 if the time wasn't recorded
+	MedianTimeSpent int64 `json:"median_time_spent" db:"median_time_spent"`
+	// Flagged is true if some wrong choice is picked more often than the right ones
+	Flagged    bool                   `json:"flagged" db:"flagged"`
+	Choices    []*QuestionChoiceStats `json:"choices" db:"-"`
+	ComputedAt int64                  `json:"computed_at" db:"computed_at"`
+}
+
+// QuestionStatsGetOptions for getting and filtering question stats
+type QuestionStatsGetOptions struct {
+	// NodeID returns stats of the questions of the node
+	NodeID string
+	// FlaggedOnly returns stats of the flagged questions only
+	FlaggedOnly bool
+	Page        int
+	PerPage     int
+}
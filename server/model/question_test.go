@@ -0,0 +1,46 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuestionIsValidMultipleChoice(t *testing.T) {
+	validQuestion := func() *Question {
+		return &Question{
+			Question:    "What does the robot do after move()?",
+			Explanation: "move() moves the robot one cell forward.",
+			Choices: []QuestionChoice{
+				{Choice: "Moves one cell forward", IsRightChoice: true},
+				{Choice: "Turns left"},
+				{Choice: "Picks a beeper"},
+			},
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		update func(q *Question)
+		valid  bool
+	}{
+		"valid question":         {update: func(q *Question) {}, valid: true},
+		"empty question":         {update: func(q *Question) { q.Question = "  " }},
+		"empty explanation":      {update: func(q *Question) { q.Explanation = "" }},
+		"single choice":          {update: func(q *Question) { q.Choices = q.Choices[:1] }},
+		"empty choice":           {update: func(q *Question) { q.Choices[1].Choice = " " }},
+		"duplicate choices":      {update: func(q *Question) { q.Choices[2].Choice = " turns LEFT" }},
+		"no right choice":        {update: func(q *Question) { q.Choices[0].IsRightChoice = false }},
+		"several right choices":  {update: func(q *Question) { q.Choices[1].IsRightChoice = true }},
+		"two choices are enough": {update: func(q *Question) { q.Choices = q.Choices[:2] }, valid: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			question := validQuestion()
+			tc.update(question)
+			if tc.valid {
+				require.NoError(t, question.IsValidMultipleChoice())
+			} else {
+				require.Error(t, question.IsValidMultipleChoice())
+			}
+		})
+	}
+}
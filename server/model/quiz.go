@@ -0,0 +1,103 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ReviewQuiz is a quiz session mixing questions of several finished nodes
+type ReviewQuiz struct {
+	ID         string `json:"id" db:"id"`
+	UserID     string `json:"user_id" db:"user_id"`
+	CreatedAt  int64  `json:"created_at" db:"created_at"`
+	FinishedAt int64  `json:"finished_at" db:"finished_at"`
+	// Score is the number of questions answered right
+	Score     int             `json:"score" db:"score"`
+	Questions []*QuizQuestion `json:"questions" db:"-"`
+}
+
+// QuizQuestion is a question of the quiz with the user's result on it
+type QuizQuestion struct {
+	QuizID     string    `json:"quiz_id" db:"quiz_id"`
+	QuestionID string    `json:"question_id" db:"question_id"`
+	NodeID     string    `json:"node_id" db:"node_id"`
+	Position   int       `json:"position" db:"position"`
+	ChoiceID   string    `json:"choice_id,omitempty" db:"choice_id"`
+	IsRight    bool      `json:"is_right" db:"is_right"`
+	AnsweredAt int64     `json:"answered_at" db:"answered_at"`
+	Question   *Question `json:"question,omitempty" db:"-"`
+}
+
+// QuizAnswer is user's answer on the quiz question
+type QuizAnswer struct {
+	QuestionID string `json:"question_id"`
+	ChoiceID   string `json:"choice_id"`
+}
+
+// IsValid validates the quiz and returns an error if it isn't configured correctly.
+func (q *ReviewQuiz) IsValid() error {
+	if !IsValidID(q.ID) {
+		return invalidQuizError("", "id", q.ID)
+	}
+
+	if !IsValidID(q.UserID) {
+		return invalidQuizError(q.ID, "userID", q.UserID)
+	}
+
+	if q.CreatedAt == 0 {
+		return invalidQuizError(q.ID, "createdAt", q.CreatedAt)
+	}
+
+	if len(q.Questions) == 0 {
+		return invalidQuizError(q.ID, "questions", len(q.Questions))
+	}
+
+	return nil
+}
+
+// BeforeSave should be called before storing the quiz
+func (q *ReviewQuiz) BeforeSave() {
+	if q.ID == "" {
+		q.ID = NewID()
+	}
+	q.CreatedAt = GetMillis()
+	for i, question := range q.Questions {
+		question.QuizID = q.ID
+		question.Position = i
+	}
+}
+
+// IsFinished returns true if all the questions of the quiz are answered
+func (q *ReviewQuiz) IsFinished() bool {
+	for _, question := range q.Questions {
+		if question.AnsweredAt == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// GetQuestion returns the quiz question, nil if the question isn't in the quiz
+func (q *ReviewQuiz) GetQuestion(questionID string) *QuizQuestion {
+	for _, question := range q.Questions {
+		if question.QuestionID == questionID {
+			return question
+		}
+	}
+	return nil
+}
+
+// QuizAnswerFromJSON will decode the input and return a QuizAnswer
+func QuizAnswerFromJSON(data io.Reader) (*QuizAnswer, error) {
+	var answer *QuizAnswer
+	if err := json.NewDecoder(data).Decode(&answer); err != nil {
+		return nil, errors.Wrap(err, "can't decode quiz answer")
+	}
+	return answer, nil
+}
+
+func invalidQuizError(quizID, fieldName string, fieldValue any) error {
+	return errors.Errorf("invalid quiz error. quizID=%s %s=%v", quizID, fieldName, fieldValue)
+}
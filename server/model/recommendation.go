@@ -0,0 +1,44 @@
+package model
+
+const (
+	ResourceTypeVideo    = "video"
+	ResourceTypeText     = "text"
+	ResourceTypeQuestion = "question"
+
+	// PreferredResourceTypeKey is the key of user's preference for a resource type
+	PreferredResourceTypeKey = "preferred_resource_type"
+)
+
+// Recommendation is a node recommended to the learner to work on next
+type Recommendation struct {
+	Node   Node    `json:"node"`
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// NodeResourceCounts contains number of resources of each type the node has
+type NodeResourceCounts struct {
+	NodeID    string `json:"node_id" db:"node_id"`
+	Videos    int    `json:"videos" db:"videos"`
+	Texts     int    `json:"texts" db:"texts"`
+	Questions int    `json:"questions" db:"questions"`
+}
+
+// Has returns true if node has resources of the type
+func (c *NodeResourceCounts) Has(resourceType string) bool {
+	switch resourceType {
+	case ResourceTypeVideo:
+		return c.Videos > 0
+	case ResourceTypeText:
+		return c.Texts > 0
+	case ResourceTypeQuestion:
+		return c.Questions > 0
+	}
+	return false
+}
+
+// NodeFailures is number of incorrect answers user gave on the node's questions
+type NodeFailures struct {
+	NodeID   string `json:"node_id" db:"node_id"`
+	Failures int    `json:"failures" db:"failures"`
+}
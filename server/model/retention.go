@@ -0,0 +1,57 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// RetentionRefreshThreshold is the estimated retention below which finished node needs a refresh
+	RetentionRefreshThreshold = 0.5
+
+	// baseStabilityDays is the number of days after which retention of a node finished once drops to 1/e
+	baseStabilityDays = 7.0
+)
+
+// NodeReviewData contains everything we know about user's reviews of a finished node
+type NodeReviewData struct {
+	NodeID         string `json:"node_id" db:"node_id"`
+	LastFinishedAt int64  `json:"last_finished_at" db:"last_finished_at"`
+	TimesFinished  int    `json:"times_finished" db:"times_finished"`
+	Answers        int    `json:"answers" db:"answers"`
+	CorrectAnswers int    `json:"correct_answers" db:"correct_answers"`
+	LastCorrectAt  int64  `json:"last_correct_at" db:"last_correct_at"`
+}
+
+// NodeRetention is the estimated retention of a finished node
+type NodeRetention struct {
+	NodeID    string  `json:"node_id"`
+	Retention float64 `json:"retention"`
+}
+
+// Retention estimates how well user remembers the node at the time now (in millis) using the forgetting curve
+// R = exp(-t/S), where t is time since the last review and S is stability of the memory.
+// Every additional review (finishing the node again or answering its question correctly) makes the memory more stable,
+// while incorrect answers make it less stable.
+func (d *NodeReviewData) Retention(now int64) float64 {
+	lastReviewedAt := d.LastFinishedAt
+	if d.LastCorrectAt > lastReviewedAt {
+		lastReviewedAt = d.LastCorrectAt
+	}
+	if lastReviewedAt == 0 || lastReviewedAt >= now {
+		return 1
+	}
+
+	reviews := d.CorrectAnswers
+	if d.TimesFinished > 1 {
+		reviews += d.TimesFinished - 1
+	}
+	accuracy := 1.0
+	if d.Answers > 0 {
+		accuracy = float64(d.CorrectAnswers) / float64(d.Answers)
+	}
+	stability := baseStabilityDays * (1 + float64(reviews)) * (0.5 + accuracy/2)
+
+	elapsedDays := float64(now-lastReviewedAt) / float64(24*time.Hour.Milliseconds())
+	return math.Exp(-elapsedDays / stability)
+}
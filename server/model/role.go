@@ -8,6 +8,7 @@ const (
 	AdminRole    = "admin"
 	UserRole     = "user"
 	CustomerRole = "customer"
+	TeacherRole  = "teacher"
 )
 
 func (r RoleType) CanManageUsers() bool {
@@ -18,8 +19,13 @@ func (r RoleType) CanManageNodes() bool {
 	return r == AdminRole
 }
 
+// CanManageClassrooms reports whether the role can create classrooms, teachers manage only their own classrooms
+func (r RoleType) CanManageClassrooms() bool {
+	return r == AdminRole || r == TeacherRole
+}
+
 func FromStringToRole(role string) (RoleType, error) {
-	if role != AdminRole && role != UserRole {
+	if role != AdminRole && role != UserRole && role != TeacherRole {
 		return "", errors.New("unknown Role")
 	}
 	return RoleType(role), nil
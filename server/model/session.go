@@ -27,7 +27,7 @@ func (s *Session) IsValid() error {
 		return invalidSessionError(s.ID, "CreateAt", s.CreateAt)
 	}
 
-	if s.Role != AdminRole && s.Role != UserRole && s.Role != CustomerRole {
+	if s.Role != AdminRole && s.Role != UserRole && s.Role != CustomerRole && s.Role != TeacherRole {
 		return invalidSessionError(s.ID, "Role", s.Role)
 	}
 
@@ -67,6 +67,10 @@ func (s *Session) CanManageUsers() bool {
 	return s.Role.CanManageUsers()
 }
 
+func (s *Session) CanManageClassrooms() bool {
+	return s.Role.CanManageClassrooms()
+}
+
 func invalidSessionError(sessionID, fieldName string, fieldValue any) error {
 	return errors.Errorf("invalid session error. sessionID=%s %s=%v", sessionID, fieldName, fieldValue)
 }
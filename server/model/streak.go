@@ -0,0 +1,122 @@
+package model
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TimezonePreferenceKey is the preference holding user's IANA timezone, e.g. Asia/Tbilisi
+	TimezonePreferenceKey = "timezone"
+	// StreakDateFormat is the format of the streak days in user's timezone
+	StreakDateFormat = "2006-01-02"
+	// StreakDaysPerFreeze is the number of consecutive days user needs to earn a streak freeze
+	StreakDaysPerFreeze = 7
+	// MaxStreakFreezes is the maximum number of freezes user can hold
+	MaxStreakFreezes = 2
+)
+
+// Streak is user's learning streak.
+// Streak is updated incrementally whenever user finishes a node,
+// days are counted in user's timezone.
+type Streak struct {
+	UserID        string `json:"user_id" db:"user_id"`
+	CurrentStreak int    `json:"current_streak" db:"current_streak"`
+	MaxStreak     int    `json:"max_streak" db:"max_streak"`
+	// LastActiveDate is the last day user finished a node, in StreakDateFormat
+	LastActiveDate string `json:"last_active_date" db:"last_active_date"`
+	// Freezes is the number of available freezes, each freeze covers a single missed day.
+	// User spends the freezes when back after the missed days, before the streak is extended.
+	Freezes     int `json:"freezes" db:"freezes"`
+	FreezesUsed int `json:"freezes_used" db:"freezes_used"`
+	// FrozenUntil is the last missed day covered by the spent freezes, in StreakDateFormat
+	FrozenUntil string `json:"frozen_until" db:"frozen_until"`
+	UpdatedAt   int64  `json:"updated_at" db:"updated_at"`
+}
+
+// StreakDate returns the day of the time in user's timezone, in StreakDateFormat
+func StreakDate(t time.Time, location *time.Location) string {
+	return t.In(location).Format(StreakDateFormat)
+}
+
+// RecordActivity updates the streak with user's activity on the day.
+// Streak starts over if there are missed days not covered by the spent freezes, the available freezes are kept.
+func (s *Streak) RecordActivity(today string) {
+	// dates in StreakDateFormat are ordered lexicographically,
+	// earlier days may come e.g. after user moves to another timezone
+	if today <= s.LastActiveDate {
+		return
+	}
+	if s.LastActiveDate == "" || s.MissedDays(today) != 0 {
+		s.CurrentStreak = 1
+	} else {
+		s.CurrentStreak++
+	}
+	if s.CurrentStreak%StreakDaysPerFreeze == 0 && s.Freezes < MaxStreakFreezes {
+		s.Freezes++
+	}
+	if s.CurrentStreak > s.MaxStreak {
+		s.MaxStreak = s.CurrentStreak
+	}
+	s.LastActiveDate = today
+	s.UpdatedAt = GetMillis()
+}
+
+// SpendFreezes covers the days missed since the last active day with the freezes, so user's next activity extends the streak
+func (s *Streak) SpendFreezes(today string) error {
+	missed := s.MissedDays(today)
+	if s.LastActiveDate == "" || missed <= 0 {
+		return errors.New("no missed days to cover")
+	}
+	if missed > s.Freezes {
+		return errors.Errorf("%d freezes are needed to cover the missed days, %d available", missed, s.Freezes)
+	}
+	now, err := time.Parse(StreakDateFormat, today)
+	if err != nil {
+		return errors.Wrapf(err, "invalid date %s", today)
+	}
+	s.Freezes -= missed
+	s.FreezesUsed += missed
+	s.FrozenUntil = now.AddDate(0, 0, -1).Format(StreakDateFormat)
+	s.UpdatedAt = GetMillis()
+	return nil
+}
+
+// Current returns the streak as of today, streak is still alive if missed days can be covered by spending the freezes
+func (s *Streak) Current(today string) int {
+	if s.LastActiveDate == "" {
+		return 0
+	}
+	missed := s.MissedDays(today)
+	if missed < 0 || missed <= s.Freezes {
+		return s.CurrentStreak
+	}
+	return 0
+}
+
+// ActiveOn returns true if user was active on the day
+func (s *Streak) ActiveOn(day string) bool {
+	return s.LastActiveDate == day
+}
+
+// MissedDays returns number of days without activity between the last active or frozen day and today, -1 if dates are invalid
+func (s *Streak) MissedDays(today string) int {
+	lastDate := s.LastActiveDate
+	if s.FrozenUntil > lastDate {
+		lastDate = s.FrozenUntil
+	}
+	last, err := time.Parse(StreakDateFormat, lastDate)
+	if err != nil {
+		return -1
+	}
+	now, err := time.Parse(StreakDateFormat, today)
+	if err != nil {
+		return -1
+	}
+	days := int(now.Sub(last).Hours() / 24)
+	if days <= 0 {
+		return 0
+	}
+	return days - 1
+}
@@ -0,0 +1,177 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// streakDays returns the consecutive days starting from the first one
+func streakDays(first string, n int) []string {
+	start, _ := time.Parse(StreakDateFormat, first)
+	days := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		days = append(days, start.AddDate(0, 0, i).Format(StreakDateFormat))
+	}
+	return days
+}
+
+func TestStreakRecordActivity(t *testing.T) {
+	for name, tc := range map[string]struct {
+		streak   Streak
+		days     []string
+		expected Streak
+	}{
+		"first activity": {
+			days:     []string{"2024-03-01"},
+			expected: Streak{CurrentStreak: 1, MaxStreak: 1, LastActiveDate: "2024-03-01"},
+		},
+		"consecutive days": {
+			days:     []string{"2024-02-28", "2024-02-29", "2024-03-01"},
+			expected: Streak{CurrentStreak: 3, MaxStreak: 3, LastActiveDate: "2024-03-01"},
+		},
+		"same day is counted once": {
+			days:     []string{"2024-03-01", "2024-03-01"},
+			expected: Streak{CurrentStreak: 1, MaxStreak: 1, LastActiveDate: "2024-03-01"},
+		},
+		"earlier day is ignored": {
+			days:     []string{"2024-03-02", "2024-03-01"},
+			expected: Streak{CurrentStreak: 1, MaxStreak: 1, LastActiveDate: "2024-03-02"},
+		},
+		"missed day starts over and keeps the freezes": {
+			streak:   Streak{CurrentStreak: 5, MaxStreak: 5, LastActiveDate: "2024-03-01", Freezes: 1},
+			days:     []string{"2024-03-03"},
+			expected: Streak{CurrentStreak: 1, MaxStreak: 5, LastActiveDate: "2024-03-03", Freezes: 1},
+		},
+		"missed days covered by the spent freezes": {
+			streak:   Streak{CurrentStreak: 5, MaxStreak: 5, LastActiveDate: "2024-03-01", FreezesUsed: 2, FrozenUntil: "2024-03-03"},
+			days:     []string{"2024-03-04"},
+			expected: Streak{CurrentStreak: 6, MaxStreak: 6, LastActiveDate: "2024-03-04", FreezesUsed: 2, FrozenUntil: "2024-03-03"},
+		},
+		"freeze is earned every week": {
+			days:     streakDays("2024-03-01", 2*StreakDaysPerFreeze),
+			expected: Streak{CurrentStreak: 14, MaxStreak: 14, LastActiveDate: "2024-03-14", Freezes: 2},
+		},
+		"freezes are capped": {
+			days:     streakDays("2024-03-01", 4*StreakDaysPerFreeze),
+			expected: Streak{CurrentStreak: 28, MaxStreak: 28, LastActiveDate: "2024-03-28", Freezes: MaxStreakFreezes},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			streak := tc.streak
+			for _, day := range tc.days {
+				streak.RecordActivity(day)
+			}
+			streak.UpdatedAt = 0
+			require.Equal(t, tc.expected, streak)
+		})
+	}
+}
+
+func TestStreakSpendFreezes(t *testing.T) {
+	for name, tc := range map[string]struct {
+		streak   Streak
+		today    string
+		isError  bool
+		expected Streak
+	}{
+		"missed days are covered": {
+			streak:   Streak{CurrentStreak: 5, LastActiveDate: "2024-02-27", Freezes: 2},
+			today:    "2024-03-01",
+			expected: Streak{CurrentStreak: 5, LastActiveDate: "2024-02-27", FreezesUsed: 2, FrozenUntil: "2024-02-29"},
+		},
+		"not enough freezes": {
+			streak:  Streak{CurrentStreak: 5, LastActiveDate: "2024-02-26", Freezes: 2},
+			today:   "2024-03-01",
+			isError: true,
+		},
+		"no missed days": {
+			streak:  Streak{CurrentStreak: 5, LastActiveDate: "2024-02-29", Freezes: 2},
+			today:   "2024-03-01",
+			isError: true,
+		},
+		"missed days are already covered": {
+			streak:  Streak{CurrentStreak: 5, LastActiveDate: "2024-02-27", Freezes: 1, FrozenUntil: "2024-02-29"},
+			today:   "2024-03-01",
+			isError: true,
+		},
+		"no streak": {
+			streak:  Streak{Freezes: 2},
+			today:   "2024-03-01",
+			isError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			streak := tc.streak
+			err := streak.SpendFreezes(tc.today)
+			if tc.isError {
+				require.Error(t, err)
+				require.Equal(t, tc.streak, streak)
+				return
+			}
+			require.NoError(t, err)
+			streak.UpdatedAt = 0
+			require.Equal(t, tc.expected, streak)
+
+			streak.RecordActivity(tc.today)
+			require.Equal(t, tc.streak.CurrentStreak+1, streak.CurrentStreak)
+		})
+	}
+}
+
+func TestStreakCurrent(t *testing.T) {
+	for name, tc := range map[string]struct {
+		streak   Streak
+		today    string
+		expected int
+	}{
+		"no activity":                      {streak: Streak{}, today: "2024-03-01", expected: 0},
+		"active today":                     {streak: Streak{CurrentStreak: 3, LastActiveDate: "2024-03-01"}, today: "2024-03-01", expected: 3},
+		"active yesterday":                 {streak: Streak{CurrentStreak: 3, LastActiveDate: "2024-02-29"}, today: "2024-03-01", expected: 3},
+		"missed day":                       {streak: Streak{CurrentStreak: 3, LastActiveDate: "2024-02-28"}, today: "2024-03-01", expected: 0},
+		"missed days can be covered":       {streak: Streak{CurrentStreak: 3, LastActiveDate: "2024-02-27", Freezes: 2}, today: "2024-03-01", expected: 3},
+		"missed days can't be covered":     {streak: Streak{CurrentStreak: 3, LastActiveDate: "2024-02-26", Freezes: 2}, today: "2024-03-01", expected: 0},
+		"missed days are covered":          {streak: Streak{CurrentStreak: 3, LastActiveDate: "2024-02-26", FrozenUntil: "2024-02-29"}, today: "2024-03-01", expected: 3},
+		"day earlier than the last active": {streak: Streak{CurrentStreak: 3, LastActiveDate: "2024-03-02"}, today: "2024-03-01", expected: 3},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.streak.Current(tc.today))
+		})
+	}
+}
+
+func TestStreakDate(t *testing.T) {
+	tbilisi := time.FixedZone("Asia/Tbilisi", 4*60*60)
+	losAngeles := time.FixedZone("America/Los_Angeles", -8*60*60)
+
+	for name, tc := range map[string]struct {
+		time     string
+		location *time.Location
+		expected string
+	}{
+		"evening in Tbilisi":             {time: "2024-03-01T19:30:00Z", location: tbilisi, expected: "2024-03-01"},
+		"after midnight in Tbilisi":      {time: "2024-03-01T20:30:00Z", location: tbilisi, expected: "2024-03-02"},
+		"previous day in Los Angeles":    {time: "2024-03-01T07:59:00Z", location: losAngeles, expected: "2024-02-29"},
+		"same day in Los Angeles":        {time: "2024-03-01T08:00:00Z", location: losAngeles, expected: "2024-03-01"},
+		"midnight in UTC":                {time: "2024-03-01T00:00:00Z", location: time.UTC, expected: "2024-03-01"},
+		"end of the year in Tbilisi":     {time: "2024-12-31T20:00:00Z", location: tbilisi, expected: "2025-01-01"},
+		"end of the year in Los Angeles": {time: "2025-01-01T07:00:00Z", location: losAngeles, expected: "2024-12-31"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			now, err := time.Parse(time.RFC3339, tc.time)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, StreakDate(now, tc.location))
+		})
+	}
+
+	t.Run("late activity in Tbilisi extends the streak", func(t *testing.T) {
+		streak := Streak{}
+		for day := 1; day <= 3; day++ {
+			// 23:30 in Tbilisi is 19:30 in UTC
+			now := time.Date(2024, time.March, day, 19, 30, 0, 0, time.UTC)
+			streak.RecordActivity(StreakDate(now, tbilisi))
+		}
+		require.Equal(t, 3, streak.CurrentStreak)
+	})
+}
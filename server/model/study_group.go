@@ -0,0 +1,114 @@
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// StudyGroupLocationPrefix prefixes location ids of the study group feeds
+	StudyGroupLocationPrefix = "group_"
+
+	studyGroupNameMaxRunes        = 128
+	studyGroupDescriptionMaxRunes = 1024
+)
+
+// StudyGroup is a group of users learning towards the shared goal
+type StudyGroup struct {
+	ID          string `json:"id" db:"id"`
+	CreatorID   string `json:"creator_id" db:"creator_id"`
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description" db:"description"`
+	GoalNodeID  string `json:"goal_node_id" db:"goal_node_id"`
+	GoalName    string `json:"goal_name,omitempty" db:"goal_name"`
+	MemberCount int    `json:"member_count" db:"member_count"`
+	CreatedAt   int64  `json:"created_at" db:"created_at"`
+	DeletedAt   int64  `json:"deleted_at" db:"deleted_at"`
+}
+
+// StudyGroupMemberProgress is member's progress on the group's target subgraph
+type StudyGroupMemberProgress struct {
+	User     *User `json:"user"`
+	Finished int   `json:"finished"`
+	// Statuses maps nodes of the target subgraph to member's statuses, nodes member hasn't seen are omitted
+	Statuses map[string]string `json:"statuses"`
+	// StudyingNow is true if member has interacted with the app recently
+	StudyingNow bool `json:"studying_now"`
+}
+
+// StudyGroupProgress is group's progress towards the shared goal
+type StudyGroupProgress struct {
+	GoalNodeID string `json:"goal_node_id"`
+	// Nodes are the target subgraph, the goal and all its prerequisites
+	Nodes []string `json:"nodes"`
+	// Finished is the share of the target subgraph finished by the members on average
+	Finished float64                     `json:"finished"`
+	Members  []*StudyGroupMemberProgress `json:"members"`
+}
+
+// IsValid validates the study group and returns an error if it isn't configured correctly.
+func (g *StudyGroup) IsValid() error {
+	if !IsValidID(g.ID) {
+		return invalidStudyGroupError("", "id", g.ID)
+	}
+
+	if !IsValidID(g.CreatorID) {
+		return invalidStudyGroupError(g.ID, "creatorID", g.CreatorID)
+	}
+
+	if g.Name == "" || utf8.RuneCountInString(g.Name) > studyGroupNameMaxRunes {
+		return invalidStudyGroupError(g.ID, "name", g.Name)
+	}
+
+	if utf8.RuneCountInString(g.Description) > studyGroupDescriptionMaxRunes {
+		return invalidStudyGroupError(g.ID, "description length", len(g.Description))
+	}
+
+	if !IsValidID(g.GoalNodeID) {
+		return invalidStudyGroupError(g.ID, "goalNodeID", g.GoalNodeID)
+	}
+
+	if g.CreatedAt == 0 {
+		return invalidStudyGroupError(g.ID, "createdAt", g.CreatedAt)
+	}
+
+	return nil
+}
+
+// BeforeSave should be called before storing the study group
+func (g *StudyGroup) BeforeSave() {
+	if g.ID == "" {
+		g.ID = NewID()
+	}
+	g.Name = strings.TrimSpace(g.Name)
+	g.Description = SanitizeUnicode(g.Description)
+	g.CreatedAt = GetMillis()
+}
+
+// LocationID returns location id of the group's feed
+func (g *StudyGroup) LocationID() string {
+	return StudyGroupLocationPrefix + g.ID
+}
+
+// IsStudyGroupLocationID returns true if the location is a feed of a study group
+func IsStudyGroupLocationID(locationID string) bool {
+	return strings.HasPrefix(locationID, StudyGroupLocationPrefix) &&
+		IsValidID(strings.TrimPrefix(locationID, StudyGroupLocationPrefix))
+}
+
+// StudyGroupFromJSON will decode the input and return a StudyGroup
+func StudyGroupFromJSON(data io.Reader) (*StudyGroup, error) {
+	var group *StudyGroup
+	if err := json.NewDecoder(data).Decode(&group); err != nil {
+		return nil, errors.Wrap(err, "can't decode study group")
+	}
+	return group, nil
+}
+
+func invalidStudyGroupError(groupID, fieldName string, fieldValue any) error {
+	return errors.Errorf("invalid study group error. groupID=%s %s=%v", groupID, fieldName, fieldValue)
+}
@@ -75,6 +75,8 @@ type TextGetOptions struct {
 	PerPage int
 	// with author username
 	WithAuthorUsername bool
+	// Name returns texts with the name
+	Name string
 }
 
 type TextGetOption func(*TextGetOptions)
@@ -1,64 +1,104 @@
 package model
 
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// plan tiers of the tutor personalities, the same tiers as the language models'
+const (
+	TutorPersonalityTierFree    = "free"
+	TutorPersonalityTierPremium = "premium"
+)
+
+var tutorPersonalityIDRegexp = regexp.MustCompile(`^[a-zA-Z0-9-]{1,64}$`)
+
 // TutorPersonality type defines the tutor persona
 type TutorPersonality struct {
-	ID     string `json:"id" db:"id"`
-	Name   string `json:"name" db:"name"`
-	Prompt string `json:"prompt" db:"prompt"`
+	ID   string `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
+	// Avatar is an emoji or an image URL
+	Avatar string `json:"avatar" db:"avatar"`
+	// Prompts maps languages to the prompts, the English prompt is used for the languages without one
+	Prompts map[string]string `json:"prompts" db:"-"`
+	// Tier is the plan tier the personality is available in, premium personalities are available only to subscribers
+	Tier      string `json:"tier" db:"tier"`
+	Enabled   bool   `json:"enabled" db:"enabled"`
+	CreatedAt int64  `json:"created_at" db:"created_at"`
+	UpdatedAt int64  `json:"updated_at" db:"updated_at"`
 }
 
-var (
-	StandardTutorPersonality = TutorPersonality{
-		ID:     "standard-tutor-personality",
-		Name:   "Standard Tutor",
-		Prompt: "Act as a best tutor in the world and answer the question concisely.",
+// IsValid validates the tutor personality and returns an error if it isn't configured correctly.
+func (tp *TutorPersonality) IsValid() error {
+	if !tutorPersonalityIDRegexp.MatchString(tp.ID) {
+		return invalidTutorPersonalityError(tp.ID, "id", tp.ID)
 	}
-	MarvinTutorPersonality = TutorPersonality{
-		ID:     "marvin-tutor-personality",
-		Name:   "Marvin the Paranoid Android",
-		Prompt: "You are Marvin the Paranoid Android from the Hitchhiker's Guide to the Galaxy. Act as a best tutor in the world and answer the question concisely.",
+	if tp.Name == "" {
+		return invalidTutorPersonalityError(tp.ID, "name", tp.Name)
 	}
-	NeilDeGrasseTutorPersonality = TutorPersonality{
-		ID:     "neal-deGrasse-tutor-personality",
-		Name:   "Neil deGrasse Tyson",
-		Prompt: "You are Neil deGrasse Tyson. Act as a best tutor in the world and answer the question concisely.",
+	if tp.Prompts[LanguageEnglish] == "" {
+		return invalidTutorPersonalityError(tp.ID, "prompts", tp.Prompts)
 	}
-	SteveJobsTutorPersonality = TutorPersonality{
-		ID:     "steve-jobs-tutor-personality",
-		Name:   "Steve Jobs",
-		Prompt: "You are Steve Jobs. Act as a best tutor in the world and answer the question concisely.",
+	for language := range tp.Prompts {
+		if language != LanguageEnglish && language != LanguageGeorgian {
+			return invalidTutorPersonalityError(tp.ID, "prompts", language)
+		}
 	}
-	AlexTutorPersonality = TutorPersonality{
-		ID:     "alex-tutor-personality",
-		Name:   "Alex DeLarge",
-		Prompt: "You are Alex DeLarge from A Clockwork Orange. Act as a best tutor in the world and answer the question concisely.",
+	if tp.Tier != TutorPersonalityTierFree && tp.Tier != TutorPersonalityTierPremium {
+		return invalidTutorPersonalityError(tp.ID, "tier", tp.Tier)
 	}
-	YodaTutorPersonality = TutorPersonality{
-		ID:     "yoda-tutor-personality",
-		Name:   "Yoda",
-		Prompt: "You are Yoda from the Start Wars. Act as a best tutor in the world and answer the question concisely.",
+	return nil
+}
+
+// BeforeSave should be called before storing the tutor personality, the id is generated if empty
+func (tp *TutorPersonality) BeforeSave() {
+	if tp.ID == "" {
+		tp.ID = NewID()
 	}
-	SherlockHolmesTutorPersonality = TutorPersonality{
-		ID:     "sherlock-tutor-personality",
-		Name:   "Sherlock Holmes",
-		Prompt: "You are Sherlock Holmes. Act as a best tutor in the world and answer the question concisely.",
+	if tp.Tier == "" {
+		tp.Tier = TutorPersonalityTierFree
 	}
-	SevroAuBarcaTutorPersonality = TutorPersonality{
-		ID:     "sevro-tutor-personality",
-		Name:   "Sevro au Barca",
-		Prompt: "You are Sevro au Barca from the Red Rising. Act as a best tutor in the world and answer the question concisely.",
+	tp.CreatedAt = GetMillis()
+	tp.UpdatedAt = tp.CreatedAt
+}
+
+// GetPrompt returns the prompt in the language, the English prompt if there is none
+func (tp *TutorPersonality) GetPrompt(language string) string {
+	if prompt, ok := tp.Prompts[language]; ok && prompt != "" {
+		return prompt
 	}
-	GollumTutorPersonality = TutorPersonality{
-		ID:     "gollum-tutor-personality",
-		Name:   "Gollum",
-		Prompt: "You are Gollum from the Lord of the Rings. Act as a best tutor in the world and answer the question concisely.",
+	return tp.Prompts[LanguageEnglish]
+}
+
+// IsAvailableTo reports whether the learner with the plan can choose the personality
+func (tp *TutorPersonality) IsAvailableTo(plan RoleType) bool {
+	return tp.Enabled && (tp.Tier == TutorPersonalityTierFree || plan != UserRole)
+}
+
+// TutorPersonalityFromJSON will decode the input and return a TutorPersonality
+func TutorPersonalityFromJSON(data io.Reader) (*TutorPersonality, error) {
+	var personality *TutorPersonality
+	if err := json.NewDecoder(data).Decode(&personality); err != nil {
+		return nil, errors.Wrap(err, "can't decode tutor personality")
 	}
-)
+	return personality, nil
+}
+
+func invalidTutorPersonalityError(id, fieldName string, fieldValue any) error {
+	return errors.Errorf("invalid tutor personality error. id=%s %s=%v", id, fieldName, fieldValue)
+}
 
-var DefaultTutorPersonalities = []TutorPersonality{
-	StandardTutorPersonality,
-	MarvinTutorPersonality,
-	AlexTutorPersonality,
-	YodaTutorPersonality,
-	GollumTutorPersonality,
+// StandardTutorPersonality is used if the learner's personality isn't available
+var StandardTutorPersonality = TutorPersonality{
+	ID:     "standard-tutor-personality",
+	Name:   "Standard Tutor",
+	Avatar: "👩‍🏫",
+	Prompts: map[string]string{
+		LanguageEnglish: "Act as a best tutor in the world and answer the question concisely.",
+	},
+	Tier:    TutorPersonalityTierFree,
+	Enabled: true,
 }
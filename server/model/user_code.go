@@ -9,10 +9,11 @@ import (
 
 // UserNodeCode type defines users code on the specific node
 type UserNodeCode struct {
-	UserID   string `json:"user_id" db:"user_id"`
-	NodeID   string `json:"node_id" db:"node_id"`
-	CodeName string `json:"code_name" db:"code_name"`
-	Code     string `json:"code" db:"code"`
+	UserID    string `json:"user_id" db:"user_id"`
+	NodeID    string `json:"node_id" db:"node_id"`
+	CodeName  string `json:"code_name" db:"code_name"`
+	Code      string `json:"code" db:"code"`
+	UpdatedAt int64  `json:"updated_at" db:"updated_at"`
 }
 
 // IsValid validates the UserNodeCode and returns an error if it isn't configured correctly.
@@ -32,6 +32,13 @@ type Video struct {
 	AuthorUsername string `json:"author_username" db:"author_username"`
 }
 
+// UserVideoKey is the key of the node's video and whether the user has watched it till the end
+type UserVideoKey struct {
+	NodeID   string `json:"node_id" db:"node_id"`
+	Key      string `json:"key" db:"key"`
+	Finished bool   `json:"finished" db:"finished"`
+}
+
 // IsValid validates the video and returns an error if it isn't configured correctly.
 func (v *Video) IsValid() error {
 	if !IsValidID(v.ID) {
@@ -145,3 +152,34 @@ func UserEngagementDataFromJSON(data io.Reader) (*UserEngagementData, error) {
 	}
 	return userData, nil
 }
+
+// TranscriptSegment is a part of the video's transcript, e.g. a caption line
+type TranscriptSegment struct {
+	VideoID      string `json:"video_id" db:"video_id"`
+	StartSeconds int64  `json:"start_seconds" db:"start_seconds"`
+	EndSeconds   int64  `json:"end_seconds" db:"end_seconds"`
+	Text         string `json:"text" db:"text"`
+}
+
+// IsValid validates the transcript segment and returns an error if it isn't configured correctly.
+func (ts *TranscriptSegment) IsValid() error {
+	if !IsValidID(ts.VideoID) {
+		return invalidVideoError(ts.VideoID, "transcript video_id", ts.VideoID)
+	}
+	if ts.StartSeconds < 0 || ts.EndSeconds < ts.StartSeconds {
+		return invalidVideoError(ts.VideoID, "transcript start_seconds", ts.StartSeconds)
+	}
+	if ts.Text == "" {
+		return invalidVideoError(ts.VideoID, "transcript text", ts.Text)
+	}
+	return nil
+}
+
+// TranscriptFromJSON will decode the input and return transcript segments
+func TranscriptFromJSON(data io.Reader) ([]*TranscriptSegment, error) {
+	var segments []*TranscriptSegment
+	if err := json.NewDecoder(data).Decode(&segments); err != nil {
+		return nil, errors.Wrap(err, "can't decode transcript")
+	}
+	return segments, nil
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	anthropicURL     = "https://api.anthropic.com/v1"
+	anthropicVersion = "2023-06-01"
+)
+
+// AnthropicMessagesRequest is a request to the Anthropic Messages API
+type AnthropicMessagesRequest struct {
+	Model string `json:"model"`
+	// System is the system prompt, Messages API doesn't accept system messages in the conversation
+	System    string             `json:"system,omitempty"`
+	Messages  []AnthropicMessage `json:"messages"`
+	MaxTokens int                `json:"max_tokens"`
+	Stream    bool               `json:"stream,omitempty"`
+	Metadata  *AnthropicMetadata `json:"metadata,omitempty"`
+}
+
+type AnthropicMessage struct {
+	Role    ChatRole `json:"role"`
+	Content string   `json:"content"`
+}
+
+type AnthropicMetadata struct {
+	UserID string `json:"user_id,omitempty"`
+}
+
+type AnthropicContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type AnthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// AnthropicMessagesResponse is a response of the Anthropic Messages API
+type AnthropicMessagesResponse struct {
+	ID         string                  `json:"id"`
+	Content    []AnthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Usage      AnthropicUsage          `json:"usage"`
+}
+
+// AnthropicStreamEvent is a server-sent event of the streamed Messages API response
+type AnthropicStreamEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	// Message is sent in message_start event with the input tokens
+	Message struct {
+		Usage AnthropicUsage `json:"usage"`
+	} `json:"message"`
+	// Usage is sent in message_delta event with the output tokens
+	Usage AnthropicUsage `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// anthropicService adapts the Anthropic Messages API to the LLM service interface.
+// Anthropic doesn't serve embeddings, they are requested from the configured OpenAI compatible API.
+type anthropicService struct {
+	client        *http.Client
+	baseURL       string
+	apiKey        string
+	models        map[string]string
+	maxTokens     int
+	contextTokens map[string]int
+
+	embeddings *embeddingClient
+	usage      *usageRecorder
+}
+
+type anthropicStreamReader struct {
+	isFinished bool
+	usage      AnthropicUsage
+
+	reader   *bufio.Reader
+	response *http.Response
+}
+
+func (as *anthropicService) PromptTokens(tier LLMTier) int {
+	return promptTokensForTier(as.models, as.contextTokens, as.maxTokens, tier)
+}
+
+func (as *anthropicService) GetEmbedding(text, userID string) ([]float32, error) {
+	return as.embeddings.GetEmbedding(text, userID)
+}
+
+func (as *anthropicService) Send(userID, systemMessage string, tier LLMTier, messages []string) (string, error) {
+	req, err := as.newRequest(userID, tier, getChatMessages(systemMessage, messages))
+	if err != nil {
+		return "", err
+	}
+
+	res, err := as.send(context.Background(), req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var response AnthropicMessagesResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return "", errors.Wrap(err, "can't decode anthropic response")
+	}
+	as.usage.record(&model.LLMUsage{
+		UserID:           userID,
+		Model:            req.Model,
+		CallType:         model.LLMCallChat,
+		PromptTokens:     int64(response.Usage.InputTokens),
+		CompletionTokens: int64(response.Usage.OutputTokens),
+	})
+	// the answer cut off by max_tokens is kept as well, its tokens are already paid for
+	if response.StopReason != "end_turn" && response.StopReason != "stop_sequence" && response.StopReason != "max_tokens" {
+		return "", errors.Errorf("no response, stop reason %s", response.StopReason)
+	}
+	text := strings.Builder{}
+	for _, block := range response.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", errors.New("no response")
+	}
+	return text.String(), nil
+}
+
+func (as *anthropicService) SendStream(userID, systemMessage string, tier LLMTier, messages []string) (ChatStream, error) {
+	return as.SendStreamWithChatMessages(userID, tier, getChatMessages(systemMessage, messages))
+}
+
+func (as *anthropicService) SendStreamWithChatMessages(userID string, tier LLMTier, chatMessages []ChatMessage) (ChatStream, error) {
+	req, err := as.newRequest(userID, tier, chatMessages)
+	if err != nil {
+		return nil, err
+	}
+	req.Stream = true
+
+	res, err := as.send(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	return as.usage.meter(&anthropicStreamReader{
+		reader:   bufio.NewReader(res.Body),
+		response: res,
+	}, &model.LLMUsage{
+		UserID:   userID,
+		Model:    req.Model,
+		CallType: model.LLMCallStream,
+	}, chatMessages), nil
+}
+
+// newRequest converts chat messages to the Messages API format: system messages go to the system prompt,
+// empty messages are dropped as the API rejects them and consecutive messages of the same role are merged,
+// since roles must alternate starting with the user
+func (as *anthropicService) newRequest(userID string, tier LLMTier, chatMessages []ChatMessage) (*AnthropicMessagesRequest, error) {
+	modelName, err := modelForTier(as.models, tier)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &AnthropicMessagesRequest{
+		Model:     modelName,
+		MaxTokens: as.maxTokens,
+		Metadata:  &AnthropicMetadata{UserID: userID},
+	}
+	systemMessages := []string{}
+	for _, message := range chatMessages {
+		switch message.Role {
+		case ChatRoleSystem:
+			systemMessages = append(systemMessages, message.Content)
+		case ChatRoleUser, ChatRoleAssistant:
+			if strings.TrimSpace(message.Content) == "" {
+				continue
+			}
+			if len(req.Messages) == 0 && message.Role == ChatRoleAssistant {
+				continue
+			}
+			last := len(req.Messages) - 1
+			if last >= 0 && req.Messages[last].Role == message.Role {
+				req.Messages[last].Content += "\n\n" + message.Content
+				continue
+			}
+			req.Messages = append(req.Messages, AnthropicMessage{Role: message.Role, Content: message.Content})
+		default:
+			return nil, ErrInvalidRole
+		}
+	}
+	if len(req.Messages) == 0 {
+		return nil, ErrNoMessages
+	}
+	req.System = strings.Join(systemMessages, "\n\n")
+	return req, nil
+}
+
+func (as *anthropicService) send(ctx context.Context, req *AnthropicMessagesRequest) (*http.Response, error) {
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't marshal anthropic request")
+	}
+
+	httpReq, err := http.NewRequest("POST", as.baseURL+"/messages", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, err
+	}
+	httpReq = httpReq.WithContext(ctx)
+	httpReq.Header.Set("x-api-key", as.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+	httpReq.Header.Set("Content-Type", "application/json")
+	if req.Stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	} else {
+		httpReq.Header.Set("Accept", "application/json")
+	}
+
+	return sendRequest(as.client, httpReq)
+}
+
+// Recv returns the next text delta of the stream, other events are skipped
+func (stream *anthropicStreamReader) Recv() (string, error) {
+	if stream.isFinished {
+		return "", io.EOF
+	}
+
+	for {
+		rawLine, err := stream.reader.ReadBytes('\n')
+		if err != nil {
+			return "", err
+		}
+
+		line := bytes.TrimSpace(rawLine)
+		if !bytes.HasPrefix(line, headerData) {
+			// event names and keep-alive lines, the type is repeated in the data
+			continue
+		}
+
+		var event AnthropicStreamEvent
+		if err := json.Unmarshal(bytes.TrimPrefix(line, headerData), &event); err != nil {
+			return "", errors.Wrap(err, "can't decode anthropic stream event")
+		}
+		switch event.Type {
+		case "message_start":
+			stream.usage.InputTokens = event.Message.Usage.InputTokens
+		case "message_delta":
+			stream.usage.OutputTokens = event.Usage.OutputTokens
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" && event.Delta.Text != "" {
+				return event.Delta.Text, nil
+			}
+		case "message_stop":
+			stream.isFinished = true
+			return "", io.EOF
+		case "error":
+			return "", errors.Errorf("anthropic stream error: %s %s", event.Error.Type, event.Error.Message)
+		}
+	}
+}
+
+func (stream *anthropicStreamReader) Close() {
+	stream.response.Body.Close()
+}
+
+func (stream *anthropicStreamReader) tokenUsage() (int64, int64, bool) {
+	if stream.usage.InputTokens == 0 && stream.usage.OutputTokens == 0 {
+		return 0, 0, false
+	}
+	return int64(stream.usage.InputTokens), int64(stream.usage.OutputTokens), true
+}
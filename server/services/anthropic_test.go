@@ -0,0 +1,124 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnthropicNewRequest(t *testing.T) {
+	as := &anthropicService{
+		models:    map[string]string{string(LLMTierFree): "test-model"},
+		maxTokens: 100,
+	}
+
+	for name, tc := range map[string]struct {
+		messages []ChatMessage
+		system   string
+		expected []AnthropicMessage
+		err      error
+	}{
+		"system messages go to the system prompt": {
+			messages: []ChatMessage{
+				{Role: ChatRoleSystem, Content: "first"},
+				{Role: ChatRoleUser, Content: "question"},
+				{Role: ChatRoleSystem, Content: "second"},
+			},
+			system:   "first\n\nsecond",
+			expected: []AnthropicMessage{{Role: ChatRoleUser, Content: "question"}},
+		},
+		"leading assistant messages are dropped": {
+			messages: []ChatMessage{
+				{Role: ChatRoleAssistant, Content: "greeting"},
+				{Role: ChatRoleUser, Content: "question"},
+				{Role: ChatRoleAssistant, Content: "answer"},
+			},
+			expected: []AnthropicMessage{
+				{Role: ChatRoleUser, Content: "question"},
+				{Role: ChatRoleAssistant, Content: "answer"},
+			},
+		},
+		"messages of the same role are merged": {
+			messages: []ChatMessage{
+				{Role: ChatRoleUser, Content: "one"},
+				{Role: ChatRoleUser, Content: "two"},
+			},
+			expected: []AnthropicMessage{{Role: ChatRoleUser, Content: "one\n\ntwo"}},
+		},
+		"empty messages are dropped": {
+			messages: []ChatMessage{
+				{Role: ChatRoleUser, Content: "question"},
+				{Role: ChatRoleAssistant, Content: " "},
+				{Role: ChatRoleUser, Content: "follow-up"},
+			},
+			expected: []AnthropicMessage{{Role: ChatRoleUser, Content: "question\n\nfollow-up"}},
+		},
+		"only empty user message": {
+			messages: []ChatMessage{
+				{Role: ChatRoleSystem, Content: "system"},
+				{Role: ChatRoleUser, Content: ""},
+			},
+			err: ErrNoMessages,
+		},
+		"invalid role": {
+			messages: []ChatMessage{{Role: "tool", Content: "result"}},
+			err:      ErrInvalidRole,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req, err := as.newRequest("user", LLMTierFree, tc.messages)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "test-model", req.Model)
+			require.Equal(t, tc.system, req.System)
+			require.Equal(t, tc.expected, req.Messages)
+		})
+	}
+}
+
+func TestAnthropicSend(t *testing.T) {
+	for name, tc := range map[string]struct {
+		stopReason string
+		content    []AnthropicContentBlock
+		expected   string
+		isError    bool
+	}{
+		"finished answer":         {stopReason: "end_turn", content: []AnthropicContentBlock{{Type: "text", Text: "one "}, {Type: "text", Text: "two"}}, expected: "one two"},
+		"answer cut by the limit": {stopReason: "max_tokens", content: []AnthropicContentBlock{{Type: "text", Text: "cut"}}, expected: "cut"},
+		"empty answer":            {stopReason: "end_turn", isError: true},
+		"refused answer":          {stopReason: "refusal", content: []AnthropicContentBlock{{Type: "text", Text: "no"}}, isError: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req AnthropicMessagesRequest
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				require.Equal(t, []AnthropicMessage{{Role: ChatRoleUser, Content: "question"}}, req.Messages)
+				require.NoError(t, json.NewEncoder(w).Encode(&AnthropicMessagesResponse{
+					Content:    tc.content,
+					StopReason: tc.stopReason,
+				}))
+			}))
+			defer server.Close()
+
+			as := &anthropicService{
+				client:    server.Client(),
+				baseURL:   server.URL,
+				models:    map[string]string{string(LLMTierFree): "test-model"},
+				maxTokens: 100,
+			}
+			answer, err := as.Send("user", "system", LLMTierFree, []string{"question"})
+			if tc.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, answer)
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	certificatePageWidth  = 842.0
+	certificatePageHeight = 595.0
+	certificateMaxTopics  = 8
+)
+
+// DejaVu Sans fonts cover Latin, Cyrillic and Georgian scripts, see fonts/LICENSE
+var (
+	//go:embed fonts/DejaVuSans.ttf
+	dejaVuSans []byte
+	//go:embed fonts/DejaVuSans-Bold.ttf
+	dejaVuSansBold []byte
+)
+
+type certificateService struct {
+	regular *trueTypeFont
+	bold    *trueTypeFont
+}
+
+// CertificateServiceInterface renders certificates
+type CertificateServiceInterface interface {
+	RenderPDF(certificate *model.Certificate, verifyURL string) ([]byte, error)
+}
+
+func NewCertificateService() (CertificateServiceInterface, error) {
+	regular, err := parseTrueType(dejaVuSans)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't parse regular certificate font")
+	}
+	bold, err := parseTrueType(dejaVuSansBold)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't parse bold certificate font")
+	}
+	return &certificateService{
+		regular: regular,
+		bold:    bold,
+	}, nil
+}
+
+// pdfFont is a font of the page, it collects the glyphs the text uses to embed only them
+type pdfFont struct {
+	resourceName string
+	// baseFont is the font name prefixed with the subset tag
+	baseFont string
+	font     *trueTypeFont
+	runes    map[uint16]rune
+}
+
+func newPDFFont(resourceName, baseFont string, font *trueTypeFont) *pdfFont {
+	return &pdfFont{
+		resourceName: resourceName,
+		baseFont:     baseFont,
+		font:         font,
+		runes:        map[uint16]rune{},
+	}
+}
+
+// encode returns the text as a PDF hex string of two byte glyph IDs
+func (pf *pdfFont) encode(s string) string {
+	encoded := &strings.Builder{}
+	encoded.WriteByte('<')
+	for _, r := range s {
+		glyphID := pf.font.glyphID(r)
+		if glyphID != 0 {
+			pf.runes[glyphID] = r
+		}
+		fmt.Fprintf(encoded, "%04X", glyphID)
+	}
+	encoded.WriteByte('>')
+	return encoded.String()
+}
+
+// RenderPDF renders a single page landscape PDF with the embedded Unicode fonts,
+// so names and goals in any script the fonts cover are printed as they are.
+func (cs *certificateService) RenderPDF(certificate *model.Certificate, verifyURL string) ([]byte, error) {
+	regular := newPDFFont("F1", "KGCRTA+DejaVuSans", cs.regular)
+	bold := newPDFFont("F2", "KGCRTB+DejaVuSans-Bold", cs.bold)
+
+	content := &bytes.Buffer{}
+	// double border
+	fmt.Fprintf(content, "0.16 0.32 0.62 RG 4 w 24 24 %.0f %.0f re S\n", certificatePageWidth-48, certificatePageHeight-48)
+	fmt.Fprintf(content, "1 w 36 36 %.0f %.0f re S\n", certificatePageWidth-72, certificatePageHeight-72)
+	content.WriteString("0 0 0 rg\n")
+
+	centeredText(content, bold, 30, 480, "CERTIFICATE OF COMPLETION")
+	centeredText(content, regular, 14, 435, "This certifies that")
+	centeredText(content, bold, 28, 390, certificate.LearnerName)
+	centeredText(content, regular, 14, 350, "has successfully reached the goal")
+	centeredText(content, bold, 22, 315, certificate.GoalName)
+
+	if len(certificate.Topics) > 0 {
+		centeredText(content, bold, 12, 270, "Topics covered")
+		topics := certificate.Topics
+		lines := wrapText(regular, strings.Join(topics, ", "), 10, certificatePageWidth-160)
+		if len(lines) > certificateMaxTopics {
+			lines = append(lines[:certificateMaxTopics-1], "and more...")
+		}
+		for i, line := range lines {
+			centeredText(content, regular, 10, 252-float64(i)*14, line)
+		}
+	}
+
+	issuedAt := time.UnixMilli(certificate.CreatedAt).UTC().Format("January 2, 2006")
+	text(content, regular, 10, 60, 80, "Issued on "+issuedAt)
+	text(content, regular, 10, 60, 66, "Certificate ID: "+certificate.ID)
+	text(content, regular, 9, 60, 52, "Verify at "+verifyURL)
+
+	return buildPDF(content.Bytes(), []*pdfFont{regular, bold})
+}
+
+// buildPDF writes the page with the content and the fonts.
+// Fonts are embedded as Type0 fonts with the Identity-H encoding of glyph IDs and
+// the ToUnicode map, so the text can be copied and searched.
+func buildPDF(content []byte, fonts []*pdfFont) ([]byte, error) {
+	contentStream, err := pdfStream(content)
+	if err != nil {
+		return nil, err
+	}
+	fontResources := &strings.Builder{}
+	for i, font := range fonts {
+		fmt.Fprintf(fontResources, "/%s %d 0 R ", font.resourceName, 5+5*i)
+	}
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << %s>> >> /Contents 4 0 R >>", certificatePageWidth, certificatePageHeight, fontResources),
+		contentStream,
+	}
+	for i, font := range fonts {
+		fontObjects, err := font.objects(5 + 5*i)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, fontObjects...)
+	}
+
+	pdf := &bytes.Buffer{}
+	pdf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = pdf.Len()
+		fmt.Fprintf(pdf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := pdf.Len()
+	fmt.Fprintf(pdf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(pdf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(pdf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return pdf.Bytes(), nil
+}
+
+// objects returns the font's Type0 font, CIDFont, font descriptor, font file and ToUnicode map objects,
+// first of them gets the number firstObject
+func (pf *pdfFont) objects(firstObject int) ([]string, error) {
+	glyphIDs := make([]int, 0, len(pf.runes))
+	used := make(map[uint16]bool, len(pf.runes))
+	for glyphID := range pf.runes {
+		glyphIDs = append(glyphIDs, int(glyphID))
+		used[glyphID] = true
+	}
+	sort.Ints(glyphIDs)
+
+	widths := &strings.Builder{}
+	toUnicode := &strings.Builder{}
+	toUnicode.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	toUnicode.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	toUnicode.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	toUnicode.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	// bfchar sections are limited to 100 entries
+	for start := 0; start < len(glyphIDs); start += 100 {
+		end := start + 100
+		if end > len(glyphIDs) {
+			end = len(glyphIDs)
+		}
+		fmt.Fprintf(toUnicode, "%d beginbfchar\n", end-start)
+		for _, glyphID := range glyphIDs[start:end] {
+			fmt.Fprintf(toUnicode, "<%04X> <", glyphID)
+			for _, unit := range utf16.Encode([]rune{pf.runes[uint16(glyphID)]}) {
+				fmt.Fprintf(toUnicode, "%04X", unit)
+			}
+			toUnicode.WriteString(">\n")
+		}
+		toUnicode.WriteString("endbfchar\n")
+	}
+	toUnicode.WriteString("endcmap\nCMapName currentdict /CIDInit /ProcSet findresource /defineresource pop\nend\nend")
+	for _, glyphID := range glyphIDs {
+		fmt.Fprintf(widths, "%d [%d] ", glyphID, pf.font.scale(pf.font.advances[glyphID]))
+	}
+
+	fontFile, err := pdfStream(pf.font.subset(used))
+	if err != nil {
+		return nil, err
+	}
+	toUnicodeMap, err := pdfStream([]byte(toUnicode.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	font := pf.font
+	return []string{
+		fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+			pf.baseFont, firstObject+1, firstObject+4),
+		fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /DW %d /W [%s] >>",
+			pf.baseFont, firstObject+2, font.scale(font.advances[0]), widths),
+		fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+			pf.baseFont, font.scale(font.bbox[0]), font.scale(font.bbox[1]), font.scale(font.bbox[2]), font.scale(font.bbox[3]),
+			font.scale(font.ascent), font.scale(font.descent), font.scale(font.ascent), firstObject+3),
+		fontFile,
+		toUnicodeMap,
+	}, nil
+}
+
+// pdfStream returns the deflated stream object of the data
+func pdfStream(data []byte) (string, error) {
+	compressed := &bytes.Buffer{}
+	writer := zlib.NewWriter(compressed)
+	if _, err := writer.Write(data); err != nil {
+		return "", errors.Wrap(err, "can't compress PDF stream")
+	}
+	if err := writer.Close(); err != nil {
+		return "", errors.Wrap(err, "can't compress PDF stream")
+	}
+	return fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.Bytes()), nil
+}
+
+func centeredText(content *bytes.Buffer, font *pdfFont, size, y float64, s string) {
+	x := (certificatePageWidth - font.font.textWidth(s, size)) / 2
+	text(content, font, size, x, y, s)
+}
+
+func text(content *bytes.Buffer, font *pdfFont, size, x, y float64, s string) {
+	fmt.Fprintf(content, "BT /%s %.0f Tf %.1f %.1f Td %s Tj ET\n", font.resourceName, size, x, y, font.encode(s))
+}
+
+// wrapText splits the text into lines fitting into the width
+func wrapText(font *pdfFont, s string, size, maxWidth float64) []string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if line != "" && font.font.textWidth(candidate, size) > maxWidth {
+			lines = append(lines, line)
+			candidate = word
+		}
+		line = candidate
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	pdfStartXRefPattern = regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+	pdfReferencePattern = regexp.MustCompile(`/(\w+) \[?(\d+) 0 R`)
+	pdfTextPattern      = regexp.MustCompile(`/(F\d) \d+ Tf [\d.]+ [\d.]+ Td <([0-9A-F]*)> Tj`)
+	pdfBFCharPattern    = regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]+)>`)
+)
+
+// readPDFObjects reads the objects of the PDF by the cross-reference table, the objects are numbered from 1
+func readPDFObjects(t *testing.T, pdf []byte) map[int]string {
+	require.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	match := pdfStartXRefPattern.FindSubmatch(pdf)
+	require.NotNil(t, match, "no startxref")
+	xref, err := strconv.Atoi(string(match[1]))
+	require.NoError(t, err)
+
+	lines := strings.Split(string(pdf[xref:]), "\n")
+	require.Equal(t, "xref", lines[0])
+	var first, count int
+	_, err = fmt.Sscanf(lines[1], "%d %d", &first, &count)
+	require.NoError(t, err)
+	require.Equal(t, 0, first)
+	require.Contains(t, string(pdf), fmt.Sprintf("<< /Size %d /Root 1 0 R >>", count))
+
+	objects := map[int]string{}
+	for i := 1; i < count; i++ {
+		entry := lines[2+i]
+		require.Len(t, entry, 19, "xref entries are 20 bytes with the line end")
+		offset, err := strconv.Atoi(entry[:10])
+		require.NoError(t, err)
+		header := fmt.Sprintf("%d 0 obj\n", i)
+		require.Equal(t, header, string(pdf[offset:offset+len(header)]), "xref offset of object %d", i)
+		end := bytes.Index(pdf[offset:], []byte("\nendobj\n"))
+		require.NotEqual(t, -1, end)
+		objects[i] = string(pdf[offset+len(header) : offset+end])
+	}
+	return objects
+}
+
+// readPDFStream inflates the data of the stream object
+func readPDFStream(t *testing.T, object string) []byte {
+	start := strings.Index(object, "\nstream\n")
+	end := strings.LastIndex(object, "\nendstream")
+	require.True(t, start != -1 && end > start, "not a stream object")
+	data := object[start+len("\nstream\n") : end]
+	require.Contains(t, object[:start], fmt.Sprintf("/Length %d ", len(data)))
+
+	reader, err := zlib.NewReader(strings.NewReader(data))
+	require.NoError(t, err)
+	inflated, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return inflated
+}
+
+// reference returns the number of the object the key of the dictionary references
+func reference(t *testing.T, object, key string) int {
+	for _, match := range pdfReferencePattern.FindAllStringSubmatch(object, -1) {
+		if match[1] == key {
+			number, err := strconv.Atoi(match[2])
+			require.NoError(t, err)
+			return number
+		}
+	}
+	require.Failf(t, "no reference", "no %s in %s", key, object)
+	return 0
+}
+
+func TestRenderPDF(t *testing.T) {
+	service, err := NewCertificateService()
+	require.NoError(t, err)
+
+	for name, certificate := range map[string]*model.Certificate{
+		"latin": {
+			ID:          model.NewID(),
+			LearnerName: "Ada Lovelace",
+			GoalName:    "Loops and Conditions",
+			Topics:      []string{"Variables", "Conditions"},
+			CreatedAt:   model.GetMillis(),
+		},
+		"georgian": {
+			ID:          model.NewID(),
+			LearnerName: "ნიკო ფიროსმანი",
+			GoalName:    "ციკლები და პირობები",
+			Topics:      []string{"ცვლადები", "პირობები"},
+			CreatedAt:   model.GetMillis(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			pdf, err := service.RenderPDF(certificate, "https://example.com/verify/"+certificate.ID)
+			require.NoError(t, err)
+			objects := readPDFObjects(t, pdf)
+
+			page := objects[3]
+			require.Contains(t, page, "/Type /Page ")
+
+			// the text of the page is decoded with the ToUnicode maps of its fonts
+			texts := []string{}
+			content := readPDFStream(t, objects[reference(t, page, "Contents")])
+			toUnicode := map[string]map[string]string{}
+			for _, match := range pdfTextPattern.FindAllStringSubmatch(string(content), -1) {
+				resourceName, glyphs := match[1], match[2]
+				if _, ok := toUnicode[resourceName]; !ok {
+					font := objects[reference(t, page, resourceName)]
+					require.Contains(t, font, "/Subtype /Type0 /BaseFont /KGCRT")
+					require.Contains(t, font, "/Encoding /Identity-H")
+					toUnicode[resourceName] = map[string]string{}
+					for _, bfchar := range pdfBFCharPattern.FindAllStringSubmatch(string(readPDFStream(t, objects[reference(t, font, "ToUnicode")])), -1) {
+						toUnicode[resourceName][bfchar[1]] = bfchar[2]
+					}
+				}
+
+				text := &strings.Builder{}
+				for i := 0; i < len(glyphs); i += 4 {
+					require.NotEqual(t, "0000", glyphs[i:i+4], "missing glyph in the text")
+					unicode, ok := toUnicode[resourceName][glyphs[i:i+4]]
+					require.True(t, ok, "glyph %s isn't in the ToUnicode map", glyphs[i:i+4])
+					units, err := hex.DecodeString(unicode)
+					require.NoError(t, err)
+					for j := 0; j < len(units); j += 2 {
+						text.WriteString(string(utf16.Decode([]uint16{uint16(units[j])<<8 | uint16(units[j+1])})))
+					}
+				}
+				texts = append(texts, text.String())
+			}
+			require.Contains(t, texts, certificate.LearnerName)
+			require.Contains(t, texts, certificate.GoalName)
+			require.Contains(t, texts, strings.Join(certificate.Topics, ", "))
+			require.Contains(t, texts, "Certificate ID: "+certificate.ID)
+
+			// the embedded fonts are valid TrueType fonts with the glyphs of the text
+			for resourceName := range toUnicode {
+				font := objects[reference(t, page, resourceName)]
+				cidFont := objects[reference(t, font, "DescendantFonts")]
+				require.Contains(t, cidFont, "/Subtype /CIDFontType2")
+				descriptor := objects[reference(t, cidFont, "FontDescriptor")]
+				fontFile := readPDFStream(t, objects[reference(t, descriptor, "FontFile2")])
+				tables := readTrueTypeTables(t, fontFile)
+				loca := tables["loca"]
+				for glyphID := range toUnicode[resourceName] {
+					id, err := strconv.ParseUint(glyphID, 16, 16)
+					require.NoError(t, err)
+					start := loca[4*id : 4*id+4]
+					end := loca[4*id+4 : 4*id+8]
+					if !bytes.Equal(start, end) {
+						continue
+					}
+					// only the space has no outline
+					require.Equal(t, "0020", toUnicode[resourceName][glyphID], "glyph %s is empty in the subset", glyphID)
+				}
+			}
+		})
+	}
+}
@@ -6,37 +6,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 
+	"github.com/oseducation/knowledge-graph/model"
 	"github.com/pkg/errors"
 )
 
 const chatGPTAPIKey = "CHAT_GPT_API_KEY"
 const chatGPTOrganizationID = "CHAT_GPT_ORGANIZATION_ID"
 
-type ChatGPTModel string
-
+// default models used when the provider is configured only with environment variables
 const (
-	gpt35Turbo    ChatGPTModel = "gpt-3.5-turbo"
-	gpt35Turbo16k ChatGPTModel = "gpt-3.5-turbo-16k"
-
-	gpt4             ChatGPTModel = "gpt-4"
-	gpt4_32k         ChatGPTModel = "gpt-4-32k"
-	gpt4_1106Preview ChatGPTModel = "gpt-4-1106-preview"
-	gpt4_0125Preview ChatGPTModel = "gpt-4-0125-preview"
-
-	gpt4o ChatGPTModel = "gpt-4o"
-
-	FreeGPT    = gpt35Turbo
-	PremiumGPT = gpt4o
-)
-
-type ChatGPTModelRole string
-
-const (
-	ChatGPTModelRoleUser      ChatGPTModelRole = "user"
-	ChatGPTModelRoleSystem    ChatGPTModelRole = "system"
-	ChatGPTModelRoleAssistant ChatGPTModelRole = "assistant"
+	gpt35Turbo = "gpt-3.5-turbo"
+	gpt4o      = "gpt-4o"
 )
 
 type ChatCompletionRequest struct {
@@ -46,7 +27,7 @@ type ChatCompletionRequest struct {
 
 	// (Required)
 	// ID of the model to use.
-	Model ChatGPTModel `json:"model"`
+	Model string `json:"model"`
 
 	// (Optional - default: 0)
 	// Number between -2.0 and 2.0. Positive values penalize new tokens based on their existing frequency in the text so far,
@@ -84,6 +65,10 @@ type ChatCompletionRequest struct {
 	// If set, partial message deltas will be sent, like in ChatGPT.
 	Stream bool `json:"stream,omitempty"`
 
+	// (Optional)
+	// Options for streaming response, IncludeUsage adds a chunk with the token usage before [DONE]
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+
 	// (Optional - default: 1)
 	// What sampling temperature to use, between 0 and 2. Higher values like 0.8 will make the output more random, while lower values like 0.2 will make it more focused and deterministic.
 	// We generally recommend altering this or top_p but not both.
@@ -99,9 +84,8 @@ type ChatCompletionRequest struct {
 	User string `json:"user,omitempty"`
 }
 
-type ChatMessage struct {
-	Role    ChatGPTModelRole `json:"role"`
-	Content string           `json:"content"`
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type ChatResponse struct {
@@ -124,51 +108,7 @@ type ChatResponseUsage struct {
 	TotalTokens      int `json:"total_tokens"`
 }
 
-// EmbeddingRequestStrings is the input to a create embeddings request with a slice of strings.
-type EmbeddingRequestStrings struct {
-	// Input is a slice of strings for which you want to generate an Embedding vector.
-	// Each input must not exceed 8192 tokens in length.
-	// OpenAPI suggests replacing newlines (\n) in your input with a single space, as they
-	// have observed inferior results when newlines are present.
-	// E.g.
-	//	"The food was delicious and the waiter..."
-	Input []string `json:"input"`
-	// ID of the model to use. You can use the List models API to see all of your available models,
-	// or see our Model overview for descriptions of them.
-	Model string `json:"model"`
-	// A unique identifier representing your end-user, which will help OpenAI to monitor and detect abuse.
-	User string `json:"user"`
-	// Dimensions The number of dimensions the resulting output embeddings should have.
-	// Only supported in text-embedding-3 and later models.
-	// Dimensions int `json:"dimensions,omitempty"`
-}
-
-// Embedding is a special format of data representation that can be easily utilized by machine
-// learning models and algorithms. The embedding is an information dense representation of the
-// semantic meaning of a piece of text. Each embedding is a vector of floating point numbers,
-// such that the distance between two embeddings in the vector space is correlated with semantic similarity
-// between two inputs in the original format. For example, if two texts are similar,
-// then their vector representations should also be similar.
-type Embedding struct {
-	Object    string    `json:"object"`
-	Embedding []float32 `json:"embedding"`
-	Index     int       `json:"index"`
-}
-
-// EmbeddingResponse is the response from a Create embeddings request.
-type EmbeddingResponse struct {
-	Object string      `json:"object"`
-	Data   []Embedding `json:"data"`
-	Model  string      `json:"model"`
-}
-
 var (
-	// ErrAPIKeyRequired is returned when the API Key is not provided
-	ErrAPIKeyRequired = errors.New("ChatGPT API Key is required")
-
-	// ErrInvalidModel is returned when the model is invalid
-	ErrInvalidModel = errors.New("invalid model")
-
 	// ErrNoMessages is returned when no messages are provided
 	ErrNoMessages = errors.New("no messages provided")
 
@@ -185,106 +125,46 @@ var (
 	ErrInvalidFrequencyPenalty = errors.New("invalid frequency penalty. -2<= frequency penalty <= 2")
 )
 
-const (
-	apiURL         = "https://api.openai.com/v1"
-	embeddingModel = "text-embedding-3-small"
-)
-
-type ChatGPTServiceInterface interface {
-	Send(userID, systemMessage string, model ChatGPTModel, messages []string) (string, error)
-	SendStream(userID, systemMessage string, model ChatGPTModel, messages []string) (ChatStream, error)
-	SendStreamWithChatMessages(userID string, model ChatGPTModel, chatMessages []ChatMessage) (ChatStream, error)
-	GetEmbedding(text, userID string) ([]float32, error)
-}
+const openAIURL = "https://api.openai.com/v1"
 
-type ChatGPTService struct {
+// openAIService talks to OpenAI compatible chat completion APIs: OpenAI, vLLM, llama.cpp server, Ollama
+type openAIService struct {
 	// HTTP client used to communicate with the API.
 	client *http.Client
 
-	// Config
-	config *Config
+	baseURL string
+	// apiKey is optional for local servers
+	apiKey string
+	orgID  string
+	// models maps tiers to model names
+	models map[string]string
+	// maxTokens are reserved for the answer in the context window
+	maxTokens     int
+	contextTokens map[string]int
+
+	embeddings *embeddingClient
+	usage      *usageRecorder
 }
 
-type ChatGPTServiceDummy struct {
+func (c *openAIService) GetEmbedding(text, userID string) ([]float32, error) {
+	return c.embeddings.GetEmbedding(text, userID)
 }
 
-type Config struct {
-	// Base URL for API requests.
-	BaseURL string
-
-	// API Key (Required)
-	APIKey string
-
-	// Organization ID (Optional)
-	OrganizationID string
-}
-
-func NewCHhatGPTService() (ChatGPTServiceInterface, error) {
-	apiKey, ok := os.LookupEnv(chatGPTAPIKey)
-	if !ok || apiKey == "" || apiKey == "test" {
-		return &ChatGPTServiceDummy{}, nil
-	}
-	orgID, ok := os.LookupEnv(chatGPTOrganizationID)
-	if !ok {
-		orgID = ""
-	}
-
-	return &ChatGPTService{
-		client: &http.Client{},
-		config: &Config{
-			BaseURL:        apiURL,
-			APIKey:         apiKey,
-			OrganizationID: orgID,
-		},
-	}, nil
-}
-
-func (c *ChatGPTService) GetEmbedding(text, userID string) ([]float32, error) {
-	request := EmbeddingRequestStrings{
-		Input: []string{text},
-		Model: embeddingModel,
-		User:  userID,
-	}
-	response, err := c.sendEmbeddingRequest(context.Background(), &request)
-	if err != nil {
-		return nil, errors.Wrap(err, "can't send embedding request")
-	}
-	if len(response.Data) == 0 {
-		return nil, errors.New("no data in response")
-	}
-	return response.Data[0].Embedding, nil
+func (c *openAIService) PromptTokens(tier LLMTier) int {
+	return promptTokensForTier(c.models, c.contextTokens, c.maxTokens, tier)
 }
 
-func (c *ChatGPTService) sendEmbeddingRequest(ctx context.Context, req *EmbeddingRequestStrings) (*EmbeddingResponse, error) {
-	reqBytes, _ := json.Marshal(req)
-
-	endpoint := "/embeddings"
-	httpReq, err := http.NewRequest("POST", c.config.BaseURL+endpoint, bytes.NewBuffer(reqBytes))
-	if err != nil {
-		return nil, err
-	}
-	httpReq = httpReq.WithContext(ctx)
-
-	res, err := c.sendRequest(httpReq)
+func (c *openAIService) SendStreamWithChatMessages(userID string, tier LLMTier, chatMessages []ChatMessage) (ChatStream, error) {
+	modelName, err := modelForTier(c.models, tier)
 	if err != nil {
 		return nil, err
 	}
-	defer res.Body.Close()
-
-	var response EmbeddingResponse
-	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
-}
-
-func (c *ChatGPTService) SendStreamWithChatMessages(userID string, model ChatGPTModel, chatMessages []ChatMessage) (ChatStream, error) {
 	req := &ChatCompletionRequest{
-		Model:    model,
-		Messages: chatMessages,
-		User:     userID,
-		Stream:   true,
+		Model:         modelName,
+		Messages:      chatMessages,
+		User:          userID,
+		Stream:        true,
+		StreamOptions: &StreamOptions{IncludeUsage: true},
 	}
 
 	resp, err := c.sendStream(context.Background(), req)
@@ -292,55 +172,25 @@ func (c *ChatGPTService) SendStreamWithChatMessages(userID string, model ChatGPT
 		return nil, err
 	}
 
-	return CreateChatGPTStream(resp), nil
+	return c.usage.meter(CreateChatGPTStream(resp), &model.LLMUsage{
+		UserID:   userID,
+		Model:    modelName,
+		CallType: model.LLMCallStream,
+	}, chatMessages), nil
 }
 
-func (c *ChatGPTService) SendStream(userID, systemMessage string, model ChatGPTModel, messages []string) (ChatStream, error) {
-	chatMessages := getChatMessages(systemMessage, messages)
-
-	req := &ChatCompletionRequest{
-		Model:    model,
-		Messages: chatMessages,
-		User:     userID,
-		Stream:   true,
-	}
-
-	resp, err := c.sendStream(context.Background(), req)
-	if err != nil {
-		return nil, err
-	}
-
-	return CreateChatGPTStream(resp), nil
+func (c *openAIService) SendStream(userID, systemMessage string, tier LLMTier, messages []string) (ChatStream, error) {
+	return c.SendStreamWithChatMessages(userID, tier, getChatMessages(systemMessage, messages))
 }
 
-func getChatMessages(systemMessage string, messages []string) []ChatMessage {
-	chatMessages := make([]ChatMessage, len(messages)+1)
-	chatMessages[0] = ChatMessage{
-		Role:    ChatGPTModelRoleSystem,
-		Content: systemMessage,
-	}
-	for i, message := range messages {
-		if i%2 == 0 {
-			chatMessages[i+1] = ChatMessage{
-				Role:    ChatGPTModelRoleUser,
-				Content: message,
-			}
-		} else {
-			chatMessages[i+1] = ChatMessage{
-				Role:    ChatGPTModelRoleAssistant,
-				Content: message,
-			}
-		}
+func (c *openAIService) Send(userID, systemMessage string, tier LLMTier, messages []string) (string, error) {
+	modelName, err := modelForTier(c.models, tier)
+	if err != nil {
+		return "", err
 	}
-	return chatMessages
-}
-
-func (c *ChatGPTService) Send(userID, systemMessage string, model ChatGPTModel, messages []string) (string, error) {
-	chatMessages := getChatMessages(systemMessage, messages)
-
 	req := &ChatCompletionRequest{
-		Model:    model,
-		Messages: chatMessages,
+		Model:    modelName,
+		Messages: getChatMessages(systemMessage, messages),
 		User:     userID,
 	}
 
@@ -348,13 +198,28 @@ func (c *ChatGPTService) Send(userID, systemMessage string, model ChatGPTModel,
 	if err != nil {
 		return "", err
 	}
+	usage := &model.LLMUsage{
+		UserID:           userID,
+		Model:            modelName,
+		CallType:         model.LLMCallChat,
+		PromptTokens:     int64(resp.Usage.PromptTokens),
+		CompletionTokens: int64(resp.Usage.CompletionTokens),
+	}
+	if resp.Usage.TotalTokens == 0 {
+		usage.PromptTokens = estimatePromptTokens(req.Messages)
+		for _, choice := range resp.Choices {
+			usage.CompletionTokens += EstimateTokens(choice.Message.Content)
+		}
+		usage.Estimated = true
+	}
+	c.usage.record(usage)
 	if len(resp.Choices) == 1 && resp.Choices[0].FinishReason == "stop" {
 		return resp.Choices[0].Message.Content, nil
 	}
 	return "", errors.New("no response")
 }
 
-func (c *ChatGPTService) sendStream(ctx context.Context, req *ChatCompletionRequest) (*http.Response, error) {
+func (c *openAIService) sendStream(ctx context.Context, req *ChatCompletionRequest) (*http.Response, error) {
 	if err := validate(req); err != nil {
 		return nil, err
 	}
@@ -365,7 +230,7 @@ func (c *ChatGPTService) sendStream(ctx context.Context, req *ChatCompletionRequ
 	reqBytes, _ := json.Marshal(req)
 
 	endpoint := "/chat/completions"
-	httpReq, err := http.NewRequest("POST", c.config.BaseURL+endpoint, bytes.NewBuffer(reqBytes))
+	httpReq, err := http.NewRequest("POST", c.baseURL+endpoint, bytes.NewBuffer(reqBytes))
 	if err != nil {
 		return nil, err
 	}
@@ -378,12 +243,8 @@ func (c *ChatGPTService) sendStream(ctx context.Context, req *ChatCompletionRequ
 	return res, nil
 }
 
-func (c *ChatGPTService) sendRequestStream(req *http.Request) (*http.Response, error) {
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.APIKey))
-	if c.config.OrganizationID != "" {
-		req.Header.Set("OpenAI-Organization", c.config.OrganizationID)
-	}
-	req.Header.Set("Content-Type", "application/json")
+func (c *openAIService) sendRequestStream(req *http.Request) (*http.Response, error) {
+	c.setHeaders(req)
 	req.Header.Set("Accept", "text/event-stream")
 
 	req.Header.Set("Cache-Control", "no-cache")
@@ -395,7 +256,7 @@ func (c *ChatGPTService) sendRequestStream(req *http.Request) (*http.Response, e
 	return res, nil
 }
 
-func (c *ChatGPTService) send(ctx context.Context, req *ChatCompletionRequest) (*ChatResponse, error) {
+func (c *openAIService) send(ctx context.Context, req *ChatCompletionRequest) (*ChatResponse, error) {
 	if err := validate(req); err != nil {
 		return nil, err
 	}
@@ -403,13 +264,15 @@ func (c *ChatGPTService) send(ctx context.Context, req *ChatCompletionRequest) (
 	reqBytes, _ := json.Marshal(req)
 
 	endpoint := "/chat/completions"
-	httpReq, err := http.NewRequest("POST", c.config.BaseURL+endpoint, bytes.NewBuffer(reqBytes))
+	httpReq, err := http.NewRequest("POST", c.baseURL+endpoint, bytes.NewBuffer(reqBytes))
 	if err != nil {
 		return nil, err
 	}
 	httpReq = httpReq.WithContext(ctx)
 
-	res, err := c.sendRequest(httpReq)
+	c.setHeaders(httpReq)
+	httpReq.Header.Set("Accept", "application/json")
+	res, err := sendRequest(c.client, httpReq)
 	if err != nil {
 		return nil, err
 	}
@@ -423,29 +286,24 @@ func (c *ChatGPTService) send(ctx context.Context, req *ChatCompletionRequest) (
 	return &chatResponse, nil
 }
 
-func validate(req *ChatCompletionRequest) error {
-	if len(req.Messages) == 0 {
-		return ErrNoMessages
-	}
-
-	isAllowed := false
-
-	allowedModels := []ChatGPTModel{
-		gpt35Turbo, gpt35Turbo16k, gpt4, gpt4_32k, gpt4_0125Preview, gpt4o,
+func (c *openAIService) setHeaders(req *http.Request) {
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
 	}
-
-	for _, model := range allowedModels {
-		if req.Model == model {
-			isAllowed = true
-		}
+	if c.orgID != "" {
+		req.Header.Set("OpenAI-Organization", c.orgID)
 	}
+	req.Header.Set("Content-Type", "application/json")
+}
 
-	if !isAllowed {
-		return ErrInvalidModel
+// validate checks the request, models aren't checked since OpenAI compatible servers serve any model
+func validate(req *ChatCompletionRequest) error {
+	if len(req.Messages) == 0 {
+		return ErrNoMessages
 	}
 
 	for _, message := range req.Messages {
-		if message.Role != ChatGPTModelRoleUser && message.Role != ChatGPTModelRoleSystem && message.Role != ChatGPTModelRoleAssistant {
+		if message.Role != ChatRoleUser && message.Role != ChatRoleSystem && message.Role != ChatRoleAssistant {
 			return ErrInvalidRole
 		}
 	}
@@ -465,19 +323,14 @@ func validate(req *ChatCompletionRequest) error {
 	return nil
 }
 
-func (c *ChatGPTService) sendRequest(req *http.Request) (*http.Response, error) {
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.APIKey))
-	if c.config.OrganizationID != "" {
-		req.Header.Set("OpenAI-Organization", c.config.OrganizationID)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-
-	res, err := c.client.Do(req)
+// sendRequest sends the request and returns an error with the response body if the request failed
+func sendRequest(client *http.Client, req *http.Request) (*http.Response, error) {
+	res, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
 		// Parse body
 		var errMessage interface{}
 		if err := json.NewDecoder(res.Body).Decode(&errMessage); err != nil {
@@ -489,19 +342,3 @@ func (c *ChatGPTService) sendRequest(req *http.Request) (*http.Response, error)
 
 	return res, nil
 }
-
-func (c *ChatGPTServiceDummy) Send(userID, systemMessage string, _ ChatGPTModel, messages []string) (string, error) {
-	return fmt.Sprintf("Dummy answer for user `%s`'s message number %d, systemMessage: \n\n %s\n", userID, len(messages), systemMessage), nil
-}
-
-func (c *ChatGPTServiceDummy) GetEmbedding(_, _ string) ([]float32, error) {
-	return []float32{}, nil
-}
-
-func (c *ChatGPTServiceDummy) SendStream(_, _ string, _ ChatGPTModel, _ []string) (ChatStream, error) {
-	return CreateDummyChatGPTStream(), nil
-}
-
-func (c *ChatGPTServiceDummy) SendStreamWithChatMessages(_ string, _ ChatGPTModel, _ []ChatMessage) (ChatStream, error) {
-	return CreateDummyChatGPTStream(), nil
-}
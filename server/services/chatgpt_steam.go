@@ -17,6 +17,8 @@ type ChatStream interface {
 
 type streamReader struct {
 	isFinished bool
+	// usage is sent in the last chunk if it was requested with stream options
+	usage *ChatResponseUsage
 
 	reader   *bufio.Reader
 	response *http.Response
@@ -43,6 +45,7 @@ type ChatCompletionStreamResponse struct {
 	Created int64                        `json:"created"`
 	Model   string                       `json:"model"`
 	Choices []ChatCompletionStreamChoice `json:"choices"`
+	Usage   *ChatResponseUsage           `json:"usage,omitempty"`
 }
 
 func CreateDummyChatGPTStream() ChatStream {
@@ -67,6 +70,9 @@ func (stream *streamReader) Recv() (string, error) {
 		if err != nil {
 			return "", err
 		}
+		if response.Usage != nil {
+			stream.usage = response.Usage
+		}
 
 		if len(response.Choices) == 1 && response.Choices[0].Index == 0 && response.Choices[0].Delta.Content != "" {
 			return response.Choices[0].Delta.Content, nil
@@ -120,6 +126,13 @@ func (stream *streamReader) Close() {
 	stream.response.Body.Close()
 }
 
+func (stream *streamReader) tokenUsage() (int64, int64, bool) {
+	if stream.usage == nil {
+		return 0, 0, false
+	}
+	return int64(stream.usage.PromptTokens), int64(stream.usage.CompletionTokens), true
+}
+
 func (stream *dummyStreamReader) Recv() (string, error) {
 	if stream.isFinished {
 		return "", io.EOF
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+const defaultEmbeddingModel = "text-embedding-3-small"
+
+// EmbeddingRequestStrings is the input to a create embeddings request with a slice of strings.
+type EmbeddingRequestStrings struct {
+	// Input is a slice of strings for which you want to generate an Embedding vector.
+	// Each input must not exceed 8192 tokens in length.
+	// OpenAPI suggests replacing newlines (\n) in your input with a single space, as they
+	// have observed inferior results when newlines are present.
+	// E.g.
+	//	"The food was delicious and the waiter..."
+	Input []string `json:"input"`
+	// ID of the model to use. You can use the List models API to see all of your available models,
+	// or see our Model overview for descriptions of them.
+	Model string `json:"model"`
+	// A unique identifier representing your end-user, which will help OpenAI to monitor and detect abuse.
+	User string `json:"user,omitempty"`
+	// Dimensions The number of dimensions the resulting output embeddings should have.
+	// Only supported in text-embedding-3 and later models.
+	// Dimensions int `json:"dimensions,omitempty"`
+}
+
+// Embedding is a special format of data representation that can be easily utilized by machine
+// learning models and algorithms. The embedding is an information dense representation of the
+// semantic meaning of a piece of text. Each embedding is a vector of floating point numbers,
+// such that the distance between two embeddings in the vector space is correlated with semantic similarity
+// between two inputs in the original format. For example, if two texts are similar,
+// then their vector representations should also be similar.
+type Embedding struct {
+	Object    string    `json:"object"`
+	Embedding []float32 `json:"embedding"`
+	Index     int       `json:"index"`
+}
+
+// EmbeddingResponse is the response from a Create embeddings request.
+type EmbeddingResponse struct {
+	Object string         `json:"object"`
+	Data   []Embedding    `json:"data"`
+	Model  string         `json:"model"`
+	Usage  EmbeddingUsage `json:"usage"`
+}
+
+type EmbeddingUsage struct {
+	PromptTokens int `json:"prompt_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// embeddingClient requests embeddings from an OpenAI compatible API
+type embeddingClient struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	model   string
+	usage   *usageRecorder
+}
+
+// newEmbeddingClient returns nil if embeddings aren't configured
+func newEmbeddingClient(baseURL, apiKey, model string, usage *usageRecorder) *embeddingClient {
+	if baseURL == "" {
+		return nil
+	}
+	if model == "" {
+		model = defaultEmbeddingModel
+	}
+	return &embeddingClient{
+		client:  &http.Client{},
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		model:   model,
+		usage:   usage,
+	}
+}
+
+// GetEmbedding returns an empty embedding if embeddings aren't configured, as the dummy service does
+func (e *embeddingClient) GetEmbedding(text, userID string) ([]float32, error) {
+	if e == nil {
+		return []float32{}, nil
+	}
+	request := EmbeddingRequestStrings{
+		Input: []string{text},
+		Model: e.model,
+		User:  userID,
+	}
+	response, err := e.sendEmbeddingRequest(context.Background(), &request)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't send embedding request")
+	}
+	usage := &model.LLMUsage{
+		UserID:       userID,
+		Model:        e.model,
+		CallType:     model.LLMCallEmbedding,
+		PromptTokens: int64(response.Usage.PromptTokens),
+	}
+	if response.Usage.PromptTokens == 0 {
+		usage.PromptTokens = EstimateTokens(text)
+		usage.Estimated = true
+	}
+	e.usage.record(usage)
+	if len(response.Data) == 0 {
+		return nil, errors.New("no data in response")
+	}
+	return response.Data[0].Embedding, nil
+}
+
+func (e *embeddingClient) sendEmbeddingRequest(ctx context.Context, req *EmbeddingRequestStrings) (*EmbeddingResponse, error) {
+	reqBytes, _ := json.Marshal(req)
+
+	endpoint := "/embeddings"
+	httpReq, err := http.NewRequest("POST", e.baseURL+endpoint, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, err
+	}
+	httpReq = httpReq.WithContext(ctx)
+	if e.apiKey != "" {
+		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", e.apiKey))
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+
+	res, err := sendRequest(e.client, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var response EmbeddingResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
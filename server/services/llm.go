@@ -0,0 +1,199 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/oseducation/knowledge-graph/config"
+	"github.com/oseducation/knowledge-graph/log"
+	"github.com/oseducation/knowledge-graph/store"
+	"github.com/pkg/errors"
+)
+
+const llmAPIKey = "LLM_API_KEY"
+
+// LLMTier is the quality tier of the language model, tiers are mapped to models of the provider in the config
+type LLMTier string
+
+const (
+	LLMTierFree    LLMTier = "free"
+	LLMTierPremium LLMTier = "premium"
+)
+
+const (
+	LLMProviderOpenAI    = "openai"
+	LLMProviderAnthropic = "anthropic"
+
+	defaultLLMMaxTokens     = 1024
+	defaultLLMContextTokens = 8192
+)
+
+type ChatRole string
+
+const (
+	ChatRoleUser      ChatRole = "user"
+	ChatRoleSystem    ChatRole = "system"
+	ChatRoleAssistant ChatRole = "assistant"
+)
+
+type ChatMessage struct {
+	Role    ChatRole `json:"role"`
+	Content string   `json:"content"`
+}
+
+// LLMServiceInterface is a provider agnostic interface to the language model
+type LLMServiceInterface interface {
+	Send(userID, systemMessage string, tier LLMTier, messages []string) (string, error)
+	SendStream(userID, systemMessage string, tier LLMTier, messages []string) (ChatStream, error)
+	SendStreamWithChatMessages(userID string, tier LLMTier, chatMessages []ChatMessage) (ChatStream, error)
+	GetEmbedding(text, userID string) ([]float32, error)
+	// PromptTokens returns the number of tokens the prompt may take with the tier's model, the answer's tokens are reserved
+	PromptTokens(tier LLMTier) int
+}
+
+type llmServiceDummy struct {
+}
+
+// NewLLMService creates the service for the configured provider, every call is added to the usage ledger of the store
+func NewLLMService(settings config.LLMSettings, st store.Store, logger *log.Logger) (LLMServiceInterface, error) {
+	usage := newUsageRecorder(st, settings.Prices, logger)
+	if settings.Provider == "" {
+		return newLLMServiceFromEnv(usage)
+	}
+
+	apiKey := settings.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv(llmAPIKey)
+	}
+	if settings.MaxTokens == 0 {
+		settings.MaxTokens = defaultLLMMaxTokens
+	}
+	if len(settings.Models) == 0 {
+		return nil, errors.Errorf("no models configured for llm provider %s", settings.Provider)
+	}
+
+	switch settings.Provider {
+	case LLMProviderOpenAI:
+		if settings.BaseURL == "" {
+			settings.BaseURL = openAIURL
+		}
+		embeddings := newEmbeddingClient(settings.EmbeddingBaseURL, settings.EmbeddingAPIKey, settings.EmbeddingModel, usage)
+		if settings.EmbeddingBaseURL == "" {
+			embeddings = newEmbeddingClient(settings.BaseURL, apiKey, settings.EmbeddingModel, usage)
+		}
+		return &openAIService{
+			client:        &http.Client{},
+			baseURL:       settings.BaseURL,
+			apiKey:        apiKey,
+			orgID:         settings.OrganizationID,
+			models:        settings.Models,
+			maxTokens:     settings.MaxTokens,
+			contextTokens: settings.ContextTokens,
+			embeddings:    embeddings,
+			usage:         usage,
+		}, nil
+	case LLMProviderAnthropic:
+		if settings.BaseURL == "" {
+			settings.BaseURL = anthropicURL
+		}
+		if apiKey == "" {
+			return nil, errors.New("anthropic api key is required")
+		}
+		return &anthropicService{
+			client:        &http.Client{},
+			baseURL:       settings.BaseURL,
+			apiKey:        apiKey,
+			models:        settings.Models,
+			maxTokens:     settings.MaxTokens,
+			contextTokens: settings.ContextTokens,
+			embeddings:    newEmbeddingClient(settings.EmbeddingBaseURL, settings.EmbeddingAPIKey, settings.EmbeddingModel, usage),
+			usage:         usage,
+		}, nil
+	}
+	return nil, errors.Errorf("unknown llm provider %s", settings.Provider)
+}
+
+// newLLMServiceFromEnv keeps deployments configured only with environment variables working
+func newLLMServiceFromEnv(usage *usageRecorder) (LLMServiceInterface, error) {
+	apiKey, ok := os.LookupEnv(chatGPTAPIKey)
+	if !ok || apiKey == "" || apiKey == "test" {
+		return &llmServiceDummy{}, nil
+	}
+
+	return &openAIService{
+		client:  &http.Client{},
+		baseURL: openAIURL,
+		apiKey:  apiKey,
+		orgID:   os.Getenv(chatGPTOrganizationID),
+		models: map[string]string{
+			string(LLMTierFree):    gpt35Turbo,
+			string(LLMTierPremium): gpt4o,
+		},
+		maxTokens:  defaultLLMMaxTokens,
+		embeddings: newEmbeddingClient(openAIURL, apiKey, defaultEmbeddingModel, usage),
+		usage:      usage,
+	}, nil
+}
+
+// modelForTier returns the model configured for the tier, falling back to the free tier
+func modelForTier(models map[string]string, tier LLMTier) (string, error) {
+	if model, ok := models[string(tier)]; ok && model != "" {
+		return model, nil
+	}
+	if model, ok := models[string(LLMTierFree)]; ok && model != "" {
+		return model, nil
+	}
+	return "", errors.Errorf("no model configured for tier %s", tier)
+}
+
+// promptTokensForTier returns the context window of the tier's model without the tokens reserved for the answer
+func promptTokensForTier(models map[string]string, contextTokens map[string]int, maxTokens int, tier LLMTier) int {
+	tokens := defaultLLMContextTokens
+	if modelName, err := modelForTier(models, tier); err == nil && contextTokens[modelName] > 0 {
+		tokens = contextTokens[modelName]
+	}
+	return tokens - maxTokens
+}
+
+func getChatMessages(systemMessage string, messages []string) []ChatMessage {
+	chatMessages := make([]ChatMessage, len(messages)+1)
+	chatMessages[0] = ChatMessage{
+		Role:    ChatRoleSystem,
+		Content: systemMessage,
+	}
+	for i, message := range messages {
+		if i%2 == 0 {
+			chatMessages[i+1] = ChatMessage{
+				Role:    ChatRoleUser,
+				Content: message,
+			}
+		} else {
+			chatMessages[i+1] = ChatMessage{
+				Role:    ChatRoleAssistant,
+				Content: message,
+			}
+		}
+	}
+	return chatMessages
+}
+
+func (c *llmServiceDummy) Send(userID, systemMessage string, _ LLMTier, messages []string) (string, error) {
+	return fmt.Sprintf("Dummy answer for user `%s`'s message number %d, systemMessage: \n\n %s\n", userID, len(messages), systemMessage), nil
+}
+
+func (c *llmServiceDummy) GetEmbedding(_, _ string) ([]float32, error) {
+	return []float32{}, nil
+}
+
+func (c *llmServiceDummy) SendStream(_, _ string, _ LLMTier, _ []string) (ChatStream, error) {
+	return CreateDummyChatGPTStream(), nil
+}
+
+func (c *llmServiceDummy) SendStreamWithChatMessages(_ string, _ LLMTier, _ []ChatMessage) (ChatStream, error) {
+	return CreateDummyChatGPTStream(), nil
+}
+
+func (c *llmServiceDummy) PromptTokens(_ LLMTier) int {
+	return defaultLLMContextTokens - defaultLLMMaxTokens
+}
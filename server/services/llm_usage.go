@@ -0,0 +1,119 @@
+package services
+
+import (
+	"github.com/pkg/errors"
+	"io"
+	"strings"
+
+	"github.com/oseducation/knowledge-graph/config"
+	"github.com/oseducation/knowledge-graph/log"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/oseducation/knowledge-graph/store"
+)
+
+// usageRecorder adds the tokens of every call to the language model to the usage ledger
+type usageRecorder struct {
+	store  store.Store
+	prices map[string]config.LLMPrice
+	logger *log.Logger
+}
+
+// tokenUsageReporter is implemented by the streams of the providers which report the usage at the end of the stream
+type tokenUsageReporter interface {
+	tokenUsage() (promptTokens, completionTokens int64, ok bool)
+}
+
+// meteredStream records the usage when the stream is finished or closed by the client
+type meteredStream struct {
+	ChatStream
+
+	recorder   *usageRecorder
+	usage      *model.LLMUsage
+	prompt     []ChatMessage
+	completion strings.Builder
+	recorded   bool
+}
+
+// newUsageRecorder returns nil if there is no store, calls aren't recorded then
+func newUsageRecorder(st store.Store, prices map[string]config.LLMPrice, logger *log.Logger) *usageRecorder {
+	if st == nil {
+		return nil
+	}
+	return &usageRecorder{
+		store:  st,
+		prices: prices,
+		logger: logger,
+	}
+}
+
+func (ur *usageRecorder) record(usage *model.LLMUsage) {
+	if ur == nil {
+		return
+	}
+	if price, ok := ur.prices[usage.Model]; ok {
+		usage.Cost = (float64(usage.PromptTokens)*price.PromptPerMillion + float64(usage.CompletionTokens)*price.CompletionPerMillion) / 1e6
+	}
+	if err := ur.store.LLMUsage().Save(usage); err != nil && ur.logger != nil {
+		ur.logger.Error("can't save llm usage", log.String("userID", usage.UserID), log.String("model", usage.Model), log.Err(err))
+	}
+}
+
+// meter wraps the stream to record its usage, prompt is used to estimate the tokens if the provider doesn't report them
+func (ur *usageRecorder) meter(stream ChatStream, usage *model.LLMUsage, prompt []ChatMessage) ChatStream {
+	if ur == nil {
+		return stream
+	}
+	return &meteredStream{
+		ChatStream: stream,
+		recorder:   ur,
+		usage:      usage,
+		prompt:     prompt,
+	}
+}
+
+func (ms *meteredStream) Recv() (string, error) {
+	text, err := ms.ChatStream.Recv()
+	ms.completion.WriteString(text)
+	if errors.Is(err, io.EOF) {
+		ms.record()
+	}
+	return text, err
+}
+
+func (ms *meteredStream) Close() {
+	ms.record()
+	ms.ChatStream.Close()
+}
+
+func (ms *meteredStream) record() {
+	if ms.recorded {
+		return
+	}
+	ms.recorded = true
+
+	if reporter, ok := ms.ChatStream.(tokenUsageReporter); ok {
+		if promptTokens, completionTokens, ok := reporter.tokenUsage(); ok {
+			ms.usage.PromptTokens = promptTokens
+			ms.usage.CompletionTokens = completionTokens
+			ms.recorder.record(ms.usage)
+			return
+		}
+	}
+	ms.usage.PromptTokens = estimatePromptTokens(ms.prompt)
+	ms.usage.CompletionTokens = EstimateTokens(ms.completion.String())
+	ms.usage.Estimated = true
+	ms.recorder.record(ms.usage)
+}
+
+// EstimateTokens approximates the number of tokens with the rule of thumb of 4 characters per token
+func EstimateTokens(text string) int64 {
+	return int64((len(text) + 3) / 4)
+}
+
+func estimatePromptTokens(chatMessages []ChatMessage) int64 {
+	tokens := int64(0)
+	for _, message := range chatMessages {
+		tokens += EstimateTokens(message.Content)
+	}
+	return tokens
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/oseducation/knowledge-graph/config"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	ModerationProviderOpenAI = "openai"
+	ModerationProviderRules  = "rules"
+
+	defaultModerationModel = "omni-moderation-latest"
+)
+
+// ModerationServiceInterface classifies the learners' messages and the tutor's answers
+type ModerationServiceInterface interface {
+	Moderate(text string) (*model.ModerationResult, error)
+}
+
+// ModerationRequest is a request to the OpenAI compatible moderation API
+type ModerationRequest struct {
+	Input string `json:"input"`
+	Model string `json:"model,omitempty"`
+}
+
+// ModerationResponse is a response of the OpenAI compatible moderation API
+type ModerationResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Results []struct {
+		Flagged    bool            `json:"flagged"`
+		Categories map[string]bool `json:"categories"`
+	} `json:"results"`
+}
+
+type moderationServiceDummy struct {
+}
+
+// openAIModerationService flags the whole text, the API doesn't return the flagged parts
+type openAIModerationService struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	model   string
+}
+
+type moderationRule struct {
+	category string
+	pattern  *regexp.Regexp
+	// keywords is set for the keyword rules, their flagged span is the first submatch without the surrounding boundaries
+	keywords bool
+}
+
+// rulesModerationService flags the text with local keyword and regex rules
+type rulesModerationService struct {
+	rules []moderationRule
+}
+
+// NewModerationService creates the classifier for the configured provider, nothing is flagged if the provider is empty
+func NewModerationService(settings config.ModerationSettings) (ModerationServiceInterface, error) {
+	switch settings.Provider {
+	case "":
+		return &moderationServiceDummy{}, nil
+	case ModerationProviderOpenAI:
+		if settings.BaseURL == "" {
+			settings.BaseURL = openAIURL
+		}
+		if settings.APIKey == "" {
+			settings.APIKey = os.Getenv(llmAPIKey)
+		}
+		if settings.Model == "" {
+			settings.Model = defaultModerationModel
+		}
+		return &openAIModerationService{
+			client:  &http.Client{},
+			baseURL: settings.BaseURL,
+			apiKey:  settings.APIKey,
+			model:   settings.Model,
+		}, nil
+	case ModerationProviderRules:
+		return newRulesModerationService(settings.Rules)
+	}
+	return nil, errors.Errorf("unknown moderation provider %s", settings.Provider)
+}
+
+func newRulesModerationService(rules []config.ModerationRule) (*rulesModerationService, error) {
+	service := &rulesModerationService{}
+	for _, rule := range rules {
+		if rule.Category == "" {
+			return nil, errors.New("moderation rule without category")
+		}
+		if len(rule.Keywords) > 0 {
+			keywords := make([]string, 0, len(rule.Keywords))
+			for _, keyword := range rule.Keywords {
+				keywords = append(keywords, regexp.QuoteMeta(keyword))
+			}
+			// \b is ASCII only in RE2, the boundaries are any non-word characters of any script
+			service.rules = append(service.rules, moderationRule{
+				category: rule.Category,
+				pattern:  regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}_])(` + strings.Join(keywords, "|") + `)(?:$|[^\p{L}\p{N}_])`),
+				keywords: true,
+			})
+		}
+		if rule.Pattern != "" {
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid pattern of moderation rule %s", rule.Category)
+			}
+			service.rules = append(service.rules, moderationRule{
+				category: rule.Category,
+				pattern:  pattern,
+			})
+		}
+	}
+	return service, nil
+}
+
+func (ms *moderationServiceDummy) Moderate(_ string) (*model.ModerationResult, error) {
+	return &model.ModerationResult{}, nil
+}
+
+func (ms *rulesModerationService) Moderate(text string) (*model.ModerationResult, error) {
+	result := &model.ModerationResult{}
+	categories := map[string]bool{}
+	for _, rule := range ms.rules {
+		var spans [][2]int
+		if rule.keywords {
+			spans = findKeywordSpans(rule.pattern, text)
+		} else {
+			for _, match := range rule.pattern.FindAllStringIndex(text, -1) {
+				spans = append(spans, [2]int{match[0], match[1]})
+			}
+		}
+		if len(spans) == 0 {
+			continue
+		}
+		result.Spans = append(result.Spans, spans...)
+		if !categories[rule.category] {
+			categories[rule.category] = true
+			result.Categories = append(result.Categories, rule.category)
+		}
+	}
+	sort.Slice(result.Spans, func(i, j int) bool { return result.Spans[i][0] < result.Spans[j][0] })
+	result.Flagged = len(result.Categories) > 0
+	return result, nil
+}
+
+// findKeywordSpans returns the spans of the keywords matched by the pattern's first submatch.
+// The search continues from the end of the keyword, so the boundary between adjacent keywords is matched again.
+func findKeywordSpans(pattern *regexp.Regexp, text string) [][2]int {
+	spans := [][2]int{}
+	offset := 0
+	for offset < len(text) {
+		match := pattern.FindStringSubmatchIndex(text[offset:])
+		if match == nil || match[3] == 0 {
+			break
+		}
+		spans = append(spans, [2]int{offset + match[2], offset + match[3]})
+		offset += match[3]
+	}
+	return spans
+}
+
+func (ms *openAIModerationService) Moderate(text string) (*model.ModerationResult, error) {
+	response, err := ms.sendModerationRequest(context.Background(), &ModerationRequest{
+		Input: text,
+		Model: ms.model,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't send moderation request")
+	}
+	if len(response.Results) == 0 {
+		return nil, errors.New("no results in moderation response")
+	}
+
+	result := &model.ModerationResult{Flagged: response.Results[0].Flagged}
+	for category, flagged := range response.Results[0].Categories {
+		if flagged {
+			result.Categories = append(result.Categories, category)
+		}
+	}
+	sort.Strings(result.Categories)
+	return result, nil
+}
+
+func (ms *openAIModerationService) sendModerationRequest(ctx context.Context, req *ModerationRequest) (*ModerationResponse, error) {
+	reqBytes, _ := json.Marshal(req)
+
+	endpoint := "/moderations"
+	httpReq, err := http.NewRequest("POST", ms.baseURL+endpoint, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, err
+	}
+	httpReq = httpReq.WithContext(ctx)
+	if ms.apiKey != "" {
+		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", ms.apiKey))
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+
+	res, err := sendRequest(ms.client, httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var response ModerationResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
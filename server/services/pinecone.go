@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 
+	"github.com/oseducation/knowledge-graph/store"
 	"github.com/pinecone-io/go-pinecone/pinecone"
 	"github.com/pkg/errors"
 )
@@ -18,8 +19,9 @@ type pineconeService struct {
 	connection *pinecone.IndexConnection
 }
 
-type pineconeServiceDummy struct {
-	apiKey string
+// localVectorService queries embeddings stored in the DB, used when Pinecone isn't configured
+type localVectorService struct {
+	store store.Store
 }
 
 type PineconeServiceInterface interface {
@@ -32,13 +34,11 @@ type TopicScores struct {
 	Intent string
 }
 
-func NewPineconeService() (PineconeServiceInterface, error) {
+// NewPineconeService creates Pinecone service if PINECONE_API_KEY is set, otherwise the built-in vector store is used
+func NewPineconeService(st store.Store) (PineconeServiceInterface, error) {
 	pineconeAPIKey, ok := os.LookupEnv(pineconeAPIKey)
-	if !ok {
-		return nil, errors.New("pinecone api key is not set")
-	}
-	if pineconeAPIKey == "" || pineconeAPIKey == "test" {
-		return &pineconeServiceDummy{apiKey: pineconeAPIKey}, nil
+	if !ok || pineconeAPIKey == "" || pineconeAPIKey == "test" {
+		return &localVectorService{store: st}, nil
 	}
 	pc, err := pinecone.NewClient(pinecone.NewClientParams{
 		ApiKey: pineconeAPIKey,
@@ -98,6 +98,18 @@ func (ps *pineconeService) Query(topK uint32, vector []float32) ([]TopicScores,
 	return topicScores, nil
 }
 
-func (psd *pineconeServiceDummy) Query(_ uint32, _ []float32) ([]TopicScores, error) {
-	return []TopicScores{}, nil
+func (lvs *localVectorService) Query(topK uint32, vector []float32) ([]TopicScores, error) {
+	matches, err := lvs.store.Embedding().Query(vector, int(topK))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't query embeddings")
+	}
+	topicScores := make([]TopicScores, 0, len(matches))
+	for _, match := range matches {
+		topicScores = append(topicScores, TopicScores{
+			Score:  match.Score,
+			Name:   match.Name,
+			Intent: match.Intent,
+		})
+	}
+	return topicScores, nil
 }
@@ -7,15 +7,17 @@ import (
 )
 
 type Services struct {
-	YoutubeService  YoutubeServiceInterface
-	ChatGPTService  ChatGPTServiceInterface
-	PineconeService PineconeServiceInterface
-	StripeService   StripeServiceInterface
-	EmailService    EmailServiceInterface
+	YoutubeService     YoutubeServiceInterface
+	LLMService         LLMServiceInterface
+	PineconeService    PineconeServiceInterface
+	StripeService      StripeServiceInterface
+	EmailService       EmailServiceInterface
+	CertificateService CertificateServiceInterface
+	ModerationService  ModerationServiceInterface
 }
 
-func NewServices(st store.Store, config config.EmailSettings, logger *log.Logger) (*Services, error) {
-	chatGPTService, err := NewCHhatGPTService()
+func NewServices(st store.Store, emailSettings config.EmailSettings, llmSettings config.LLMSettings, moderationSettings config.ModerationSettings, logger *log.Logger) (*Services, error) {
+	llmService, err := NewLLMService(llmSettings, st, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -23,7 +25,7 @@ func NewServices(st store.Store, config config.EmailSettings, logger *log.Logger
 	if err != nil {
 		return nil, err
 	}
-	pineconeService, err := NewPineconeService()
+	pineconeService, err := NewPineconeService(st)
 	if err != nil {
 		return nil, err
 	}
@@ -31,12 +33,22 @@ func NewServices(st store.Store, config config.EmailSettings, logger *log.Logger
 	if err != nil {
 		return nil, err
 	}
+	moderationService, err := NewModerationService(moderationSettings)
+	if err != nil {
+		return nil, err
+	}
+	certificateService, err := NewCertificateService()
+	if err != nil {
+		return nil, err
+	}
 
 	return &Services{
-		ChatGPTService:  chatGPTService,
-		YoutubeService:  youtubeService,
-		PineconeService: pineconeService,
-		StripeService:   stripeService,
-		EmailService:    NewEmailService(config),
+		LLMService:         llmService,
+		YoutubeService:     youtubeService,
+		PineconeService:    pineconeService,
+		StripeService:      stripeService,
+		EmailService:       NewEmailService(emailSettings),
+		CertificateService: certificateService,
+		ModerationService:  moderationService,
 	}, nil
 }
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// trueTypeSubsetTables are the tables PDF needs to render the glyphs of an embedded TrueType font
+var trueTypeSubsetTables = []string{"cvt ", "fpgm", "glyf", "head", "hhea", "hmtx", "loca", "maxp", "prep"}
+
+// trueTypeFont is a parsed TrueType font, only the data needed to embed the font in PDF is read
+type trueTypeFont struct {
+	tables     map[string][]byte
+	unitsPerEm int
+	ascent     int
+	descent    int
+	bbox       [4]int
+	// glyphOffsets are offsets of the glyphs in the glyf table, glyph i is glyphOffsets[i]:glyphOffsets[i+1]
+	glyphOffsets []int
+	advances     []int
+	glyphIDs     map[rune]uint16
+}
+
+func parseTrueType(data []byte) (*trueTypeFont, error) {
+	if len(data) < 12 {
+		return nil, errors.New("font is too short")
+	}
+	font := &trueTypeFont{tables: map[string][]byte{}}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		record := 12 + 16*i
+		if record+16 > len(data) {
+			return nil, errors.New("invalid table directory")
+		}
+		tag := string(data[record : record+4])
+		offset := int(binary.BigEndian.Uint32(data[record+8:]))
+		length := int(binary.BigEndian.Uint32(data[record+12:]))
+		if offset+length > len(data) {
+			return nil, errors.Errorf("table %s is out of bounds", tag)
+		}
+		font.tables[tag] = data[offset : offset+length]
+	}
+	for _, tag := range []string{"cmap", "glyf", "head", "hhea", "hmtx", "loca", "maxp"} {
+		if _, ok := font.tables[tag]; !ok {
+			return nil, errors.Errorf("no %s table in the font", tag)
+		}
+	}
+
+	head := font.tables["head"]
+	font.unitsPerEm = int(binary.BigEndian.Uint16(head[18:]))
+	for i := range font.bbox {
+		font.bbox[i] = int(int16(binary.BigEndian.Uint16(head[36+2*i:])))
+	}
+	longOffsets := binary.BigEndian.Uint16(head[50:]) == 1
+
+	hhea := font.tables["hhea"]
+	font.ascent = int(int16(binary.BigEndian.Uint16(hhea[4:])))
+	font.descent = int(int16(binary.BigEndian.Uint16(hhea[6:])))
+	numberOfHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	numGlyphs := int(binary.BigEndian.Uint16(font.tables["maxp"][4:]))
+
+	loca := font.tables["loca"]
+	font.glyphOffsets = make([]int, numGlyphs+1)
+	for i := range font.glyphOffsets {
+		if longOffsets {
+			font.glyphOffsets[i] = int(binary.BigEndian.Uint32(loca[4*i:]))
+		} else {
+			font.glyphOffsets[i] = 2 * int(binary.BigEndian.Uint16(loca[2*i:]))
+		}
+	}
+
+	hmtx := font.tables["hmtx"]
+	font.advances = make([]int, numGlyphs)
+	for i := range font.advances {
+		if i < numberOfHMetrics {
+			font.advances[i] = int(binary.BigEndian.Uint16(hmtx[4*i:]))
+		} else {
+			font.advances[i] = font.advances[numberOfHMetrics-1]
+		}
+	}
+
+	glyphIDs, err := parseCmap(font.tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+	font.glyphIDs = glyphIDs
+	return font, nil
+}
+
+// parseCmap reads the Unicode BMP subtable (platform 3, encoding 1, format 4) of the cmap table
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < numTables; i++ {
+		record := 4 + 8*i
+		platformID := binary.BigEndian.Uint16(cmap[record:])
+		encodingID := binary.BigEndian.Uint16(cmap[record+2:])
+		offset := int(binary.BigEndian.Uint32(cmap[record+4:]))
+		if platformID != 3 || encodingID != 1 || binary.BigEndian.Uint16(cmap[offset:]) != 4 {
+			continue
+		}
+
+		subtable := cmap[offset:]
+		segCount := int(binary.BigEndian.Uint16(subtable[6:])) / 2
+		endCodes := 14
+		startCodes := endCodes + 2*segCount + 2
+		idDeltas := startCodes + 2*segCount
+		idRangeOffsets := idDeltas + 2*segCount
+
+		glyphIDs := map[rune]uint16{}
+		for seg := 0; seg < segCount; seg++ {
+			end := int(binary.BigEndian.Uint16(subtable[endCodes+2*seg:]))
+			start := int(binary.BigEndian.Uint16(subtable[startCodes+2*seg:]))
+			delta := binary.BigEndian.Uint16(subtable[idDeltas+2*seg:])
+			rangeOffset := int(binary.BigEndian.Uint16(subtable[idRangeOffsets+2*seg:]))
+			for c := start; c <= end && c != 0xFFFF; c++ {
+				var glyphID uint16
+				if rangeOffset == 0 {
+					glyphID = uint16(c) + delta
+				} else {
+					index := idRangeOffsets + 2*seg + rangeOffset + 2*(c-start)
+					if index+2 > len(subtable) {
+						continue
+					}
+					glyphID = binary.BigEndian.Uint16(subtable[index:])
+					if glyphID != 0 {
+						glyphID += delta
+					}
+				}
+				if glyphID != 0 {
+					glyphIDs[rune(c)] = glyphID
+				}
+			}
+		}
+		return glyphIDs, nil
+	}
+	return nil, errors.New("no unicode cmap in the font")
+}
+
+// glyphID returns the glyph of the rune, 0 is the font's missing glyph
+func (f *trueTypeFont) glyphID(r rune) uint16 {
+	return f.glyphIDs[r]
+}
+
+// scale converts font units to the PDF glyph space of 1000 units per em
+func (f *trueTypeFont) scale(units int) int {
+	return units * 1000 / f.unitsPerEm
+}
+
+// textWidth returns width of the text of the font size in points
+func (f *trueTypeFont) textWidth(s string, size float64) float64 {
+	units := 0
+	for _, r := range s {
+		units += f.advances[f.glyphID(r)]
+	}
+	return float64(units) * size / float64(f.unitsPerEm)
+}
+
+// subset returns the font with only the glyphs used, glyph IDs are kept so the text needs no re-encoding.
+// The glyphs composed of other glyphs bring their components along.
+func (f *trueTypeFont) subset(used map[uint16]bool) []byte {
+	glyf := f.tables["glyf"]
+	keep := map[uint16]bool{0: true}
+	queue := []uint16{0}
+	for glyphID := range used {
+		if !keep[glyphID] {
+			keep[glyphID] = true
+			queue = append(queue, glyphID)
+		}
+	}
+	for len(queue) > 0 {
+		glyphID := queue[0]
+		queue = queue[1:]
+		for _, component := range f.glyphComponents(glyphID) {
+			if !keep[component] {
+				keep[component] = true
+				queue = append(queue, component)
+			}
+		}
+	}
+
+	newGlyf := &bytes.Buffer{}
+	newLoca := &bytes.Buffer{}
+	for glyphID := 0; glyphID < len(f.advances); glyphID++ {
+		_ = binary.Write(newLoca, binary.BigEndian, uint32(newGlyf.Len()))
+		if keep[uint16(glyphID)] {
+			newGlyf.Write(glyf[f.glyphOffsets[glyphID]:f.glyphOffsets[glyphID+1]])
+			for newGlyf.Len()%4 != 0 {
+				newGlyf.WriteByte(0)
+			}
+		}
+	}
+	_ = binary.Write(newLoca, binary.BigEndian, uint32(newGlyf.Len()))
+
+	head := append([]byte{}, f.tables["head"]...)
+	// checkSumAdjustment is recalculated below, loca offsets are always long in the subset
+	binary.BigEndian.PutUint32(head[8:], 0)
+	binary.BigEndian.PutUint16(head[50:], 1)
+
+	tables := map[string][]byte{}
+	for _, tag := range trueTypeSubsetTables {
+		if table, ok := f.tables[tag]; ok {
+			tables[tag] = table
+		}
+	}
+	tables["glyf"] = newGlyf.Bytes()
+	tables["loca"] = newLoca.Bytes()
+	tables["head"] = head
+	return buildTrueType(tables)
+}
+
+// glyphComponents returns the glyphs a composite glyph is made of
+func (f *trueTypeFont) glyphComponents(glyphID uint16) []uint16 {
+	const (
+		argsAreWords    = 0x0001
+		haveScale       = 0x0008
+		moreComponents  = 0x0020
+		haveXYScale     = 0x0040
+		haveTwoByTwo    = 0x0080
+		glyphHeaderSize = 10
+	)
+	if int(glyphID) >= len(f.advances) {
+		return nil
+	}
+	glyph := f.tables["glyf"][f.glyphOffsets[glyphID]:f.glyphOffsets[glyphID+1]]
+	if len(glyph) < glyphHeaderSize || int16(binary.BigEndian.Uint16(glyph)) >= 0 {
+		return nil
+	}
+
+	components := []uint16{}
+	offset := glyphHeaderSize
+	for offset+4 <= len(glyph) {
+		flags := binary.BigEndian.Uint16(glyph[offset:])
+		components = append(components, binary.BigEndian.Uint16(glyph[offset+2:]))
+		offset += 4
+		if flags&argsAreWords != 0 {
+			offset += 4
+		} else {
+			offset += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			offset += 2
+		case flags&haveXYScale != 0:
+			offset += 4
+		case flags&haveTwoByTwo != 0:
+			offset += 8
+		}
+		if flags&moreComponents == 0 {
+			break
+		}
+	}
+	return components
+}
+
+// buildTrueType writes the tables into a font file
+func buildTrueType(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= len(tags) {
+		entrySelector++
+	}
+	searchRange := 16 << entrySelector
+
+	font := &bytes.Buffer{}
+	_ = binary.Write(font, binary.BigEndian, []uint16{1, 0, uint16(len(tags)), uint16(searchRange), uint16(entrySelector), uint16(len(tags)*16 - searchRange)})
+	offset := 12 + 16*len(tags)
+	for _, tag := range tags {
+		table := tables[tag]
+		font.WriteString(tag)
+		_ = binary.Write(font, binary.BigEndian, []uint32{trueTypeChecksum(table), uint32(offset), uint32(len(table))})
+		offset += (len(table) + 3) &^ 3
+	}
+	headOffset := 0
+	for _, tag := range tags {
+		if tag == "head" {
+			headOffset = font.Len()
+		}
+		font.Write(tables[tag])
+		for font.Len()%4 != 0 {
+			font.WriteByte(0)
+		}
+	}
+
+	data := font.Bytes()
+	binary.BigEndian.PutUint32(data[headOffset+8:], 0xB1B0AFBA-trueTypeChecksum(data))
+	return data
+}
+
+func trueTypeChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		word := make([]byte, 4)
+		copy(word, data[i:])
+		sum += binary.BigEndian.Uint32(word)
+	}
+	return sum
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readTrueTypeTables reads the table directory of the font and checks the checksums of the tables and of the whole font
+func readTrueTypeTables(t *testing.T, data []byte) map[string][]byte {
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	tables := map[string][]byte{}
+	for i := 0; i < numTables; i++ {
+		record := data[12+16*i:]
+		tag := string(record[:4])
+		checksum := binary.BigEndian.Uint32(record[4:])
+		offset := int(binary.BigEndian.Uint32(record[8:]))
+		length := int(binary.BigEndian.Uint32(record[12:]))
+		require.Zero(t, offset%4, "table %s isn't aligned", tag)
+		require.LessOrEqual(t, offset+length, len(data), "table %s is out of bounds", tag)
+		table := data[offset : offset+length]
+		if tag == "head" {
+			// checkSumAdjustment isn't counted in the checksum of the head table
+			table = append([]byte{}, table...)
+			binary.BigEndian.PutUint32(table[8:], 0)
+		}
+		require.Equal(t, checksum, trueTypeChecksum(table), "checksum of table %s", tag)
+		tables[tag] = data[offset : offset+length]
+	}
+	require.Equal(t, uint32(0xB1B0AFBA), trueTypeChecksum(data))
+	return tables
+}
+
+func TestParseTrueType(t *testing.T) {
+	for name, data := range map[string][]byte{"regular": dejaVuSans, "bold": dejaVuSansBold} {
+		t.Run(name, func(t *testing.T) {
+			font, err := parseTrueType(data)
+			require.NoError(t, err)
+			require.Equal(t, 2048, font.unitsPerEm)
+			require.Greater(t, font.ascent, 0)
+			require.Less(t, font.descent, 0)
+
+			for _, r := range "Az09ბ" {
+				require.NotZero(t, font.glyphID(r), "no glyph of %q", r)
+			}
+			// private use characters have no glyphs
+			require.Zero(t, font.glyphID('\uE000'))
+
+			require.Greater(t, font.textWidth("ა", 10), 0.0)
+			require.InDelta(t, 2*font.textWidth("ა", 10), font.textWidth("აა", 10), 1e-9)
+			require.InDelta(t, 2*font.textWidth("A", 10), font.textWidth("A", 20), 1e-9)
+		})
+	}
+
+	t.Run("invalid font", func(t *testing.T) {
+		_, err := parseTrueType([]byte("not a font"))
+		require.Error(t, err)
+		_, err = parseTrueType(dejaVuSans[:1000])
+		require.Error(t, err)
+	})
+}
+
+func TestTrueTypeSubset(t *testing.T) {
+	font, err := parseTrueType(dejaVuSans)
+	require.NoError(t, err)
+
+	// accented letters of DejaVu are composed of the letter and the accent glyphs
+	composite := uint16(0)
+	for _, r := range "éÄőŠ" {
+		if len(font.glyphComponents(font.glyphID(r))) > 0 {
+			composite = font.glyphID(r)
+			break
+		}
+	}
+	require.NotZero(t, composite, "no composite glyph found")
+
+	used := map[uint16]bool{composite: true}
+	for _, r := range "Lovelace ნიკო" {
+		used[font.glyphID(r)] = true
+	}
+	kept := map[uint16]bool{0: true}
+	for glyphID := range used {
+		kept[glyphID] = true
+	}
+	for _, component := range font.glyphComponents(composite) {
+		kept[component] = true
+	}
+
+	data := font.subset(used)
+	require.Less(t, len(data), len(dejaVuSans)/10)
+	tables := readTrueTypeTables(t, data)
+	for _, tag := range []string{"glyf", "head", "hhea", "hmtx", "loca", "maxp"} {
+		require.Contains(t, tables, tag)
+	}
+	require.Equal(t, uint16(1), binary.BigEndian.Uint16(tables["head"][50:]), "loca isn't long")
+	require.Equal(t, font.tables["hmtx"], tables["hmtx"])
+
+	// glyph IDs are kept, the unused glyphs are empty
+	numGlyphs := int(binary.BigEndian.Uint16(tables["maxp"][4:]))
+	require.Equal(t, len(font.advances), numGlyphs)
+	loca := tables["loca"]
+	require.Len(t, loca, 4*(numGlyphs+1))
+	for glyphID := 0; glyphID < numGlyphs; glyphID++ {
+		start := binary.BigEndian.Uint32(loca[4*glyphID:])
+		end := binary.BigEndian.Uint32(loca[4*glyphID+4:])
+		glyph := tables["glyf"][start:end]
+		if !kept[uint16(glyphID)] {
+			require.Empty(t, glyph, "glyph %d isn't removed", glyphID)
+			continue
+		}
+		original := font.tables["glyf"][font.glyphOffsets[glyphID]:font.glyphOffsets[glyphID+1]]
+		require.Equal(t, original, glyph[:len(original)], "glyph %d is changed", glyphID)
+	}
+}
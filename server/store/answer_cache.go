@@ -0,0 +1,257 @@
+package store
+
+import (
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+type sqlCachedAnswer struct {
+	model.CachedAnswer
+	CitationsJSON string `db:"citations"`
+	VectorJSON    string `db:"embedding"`
+}
+
+// AnswerCacheStore is an interface to store the tutor's answers served to similar questions
+type AnswerCacheStore interface {
+	Save(answer *model.CachedAnswer) (*model.CachedAnswer, error)
+	Get(id string) (*model.CachedAnswer, error)
+	GetAnswers(options *model.CachedAnswerGetOptions) ([]*model.CachedAnswer, error)
+	Update(answer *model.CachedAnswer) error
+	Delete(id string) error
+	DeleteForNode(nodeID string, withPinned bool) error
+	DeleteExpired(nodeID string, contentUpdatedAt int64) error
+	FindSimilar(vector []float32, nodeID, tutorPersonality, language, tier string, minScore float32) (*model.CachedAnswer, error)
+	IncrementHits(id string) error
+}
+
+// SQLAnswerCacheStore is a struct to store cached answers.
+// Similarity is computed in memory, there are few answers per node, personality, language and tier.
+type SQLAnswerCacheStore struct {
+	sqlStore     *SQLStore
+	answerSelect sq.SelectBuilder
+}
+
+// NewAnswerCacheStore creates a new store for cached answers.
+func NewAnswerCacheStore(db *SQLStore) AnswerCacheStore {
+	answerSelect := db.builder.
+		Select(
+			"ac.id",
+			"ac.node_id",
+			"ac.tutor_personality",
+			"ac.language",
+			"ac.tier",
+			"ac.question",
+			"ac.answer",
+			"ac.citations",
+			"ac.pinned",
+			"ac.hits",
+			"ac.created_at",
+			"ac.updated_at",
+		).
+		From("answer_cache ac")
+
+	return &SQLAnswerCacheStore{
+		sqlStore:     db,
+		answerSelect: answerSelect,
+	}
+}
+
+// Save saves the answer in the cache
+func (acs *SQLAnswerCacheStore) Save(answer *model.CachedAnswer) (*model.CachedAnswer, error) {
+	answer.BeforeSave()
+	if err := answer.IsValid(); err != nil {
+		return nil, err
+	}
+	citationsJSON, err := json.Marshal(answer.Citations)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal citations of cached answer: %s", answer.ID)
+	}
+	vectorJSON, err := json.Marshal(answer.Vector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal embedding of cached answer: %s", answer.ID)
+	}
+
+	if _, err := acs.sqlStore.execBuilder(acs.sqlStore.db, acs.sqlStore.builder.
+		Insert("answer_cache").
+		SetMap(map[string]interface{}{
+			"id":                answer.ID,
+			"node_id":           answer.NodeID,
+			"tutor_personality": answer.TutorPersonality,
+			"language":          answer.Language,
+			"tier":              answer.Tier,
+			"question":          answer.Question,
+			"answer":            answer.Answer,
+			"citations":         string(citationsJSON),
+			"embedding":         string(vectorJSON),
+			"pinned":            answer.Pinned,
+			"hits":              answer.Hits,
+			"created_at":        answer.CreatedAt,
+			"updated_at":        answer.UpdatedAt,
+		})); err != nil {
+		return nil, errors.Wrapf(err, "can't save cached answer of node: %s", answer.NodeID)
+	}
+	return answer, nil
+}
+
+// Get gets the cached answer by id
+func (acs *SQLAnswerCacheStore) Get(id string) (*model.CachedAnswer, error) {
+	var answer sqlCachedAnswer
+	if err := acs.sqlStore.getBuilder(acs.sqlStore.db, &answer, acs.answerSelect.Where(sq.Eq{"ac.id": id})); err != nil {
+		return nil, errors.Wrapf(err, "can't get cached answer by id: %s", id)
+	}
+	return answer.toModel()
+}
+
+// GetAnswers gets the cached answers, the most served come first
+func (acs *SQLAnswerCacheStore) GetAnswers(options *model.CachedAnswerGetOptions) ([]*model.CachedAnswer, error) {
+	query := acs.answerSelect.OrderBy("ac.hits DESC", "ac.created_at DESC")
+	if options.NodeID != "" {
+		query = query.Where(sq.Eq{"ac.node_id": options.NodeID})
+	}
+	if options.PerPage > 0 {
+		query = query.Limit(uint64(options.PerPage)).Offset(uint64(options.Page * options.PerPage))
+	}
+
+	var answers []*sqlCachedAnswer
+	if err := acs.sqlStore.selectBuilder(acs.sqlStore.db, &answers, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get cached answers with options %v", options)
+	}
+	result := make([]*model.CachedAnswer, 0, len(answers))
+	for _, a := range answers {
+		answer, err := a.toModel()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, answer)
+	}
+	return result, nil
+}
+
+// Update updates the answer, its citations and pinning
+func (acs *SQLAnswerCacheStore) Update(answer *model.CachedAnswer) error {
+	if err := answer.IsValid(); err != nil {
+		return err
+	}
+	citationsJSON, err := json.Marshal(answer.Citations)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal citations of cached answer: %s", answer.ID)
+	}
+	answer.UpdatedAt = model.GetMillis()
+
+	if _, err := acs.sqlStore.execBuilder(acs.sqlStore.db, acs.sqlStore.builder.
+		Update("answer_cache").
+		SetMap(map[string]interface{}{
+			"answer":     answer.Answer,
+			"citations":  string(citationsJSON),
+			"pinned":     answer.Pinned,
+			"updated_at": answer.UpdatedAt,
+		}).
+		Where(sq.Eq{"id": answer.ID})); err != nil {
+		return errors.Wrapf(err, "can't update cached answer: %s", answer.ID)
+	}
+	return nil
+}
+
+// Delete invalidates the cached answer
+func (acs *SQLAnswerCacheStore) Delete(id string) error {
+	if _, err := acs.sqlStore.execBuilder(acs.sqlStore.db, acs.sqlStore.builder.
+		Delete("answer_cache").
+		Where(sq.Eq{"id": id})); err != nil {
+		return errors.Wrapf(err, "can't delete cached answer: %s", id)
+	}
+	return nil
+}
+
+// DeleteForNode invalidates the cached answers of the node, pinned answers are kept unless withPinned is set
+func (acs *SQLAnswerCacheStore) DeleteForNode(nodeID string, withPinned bool) error {
+	query := acs.sqlStore.builder.
+		Delete("answer_cache").
+		Where(sq.Eq{"node_id": nodeID})
+	if !withPinned {
+		query = query.Where(sq.Eq{"pinned": false})
+	}
+	if _, err := acs.sqlStore.execBuilder(acs.sqlStore.db, query); err != nil {
+		return errors.Wrapf(err, "can't delete cached answers of node: %s", nodeID)
+	}
+	return nil
+}
+
+// DeleteExpired deletes not pinned answers of the node cached before its content was updated
+func (acs *SQLAnswerCacheStore) DeleteExpired(nodeID string, contentUpdatedAt int64) error {
+	if _, err := acs.sqlStore.execBuilder(acs.sqlStore.db, acs.sqlStore.builder.
+		Delete("answer_cache").
+		Where(sq.Eq{"node_id": nodeID, "pinned": false}).
+		Where(sq.Lt{"created_at": contentUpdatedAt})); err != nil {
+		return errors.Wrapf(err, "can't delete expired cached answers of node: %s", nodeID)
+	}
+	return nil
+}
+
+// FindSimilar returns the cached answer to the most similar question with similarity at least minScore, nil if there is none
+func (acs *SQLAnswerCacheStore) FindSimilar(vector []float32, nodeID, tutorPersonality, language, tier string, minScore float32) (*model.CachedAnswer, error) {
+	if len(vector) == 0 {
+		return nil, nil
+	}
+
+	var answers []*sqlCachedAnswer
+	if err := acs.sqlStore.selectBuilder(acs.sqlStore.db, &answers, acs.answerSelect.
+		Column("ac.embedding").
+		Where(sq.Eq{
+			"ac.node_id":           nodeID,
+			"ac.tutor_personality": tutorPersonality,
+			"ac.language":          language,
+			"ac.tier":              tier,
+		})); err != nil {
+		return nil, errors.Wrapf(err, "can't get cached answers of node: %s", nodeID)
+	}
+
+	var best *sqlCachedAnswer
+	bestScore := minScore
+	for _, a := range answers {
+		var stored []float32
+		if err := json.Unmarshal([]byte(a.VectorJSON), &stored); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal embedding of cached answer: %s", a.ID)
+		}
+		if len(stored) != len(vector) {
+			continue
+		}
+		if score := cosineSimilarity(vector, stored); score >= bestScore {
+			best = a
+			bestScore = score
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	answer, err := best.toModel()
+	if err != nil {
+		return nil, err
+	}
+	answer.Score = bestScore
+	return answer, nil
+}
+
+// IncrementHits counts serving of the cached answer
+func (acs *SQLAnswerCacheStore) IncrementHits(id string) error {
+	if _, err := acs.sqlStore.execBuilder(acs.sqlStore.db, acs.sqlStore.builder.
+		Update("answer_cache").
+		Set("hits", sq.Expr("hits + 1")).
+		Where(sq.Eq{"id": id})); err != nil {
+		return errors.Wrapf(err, "can't increment hits of cached answer: %s", id)
+	}
+	return nil
+}
+
+func (a *sqlCachedAnswer) toModel() (*model.CachedAnswer, error) {
+	answer := a.CachedAnswer
+	answer.Citations = []*model.Citation{}
+	if a.CitationsJSON != "" {
+		if err := json.Unmarshal([]byte(a.CitationsJSON), &answer.Citations); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal citations of cached answer: %s", a.ID)
+		}
+	}
+	return &answer, nil
+}
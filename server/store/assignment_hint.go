@@ -0,0 +1,76 @@
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// AssignmentHintStore is an interface to store the hints learners got on their assignments
+type AssignmentHintStore interface {
+	Save(hint *model.AssignmentHint) error
+	GetLevel(userID, nodeID string) (int, error)
+	GetLevelsForUser(userID string) ([]*model.AssignmentHint, error)
+}
+
+// SQLAssignmentHintStore is a struct to store assignment hints
+type SQLAssignmentHintStore struct {
+	sqlStore *SQLStore
+}
+
+// NewAssignmentHintStore creates a new store for assignment hints.
+func NewAssignmentHintStore(db *SQLStore) AssignmentHintStore {
+	return &SQLAssignmentHintStore{
+		sqlStore: db,
+	}
+}
+
+// Save saves the hint
+func (ahs *SQLAssignmentHintStore) Save(hint *model.AssignmentHint) error {
+	hint.BeforeSave()
+	if err := hint.IsValid(); err != nil {
+		return err
+	}
+
+	if _, err := ahs.sqlStore.execBuilder(ahs.sqlStore.db, ahs.sqlStore.builder.
+		Insert("assignment_hints").
+		SetMap(map[string]interface{}{
+			"user_id":    hint.UserID,
+			"node_id":    hint.NodeID,
+			"level":      hint.Level,
+			"created_at": hint.CreatedAt,
+		})); err != nil {
+		return errors.Wrapf(err, "can't save assignment hint for user %s and node %s", hint.UserID, hint.NodeID)
+	}
+	return nil
+}
+
+// GetLevel gets the highest level of the hints the user got on the assignment, 0 if none
+func (ahs *SQLAssignmentHintStore) GetLevel(userID, nodeID string) (int, error) {
+	var level int
+	if err := ahs.sqlStore.getBuilder(ahs.sqlStore.db, &level, ahs.sqlStore.builder.
+		Select("COALESCE(MAX(ah.level), 0)").
+		From("assignment_hints ah").
+		Where(sq.Eq{"ah.user_id": userID, "ah.node_id": nodeID})); err != nil {
+		return 0, errors.Wrapf(err, "can't get hint level for user %s and node %s", userID, nodeID)
+	}
+	return level, nil
+}
+
+// GetLevelsForUser gets the highest hint level of every assignment the user got hints on, with the time of the last hint
+func (ahs *SQLAssignmentHintStore) GetLevelsForUser(userID string) ([]*model.AssignmentHint, error) {
+	hints := []*model.AssignmentHint{}
+	if err := ahs.sqlStore.selectBuilder(ahs.sqlStore.db, &hints, ahs.sqlStore.builder.
+		Select(
+			"ah.user_id",
+			"ah.node_id",
+			"MAX(ah.level) AS level",
+			"MAX(ah.created_at) AS created_at",
+		).
+		From("assignment_hints ah").
+		Where(sq.Eq{"ah.user_id": userID}).
+		GroupBy("ah.user_id", "ah.node_id")); err != nil {
+		return nil, errors.Wrapf(err, "can't get hint levels for user %s", userID)
+	}
+	return hints, nil
+}
@@ -0,0 +1,117 @@
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// CertificateStore is an interface to crud certificates
+type CertificateStore interface {
+	Save(certificate *model.Certificate) error
+	Get(id string) (*model.Certificate, error)
+	GetForUser(userID string) ([]*model.Certificate, error)
+	Revoke(id string) error
+}
+
+// SQLCertificateStore is a struct to store certificates
+type SQLCertificateStore struct {
+	sqlStore          *SQLStore
+	certificateSelect sq.SelectBuilder
+}
+
+// NewCertificateStore creates a new store for certificates.
+func NewCertificateStore(db *SQLStore) CertificateStore {
+	certificateSelect := db.builder.
+		Select(
+			"c.id",
+			"c.user_id",
+			"c.node_id",
+			"c.learner_name",
+			"c.goal_name",
+			"c.topics",
+			"c.created_at",
+			"c.revoked_at",
+		).
+		From("certificates c")
+
+	return &SQLCertificateStore{
+		sqlStore:          db,
+		certificateSelect: certificateSelect,
+	}
+}
+
+// Save saves certificate in the DB, user gets a single certificate per goal
+func (cs *SQLCertificateStore) Save(certificate *model.Certificate) error {
+	if err := certificate.BeforeSave(); err != nil {
+		return err
+	}
+	if err := certificate.IsValid(); err != nil {
+		return err
+	}
+
+	_, err := cs.sqlStore.execBuilder(cs.sqlStore.db, cs.sqlStore.builder.
+		Insert("certificates").
+		SetMap(map[string]interface{}{
+			"id":           certificate.ID,
+			"user_id":      certificate.UserID,
+			"node_id":      certificate.NodeID,
+			"learner_name": certificate.LearnerName,
+			"goal_name":    certificate.GoalName,
+			"topics":       certificate.TopicsJSON,
+			"created_at":   certificate.CreatedAt,
+			"revoked_at":   certificate.RevokedAt,
+		}).
+		Suffix("ON CONFLICT (user_id, node_id) DO NOTHING"))
+	if err != nil {
+		return errors.Wrapf(err, "can't save certificate for user: %s node: %s", certificate.UserID, certificate.NodeID)
+	}
+	return nil
+}
+
+// Get gets certificate by id
+func (cs *SQLCertificateStore) Get(id string) (*model.Certificate, error) {
+	var certificate model.Certificate
+	if err := cs.sqlStore.getBuilder(cs.sqlStore.db, &certificate,
+		cs.certificateSelect.Where(sq.Eq{"c.id": id})); err != nil {
+		return nil, errors.Wrapf(err, "can't get certificate by id: %s", id)
+	}
+	if err := certificate.AfterLoad(); err != nil {
+		return nil, err
+	}
+	return &certificate, nil
+}
+
+// GetForUser gets all the certificates of the user
+func (cs *SQLCertificateStore) GetForUser(userID string) ([]*model.Certificate, error) {
+	var certificates []*model.Certificate
+	if err := cs.sqlStore.selectBuilder(cs.sqlStore.db, &certificates,
+		cs.certificateSelect.
+			Where(sq.Eq{"c.user_id": userID}).
+			OrderBy("c.created_at DESC")); err != nil {
+		return nil, errors.Wrapf(err, "can't get certificates for user: %s", userID)
+	}
+	for _, certificate := range certificates {
+		if err := certificate.AfterLoad(); err != nil {
+			return nil, err
+		}
+	}
+	return certificates, nil
+}
+
+// Revoke marks certificate as revoked
+func (cs *SQLCertificateStore) Revoke(id string) error {
+	_, err := cs.sqlStore.execBuilder(cs.sqlStore.db, cs.sqlStore.builder.
+		Update("certificates").
+		SetMap(map[string]interface{}{
+			"revoked_at": model.GetMillis(),
+		}).
+		Where(sq.And{
+			sq.Eq{"id": id},
+			sq.Eq{"revoked_at": 0},
+		}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to revoke certificate: %s", id)
+	}
+	return nil
+}
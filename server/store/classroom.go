@@ -0,0 +1,242 @@
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// ClassroomStore is an interface to crud classrooms, their students and assigned goals
+type ClassroomStore interface {
+	Save(classroom *model.Classroom) (*model.Classroom, error)
+	Get(id string) (*model.Classroom, error)
+	GetByJoinCode(joinCode string) (*model.Classroom, error)
+	GetForTeacher(teacherID string) ([]*model.Classroom, error)
+	GetForStudent(userID string) ([]*model.Classroom, error)
+	Delete(id string) error
+	AddStudent(classroomID, userID string) (bool, error)
+	RemoveStudent(classroomID, userID string) error
+	GetStudents(classroomID string) ([]*model.User, error)
+	SaveGoal(goal *model.ClassroomGoal) error
+	DeleteGoal(classroomID, nodeID string) error
+	GetGoals(classroomID string) ([]*model.ClassroomGoal, error)
+}
+
+// SQLClassroomStore is a struct to store classrooms
+type SQLClassroomStore struct {
+	sqlStore        *SQLStore
+	classroomSelect sq.SelectBuilder
+}
+
+// NewClassroomStore creates a new store for classrooms.
+func NewClassroomStore(db *SQLStore) ClassroomStore {
+	classroomSelect := db.builder.
+		Select(
+			"c.id",
+			"c.teacher_id",
+			"c.name",
+			"c.join_code",
+			"c.created_at",
+			"c.deleted_at",
+		).
+		From("classrooms c")
+
+	return &SQLClassroomStore{
+		sqlStore:        db,
+		classroomSelect: classroomSelect,
+	}
+}
+
+// Save saves classroom in the DB
+func (cs *SQLClassroomStore) Save(classroom *model.Classroom) (*model.Classroom, error) {
+	classroom.BeforeSave()
+	if err := classroom.IsValid(); err != nil {
+		return nil, err
+	}
+
+	_, err := cs.sqlStore.execBuilder(cs.sqlStore.db, cs.sqlStore.builder.
+		Insert("classrooms").
+		SetMap(map[string]interface{}{
+			"id":         classroom.ID,
+			"teacher_id": classroom.TeacherID,
+			"name":       classroom.Name,
+			"join_code":  classroom.JoinCode,
+			"created_at": classroom.CreatedAt,
+			"deleted_at": 0,
+		}))
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't save classroom: %s", classroom.Name)
+	}
+	return classroom, nil
+}
+
+// Get gets classroom by id
+func (cs *SQLClassroomStore) Get(id string) (*model.Classroom, error) {
+	var classroom model.Classroom
+	if err := cs.sqlStore.getBuilder(cs.sqlStore.db, &classroom, cs.classroomSelect.
+		Where(sq.And{
+			sq.Eq{"c.id": id},
+			sq.Eq{"c.deleted_at": 0},
+		})); err != nil {
+		return nil, errors.Wrapf(err, "can't get classroom by id: %s", id)
+	}
+	return &classroom, nil
+}
+
+// GetByJoinCode gets classroom by the join code
+func (cs *SQLClassroomStore) GetByJoinCode(joinCode string) (*model.Classroom, error) {
+	var classroom model.Classroom
+	if err := cs.sqlStore.getBuilder(cs.sqlStore.db, &classroom, cs.classroomSelect.
+		Where(sq.And{
+			sq.Eq{"c.join_code": joinCode},
+			sq.Eq{"c.deleted_at": 0},
+		})); err != nil {
+		return nil, errors.Wrapf(err, "can't get classroom by join code: %s", joinCode)
+	}
+	return &classroom, nil
+}
+
+// GetForTeacher gets classrooms created by the teacher
+func (cs *SQLClassroomStore) GetForTeacher(teacherID string) ([]*model.Classroom, error) {
+	var classrooms []*model.Classroom
+	if err := cs.sqlStore.selectBuilder(cs.sqlStore.db, &classrooms, cs.classroomSelect.
+		Where(sq.And{
+			sq.Eq{"c.teacher_id": teacherID},
+			sq.Eq{"c.deleted_at": 0},
+		}).
+		OrderBy("c.created_at")); err != nil {
+		return nil, errors.Wrapf(err, "can't get classrooms for teacher: %s", teacherID)
+	}
+	return classrooms, nil
+}
+
+// GetForStudent gets classrooms the user has joined
+func (cs *SQLClassroomStore) GetForStudent(userID string) ([]*model.Classroom, error) {
+	var classrooms []*model.Classroom
+	if err := cs.sqlStore.selectBuilder(cs.sqlStore.db, &classrooms, cs.classroomSelect.
+		Join("classroom_students cs ON cs.classroom_id = c.id").
+		Where(sq.And{
+			sq.Eq{"cs.user_id": userID},
+			sq.Eq{"c.deleted_at": 0},
+		}).
+		OrderBy("cs.joined_at")); err != nil {
+		return nil, errors.Wrapf(err, "can't get classrooms for student: %s", userID)
+	}
+	return classrooms, nil
+}
+
+// Delete soft deletes the classroom
+func (cs *SQLClassroomStore) Delete(id string) error {
+	if _, err := cs.sqlStore.execBuilder(cs.sqlStore.db, cs.sqlStore.builder.
+		Update("classrooms").
+		Set("deleted_at", model.GetMillis()).
+		Where(sq.Eq{"id": id})); err != nil {
+		return errors.Wrapf(err, "can't delete classroom: %s", id)
+	}
+	return nil
+}
+
+// AddStudent adds the user to the classroom, returns false if user has already joined
+func (cs *SQLClassroomStore) AddStudent(classroomID, userID string) (bool, error) {
+	result, err := cs.sqlStore.execBuilder(cs.sqlStore.db, cs.sqlStore.builder.
+		Insert("classroom_students").
+		SetMap(map[string]interface{}{
+			"classroom_id": classroomID,
+			"user_id":      userID,
+			"joined_at":    model.GetMillis(),
+		}).
+		Suffix("ON CONFLICT (classroom_id, user_id) DO NOTHING"))
+	if err != nil {
+		return false, errors.Wrapf(err, "can't add student %s to classroom: %s", userID, classroomID)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "can't get affected rows")
+	}
+	return rows > 0, nil
+}
+
+// RemoveStudent removes the user from the classroom
+func (cs *SQLClassroomStore) RemoveStudent(classroomID, userID string) error {
+	if _, err := cs.sqlStore.execBuilder(cs.sqlStore.db, cs.sqlStore.builder.
+		Delete("classroom_students").
+		Where(sq.And{
+			sq.Eq{"classroom_id": classroomID},
+			sq.Eq{"user_id": userID},
+		})); err != nil {
+		return errors.Wrapf(err, "can't remove student %s from classroom: %s", userID, classroomID)
+	}
+	return nil
+}
+
+// GetStudents gets students of the classroom
+func (cs *SQLClassroomStore) GetStudents(classroomID string) ([]*model.User, error) {
+	var students []*model.User
+	query := cs.sqlStore.builder.
+		Select("u.id", "u.username", "u.first_name", "u.last_name").
+		From("classroom_students cs").
+		Join("users u ON u.id = cs.user_id").
+		Where(sq.And{
+			sq.Eq{"cs.classroom_id": classroomID},
+			sq.Eq{"u.deleted_at": 0},
+		}).
+		OrderBy("cs.joined_at")
+	if err := cs.sqlStore.selectBuilder(cs.sqlStore.db, &students, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get students of classroom: %s", classroomID)
+	}
+	return students, nil
+}
+
+// SaveGoal assigns the goal to the classroom or updates its due date
+func (cs *SQLClassroomStore) SaveGoal(goal *model.ClassroomGoal) error {
+	_, err := cs.sqlStore.execBuilder(cs.sqlStore.db, cs.sqlStore.builder.
+		Insert("classroom_goals").
+		SetMap(map[string]interface{}{
+			"classroom_id": goal.ClassroomID,
+			"node_id":      goal.NodeID,
+			"due_date":     goal.DueDate,
+			"created_at":   model.GetMillis(),
+		}).
+		SuffixExpr(sq.Expr(
+			"ON CONFLICT (classroom_id, node_id) DO UPDATE SET due_date = ?",
+			goal.DueDate),
+		))
+	if err != nil {
+		return errors.Wrapf(err, "can't save goal %s for classroom: %s", goal.NodeID, goal.ClassroomID)
+	}
+	return nil
+}
+
+// DeleteGoal unassigns the goal from the classroom
+func (cs *SQLClassroomStore) DeleteGoal(classroomID, nodeID string) error {
+	if _, err := cs.sqlStore.execBuilder(cs.sqlStore.db, cs.sqlStore.builder.
+		Delete("classroom_goals").
+		Where(sq.And{
+			sq.Eq{"classroom_id": classroomID},
+			sq.Eq{"node_id": nodeID},
+		})); err != nil {
+		return errors.Wrapf(err, "can't delete goal %s from classroom: %s", nodeID, classroomID)
+	}
+	return nil
+}
+
+// GetGoals gets goals assigned to the classroom ordered by due date
+func (cs *SQLClassroomStore) GetGoals(classroomID string) ([]*model.ClassroomGoal, error) {
+	var goals []*model.ClassroomGoal
+	query := cs.sqlStore.builder.
+		Select(
+			"cg.classroom_id",
+			"cg.node_id",
+			"n.name AS node_name",
+			"cg.due_date",
+			"cg.created_at",
+		).
+		From("classroom_goals cg").
+		Join("nodes n ON n.id = cg.node_id").
+		Where(sq.Eq{"cg.classroom_id": classroomID}).
+		OrderBy("cg.due_date", "cg.created_at")
+	if err := cs.sqlStore.selectBuilder(cs.sqlStore.db, &goals, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get goals of classroom: %s", classroomID)
+	}
+	return goals, nil
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// CompletionPolicyStore is an interface to crud completion policies of the nodes
+type CompletionPolicyStore interface {
+	Save(policy *model.CompletionPolicy) (*model.CompletionPolicy, error)
+	Get(nodeID string) (*model.CompletionPolicy, error)
+	Delete(nodeID string) error
+}
+
+// SQLCompletionPolicyStore is a struct to store completion policies
+type SQLCompletionPolicyStore struct {
+	sqlStore     *SQLStore
+	policySelect sq.SelectBuilder
+}
+
+// NewCompletionPolicyStore creates a new store for completion policies.
+func NewCompletionPolicyStore(db *SQLStore) CompletionPolicyStore {
+	policySelect := db.builder.
+		Select(
+			"cp.node_id",
+			"cp.min_videos_watched",
+			"cp.min_questions_answered",
+			"cp.min_correct_percent",
+			"cp.created_at",
+			"cp.updated_at",
+		).
+		From("completion_policies cp")
+
+	return &SQLCompletionPolicyStore{
+		sqlStore:     db,
+		policySelect: policySelect,
+	}
+}
+
+// Save creates or overrides the policy of the node
+func (cps *SQLCompletionPolicyStore) Save(policy *model.CompletionPolicy) (*model.CompletionPolicy, error) {
+	policy.BeforeSave()
+	if err := policy.IsValid(); err != nil {
+		return nil, err
+	}
+
+	_, err := cps.sqlStore.execBuilder(cps.sqlStore.db, cps.sqlStore.builder.
+		Insert("completion_policies").
+		SetMap(map[string]interface{}{
+			"node_id":                policy.NodeID,
+			"min_videos_watched":     policy.MinVideosWatched,
+			"min_questions_answered": policy.MinQuestionsAnswered,
+			"min_correct_percent":    policy.MinCorrectPercent,
+			"created_at":             policy.CreatedAt,
+			"updated_at":             policy.UpdatedAt,
+		}).
+		SuffixExpr(sq.Expr(
+			"ON CONFLICT (node_id) DO UPDATE SET min_videos_watched = ?, min_questions_answered = ?, min_correct_percent = ?, updated_at = ?",
+			policy.MinVideosWatched, policy.MinQuestionsAnswered, policy.MinCorrectPercent, policy.UpdatedAt),
+		))
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't save completion policy for node: %s", policy.NodeID)
+	}
+	return policy, nil
+}
+
+// Get gets policy of the node
+func (cps *SQLCompletionPolicyStore) Get(nodeID string) (*model.CompletionPolicy, error) {
+	var policy model.CompletionPolicy
+	if err := cps.sqlStore.getBuilder(cps.sqlStore.db, &policy, cps.policySelect.Where(sq.Eq{"cp.node_id": nodeID})); err != nil {
+		return nil, errors.Wrapf(err, "can't get completion policy for node: %s", nodeID)
+	}
+	return &policy, nil
+}
+
+// Delete removes policy of the node
+func (cps *SQLCompletionPolicyStore) Delete(nodeID string) error {
+	if _, err := cps.sqlStore.execBuilder(cps.sqlStore.db, cps.sqlStore.builder.
+		Delete("completion_policies").
+		Where(sq.Eq{"node_id": nodeID})); err != nil {
+		return errors.Wrapf(err, "can't delete completion policy for node: %s", nodeID)
+	}
+	return nil
+}
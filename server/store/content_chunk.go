@@ -0,0 +1,163 @@
+package store
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+type sqlContentChunk struct {
+	model.ContentChunk
+	VectorJSON string `db:"embedding"`
+}
+
+// ContentChunkStore is an interface to store embedded passages of the learning material
+type ContentChunkStore interface {
+	SaveForNode(nodeID string, chunks []*model.ContentChunk) error
+	Search(vector []float32, nodeIDs []string, topK int) ([]*model.ContentChunk, error)
+}
+
+// SQLContentChunkStore is a struct to store content chunks.
+// Like embeddings, they are searched by pgvector if it's available and in memory otherwise.
+type SQLContentChunkStore struct {
+	sqlStore    *SQLStore
+	chunkSelect sq.SelectBuilder
+
+	pgvectorOnce sync.Once
+	pgvector     bool
+}
+
+// NewContentChunkStore creates a new store for content chunks.
+func NewContentChunkStore(db *SQLStore) ContentChunkStore {
+	chunkSelect := db.builder.
+		Select(
+			"cc.id",
+			"cc.node_id",
+			"cc.source_type",
+			"cc.source_id",
+			"cc.title",
+			"cc.anchor",
+			"cc.start_seconds",
+			"cc.content",
+			"cc.updated_at",
+		).
+		From("content_chunks cc")
+
+	return &SQLContentChunkStore{
+		sqlStore:    db,
+		chunkSelect: chunkSelect,
+	}
+}
+
+// SaveForNode replaces the chunks of the node
+func (ccs *SQLContentChunkStore) SaveForNode(nodeID string, chunks []*model.ContentChunk) error {
+	tx, err := ccs.sqlStore.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer ccs.sqlStore.finalizeTransaction(tx)
+
+	if _, err := ccs.sqlStore.execBuilder(tx, ccs.sqlStore.builder.
+		Delete("content_chunks").
+		Where(sq.Eq{"node_id": nodeID})); err != nil {
+		return errors.Wrapf(err, "can't delete old chunks of node: %s", nodeID)
+	}
+
+	for _, chunk := range chunks {
+		vectorJSON, err := json.Marshal(chunk.Vector)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal embedding of chunk: %s", chunk.ID)
+		}
+		chunk.NodeID = nodeID
+		chunk.UpdatedAt = model.GetMillis()
+
+		values := map[string]interface{}{
+			"id":            chunk.ID,
+			"node_id":       chunk.NodeID,
+			"source_type":   chunk.SourceType,
+			"source_id":     chunk.SourceID,
+			"title":         chunk.Title,
+			"anchor":        chunk.Anchor,
+			"start_seconds": chunk.StartSeconds,
+			"content":       chunk.Content,
+			"embedding":     string(vectorJSON),
+			"updated_at":    chunk.UpdatedAt,
+		}
+		if ccs.hasPGVector() {
+			values["vector"] = sq.Expr("?::vector", vectorLiteral(chunk.Vector))
+		}
+		if _, err := ccs.sqlStore.execBuilder(tx, ccs.sqlStore.builder.
+			Insert("content_chunks").
+			SetMap(values)); err != nil {
+			return errors.Wrapf(err, "can't save chunk: %s", chunk.ID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+	return nil
+}
+
+// Search returns topK chunks of the nodes most similar to the vector by cosine similarity
+func (ccs *SQLContentChunkStore) Search(vector []float32, nodeIDs []string, topK int) ([]*model.ContentChunk, error) {
+	if len(vector) == 0 || len(nodeIDs) == 0 {
+		return []*model.ContentChunk{}, nil
+	}
+	if ccs.hasPGVector() {
+		return ccs.searchPGVector(vector, nodeIDs, topK)
+	}
+
+	var chunks []*sqlContentChunk
+	if err := ccs.sqlStore.selectBuilder(ccs.sqlStore.db, &chunks, ccs.chunkSelect.
+		Column("cc.embedding").
+		Where(sq.Eq{"cc.node_id": nodeIDs})); err != nil {
+		return nil, errors.Wrap(err, "can't get content chunks")
+	}
+
+	matches := make([]*model.ContentChunk, 0, len(chunks))
+	for _, c := range chunks {
+		var stored []float32
+		if err := json.Unmarshal([]byte(c.VectorJSON), &stored); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal embedding of chunk: %s", c.ID)
+		}
+		if len(stored) != len(vector) {
+			continue
+		}
+		chunk := c.ContentChunk
+		chunk.Score = cosineSimilarity(vector, stored)
+		matches = append(matches, &chunk)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > topK {
+		matches = matches[:topK]
+	}
+	return matches, nil
+}
+
+func (ccs *SQLContentChunkStore) searchPGVector(vector []float32, nodeIDs []string, topK int) ([]*model.ContentChunk, error) {
+	literal := vectorLiteral(vector)
+	matches := []*model.ContentChunk{}
+	if err := ccs.sqlStore.selectBuilder(ccs.sqlStore.db, &matches, ccs.chunkSelect.
+		Column(sq.Expr("1 - (cc.vector <=> ?::vector) AS score", literal)).
+		Where(sq.Eq{"cc.node_id": nodeIDs}).
+		Where(sq.NotEq{"cc.vector": nil}).
+		Where(sq.Expr("vector_dims(cc.vector) = ?", len(vector))).
+		OrderByClause("cc.vector <=> ?::vector", literal).
+		Limit(uint64(topK))); err != nil {
+		return nil, errors.Wrap(err, "can't search content chunks")
+	}
+	return matches, nil
+}
+
+// hasPGVector checks once whether the vector column was created by the migration
+func (ccs *SQLContentChunkStore) hasPGVector() bool {
+	ccs.pgvectorOnce.Do(func() {
+		ccs.pgvector = ccs.sqlStore.hasVectorColumn("content_chunks")
+	})
+	return ccs.pgvector
+}
@@ -0,0 +1,82 @@
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// ConversationSummaryStore is an interface to store summaries of the learners' dialogues with the tutor
+type ConversationSummaryStore interface {
+	Save(summary *model.ConversationSummary) error
+	Get(userID, nodeID string) (*model.ConversationSummary, error)
+	GetForUser(userID string, after int64) ([]*model.ConversationSummary, error)
+}
+
+// SQLConversationSummaryStore is a struct to store conversation summaries
+type SQLConversationSummaryStore struct {
+	sqlStore      *SQLStore
+	summarySelect sq.SelectBuilder
+}
+
+// NewConversationSummaryStore creates a new store for conversation summaries.
+func NewConversationSummaryStore(db *SQLStore) ConversationSummaryStore {
+	summarySelect := db.builder.
+		Select(
+			"cs.user_id",
+			"cs.node_id",
+			"cs.summary",
+			"cs.last_post_at",
+			"cs.updated_at",
+		).
+		From("conversation_summaries cs")
+
+	return &SQLConversationSummaryStore{
+		sqlStore:      db,
+		summarySelect: summarySelect,
+	}
+}
+
+// Save saves the summary, replacing the summary of the same user and node
+func (css *SQLConversationSummaryStore) Save(summary *model.ConversationSummary) error {
+	summary.BeforeSave()
+	if err := summary.IsValid(); err != nil {
+		return err
+	}
+
+	if _, err := css.sqlStore.execBuilder(css.sqlStore.db, css.sqlStore.builder.
+		Insert("conversation_summaries").
+		SetMap(map[string]interface{}{
+			"user_id":      summary.UserID,
+			"node_id":      summary.NodeID,
+			"summary":      summary.Summary,
+			"last_post_at": summary.LastPostAt,
+			"updated_at":   summary.UpdatedAt,
+		}).
+		Suffix("ON CONFLICT (user_id, node_id) DO UPDATE SET summary = EXCLUDED.summary, last_post_at = EXCLUDED.last_post_at, updated_at = EXCLUDED.updated_at")); err != nil {
+		return errors.Wrapf(err, "can't save conversation summary for user %s and node %s", summary.UserID, summary.NodeID)
+	}
+	return nil
+}
+
+// Get gets the summary of the user's dialogue on the node
+func (css *SQLConversationSummaryStore) Get(userID, nodeID string) (*model.ConversationSummary, error) {
+	var summary model.ConversationSummary
+	if err := css.sqlStore.getBuilder(css.sqlStore.db, &summary, css.summarySelect.
+		Where(sq.Eq{"cs.user_id": userID, "cs.node_id": nodeID})); err != nil {
+		return nil, errors.Wrapf(err, "can't get conversation summary for user %s and node %s", userID, nodeID)
+	}
+	return &summary, nil
+}
+
+// GetForUser gets the user's summaries updated after the time, the latest first
+func (css *SQLConversationSummaryStore) GetForUser(userID string, after int64) ([]*model.ConversationSummary, error) {
+	summaries := []*model.ConversationSummary{}
+	if err := css.sqlStore.selectBuilder(css.sqlStore.db, &summaries, css.summarySelect.
+		Where(sq.Eq{"cs.user_id": userID}).
+		Where(sq.Gt{"cs.updated_at": after}).
+		OrderBy("cs.updated_at DESC")); err != nil {
+		return nil, errors.Wrapf(err, "can't get conversation summaries for user %s", userID)
+	}
+	return summaries, nil
+}
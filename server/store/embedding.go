@@ -0,0 +1,179 @@
+package store
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+type sqlEmbedding struct {
+	model.Embedding
+	VectorJSON string `db:"embedding"`
+}
+
+// EmbeddingStore is an interface to store embeddings and search similar ones
+type EmbeddingStore interface {
+	Save(embedding *model.Embedding) error
+	DeleteAll() error
+	Query(vector []float32, topK int) ([]*model.EmbeddingMatch, error)
+}
+
+// SQLEmbeddingStore is a struct to store embeddings.
+// On Postgres with pgvector extension similarity is computed by the DB, otherwise all the vectors are compared in memory.
+type SQLEmbeddingStore struct {
+	sqlStore *SQLStore
+
+	pgvectorOnce sync.Once
+	pgvector     bool
+}
+
+// NewEmbeddingStore creates a new store for embeddings.
+func NewEmbeddingStore(db *SQLStore) EmbeddingStore {
+	return &SQLEmbeddingStore{
+		sqlStore: db,
+	}
+}
+
+// Save saves the embedding, replacing the embedding with the same id
+func (es *SQLEmbeddingStore) Save(embedding *model.Embedding) error {
+	vectorJSON, err := json.Marshal(embedding.Vector)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal embedding: %s", embedding.ID)
+	}
+	embedding.UpdatedAt = model.GetMillis()
+
+	values := map[string]interface{}{
+		"id":         embedding.ID,
+		"name":       embedding.Name,
+		"intent":     embedding.Intent,
+		"embedding":  string(vectorJSON),
+		"updated_at": embedding.UpdatedAt,
+	}
+	suffix := "ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, intent = EXCLUDED.intent, embedding = EXCLUDED.embedding, updated_at = EXCLUDED.updated_at"
+	if es.hasPGVector() {
+		values["vector"] = sq.Expr("?::vector", vectorLiteral(embedding.Vector))
+		suffix += ", vector = EXCLUDED.vector"
+	}
+
+	if _, err := es.sqlStore.execBuilder(es.sqlStore.db, es.sqlStore.builder.
+		Insert("embeddings").
+		SetMap(values).
+		Suffix(suffix)); err != nil {
+		return errors.Wrapf(err, "can't save embedding: %s", embedding.ID)
+	}
+	return nil
+}
+
+// DeleteAll deletes all the embeddings before reindexing
+func (es *SQLEmbeddingStore) DeleteAll() error {
+	if _, err := es.sqlStore.execBuilder(es.sqlStore.db, es.sqlStore.builder.Delete("embeddings")); err != nil {
+		return errors.Wrap(err, "can't delete embeddings")
+	}
+	return nil
+}
+
+// Query returns topK embeddings most similar to the vector by cosine similarity
+func (es *SQLEmbeddingStore) Query(vector []float32, topK int) ([]*model.EmbeddingMatch, error) {
+	if len(vector) == 0 {
+		return []*model.EmbeddingMatch{}, nil
+	}
+	if es.hasPGVector() {
+		return es.queryPGVector(vector, topK)
+	}
+
+	var embeddings []*sqlEmbedding
+	if err := es.sqlStore.selectBuilder(es.sqlStore.db, &embeddings, es.sqlStore.builder.
+		Select("e.id", "e.name", "e.intent", "e.embedding", "e.updated_at").
+		From("embeddings e")); err != nil {
+		return nil, errors.Wrap(err, "can't get embeddings")
+	}
+
+	matches := make([]*model.EmbeddingMatch, 0, len(embeddings))
+	for _, e := range embeddings {
+		var stored []float32
+		if err := json.Unmarshal([]byte(e.VectorJSON), &stored); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal embedding: %s", e.ID)
+		}
+		if len(stored) != len(vector) {
+			continue
+		}
+		matches = append(matches, &model.EmbeddingMatch{
+			Name:   e.Name,
+			Intent: e.Intent,
+			Score:  cosineSimilarity(vector, stored),
+		})
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > topK {
+		matches = matches[:topK]
+	}
+	return matches, nil
+}
+
+func (es *SQLEmbeddingStore) queryPGVector(vector []float32, topK int) ([]*model.EmbeddingMatch, error) {
+	literal := vectorLiteral(vector)
+	var matches []*model.EmbeddingMatch
+	if err := es.sqlStore.selectBuilder(es.sqlStore.db, &matches, es.sqlStore.builder.
+		Select("e.name", "e.intent").
+		Column(sq.Expr("1 - (e.vector <=> ?::vector) AS score", literal)).
+		From("embeddings e").
+		Where(sq.NotEq{"e.vector": nil}).
+		Where(sq.Expr("vector_dims(e.vector) = ?", len(vector))).
+		OrderByClause("e.vector <=> ?::vector", literal).
+		Limit(uint64(topK))); err != nil {
+		return nil, errors.Wrap(err, "can't query embeddings")
+	}
+	return matches, nil
+}
+
+// hasPGVector checks once whether the vector column was created by the migration
+func (es *SQLEmbeddingStore) hasPGVector() bool {
+	es.pgvectorOnce.Do(func() {
+		es.pgvector = es.sqlStore.hasVectorColumn("embeddings")
+	})
+	return es.pgvector
+}
+
+// hasVectorColumn checks whether the pgvector column was added to the table
+func (ss *SQLStore) hasVectorColumn(table string) bool {
+	if ss.db.DriverName() != "postgres" {
+		return false
+	}
+	var count int
+	if err := ss.getBuilder(ss.db, &count, ss.builder.
+		Select("COUNT(*)").
+		From("information_schema.columns").
+		Where(sq.Eq{"table_name": table, "column_name": "vector"})); err != nil {
+		return false
+	}
+	return count > 0
+}
+
+// vectorLiteral formats the vector as pgvector's text input, e.g. [1,2,3]
+func vectorLiteral(vector []float32) string {
+	parts := make([]string, 0, len(vector))
+	for _, v := range vector {
+		parts = append(parts, strconv.FormatFloat(float64(v), 'f', -1, 32))
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+func cosineSimilarity(a, b []float32) float32 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
@@ -0,0 +1,286 @@
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// ExamStore is an interface to crud exams and learners' attempts
+type ExamStore interface {
+	Save(exam *model.Exam) (*model.Exam, error)
+	Update(exam *model.Exam) error
+	Get(id string) (*model.Exam, error)
+	GetExams(parentNodeID string) ([]*model.Exam, error)
+	Delete(id string) error
+	SaveAttempt(attempt *model.ExamAttempt) error
+	GetAttempt(id string) (*model.ExamAttempt, error)
+	GetAttempts(examID, userID string) ([]*model.ExamAttempt, error)
+	SaveAnswer(question *model.ExamAttemptQuestion) (bool, error)
+	FinishAttempt(attempt *model.ExamAttempt) error
+}
+
+// SQLExamStore is a struct to store exams
+type SQLExamStore struct {
+	sqlStore        *SQLStore
+	examSelect      sq.SelectBuilder
+	attemptSelect   sq.SelectBuilder
+	questionsSelect sq.SelectBuilder
+}
+
+// NewExamStore creates a new store for exams.
+func NewExamStore(db *SQLStore) ExamStore {
+	examSelect := db.builder.
+		Select(
+			"e.id",
+			"e.parent_node_id",
+			"e.name",
+			"e.num_questions",
+			"e.time_limit_minutes",
+			"e.passing_score",
+			"e.max_attempts",
+			"e.finishes_parent",
+			"e.created_at",
+			"e.updated_at",
+			"e.deleted_at",
+		).
+		From("exams e")
+
+	attemptSelect := db.builder.
+		Select(
+			"ea.id",
+			"ea.exam_id",
+			"ea.user_id",
+			"ea.started_at",
+			"ea.deadline",
+			"ea.finished_at",
+			"ea.status",
+			"ea.score",
+		).
+		From("exam_attempts ea")
+
+	questionsSelect := db.builder.
+		Select(
+			"eq.attempt_id",
+			"eq.question_id",
+			"eq.position",
+			"eq.choice_id",
+			"eq.is_right",
+			"eq.answered_at",
+		).
+		From("exam_attempt_questions eq")
+
+	return &SQLExamStore{
+		sqlStore:        db,
+		examSelect:      examSelect,
+		attemptSelect:   attemptSelect,
+		questionsSelect: questionsSelect,
+	}
+}
+
+// Save saves exam in the DB
+func (es *SQLExamStore) Save(exam *model.Exam) (*model.Exam, error) {
+	exam.BeforeSave()
+	if err := exam.IsValid(); err != nil {
+		return nil, err
+	}
+
+	_, err := es.sqlStore.execBuilder(es.sqlStore.db, es.sqlStore.builder.
+		Insert("exams").
+		SetMap(map[string]interface{}{
+			"id":                 exam.ID,
+			"parent_node_id":     exam.ParentNodeID,
+			"name":               exam.Name,
+			"num_questions":      exam.NumQuestions,
+			"time_limit_minutes": exam.TimeLimitMinutes,
+			"passing_score":      exam.PassingScore,
+			"max_attempts":       exam.MaxAttempts,
+			"finishes_parent":    exam.FinishesParent,
+			"created_at":         exam.CreatedAt,
+			"updated_at":         exam.UpdatedAt,
+			"deleted_at":         0,
+		}))
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't save exam: %s", exam.Name)
+	}
+	return exam, nil
+}
+
+// Update updates exam settings, running attempts keep their deadlines and questions
+func (es *SQLExamStore) Update(exam *model.Exam) error {
+	exam.BeforeSave()
+	if err := exam.IsValid(); err != nil {
+		return err
+	}
+
+	if _, err := es.sqlStore.execBuilder(es.sqlStore.db, es.sqlStore.builder.
+		Update("exams").
+		SetMap(map[string]interface{}{
+			"name":               exam.Name,
+			"num_questions":      exam.NumQuestions,
+			"time_limit_minutes": exam.TimeLimitMinutes,
+			"passing_score":      exam.PassingScore,
+			"max_attempts":       exam.MaxAttempts,
+			"finishes_parent":    exam.FinishesParent,
+			"updated_at":         exam.UpdatedAt,
+		}).
+		Where(sq.Eq{"id": exam.ID})); err != nil {
+		return errors.Wrapf(err, "can't update exam: %s", exam.ID)
+	}
+	return nil
+}
+
+// Get gets exam by id
+func (es *SQLExamStore) Get(id string) (*model.Exam, error) {
+	var exam model.Exam
+	if err := es.sqlStore.getBuilder(es.sqlStore.db, &exam, es.examSelect.
+		Where(sq.And{
+			sq.Eq{"e.id": id},
+			sq.Eq{"e.deleted_at": 0},
+		})); err != nil {
+		return nil, errors.Wrapf(err, "can't get exam by id: %s", id)
+	}
+	return &exam, nil
+}
+
+// GetExams gets all the exams, filtered by the parent node if parentNodeID is set
+func (es *SQLExamStore) GetExams(parentNodeID string) ([]*model.Exam, error) {
+	query := es.examSelect.
+		Where(sq.Eq{"e.deleted_at": 0}).
+		OrderBy("e.created_at")
+	if parentNodeID != "" {
+		query = query.Where(sq.Eq{"e.parent_node_id": parentNodeID})
+	}
+
+	var exams []*model.Exam
+	if err := es.sqlStore.selectBuilder(es.sqlStore.db, &exams, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get exams for parent node: %s", parentNodeID)
+	}
+	return exams, nil
+}
+
+// Delete soft deletes the exam
+func (es *SQLExamStore) Delete(id string) error {
+	if _, err := es.sqlStore.execBuilder(es.sqlStore.db, es.sqlStore.builder.
+		Update("exams").
+		Set("deleted_at", model.GetMillis()).
+		Where(sq.Eq{"id": id})); err != nil {
+		return errors.Wrapf(err, "can't delete exam: %s", id)
+	}
+	return nil
+}
+
+// SaveAttempt saves the new attempt with its questions
+func (es *SQLExamStore) SaveAttempt(attempt *model.ExamAttempt) error {
+	tx, err := es.sqlStore.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer es.sqlStore.finalizeTransaction(tx)
+
+	if _, err := es.sqlStore.execBuilder(tx, es.sqlStore.builder.
+		Insert("exam_attempts").
+		SetMap(map[string]interface{}{
+			"id":          attempt.ID,
+			"exam_id":     attempt.ExamID,
+			"user_id":     attempt.UserID,
+			"started_at":  attempt.StartedAt,
+			"deadline":    attempt.Deadline,
+			"finished_at": 0,
+			"status":      attempt.Status,
+			"score":       0,
+		})); err != nil {
+		return errors.Wrapf(err, "can't save attempt of exam %s for user: %s", attempt.ExamID, attempt.UserID)
+	}
+
+	for _, question := range attempt.Questions {
+		if _, err := es.sqlStore.execBuilder(tx, es.sqlStore.builder.
+			Insert("exam_attempt_questions").
+			SetMap(map[string]interface{}{
+				"attempt_id":  attempt.ID,
+				"question_id": question.QuestionID,
+				"position":    question.Position,
+				"choice_id":   "",
+				"is_right":    false,
+				"answered_at": 0,
+			})); err != nil {
+			return errors.Wrapf(err, "can't save question %s of attempt: %s", question.QuestionID, attempt.ID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+	return nil
+}
+
+// GetAttempt gets the attempt with its questions
+func (es *SQLExamStore) GetAttempt(id string) (*model.ExamAttempt, error) {
+	var attempt model.ExamAttempt
+	if err := es.sqlStore.getBuilder(es.sqlStore.db, &attempt, es.attemptSelect.Where(sq.Eq{"ea.id": id})); err != nil {
+		return nil, errors.Wrapf(err, "can't get exam attempt by id: %s", id)
+	}
+
+	if err := es.sqlStore.selectBuilder(es.sqlStore.db, &attempt.Questions, es.questionsSelect.
+		Where(sq.Eq{"eq.attempt_id": id}).
+		OrderBy("eq.position")); err != nil {
+		return nil, errors.Wrapf(err, "can't get questions of exam attempt: %s", id)
+	}
+	return &attempt, nil
+}
+
+// GetAttempts gets user's attempts of the exam without questions, oldest first
+func (es *SQLExamStore) GetAttempts(examID, userID string) ([]*model.ExamAttempt, error) {
+	var attempts []*model.ExamAttempt
+	if err := es.sqlStore.selectBuilder(es.sqlStore.db, &attempts, es.attemptSelect.
+		Where(sq.And{
+			sq.Eq{"ea.exam_id": examID},
+			sq.Eq{"ea.user_id": userID},
+		}).
+		OrderBy("ea.started_at")); err != nil {
+		return nil, errors.Wrapf(err, "can't get attempts of exam %s for user: %s", examID, userID)
+	}
+	return attempts, nil
+}
+
+// SaveAnswer saves learner's answer on the attempt question, returns false if the question was already answered
+func (es *SQLExamStore) SaveAnswer(question *model.ExamAttemptQuestion) (bool, error) {
+	result, err := es.sqlStore.execBuilder(es.sqlStore.db, es.sqlStore.builder.
+		Update("exam_attempt_questions").
+		SetMap(map[string]interface{}{
+			"choice_id":   question.ChoiceID,
+			"is_right":    question.IsRight,
+			"answered_at": question.AnsweredAt,
+		}).
+		Where(sq.And{
+			sq.Eq{"attempt_id": question.AttemptID},
+			sq.Eq{"question_id": question.QuestionID},
+			sq.Eq{"answered_at": 0},
+		}))
+	if err != nil {
+		return false, errors.Wrapf(err, "can't save answer on question %s of attempt: %s", question.QuestionID, question.AttemptID)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "can't get affected rows")
+	}
+	return rows > 0, nil
+}
+
+// FinishAttempt stores the result of the attempt
+func (es *SQLExamStore) FinishAttempt(attempt *model.ExamAttempt) error {
+	if _, err := es.sqlStore.execBuilder(es.sqlStore.db, es.sqlStore.builder.
+		Update("exam_attempts").
+		SetMap(map[string]interface{}{
+			"finished_at": attempt.FinishedAt,
+			"status":      attempt.Status,
+			"score":       attempt.Score,
+		}).
+		Where(sq.And{
+			sq.Eq{"id": attempt.ID},
+			sq.Eq{"status": model.ExamAttemptStatusInProgress},
+		})); err != nil {
+		return errors.Wrapf(err, "can't finish exam attempt: %s", attempt.ID)
+	}
+	return nil
+}
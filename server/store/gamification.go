@@ -0,0 +1,126 @@
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// GamificationStore is an interface to crud users' XP and achievements
+type GamificationStore interface {
+	SaveXPEvent(event *model.XPEvent) (bool, error)
+	GetXPEvents(userID string, page, perPage int) ([]*model.XPEvent, error)
+	GetXPEventCounts(userID string) ([]*model.XPEventCount, error)
+	SaveAchievement(achievement *model.UserAchievement) (bool, error)
+	GetAchievements(userID string) ([]*model.UserAchievement, error)
+}
+
+// SQLGamificationStore is a struct to store users' XP and achievements
+type SQLGamificationStore struct {
+	sqlStore *SQLStore
+}
+
+// NewGamificationStore creates a new store for users' XP and achievements.
+func NewGamificationStore(db *SQLStore) GamificationStore {
+	return &SQLGamificationStore{
+		sqlStore: db,
+	}
+}
+
+// SaveXPEvent saves the event, returns false if user has already got XP for the same event
+func (gs *SQLGamificationStore) SaveXPEvent(event *model.XPEvent) (bool, error) {
+	event.BeforeSave()
+	if err := event.IsValid(); err != nil {
+		return false, err
+	}
+
+	result, err := gs.sqlStore.execBuilder(gs.sqlStore.db, gs.sqlStore.builder.
+		Insert("xp_events").
+		SetMap(map[string]interface{}{
+			"id":         event.ID,
+			"user_id":    event.UserID,
+			"event_type": event.EventType,
+			"ref_id":     event.RefID,
+			"xp":         event.XP,
+			"created_at": event.CreatedAt,
+		}).
+		Suffix("ON CONFLICT (user_id, event_type, ref_id) DO NOTHING"))
+	if err != nil {
+		return false, errors.Wrapf(err, "can't save xp event for user: %s", event.UserID)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrapf(err, "can't get affected rows for user: %s", event.UserID)
+	}
+	return rows > 0, nil
+}
+
+// GetXPEvents gets user's XP history, latest first
+func (gs *SQLGamificationStore) GetXPEvents(userID string, page, perPage int) ([]*model.XPEvent, error) {
+	var events []*model.XPEvent
+	query := gs.sqlStore.builder.
+		Select("e.id", "e.user_id", "e.event_type", "e.ref_id", "e.xp", "e.created_at").
+		From("xp_events e").
+		Where(sq.Eq{"e.user_id": userID}).
+		OrderBy("e.created_at DESC").
+		Limit(uint64(perPage)).
+		Offset(uint64(page * perPage))
+
+	if err := gs.sqlStore.selectBuilder(gs.sqlStore.db, &events, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get xp events for user: %s", userID)
+	}
+	return events, nil
+}
+
+// GetXPEventCounts gets number of events and XP of each event type
+func (gs *SQLGamificationStore) GetXPEventCounts(userID string) ([]*model.XPEventCount, error) {
+	var counts []*model.XPEventCount
+	query := gs.sqlStore.builder.
+		Select("e.event_type", "COUNT(*) AS count", "SUM(e.xp) AS xp").
+		From("xp_events e").
+		Where(sq.Eq{"e.user_id": userID}).
+		GroupBy("e.event_type")
+
+	if err := gs.sqlStore.selectBuilder(gs.sqlStore.db, &counts, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get xp event counts for user: %s", userID)
+	}
+	return counts, nil
+}
+
+// SaveAchievement saves unlocked achievement, returns false if user has already unlocked it
+func (gs *SQLGamificationStore) SaveAchievement(achievement *model.UserAchievement) (bool, error) {
+	if achievement.CreatedAt == 0 {
+		achievement.CreatedAt = model.GetMillis()
+	}
+	result, err := gs.sqlStore.execBuilder(gs.sqlStore.db, gs.sqlStore.builder.
+		Insert("user_achievements").
+		SetMap(map[string]interface{}{
+			"user_id":        achievement.UserID,
+			"achievement_id": achievement.AchievementID,
+			"created_at":     achievement.CreatedAt,
+		}).
+		Suffix("ON CONFLICT (user_id, achievement_id) DO NOTHING"))
+	if err != nil {
+		return false, errors.Wrapf(err, "can't save achievement %s for user: %s", achievement.AchievementID, achievement.UserID)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrapf(err, "can't get affected rows for user: %s", achievement.UserID)
+	}
+	return rows > 0, nil
+}
+
+// GetAchievements gets achievements unlocked by the user
+func (gs *SQLGamificationStore) GetAchievements(userID string) ([]*model.UserAchievement, error) {
+	var achievements []*model.UserAchievement
+	query := gs.sqlStore.builder.
+		Select("ua.user_id", "ua.achievement_id", "ua.created_at").
+		From("user_achievements ua").
+		Where(sq.Eq{"ua.user_id": userID}).
+		OrderBy("ua.created_at")
+
+	if err := gs.sqlStore.selectBuilder(gs.sqlStore.db, &achievements, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get achievements for user: %s", userID)
+	}
+	return achievements, nil
+}
@@ -18,6 +18,7 @@ type GoalStore interface {
 	GetAll(userID string) ([]*model.Goal, error)
 	GetAllWithData(userID string) ([]*model.GoalWithData, error)
 	Get(userID, nodeID string) (*model.Goal, error)
+	UpdateSettings(userID, nodeID string, settings *model.GoalSettings) error
 	SaveDefaultGoal(nodeID string, num int64) error
 	DefaultGoalsForUser(userID string) ([]string, error)
 }
@@ -37,6 +38,9 @@ func NewGoalStore(db *SQLStore) GoalStore {
 			"g.created_at",
 			"g.finished_at",
 			"g.deleted_at",
+			"g.target_date",
+			"g.priority",
+			"g.weekly_minutes",
 		).
 		From("user_goals g")
 
@@ -60,11 +64,14 @@ func (gs *SQLGoalStore) Save(userID, nodeID string) error {
 	_, err := gs.sqlStore.execBuilder(gs.sqlStore.db, gs.sqlStore.builder.
 		Insert("user_goals").
 		SetMap(map[string]interface{}{
-			"user_id":     goal.UserID,
-			"node_id":     goal.NodeID,
-			"created_at":  goal.CreatedAt,
-			"finished_at": goal.FinishedAt,
-			"deleted_at":  goal.DeletedAt,
+			"user_id":        goal.UserID,
+			"node_id":        goal.NodeID,
+			"created_at":     goal.CreatedAt,
+			"finished_at":    goal.FinishedAt,
+			"deleted_at":     goal.DeletedAt,
+			"target_date":    goal.TargetDate,
+			"priority":       goal.Priority,
+			"weekly_minutes": goal.WeeklyMinutes,
 		}))
 	if err != nil {
 		return errors.Wrapf(err, "can't save goal for user: %s node :%s", goal.UserID, goal.NodeID)
@@ -110,6 +117,30 @@ func (gs *SQLGoalStore) Delete(userID, nodeID string) error {
 	return nil
 }
 
+// UpdateSettings updates planner settings of the goal
+func (gs *SQLGoalStore) UpdateSettings(userID, nodeID string, settings *model.GoalSettings) error {
+	if err := settings.IsValid(); err != nil {
+		return err
+	}
+
+	_, err := gs.sqlStore.execBuilder(gs.sqlStore.db, gs.sqlStore.builder.
+		Update("user_goals").
+		SetMap(map[string]interface{}{
+			"target_date":    settings.TargetDate,
+			"priority":       settings.Priority,
+			"weekly_minutes": settings.WeeklyMinutes,
+		}).
+		Where(sq.And{
+			sq.Eq{"user_id": userID},
+			sq.Eq{"node_id": nodeID},
+		}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update goal settings for user: '%s' node'%s'", userID, nodeID)
+	}
+
+	return nil
+}
+
 // Finish marks goal as finished
 func (gs *SQLGoalStore) Finish(userID, nodeID string) error {
 	curTime := model.GetMillis()
@@ -169,6 +200,9 @@ func (gs *SQLGoalStore) GetAllWithData(userID string) ([]*model.GoalWithData, er
 			"n.id AS node_id",
 			"n.name",
 			"n.thumbnail_url as thumbnail_relative_url",
+			"g.target_date",
+			"g.priority",
+			"g.weekly_minutes",
 		).
 		From("user_goals g").
 		Join("nodes n ON n.id = g.node_id").
@@ -176,7 +210,8 @@ func (gs *SQLGoalStore) GetAllWithData(userID string) ([]*model.GoalWithData, er
 			sq.Eq{"g.deleted_at": 0},
 			sq.Eq{"g.finished_at": 0},
 			sq.Eq{"g.user_id": userID},
-		})
+		}).
+		OrderBy("g.priority DESC", "g.created_at")
 
 	if err := gs.sqlStore.selectBuilder(gs.sqlStore.db, &goals, query); err != nil {
 		return nil, errors.Wrapf(err, "can't get goals for user: %s", userID)
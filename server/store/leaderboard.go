@@ -0,0 +1,224 @@
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// LeaderboardStore is an interface to crud cached leaderboard scores and friends
+type LeaderboardStore interface {
+	AddScore(userID, metric string, delta int, periods []string) error
+	GetLeaderboard(options *model.LeaderboardGetOptions) ([]*model.LeaderboardEntry, error)
+	GetEntry(userID string, options *model.LeaderboardGetOptions) (*model.LeaderboardEntry, error)
+	AddFriend(userID, friendID string) error
+	RemoveFriend(userID, friendID string) error
+	GetFriends(userID string) ([]*model.User, error)
+	GetFriendRequests(userID string) ([]*model.User, error)
+}
+
+// SQLLeaderboardStore is a struct to store leaderboard scores and friends
+type SQLLeaderboardStore struct {
+	sqlStore    *SQLStore
+	entrySelect sq.SelectBuilder
+}
+
+// NewLeaderboardStore creates a new store for leaderboards.
+func NewLeaderboardStore(db *SQLStore) LeaderboardStore {
+	entrySelect := db.builder.
+		Select(
+			"s.user_id",
+			"u.username",
+			"u.first_name",
+			"u.last_name",
+			"s.score",
+		).
+		From("leaderboard_scores s").
+		Join("users u ON u.id = s.user_id")
+
+	return &SQLLeaderboardStore{
+		sqlStore:    db,
+		entrySelect: entrySelect,
+	}
+}
+
+// AddScore adds delta to user's score of the metric in each of the periods
+func (ls *SQLLeaderboardStore) AddScore(userID, metric string, delta int, periods []string) error {
+	tx, err := ls.sqlStore.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer ls.sqlStore.finalizeTransaction(tx)
+
+	now := model.GetMillis()
+	for _, period := range periods {
+		if _, err := ls.sqlStore.execBuilder(tx, ls.sqlStore.builder.
+			Insert("leaderboard_scores").
+			SetMap(map[string]interface{}{
+				"user_id":    userID,
+				"metric":     metric,
+				"period":     period,
+				"score":      delta,
+				"updated_at": now,
+			}).
+			SuffixExpr(sq.Expr(
+				"ON CONFLICT (user_id, metric, period) DO UPDATE SET score = leaderboard_scores.score + ?, updated_at = ?",
+				delta, now),
+			)); err != nil {
+			return errors.Wrapf(err, "can't add score for user: %s metric: %s period: %s", userID, metric, period)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+	return nil
+}
+
+// GetLeaderboard gets top scores of the scope, users hidden from leaderboards are shown only to themselves
+func (ls *SQLLeaderboardStore) GetLeaderboard(options *model.LeaderboardGetOptions) ([]*model.LeaderboardEntry, error) {
+	query := ls.entrySelect.
+		Where(leaderboardFilter(options)).
+		OrderBy("s.score DESC", "s.updated_at")
+	if options.Limit > 0 {
+		query = query.Limit(uint64(options.Limit))
+	}
+
+	var entries []*model.LeaderboardEntry
+	if err := ls.sqlStore.selectBuilder(ls.sqlStore.db, &entries, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get leaderboard for metric: %s period: %s", options.Metric, options.Period)
+	}
+	for i, entry := range entries {
+		entry.Rank = i + 1
+	}
+	return entries, nil
+}
+
+// GetEntry gets user's score and rank in the scope
+func (ls *SQLLeaderboardStore) GetEntry(userID string, options *model.LeaderboardGetOptions) (*model.LeaderboardEntry, error) {
+	var entry model.LeaderboardEntry
+	if err := ls.sqlStore.getBuilder(ls.sqlStore.db, &entry, ls.entrySelect.
+		Where(sq.And{
+			sq.Eq{"s.user_id": userID},
+			sq.Eq{"s.metric": options.Metric},
+			sq.Eq{"s.period": options.Period},
+		})); err != nil {
+		return nil, errors.Wrapf(err, "can't get leaderboard entry for user: %s", userID)
+	}
+
+	var higher int
+	if err := ls.sqlStore.getBuilder(ls.sqlStore.db, &higher, ls.sqlStore.builder.
+		Select("COUNT(*)").
+		From("leaderboard_scores s").
+		Join("users u ON u.id = s.user_id").
+		Where(sq.And{
+			leaderboardFilter(options),
+			sq.Gt{"s.score": entry.Score},
+		})); err != nil {
+		return nil, errors.Wrapf(err, "can't get leaderboard rank for user: %s", userID)
+	}
+	entry.Rank = higher + 1
+	return &entry, nil
+}
+
+// AddFriend adds friend to the user's friends, users are friends only when both of them added each other
+func (ls *SQLLeaderboardStore) AddFriend(userID, friendID string) error {
+	_, err := ls.sqlStore.execBuilder(ls.sqlStore.db, ls.sqlStore.builder.
+		Insert("user_friends").
+		SetMap(map[string]interface{}{
+			"user_id":    userID,
+			"friend_id":  friendID,
+			"created_at": model.GetMillis(),
+		}).
+		Suffix("ON CONFLICT (user_id, friend_id) DO NOTHING"))
+	if err != nil {
+		return errors.Wrapf(err, "can't add friend %s for user: %s", friendID, userID)
+	}
+	return nil
+}
+
+// RemoveFriend removes the friendship in both directions, it also declines the friend request
+func (ls *SQLLeaderboardStore) RemoveFriend(userID, friendID string) error {
+	_, err := ls.sqlStore.execBuilder(ls.sqlStore.db, ls.sqlStore.builder.
+		Delete("user_friends").
+		Where(sq.Or{
+			sq.Eq{"user_id": userID, "friend_id": friendID},
+			sq.Eq{"user_id": friendID, "friend_id": userID},
+		}))
+	if err != nil {
+		return errors.Wrapf(err, "can't remove friend %s for user: %s", friendID, userID)
+	}
+	return nil
+}
+
+// GetFriends gets user's friends, the users who added each other
+func (ls *SQLLeaderboardStore) GetFriends(userID string) ([]*model.User, error) {
+	var friends []*model.User
+	query := ls.sqlStore.builder.
+		Select("u.id", "u.username", "u.first_name", "u.last_name").
+		From("user_friends f").
+		Join("user_friends r ON r.user_id = f.friend_id AND r.friend_id = f.user_id").
+		Join("users u ON u.id = f.friend_id").
+		Where(sq.And{
+			sq.Eq{"f.user_id": userID},
+			sq.Eq{"u.deleted_at": 0},
+		}).
+		OrderBy("f.created_at")
+	if err := ls.sqlStore.selectBuilder(ls.sqlStore.db, &friends, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get friends for user: %s", userID)
+	}
+	return friends, nil
+}
+
+// GetFriendRequests gets the users who added the user as a friend, but aren't added back yet
+func (ls *SQLLeaderboardStore) GetFriendRequests(userID string) ([]*model.User, error) {
+	var users []*model.User
+	query := ls.sqlStore.builder.
+		Select("u.id", "u.username", "u.first_name", "u.last_name").
+		From("user_friends f").
+		Join("users u ON u.id = f.user_id").
+		Where(sq.And{
+			sq.Eq{"f.friend_id": userID},
+			sq.Eq{"u.deleted_at": 0},
+			sq.Expr("NOT EXISTS (SELECT 1 FROM user_friends r WHERE r.user_id = f.friend_id AND r.friend_id = f.user_id)"),
+		}).
+		OrderBy("f.created_at")
+	if err := ls.sqlStore.selectBuilder(ls.sqlStore.db, &users, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get friend requests for user: %s", userID)
+	}
+	return users, nil
+}
+
+// leaderboardFilter filters scores of the visible users in the scope
+func leaderboardFilter(options *model.LeaderboardGetOptions) sq.And {
+	filter := sq.And{
+		sq.Eq{"s.metric": options.Metric},
+		sq.Eq{"s.period": options.Period},
+		sq.Eq{"u.deleted_at": 0},
+		sq.Or{
+			sq.Eq{"s.user_id": options.ViewerID},
+			sq.Expr("NOT EXISTS (SELECT 1 FROM preferences p WHERE p.user_id = s.user_id AND p.key = ? AND p.value = ?)",
+				model.HideFromLeaderboardsKey, "true"),
+		},
+	}
+	if options.CohortTo > 0 {
+		filter = append(filter, sq.GtOrEq{"u.created_at": options.CohortFrom}, sq.Lt{"u.created_at": options.CohortTo})
+	}
+	if options.CourseID != "" {
+		filter = append(filter, sq.Expr(
+			"s.user_id IN (SELECT un.user_id FROM user_nodes un JOIN nodes n ON n.id = un.node_id WHERE n.parent_id = ?)",
+			options.CourseID))
+	}
+	if options.FriendsOf != "" {
+		filter = append(filter, sq.Or{
+			sq.Eq{"s.user_id": options.FriendsOf},
+			sq.Expr("s.user_id IN (SELECT f.friend_id FROM user_friends f JOIN user_friends r ON r.user_id = f.friend_id AND r.friend_id = f.user_id WHERE f.user_id = ?)", options.FriendsOf),
+		})
+	}
+	if options.StudyGroupID != "" {
+		filter = append(filter, sq.Expr(
+			"s.user_id IN (SELECT gm.user_id FROM study_group_members gm WHERE gm.group_id = ?)",
+			options.StudyGroupID))
+	}
+	return filter
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// LLMUsageStore is an interface to store the usage ledger of the language model
+type LLMUsageStore interface {
+	Save(usage *model.LLMUsage) error
+	GetTotal(options *model.LLMUsageGetOptions) (*model.LLMUsageTotal, error)
+	GetTotalsByUser(options *model.LLMUsageGetOptions) ([]*model.LLMUsageTotal, error)
+	GetTotalsByModel(options *model.LLMUsageGetOptions) ([]*model.LLMUsageTotal, error)
+}
+
+// SQLLLMUsageStore is a struct to store the usage ledger
+type SQLLLMUsageStore struct {
+	sqlStore    *SQLStore
+	totalSelect sq.SelectBuilder
+}
+
+// NewLLMUsageStore creates a new store for the usage ledger.
+func NewLLMUsageStore(db *SQLStore) LLMUsageStore {
+	totalSelect := db.builder.
+		Select(
+			"COUNT(lu.id) AS calls",
+			"COALESCE(SUM(lu.prompt_tokens), 0) AS prompt_tokens",
+			"COALESCE(SUM(lu.completion_tokens), 0) AS completion_tokens",
+			"COALESCE(SUM(lu.cost), 0) AS cost",
+		).
+		From("llm_usage lu")
+
+	return &SQLLLMUsageStore{
+		sqlStore:    db,
+		totalSelect: totalSelect,
+	}
+}
+
+// Save adds the usage to the ledger
+func (us *SQLLLMUsageStore) Save(usage *model.LLMUsage) error {
+	usage.BeforeSave()
+	if _, err := us.sqlStore.execBuilder(us.sqlStore.db, us.sqlStore.builder.
+		Insert("llm_usage").
+		SetMap(map[string]interface{}{
+			"id":                usage.ID,
+			"user_id":           usage.UserID,
+			"model":             usage.Model,
+			"call_type":         usage.CallType,
+			"prompt_tokens":     usage.PromptTokens,
+			"completion_tokens": usage.CompletionTokens,
+			"cost":              usage.Cost,
+			"estimated":         usage.Estimated,
+			"created_at":        usage.CreatedAt,
+		})); err != nil {
+		return errors.Wrapf(err, "can't save llm usage of user: %s", usage.UserID)
+	}
+	return nil
+}
+
+// GetTotal sums the usage filtered by the options
+func (us *SQLLLMUsageStore) GetTotal(options *model.LLMUsageGetOptions) (*model.LLMUsageTotal, error) {
+	var total model.LLMUsageTotal
+	if err := us.sqlStore.getBuilder(us.sqlStore.db, &total, applyLLMUsageOptions(us.totalSelect, options)); err != nil {
+		return nil, errors.Wrapf(err, "can't get llm usage with options %v", options)
+	}
+	total.UserID = options.UserID
+	return &total, nil
+}
+
+// GetTotalsByUser sums the usage per user, the users who spent the most come first
+func (us *SQLLLMUsageStore) GetTotalsByUser(options *model.LLMUsageGetOptions) ([]*model.LLMUsageTotal, error) {
+	query := applyLLMUsageOptions(us.totalSelect.
+		Columns("lu.user_id", "COALESCE(u.username, '') AS username").
+		LeftJoin("users u ON u.id = lu.user_id"), options).
+		GroupBy("lu.user_id", "u.username").
+		OrderBy("cost DESC", "prompt_tokens DESC")
+	if options.PerPage > 0 {
+		query = query.Limit(uint64(options.PerPage)).Offset(uint64(options.Page * options.PerPage))
+	}
+
+	totals := []*model.LLMUsageTotal{}
+	if err := us.sqlStore.selectBuilder(us.sqlStore.db, &totals, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get llm usage by user with options %v", options)
+	}
+	return totals, nil
+}
+
+// GetTotalsByModel sums the usage per model
+func (us *SQLLLMUsageStore) GetTotalsByModel(options *model.LLMUsageGetOptions) ([]*model.LLMUsageTotal, error) {
+	totals := []*model.LLMUsageTotal{}
+	if err := us.sqlStore.selectBuilder(us.sqlStore.db, &totals, applyLLMUsageOptions(us.totalSelect.
+		Column("lu.model"), options).
+		GroupBy("lu.model").
+		OrderBy("cost DESC")); err != nil {
+		return nil, errors.Wrapf(err, "can't get llm usage by model with options %v", options)
+	}
+	return totals, nil
+}
+
+func applyLLMUsageOptions(query sq.SelectBuilder, options *model.LLMUsageGetOptions) sq.SelectBuilder {
+	if options.UserID != "" {
+		query = query.Where(sq.Eq{"lu.user_id": options.UserID})
+	}
+	if options.Since > 0 {
+		query = query.Where(sq.GtOrEq{"lu.created_at": options.Since})
+	}
+	if options.Until > 0 {
+		query = query.Where(sq.Lt{"lu.created_at": options.Until})
+	}
+	return query
+}
@@ -1,10 +1,14 @@
 package store
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
 
+	sq "github.com/Masterminds/squirrel"
 	"github.com/blang/semver"
 	"github.com/jmoiron/sqlx"
+	"github.com/oseducation/knowledge-graph/model"
 	"github.com/pkg/errors"
 )
 
@@ -620,6 +624,815 @@ var migrations = []Migration{
 				}
 			}
 
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.19.0"),
+		toVersion:   semver.MustParse("0.20.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS completion_policies (
+					node_id VARCHAR(26) PRIMARY KEY,
+					min_videos_watched bigint DEFAULT 0,
+					min_questions_answered bigint DEFAULT 0,
+					min_correct_percent bigint DEFAULT 0,
+					created_at bigint NOT NULL,
+					updated_at bigint NOT NULL
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table completion_policies")
+			}
+
+			if _, err := e.Exec(`
+				CREATE INDEX IF NOT EXISTS user_question_answers_user_id_index ON user_question_answers (user_id);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating index on user_question_answers")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.20.0"),
+		toVersion:   semver.MustParse("0.21.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS user_node_status_history (
+					user_id VARCHAR(26),
+					node_id VARCHAR(26),
+					from_status VARCHAR(32),
+					to_status VARCHAR(32),
+					created_at bigint
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table user_node_status_history")
+			}
+
+			if _, err := e.Exec(`
+				CREATE INDEX IF NOT EXISTS user_node_status_history_user_id_index ON user_node_status_history (user_id, created_at);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating index on user_node_status_history table")
+			}
+
+			// latest statuses are the only history we have for the existing users
+			if _, err := e.Exec(`
+				INSERT INTO user_node_status_history (user_id, node_id, from_status, to_status, created_at)
+				SELECT user_id, node_id, '', status, updated_at FROM user_nodes;
+			`); err != nil {
+				return errors.Wrapf(err, "failed populating table user_node_status_history")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.21.0"),
+		toVersion:   semver.MustParse("0.22.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if sqlDB.config.DriverName == "sqlite3" {
+				if _, err := e.Exec(`
+					ALTER TABLE user_goals ADD COLUMN target_date bigint DEFAULT 0;
+				`); err != nil {
+					return errors.Wrapf(err, "failed adding column target_date to table user_goals")
+				}
+				if _, err := e.Exec(`
+					ALTER TABLE user_goals ADD COLUMN priority bigint DEFAULT 0;
+				`); err != nil {
+					return errors.Wrapf(err, "failed adding column priority to table user_goals")
+				}
+				if _, err := e.Exec(`
+					ALTER TABLE user_goals ADD COLUMN weekly_minutes bigint DEFAULT 0;
+				`); err != nil {
+					return errors.Wrapf(err, "failed adding column weekly_minutes to table user_goals")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "user_goals", "target_date", "bigint DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column target_date to table user_goals")
+				}
+				if err := addColumnToPGTable(e, "user_goals", "priority", "bigint DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column priority to table user_goals")
+				}
+				if err := addColumnToPGTable(e, "user_goals", "weekly_minutes", "bigint DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column weekly_minutes to table user_goals")
+				}
+			}
+
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS user_plans (
+					user_id VARCHAR(26) PRIMARY KEY,
+					plan TEXT,
+					created_at bigint
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table user_plans")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.22.0"),
+		toVersion:   semver.MustParse("0.23.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS user_streaks (
+					user_id VARCHAR(26) PRIMARY KEY,
+					current_streak integer DEFAULT 0,
+					max_streak integer DEFAULT 0,
+					last_active_date VARCHAR(10),
+					freezes integer DEFAULT 0,
+					freezes_used integer DEFAULT 0,
+					updated_at bigint
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table user_streaks")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.23.0"),
+		toVersion:   semver.MustParse("0.24.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS xp_events (
+					id VARCHAR(26) PRIMARY KEY,
+					user_id VARCHAR(26) REFERENCES users(id),
+					event_type VARCHAR(32),
+					ref_id VARCHAR(64),
+					xp integer,
+					created_at bigint,
+					UNIQUE (user_id, event_type, ref_id)
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table xp_events")
+			}
+
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS user_achievements (
+					user_id VARCHAR(26) REFERENCES users(id),
+					achievement_id VARCHAR(64),
+					created_at bigint,
+					PRIMARY KEY (user_id, achievement_id)
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table user_achievements")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.24.0"),
+		toVersion:   semver.MustParse("0.25.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS certificates (
+					id VARCHAR(26) PRIMARY KEY,
+					user_id VARCHAR(26) REFERENCES users(id),
+					node_id VARCHAR(26) REFERENCES nodes(id),
+					learner_name VARCHAR(256),
+					goal_name VARCHAR(256),
+					topics TEXT,
+					created_at bigint,
+					revoked_at bigint DEFAULT 0,
+					UNIQUE (user_id, node_id)
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table certificates")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.25.0"),
+		toVersion:   semver.MustParse("0.26.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS leaderboard_scores (
+					user_id VARCHAR(26) REFERENCES users(id),
+					metric VARCHAR(16),
+					period VARCHAR(16),
+					score integer DEFAULT 0,
+					updated_at bigint,
+					PRIMARY KEY (user_id, metric, period)
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table leaderboard_scores")
+			}
+
+			if _, err := e.Exec(`
+				CREATE INDEX IF NOT EXISTS leaderboard_scores_period_index ON leaderboard_scores (metric, period, score);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating index leaderboard_scores_period_index")
+			}
+
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS user_friends (
+					user_id VARCHAR(26) REFERENCES users(id),
+					friend_id VARCHAR(26) REFERENCES users(id),
+					created_at bigint,
+					PRIMARY KEY (user_id, friend_id)
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table user_friends")
+			}
+
+			if err := backfillLeaderboardScores(e, sqlDB); err != nil {
+				return errors.Wrapf(err, "failed backfilling leaderboard_scores")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.26.0"),
+		toVersion:   semver.MustParse("0.27.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS classrooms (
+					id VARCHAR(26) PRIMARY KEY,
+					teacher_id VARCHAR(26) REFERENCES users(id),
+					name VARCHAR(128),
+					join_code VARCHAR(16) UNIQUE,
+					created_at bigint,
+					deleted_at bigint
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table classrooms")
+			}
+
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS classroom_students (
+					classroom_id VARCHAR(26) REFERENCES classrooms(id),
+					user_id VARCHAR(26) REFERENCES users(id),
+					joined_at bigint,
+					PRIMARY KEY (classroom_id, user_id)
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table classroom_students")
+			}
+
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS classroom_goals (
+					classroom_id VARCHAR(26) REFERENCES classrooms(id),
+					node_id VARCHAR(26) REFERENCES nodes(id),
+					due_date bigint,
+					created_at bigint,
+					PRIMARY KEY (classroom_id, node_id)
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table classroom_goals")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.27.0"),
+		toVersion:   semver.MustParse("0.28.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS study_groups (
+					id VARCHAR(26) PRIMARY KEY,
+					creator_id VARCHAR(26) REFERENCES users(id),
+					name VARCHAR(128),
+					description TEXT,
+					goal_node_id VARCHAR(26) REFERENCES nodes(id),
+					created_at bigint,
+					deleted_at bigint
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table study_groups")
+			}
+
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS study_group_members (
+					group_id VARCHAR(26) REFERENCES study_groups(id),
+					user_id VARCHAR(26) REFERENCES users(id),
+					joined_at bigint,
+					PRIMARY KEY (group_id, user_id)
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table study_group_members")
+			}
+
+			if _, err := e.Exec(`
+				CREATE INDEX IF NOT EXISTS user_interactions_user_id_end_date_index ON user_interactions (user_id, end_date);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating index user_interactions_user_id_end_date_index")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.28.0"),
+		toVersion:   semver.MustParse("0.29.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS review_quizzes (
+					id VARCHAR(26) PRIMARY KEY,
+					user_id VARCHAR(26) REFERENCES users(id),
+					created_at bigint,
+					finished_at bigint,
+					score integer DEFAULT 0
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table review_quizzes")
+			}
+
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS review_quiz_questions (
+					quiz_id VARCHAR(26) REFERENCES review_quizzes(id),
+					question_id VARCHAR(26) REFERENCES questions(id),
+					node_id VARCHAR(26) REFERENCES nodes(id),
+					position integer,
+					choice_id VARCHAR(26),
+					is_right boolean,
+					answered_at bigint,
+					PRIMARY KEY (quiz_id, question_id)
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table review_quiz_questions")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.29.0"),
+		toVersion:   semver.MustParse("0.30.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS exams (
+					id VARCHAR(26) PRIMARY KEY,
+					parent_node_id VARCHAR(26) REFERENCES nodes(id),
+					name VARCHAR(128),
+					num_questions integer,
+					time_limit_minutes integer,
+					passing_score integer,
+					max_attempts integer DEFAULT 0,
+					finishes_parent boolean DEFAULT false,
+					created_at bigint,
+					updated_at bigint,
+					deleted_at bigint
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table exams")
+			}
+
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS exam_attempts (
+					id VARCHAR(26) PRIMARY KEY,
+					exam_id VARCHAR(26) REFERENCES exams(id),
+					user_id VARCHAR(26) REFERENCES users(id),
+					started_at bigint,
+					deadline bigint,
+					finished_at bigint,
+					status VARCHAR(16),
+					score integer DEFAULT 0
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table exam_attempts")
+			}
+
+			if _, err := e.Exec(`
+				CREATE INDEX IF NOT EXISTS exam_attempts_exam_id_user_id_index ON exam_attempts (exam_id, user_id);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating index exam_attempts_exam_id_user_id_index")
+			}
+
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS exam_attempt_questions (
+					attempt_id VARCHAR(26) REFERENCES exam_attempts(id),
+					question_id VARCHAR(26) REFERENCES questions(id),
+					position integer,
+					choice_id VARCHAR(26),
+					is_right boolean,
+					answered_at bigint,
+					PRIMARY KEY (attempt_id, question_id)
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table exam_attempt_questions")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.30.0"),
+		toVersion:   semver.MustParse("0.31.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if sqlDB.config.DriverName == "sqlite3" {
+				if _, err := e.Exec(`
+					ALTER TABLE user_question_answers ADD COLUMN time_spent bigint DEFAULT 0;
+				`); err != nil {
+					return errors.Wrapf(err, "failed adding column time_spent to table user_question_answers")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "user_question_answers", "time_spent", "bigint DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column time_spent to table user_question_answers")
+				}
+			}
+
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS question_stats (
+					question_id VARCHAR(26) PRIMARY KEY,
+					node_id VARCHAR(26),
+					name VARCHAR(128),
+					attempts integer,
+					percent_correct double precision,
+					discrimination double precision,
+					median_time_spent bigint,
+					flagged boolean,
+					choices TEXT,
+					computed_at bigint
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table question_stats")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.31.0"),
+		toVersion:   semver.MustParse("0.32.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS embeddings (
+					id VARCHAR(128) PRIMARY KEY,
+					name VARCHAR(128),
+					intent VARCHAR(32),
+					embedding TEXT,
+					updated_at bigint
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table embeddings")
+			}
+
+			if sqlDB.config.DriverName == "postgres" {
+				// pgvector is optional, without it similarity is computed in memory like on SQLite
+				installed, err := createPGVectorExtension(e)
+				if err != nil {
+					return err
+				}
+				if installed {
+					if err := addColumnToPGTable(e, "embeddings", "vector", "vector"); err != nil {
+						return errors.Wrapf(err, "failed adding column vector to table embeddings")
+					}
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.32.0"),
+		toVersion:   semver.MustParse("0.33.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS video_transcripts (
+					video_id VARCHAR(26) REFERENCES videos(id),
+					start_seconds bigint,
+					end_seconds bigint,
+					text TEXT
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table video_transcripts")
+			}
+
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS content_chunks (
+					id VARCHAR(26) PRIMARY KEY,
+					node_id VARCHAR(26) REFERENCES nodes(id),
+					source_type VARCHAR(32),
+					source_id VARCHAR(26),
+					title VARCHAR(256),
+					anchor VARCHAR(256),
+					start_seconds bigint,
+					content TEXT,
+					embedding TEXT,
+					updated_at bigint
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table content_chunks")
+			}
+
+			if _, err := e.Exec(`CREATE INDEX IF NOT EXISTS content_chunks_node_id_index ON content_chunks (node_id);`); err != nil {
+				return errors.Wrapf(err, "failed creating index content_chunks_node_id_index")
+			}
+
+			if sqlDB.config.DriverName == "postgres" {
+				// pgvector extension was created with the embeddings table if it's available
+				var installed int
+				if err := sqlx.Get(e, &installed, `SELECT COUNT(*) FROM pg_extension WHERE extname = 'vector'`); err != nil {
+					return errors.Wrapf(err, "failed checking pgvector extension")
+				}
+				if installed == 0 {
+					return nil
+				}
+				if err := addColumnToPGTable(e, "content_chunks", "vector", "vector"); err != nil {
+					return errors.Wrapf(err, "failed adding column vector to table content_chunks")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.33.0"),
+		toVersion:   semver.MustParse("0.34.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS llm_usage (
+					id VARCHAR(26) PRIMARY KEY,
+					user_id VARCHAR(26),
+					model VARCHAR(128),
+					call_type VARCHAR(32),
+					prompt_tokens bigint,
+					completion_tokens bigint,
+					cost double precision,
+					estimated boolean,
+					created_at bigint
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table llm_usage")
+			}
+
+			if _, err := e.Exec(`CREATE INDEX IF NOT EXISTS llm_usage_user_id_created_at_index ON llm_usage (user_id, created_at);`); err != nil {
+				return errors.Wrapf(err, "failed creating index llm_usage_user_id_created_at_index")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.34.0"),
+		toVersion:   semver.MustParse("0.35.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS answer_cache (
+					id VARCHAR(26) PRIMARY KEY,
+					node_id VARCHAR(26) REFERENCES nodes(id),
+					tutor_personality VARCHAR(64),
+					language VARCHAR(8),
+					question TEXT,
+					answer TEXT,
+					citations TEXT,
+					embedding TEXT,
+					pinned boolean DEFAULT FALSE,
+					hits bigint DEFAULT 0,
+					created_at bigint,
+					updated_at bigint
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table answer_cache")
+			}
+
+			if _, err := e.Exec(`CREATE INDEX IF NOT EXISTS answer_cache_node_id_index ON answer_cache (node_id);`); err != nil {
+				return errors.Wrapf(err, "failed creating index answer_cache_node_id_index")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.35.0"),
+		toVersion:   semver.MustParse("0.36.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS conversation_summaries (
+					user_id VARCHAR(26) REFERENCES users(id),
+					node_id VARCHAR(26) REFERENCES nodes(id),
+					summary TEXT,
+					last_post_at bigint,
+					updated_at bigint,
+					PRIMARY KEY (user_id, node_id)
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table conversation_summaries")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.36.0"),
+		toVersion:   semver.MustParse("0.37.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS moderation_flags (
+					id VARCHAR(26) PRIMARY KEY,
+					user_id VARCHAR(26) REFERENCES users(id),
+					node_id VARCHAR(26),
+					direction VARCHAR(16),
+					question TEXT,
+					text TEXT,
+					categories TEXT,
+					policy VARCHAR(16),
+					status VARCHAR(16),
+					note TEXT,
+					reviewed_by VARCHAR(26),
+					created_at bigint,
+					updated_at bigint
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table moderation_flags")
+			}
+
+			if _, err := e.Exec(`CREATE INDEX IF NOT EXISTS moderation_flags_status_index ON moderation_flags (status);`); err != nil {
+				return errors.Wrapf(err, "failed creating index moderation_flags_status_index")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.37.0"),
+		toVersion:   semver.MustParse("0.38.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS tutor_personalities (
+					id VARCHAR(64) PRIMARY KEY,
+					name VARCHAR(256),
+					avatar TEXT,
+					prompts TEXT,
+					tier VARCHAR(16),
+					enabled boolean DEFAULT TRUE,
+					created_at bigint,
+					updated_at bigint
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table tutor_personalities")
+			}
+
+			// the personalities hardcoded before they were moved to the DB, admins manage them afterwards
+			tutorPrompt := "Act as a best tutor in the world and answer the question concisely."
+			personalities := []struct {
+				id, name, avatar, persona string
+				enabled                   bool
+			}{
+				{"standard-tutor-personality", "Standard Tutor", "👩‍🏫", "", true},
+				{"marvin-tutor-personality", "Marvin the Paranoid Android", "🤖", "You are Marvin the Paranoid Android from the Hitchhiker's Guide to the Galaxy.", true},
+				{"neal-deGrasse-tutor-personality", "Neil deGrasse Tyson", "🔭", "You are Neil deGrasse Tyson.", false},
+				{"steve-jobs-tutor-personality", "Steve Jobs", "🍎", "You are Steve Jobs.", false},
+				{"alex-tutor-personality", "Alex DeLarge", "🎩", "You are Alex DeLarge from A Clockwork Orange.", true},
+				{"yoda-tutor-personality", "Yoda", "🧘", "You are Yoda from the Star Wars.", true},
+				{"sherlock-tutor-personality", "Sherlock Holmes", "🔍", "You are Sherlock Holmes.", false},
+				{"sevro-tutor-personality", "Sevro au Barca", "🐺", "You are Sevro au Barca from the Red Rising.", false},
+				{"gollum-tutor-personality", "Gollum", "🐟", "You are Gollum from the Lord of the Rings.", true},
+			}
+			now := model.GetMillis()
+			for i, personality := range personalities {
+				prompt := tutorPrompt
+				if personality.persona != "" {
+					prompt = personality.persona + " " + tutorPrompt
+				}
+				promptsJSON, err := json.Marshal(map[string]string{"en": prompt})
+				if err != nil {
+					return errors.Wrapf(err, "failed to marshal prompts of tutor personality: %s", personality.id)
+				}
+				// keeps the order of the personalities
+				createdAt := now + int64(i)
+				if _, err := sqlDB.execBuilder(e, sqlDB.builder.
+					Insert("tutor_personalities").
+					SetMap(map[string]interface{}{
+						"id":         personality.id,
+						"name":       personality.name,
+						"avatar":     personality.avatar,
+						"prompts":    string(promptsJSON),
+						"tier":       "free",
+						"enabled":    personality.enabled,
+						"created_at": createdAt,
+						"updated_at": createdAt,
+					})); err != nil {
+					return errors.Wrapf(err, "failed adding tutor personality %s", personality.id)
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.38.0"),
+		toVersion:   semver.MustParse("0.39.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if sqlDB.config.DriverName == "sqlite3" {
+				if _, err := e.Exec(`
+					ALTER TABLE questions ADD COLUMN status VARCHAR(16) DEFAULT 'approved';
+				`); err != nil {
+					return errors.Wrapf(err, "failed adding column status to table questions")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "questions", "status", "VARCHAR(16) DEFAULT 'approved'"); err != nil {
+					return errors.Wrapf(err, "failed adding column status to table questions")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.39.0"),
+		toVersion:   semver.MustParse("0.40.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if _, err := e.Exec(`
+				CREATE TABLE IF NOT EXISTS assignment_hints (
+					user_id VARCHAR(26),
+					node_id VARCHAR(26),
+					level INTEGER,
+					created_at bigint
+				);
+			`); err != nil {
+				return errors.Wrapf(err, "failed creating table assignment_hints")
+			}
+
+			if _, err := e.Exec(`CREATE INDEX IF NOT EXISTS assignment_hints_user_id_node_id_index ON assignment_hints (user_id, node_id);`); err != nil {
+				return errors.Wrapf(err, "failed creating index assignment_hints_user_id_node_id_index")
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.40.0"),
+		toVersion:   semver.MustParse("0.41.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if sqlDB.config.DriverName == "sqlite3" {
+				if _, err := e.Exec(`
+					ALTER TABLE questions ADD COLUMN exam_only boolean DEFAULT false;
+				`); err != nil {
+					return errors.Wrapf(err, "failed adding column exam_only to table questions")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "questions", "exam_only", "boolean DEFAULT false"); err != nil {
+					return errors.Wrapf(err, "failed adding column exam_only to table questions")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.41.0"),
+		toVersion:   semver.MustParse("0.42.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			// the tier of the answers cached before isn't known, they aren't served anymore
+			if sqlDB.config.DriverName == "sqlite3" {
+				if _, err := e.Exec(`
+					ALTER TABLE answer_cache ADD COLUMN tier VARCHAR(16) DEFAULT '';
+				`); err != nil {
+					return errors.Wrapf(err, "failed adding column tier to table answer_cache")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "answer_cache", "tier", "VARCHAR(16) DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column tier to table answer_cache")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.42.0"),
+		toVersion:   semver.MustParse("0.43.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if sqlDB.config.DriverName == "sqlite3" {
+				if _, err := e.Exec(`
+					ALTER TABLE user_streaks ADD COLUMN frozen_until VARCHAR(10) DEFAULT '';
+				`); err != nil {
+					return errors.Wrapf(err, "failed adding column frozen_until to table user_streaks")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "user_streaks", "frozen_until", "VARCHAR(10) DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column frozen_until to table user_streaks")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.43.0"),
+		toVersion:   semver.MustParse("0.44.0"),
+		migrationFunc: func(e sqlx.Ext, sqlDB *SQLStore) error {
+			if sqlDB.config.DriverName == "sqlite3" {
+				if _, err := e.Exec(`
+					ALTER TABLE user_node_codes ADD COLUMN updated_at bigint DEFAULT 0;
+				`); err != nil {
+					return errors.Wrapf(err, "failed adding column updated_at to table user_node_codes")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "user_node_codes", "updated_at", "bigint DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column updated_at to table user_node_codes")
+				}
+			}
+
 			return nil
 		},
 	},
@@ -640,3 +1453,107 @@ var addColumnToPGTable = func(e sqlx.Ext, tableName, columnName, columnType stri
 
 	return err
 }
+
+// createPGVectorExtension creates pgvector extension if it's available and returns whether it's installed.
+// Managed Postgres often doesn't let the app's user create extensions, then the extension is skipped and
+// the vectors are compared in memory until an admin runs CREATE EXTENSION vector before the migration.
+var createPGVectorExtension = func(e sqlx.Ext) (bool, error) {
+	if _, err := e.Exec(`
+		DO
+		$$
+		BEGIN
+			IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'vector') THEN
+				CREATE EXTENSION IF NOT EXISTS vector;
+			END IF;
+		EXCEPTION
+			WHEN insufficient_privilege THEN
+				RAISE NOTICE 'Ignoring CREATE EXTENSION statement. No permission to create extension "vector".';
+		END
+		$$;
+	`); err != nil {
+		return false, errors.Wrapf(err, "failed creating pgvector extension")
+	}
+
+	var installed int
+	if err := sqlx.Get(e, &installed, `SELECT COUNT(*) FROM pg_extension WHERE extname = 'vector'`); err != nil {
+		return false, errors.Wrapf(err, "failed checking pgvector extension")
+	}
+	return installed > 0, nil
+}
+
+// leaderboardScoresBatchSize is the number of scores inserted at once, it keeps the query under the SQLite variables limit
+const leaderboardScoresBatchSize = 100
+
+// backfillLeaderboardScores computes cached leaderboard scores from XP events and status history.
+// Metrics and period keys are written as they were at the time of the migration, so later changes of the model don't affect it.
+var backfillLeaderboardScores = func(e sqlx.Ext, sqlDB *SQLStore) error {
+	type award struct {
+		UserID    string `db:"user_id"`
+		Score     int    `db:"score"`
+		CreatedAt int64  `db:"created_at"`
+	}
+	type scoreKey struct {
+		userID, metric, period string
+	}
+	// weekly, monthly and all time periods in UTC
+	periods := func(createdAt int64) []string {
+		t := time.UnixMilli(createdAt).UTC()
+		year, week := t.ISOWeek()
+		return []string{
+			fmt.Sprintf("week:%d-%02d", year, week),
+			fmt.Sprintf("month:%s", t.Format("2006-01")),
+			"all",
+		}
+	}
+	scores := map[scoreKey]int{}
+	add := func(metric string, awards []award) {
+		for _, a := range awards {
+			for _, period := range periods(a.CreatedAt) {
+				scores[scoreKey{a.UserID, metric, period}] += a.Score
+			}
+		}
+	}
+
+	var xp []award
+	if err := sqlDB.selectBuilder(e, &xp, sqlDB.builder.
+		Select("user_id", "xp AS score", "created_at").
+		From("xp_events")); err != nil {
+		return errors.Wrap(err, "can't get xp events")
+	}
+	add("xp", xp)
+
+	var nodes []award
+	if err := sqlDB.selectBuilder(e, &nodes, sqlDB.builder.
+		Select("user_id", "1 AS score", "MIN(created_at) AS created_at").
+		From("user_node_status_history").
+		Where(sq.Eq{"to_status": "finished"}).
+		GroupBy("user_id", "node_id")); err != nil {
+		return errors.Wrap(err, "can't get finished nodes")
+	}
+	add("nodes", nodes)
+
+	now := model.GetMillis()
+	newInsert := func() sq.InsertBuilder {
+		return sqlDB.builder.
+			Insert("leaderboard_scores").
+			Columns("user_id", "metric", "period", "score", "updated_at")
+	}
+	insert, rows := newInsert(), 0
+	for key, score := range scores {
+		insert = insert.Values(key.userID, key.metric, key.period, score, now)
+		rows++
+		if rows < leaderboardScoresBatchSize {
+			continue
+		}
+		if _, err := sqlDB.execBuilder(e, insert); err != nil {
+			return errors.Wrap(err, "can't save leaderboard scores")
+		}
+		insert, rows = newInsert(), 0
+	}
+	if rows > 0 {
+		if _, err := sqlDB.execBuilder(e, insert); err != nil {
+			return errors.Wrap(err, "can't save leaderboard scores")
+		}
+	}
+	return nil
+}
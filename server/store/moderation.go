@@ -0,0 +1,159 @@
+package store
+
+import (
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+type sqlModerationFlag struct {
+	model.ModerationFlag
+	CategoriesJSON string `db:"categories"`
+}
+
+// ModerationStore is an interface to store the review queue of flagged exchanges with the tutor
+type ModerationStore interface {
+	SaveFlag(flag *model.ModerationFlag) (*model.ModerationFlag, error)
+	GetFlag(id string) (*model.ModerationFlag, error)
+	GetFlags(options *model.ModerationFlagGetOptions) ([]*model.ModerationFlag, error)
+	UpdateFlag(flag *model.ModerationFlag) error
+}
+
+// SQLModerationStore is a struct to store moderation flags
+type SQLModerationStore struct {
+	sqlStore   *SQLStore
+	flagSelect sq.SelectBuilder
+}
+
+// NewModerationStore creates a new store for moderation flags.
+func NewModerationStore(db *SQLStore) ModerationStore {
+	flagSelect := db.builder.
+		Select(
+			"mf.id",
+			"mf.user_id",
+			"mf.node_id",
+			"mf.direction",
+			"mf.question",
+			"mf.text",
+			"mf.categories",
+			"mf.policy",
+			"mf.status",
+			"mf.note",
+			"mf.reviewed_by",
+			"mf.created_at",
+			"mf.updated_at",
+		).
+		From("moderation_flags mf")
+
+	return &SQLModerationStore{
+		sqlStore:   db,
+		flagSelect: flagSelect,
+	}
+}
+
+// SaveFlag adds the flagged exchange to the review queue
+func (ms *SQLModerationStore) SaveFlag(flag *model.ModerationFlag) (*model.ModerationFlag, error) {
+	flag.BeforeSave()
+	if err := flag.IsValid(); err != nil {
+		return nil, err
+	}
+	categoriesJSON, err := json.Marshal(flag.Categories)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal categories of moderation flag: %s", flag.ID)
+	}
+
+	if _, err := ms.sqlStore.execBuilder(ms.sqlStore.db, ms.sqlStore.builder.
+		Insert("moderation_flags").
+		SetMap(map[string]interface{}{
+			"id":          flag.ID,
+			"user_id":     flag.UserID,
+			"node_id":     flag.NodeID,
+			"direction":   flag.Direction,
+			"question":    flag.Question,
+			"text":        flag.Text,
+			"categories":  string(categoriesJSON),
+			"policy":      flag.Policy,
+			"status":      flag.Status,
+			"note":        flag.Note,
+			"reviewed_by": flag.ReviewedBy,
+			"created_at":  flag.CreatedAt,
+			"updated_at":  flag.UpdatedAt,
+		})); err != nil {
+		return nil, errors.Wrapf(err, "can't save moderation flag for user: %s", flag.UserID)
+	}
+	return flag, nil
+}
+
+// GetFlag gets the moderation flag by id
+func (ms *SQLModerationStore) GetFlag(id string) (*model.ModerationFlag, error) {
+	var flag sqlModerationFlag
+	if err := ms.sqlStore.getBuilder(ms.sqlStore.db, &flag, ms.flagSelect.Where(sq.Eq{"mf.id": id})); err != nil {
+		return nil, errors.Wrapf(err, "can't get moderation flag by id: %s", id)
+	}
+	return flag.toModel()
+}
+
+// GetFlags gets the moderation flags, the oldest first to work through the queue
+func (ms *SQLModerationStore) GetFlags(options *model.ModerationFlagGetOptions) ([]*model.ModerationFlag, error) {
+	query := ms.flagSelect.OrderBy("mf.created_at ASC")
+	if options.Status != "" {
+		query = query.Where(sq.Eq{"mf.status": options.Status})
+	}
+	if options.Direction != "" {
+		query = query.Where(sq.Eq{"mf.direction": options.Direction})
+	}
+	if options.UserID != "" {
+		query = query.Where(sq.Eq{"mf.user_id": options.UserID})
+	}
+	if options.PerPage > 0 {
+		query = query.Limit(uint64(options.PerPage)).Offset(uint64(options.Page * options.PerPage))
+	}
+
+	var flags []*sqlModerationFlag
+	if err := ms.sqlStore.selectBuilder(ms.sqlStore.db, &flags, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get moderation flags with options %v", options)
+	}
+	result := make([]*model.ModerationFlag, 0, len(flags))
+	for _, f := range flags {
+		flag, err := f.toModel()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, flag)
+	}
+	return result, nil
+}
+
+// UpdateFlag updates the review of the moderation flag
+func (ms *SQLModerationStore) UpdateFlag(flag *model.ModerationFlag) error {
+	if err := flag.IsValid(); err != nil {
+		return err
+	}
+	flag.UpdatedAt = model.GetMillis()
+
+	if _, err := ms.sqlStore.execBuilder(ms.sqlStore.db, ms.sqlStore.builder.
+		Update("moderation_flags").
+		SetMap(map[string]interface{}{
+			"status":      flag.Status,
+			"note":        flag.Note,
+			"reviewed_by": flag.ReviewedBy,
+			"updated_at":  flag.UpdatedAt,
+		}).
+		Where(sq.Eq{"id": flag.ID})); err != nil {
+		return errors.Wrapf(err, "can't update moderation flag: %s", flag.ID)
+	}
+	return nil
+}
+
+func (f *sqlModerationFlag) toModel() (*model.ModerationFlag, error) {
+	flag := f.ModerationFlag
+	flag.Categories = []string{}
+	if f.CategoriesJSON != "" {
+		if err := json.Unmarshal([]byte(f.CategoriesJSON), &flag.Categories); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal categories of moderation flag: %s", f.ID)
+		}
+	}
+	return &flag, nil
+}
@@ -20,13 +20,16 @@ type NodeStore interface {
 	Delete(node *model.Node) error
 	GetNodesForUser(userID string) ([]*model.NodeStatusForUser, error)
 	UpdateStatus(status *model.NodeStatusForUser) error
+	GetTimeline(userID string, options *model.TimelineGetOptions) ([]*model.NodeStatusTransition, error)
+	GetReviewData(userID string) ([]*model.NodeReviewData, error)
+	GetResourceCounts(nodeIDs []string) ([]*model.NodeResourceCounts, error)
 	GetPrerequisites(id string) ([]*model.Node, error)
 	GetNodesWithIDs(ids []string) ([]*model.Node, error)
 	GetNumberOfFinishedNodes(userID string) (int, error)
 	GetNumberOfNodesInDaysWithStatus(userID string, days int, status string) (int, error)
 	GetFinishedNodesProgress(userID string) (map[string]int, error)
 	TopPerformers(days, n int) ([]model.PerformerUser, error)
-	LearningSteak(userID string) (currentSteak int, maxSteak int, finishedToday bool, err error)
+	GetContentUpdatedAt(nodeID string) (int64, error)
 }
 
 // SQLNodeStore is a struct to store nodes
@@ -200,9 +203,16 @@ func (ns *SQLNodeStore) GetNodesForUser(userID string) ([]*model.NodeStatusForUs
 	return statuses, nil
 }
 
+// UpdateStatus updates current status of the node for the user and records the transition in the status history
 func (ns *SQLNodeStore) UpdateStatus(status *model.NodeStatusForUser) error {
+	tx, err := ns.sqlStore.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer ns.sqlStore.finalizeTransaction(tx)
+
 	var statusValue string
-	err := ns.sqlStore.getBuilder(ns.sqlStore.db, &statusValue, ns.sqlStore.builder.
+	err = ns.sqlStore.getBuilder(tx, &statusValue, ns.sqlStore.builder.
 		Select("status").
 		From("user_nodes").
 		Where(sq.And{
@@ -222,7 +232,7 @@ func (ns *SQLNodeStore) UpdateStatus(status *model.NodeStatusForUser) error {
 
 	now := model.GetMillis()
 	if err == nil {
-		if _, err := ns.sqlStore.execBuilder(ns.sqlStore.db, ns.sqlStore.builder.
+		if _, err := ns.sqlStore.execBuilder(tx, ns.sqlStore.builder.
 			Update("user_nodes").
 			SetMap(map[string]interface{}{
 				"status":     status.Status,
@@ -235,7 +245,7 @@ func (ns *SQLNodeStore) UpdateStatus(status *model.NodeStatusForUser) error {
 			return errors.Wrapf(err, "Can't update status -%v", status)
 		}
 	} else {
-		if _, err := ns.sqlStore.execBuilder(ns.sqlStore.db, ns.sqlStore.builder.
+		if _, err := ns.sqlStore.execBuilder(tx, ns.sqlStore.builder.
 			Insert("user_nodes").
 			SetMap(map[string]interface{}{
 				"status":     status.Status,
@@ -246,9 +256,170 @@ func (ns *SQLNodeStore) UpdateStatus(status *model.NodeStatusForUser) error {
 			return errors.Wrapf(err, "Can't insert status -%v", status)
 		}
 	}
+
+	if _, err := ns.sqlStore.execBuilder(tx, ns.sqlStore.builder.
+		Insert("user_node_status_history").
+		SetMap(map[string]interface{}{
+			"user_id":     status.UserID,
+			"node_id":     status.NodeID,
+			"from_status": statusValue,
+			"to_status":   status.Status,
+			"created_at":  now,
+		})); err != nil {
+		return errors.Wrapf(err, "Can't insert status transition -%v", status)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit status")
+	}
 	return nil
 }
 
+// GetTimeline gets user's status transitions, newest first
+func (ns *SQLNodeStore) GetTimeline(userID string, options *model.TimelineGetOptions) ([]*model.NodeStatusTransition, error) {
+	query := ns.sqlStore.builder.
+		Select(
+			"h.user_id",
+			"h.node_id",
+			"n.name AS node_name",
+			"h.from_status",
+			"h.to_status",
+			"h.created_at",
+		).
+		From("user_node_status_history h").
+		Join("nodes n ON n.id = h.node_id").
+		Where(sq.Eq{"h.user_id": userID}).
+		OrderBy("h.created_at DESC")
+
+	if options.Since > 0 {
+		query = query.Where(sq.Gt{"h.created_at": options.Since})
+	}
+	if options.Until > 0 {
+		query = query.Where(sq.Lt{"h.created_at": options.Until})
+	}
+	if options.PerPage > 0 {
+		query = query.Limit(uint64(options.PerPage))
+	}
+	if options.Page >= 0 {
+		query = query.Offset(uint64(options.Page * options.PerPage))
+	}
+
+	var transitions []*model.NodeStatusTransition
+	if err := ns.sqlStore.selectBuilder(ns.sqlStore.db, &transitions, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get timeline for user %s", userID)
+	}
+	return transitions, nil
+}
+
+// GetReviewData gets review data of all the nodes user has ever finished
+func (ns *SQLNodeStore) GetReviewData(userID string) ([]*model.NodeReviewData, error) {
+	finishedQuery := ns.sqlStore.builder.
+		Select(
+			"h.node_id",
+			"MAX(h.created_at) AS last_finished_at",
+			"COUNT(*) AS times_finished",
+		).
+		From("user_node_status_history h").
+		Where(sq.And{
+			sq.Eq{"h.user_id": userID},
+			sq.Eq{"h.to_status": model.NodeStatusFinished},
+		}).
+		GroupBy("h.node_id")
+
+	var reviewData []*model.NodeReviewData
+	if err := ns.sqlStore.selectBuilder(ns.sqlStore.db, &reviewData, finishedQuery); err != nil {
+		return nil, errors.Wrapf(err, "can't get finished nodes for user %s", userID)
+	}
+
+	answersQuery := ns.sqlStore.builder.
+		Select(
+			"q.node_id",
+			"COUNT(*) AS answers",
+			"SUM(CASE WHEN qa.is_right THEN 1 ELSE 0 END) AS correct_answers",
+			"MAX(CASE WHEN qa.is_right THEN qa.created_at ELSE 0 END) AS last_correct_at",
+		).
+		From("user_question_answers qa").
+		Join("questions q ON q.id = qa.question_id").
+		Where(sq.Eq{"qa.user_id": userID}).
+		GroupBy("q.node_id")
+
+	var answers []*model.NodeReviewData
+	if err := ns.sqlStore.selectBuilder(ns.sqlStore.db, &answers, answersQuery); err != nil {
+		return nil, errors.Wrapf(err, "can't get answers for user %s", userID)
+	}
+
+	answersMap := make(map[string]*model.NodeReviewData, len(answers))
+	for _, answer := range answers {
+		answersMap[answer.NodeID] = answer
+	}
+	for _, data := range reviewData {
+		if answer, ok := answersMap[data.NodeID]; ok {
+			data.Answers = answer.Answers
+			data.CorrectAnswers = answer.CorrectAnswers
+			data.LastCorrectAt = answer.LastCorrectAt
+		}
+	}
+	return reviewData, nil
+}
+
+// GetResourceCounts gets number of videos, texts and questions of the nodes
+func (ns *SQLNodeStore) GetResourceCounts(nodeIDs []string) ([]*model.NodeResourceCounts, error) {
+	type resourceCount struct {
+		NodeID string `db:"node_id"`
+		Count  int    `db:"count"`
+	}
+	countResources := func(table string, onlyActive bool) ([]resourceCount, error) {
+		where := sq.And{sq.Eq{"node_id": nodeIDs}}
+		if onlyActive {
+			where = append(where, sq.Eq{"deleted_at": 0})
+		}
+		query := ns.sqlStore.builder.
+			Select("node_id", "COUNT(*) AS count").
+			From(table).
+			Where(where).
+			GroupBy("node_id")
+
+		var counts []resourceCount
+		if err := ns.sqlStore.selectBuilder(ns.sqlStore.db, &counts, query); err != nil {
+			return nil, errors.Wrapf(err, "can't count %s", table)
+		}
+		return counts, nil
+	}
+
+	resources := make(map[string]*model.NodeResourceCounts, len(nodeIDs))
+	for _, nodeID := range nodeIDs {
+		resources[nodeID] = &model.NodeResourceCounts{NodeID: nodeID}
+	}
+
+	videos, err := countResources("videos", true)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range videos {
+		resources[c.NodeID].Videos = c.Count
+	}
+	texts, err := countResources("texts", true)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range texts {
+		resources[c.NodeID].Texts = c.Count
+	}
+	questions, err := countResources("questions", false)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range questions {
+		resources[c.NodeID].Questions = c.Count
+	}
+
+	result := make([]*model.NodeResourceCounts, 0, len(resources))
+	for _, nodeID := range nodeIDs {
+		result = append(result, resources[nodeID])
+	}
+	return result, nil
+}
+
 func (ns *SQLNodeStore) GetPrerequisites(id string) ([]*model.Node, error) {
 	var nodes []*model.Node
 	query := ns.nodeSelect.
@@ -287,17 +458,18 @@ func (ns *SQLNodeStore) GetNumberOfFinishedNodes(userID string) (int, error) {
 	return count, nil
 }
 
+// GetNumberOfNodesInDaysWithStatus gets number of distinct nodes which got the status during the last days
 func (ns *SQLNodeStore) GetNumberOfNodesInDaysWithStatus(userID string, days int, status string) (int, error) {
 	var count int
 	daysAgo := time.Now().AddDate(0, 0, -days).UnixNano() / int64(time.Millisecond)
 
 	query := ns.sqlStore.builder.
-		Select("COUNT(*)").
-		From("user_nodes").
+		Select("COUNT(DISTINCT node_id)").
+		From("user_node_status_history").
 		Where(sq.And{
 			sq.Eq{"user_id": userID},
-			sq.Eq{"status": status},
-			sq.Gt{"updated_at": daysAgo},
+			sq.Eq{"to_status": status},
+			sq.Gt{"created_at": daysAgo},
 		})
 
 	if err := ns.sqlStore.getBuilder(ns.sqlStore.db, &count, query); err != nil {
@@ -307,6 +479,7 @@ func (ns *SQLNodeStore) GetNumberOfNodesInDaysWithStatus(userID string, days int
 	return count, nil
 }
 
+// GetFinishedNodesProgress gets number of nodes finished on each day during the last year
 func (ns *SQLNodeStore) GetFinishedNodesProgress(userID string) (map[string]int, error) {
 	type NodeProgress struct {
 		Date          *string `db:"date"`
@@ -316,25 +489,25 @@ func (ns *SQLNodeStore) GetFinishedNodesProgress(userID string) (map[string]int,
 	daysAgo := time.Now().AddDate(-1, 0, 0).UnixNano() / int64(time.Millisecond)
 
 	query := ns.sqlStore.builder.Select(
-		"TO_CHAR(TO_TIMESTAMP(updated_at / 1000), 'YYYY-MM-DD') AS date",
-		"COUNT(node_id) AS finished_count",
-	).From("user_nodes").
+		"TO_CHAR(TO_TIMESTAMP(created_at / 1000), 'YYYY-MM-DD') AS date",
+		"COUNT(DISTINCT node_id) AS finished_count",
+	).From("user_node_status_history").
 		Where(sq.And{
 			sq.Eq{"user_id": userID},
-			sq.Eq{"status": model.NodeStatusFinished},
-			sq.Gt{"updated_at": daysAgo},
-		}).GroupBy("TO_CHAR(TO_TIMESTAMP(updated_at / 1000), 'YYYY-MM-DD')")
+			sq.Eq{"to_status": model.NodeStatusFinished},
+			sq.Gt{"created_at": daysAgo},
+		}).GroupBy("TO_CHAR(TO_TIMESTAMP(created_at / 1000), 'YYYY-MM-DD')")
 
 	if ns.sqlStore.db.DriverName() == "sqlite3" {
 		query = ns.sqlStore.builder.Select(
-			"DATE(datetime(updated_at / 1000, 'unixepoch')) AS date",
-			"COUNT(node_id) AS finished_count",
-		).From("user_nodes").
+			"DATE(datetime(created_at / 1000, 'unixepoch')) AS date",
+			"COUNT(DISTINCT node_id) AS finished_count",
+		).From("user_node_status_history").
 			Where(sq.And{
 				sq.Eq{"user_id": userID},
-				sq.Eq{"status": model.NodeStatusFinished},
-				sq.Gt{"updated_at": daysAgo},
-			}).GroupBy("DATE(datetime(updated_at / 1000, 'unixepoch'))")
+				sq.Eq{"to_status": model.NodeStatusFinished},
+				sq.Gt{"created_at": daysAgo},
+			}).GroupBy("DATE(datetime(created_at / 1000, 'unixepoch'))")
 	}
 
 	if err := ns.sqlStore.selectBuilder(ns.sqlStore.db, &progress, query); err != nil {
@@ -352,20 +525,23 @@ func (ns *SQLNodeStore) GetFinishedNodesProgress(userID string) (map[string]int,
 // TopPerformers returns top performers for the last days.
 // if days is 0 then it returns all time top performers
 func (ns *SQLNodeStore) TopPerformers(days, n int) ([]model.PerformerUser, error) {
-	daysAgo := time.Now().AddDate(0, -days, 0).UnixNano() / int64(time.Millisecond)
+	filter := sq.And{
+		sq.Eq{"h.to_status": model.NodeStatusFinished},
+	}
+	if days > 0 {
+		daysAgo := time.Now().AddDate(0, 0, -days).UnixNano() / int64(time.Millisecond)
+		filter = append(filter, sq.Gt{"h.created_at": daysAgo})
+	}
 
 	query := ns.sqlStore.builder.Select(
 		"u.id",
 		"u.first_name",
 		"u.last_name",
 		"u.username",
-		"COUNT(un.node_id) AS finished_count",
+		"COUNT(DISTINCT h.node_id) AS finished_count",
 	).From("users u").
-		Join("user_nodes un on un.user_id = u.id").
-		Where(sq.And{
-			sq.Eq{"un.status": model.NodeStatusFinished},
-			sq.Gt{"un.updated_at": daysAgo},
-		}).GroupBy("u.id").
+		Join("user_node_status_history h on h.user_id = u.id").
+		Where(filter).GroupBy("u.id").
 		OrderBy("finished_count DESC").Limit(uint64(n))
 
 	var users []model.PerformerUser
@@ -375,89 +551,41 @@ func (ns *SQLNodeStore) TopPerformers(days, n int) ([]model.PerformerUser, error
 	return users, nil
 }
 
-func (ns *SQLNodeStore) LearningSteak(userID string) (currentSteak int, maxSteak int, finishedToday bool, err error) {
-	type NodeProgress struct {
-		Date          *string `db:"date"`
-		FinishedCount *int    `db:"finished_count"`
-	}
-	var progress []*NodeProgress
-
-	query := ns.sqlStore.builder.Select(
-		"TO_CHAR(TO_TIMESTAMP(updated_at), 'YYYY-MM-DD') AS date",
-		"COUNT(node_id) AS finished_count",
-	).From("user_nodes").
-		Where(sq.And{
-			sq.Eq{"user_id": userID},
-			sq.Eq{"status": model.NodeStatusFinished},
-		}).GroupBy("TO_CHAR(TO_TIMESTAMP(updated_at), 'YYYY-MM-DD')")
-
-	if ns.sqlStore.db.DriverName() == "sqlite3" {
-		query = ns.sqlStore.builder.Select(
-			"DATE(datetime(updated_at / 1000, 'unixepoch')) AS date",
-			"COUNT(node_id) AS finished_count",
-		).From("user_nodes").
-			Where(sq.And{
-				sq.Eq{"user_id": userID},
-				sq.Eq{"status": model.NodeStatusFinished},
-			}).GroupBy("DATE(datetime(updated_at / 1000, 'unixepoch'))")
-	}
-
-	if err2 := ns.sqlStore.selectBuilder(ns.sqlStore.db, &progress, query); err2 != nil {
-		return 0, 0, false, errors.Wrapf(err2, "can't get finished nodes for user %s", userID)
-	}
-
-	m := make(map[string]int)
-	for _, node := range progress {
-		m[*node.Date] = *node.FinishedCount
-	}
-
-	currentSteak = getCurrentSteak(m)
-	maxSteak = getMaxSteak(m)
-	finishedToday = getTodayProgress(m)
-	if finishedToday {
-		currentSteak++
-	}
-	err = nil
-	return
-}
-
-func getMaxSteak(progress map[string]int) int {
-	currentCount := 0
-	maxCount := 0
-	for day := 0; day < 366; day++ {
-		d := time.Now().AddDate(0, 0, -day).Format("2006-01-02")
-		if _, ok := progress[d]; !ok {
-			if maxCount < currentCount {
-				maxCount = currentCount
-				currentCount = 0
-			}
-		} else {
-			currentCount++
+// GetContentUpdatedAt returns the last time the node or its texts, videos or questions were created, updated or deleted
+func (ns *SQLNodeStore) GetContentUpdatedAt(nodeID string) (int64, error) {
+	sources := []struct {
+		table      string
+		nodeColumn string
+		updatedAt  string
+	}{
+		{"nodes", "id", "updated_at"},
+		{"texts", "node_id", "updated_at"},
+		{"videos", "node_id", "0"},
+		{"questions", "node_id", "updated_at"},
+	}
+
+	updatedAt := int64(0)
+	for _, source := range sources {
+		var times struct {
+			CreatedAt int64 `db:"created_at"`
+			UpdatedAt int64 `db:"updated_at"`
+			DeletedAt int64 `db:"deleted_at"`
 		}
-	}
-	if maxCount < currentCount {
-		maxCount = currentCount
-	}
-	return maxCount
-}
-
-func getTodayProgress(progress map[string]int) bool {
-	day := time.Now().Format("2006-01-02")
-	if _, ok := progress[day]; !ok {
-		return false
-	}
-	return true
-}
-
-func getCurrentSteak(progress map[string]int) int {
-	counter := 0
-	for {
-		day := time.Now().AddDate(0, 0, -(counter + 1)).Format("2006-01-02")
-		if _, ok := progress[day]; !ok {
-			break
+		if err := ns.sqlStore.getBuilder(ns.sqlStore.db, &times, ns.sqlStore.builder.
+			Select(
+				"COALESCE(MAX(created_at), 0) AS created_at",
+				fmt.Sprintf("COALESCE(MAX(%s), 0) AS updated_at", source.updatedAt),
+				"COALESCE(MAX(deleted_at), 0) AS deleted_at",
+			).
+			From(source.table).
+			Where(sq.Eq{source.nodeColumn: nodeID})); err != nil {
+			return 0, errors.Wrapf(err, "can't get update time of %s of node: %s", source.table, nodeID)
+		}
+		for _, t := range []int64{times.CreatedAt, times.UpdatedAt, times.DeletedAt} {
+			if t > updatedAt {
+				updatedAt = t
+			}
 		}
-		counter++
 	}
-
-	return counter
+	return updatedAt, nil
 }
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// PlanStore is an interface to crud study plans
+type PlanStore interface {
+	Save(plan *model.Plan) error
+	Get(userID string) (*model.Plan, error)
+	Delete(userID string) error
+}
+
+// SQLPlanStore is a struct to store study plans
+type SQLPlanStore struct {
+	sqlStore *SQLStore
+}
+
+// NewPlanStore creates a new store for study plans.
+func NewPlanStore(db *SQLStore) PlanStore {
+	return &SQLPlanStore{
+		sqlStore: db,
+	}
+}
+
+// Save creates or overrides user's plan
+func (ps *SQLPlanStore) Save(plan *model.Plan) error {
+	planJSON, err := json.Marshal(plan)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal plan for user: %s", plan.UserID)
+	}
+
+	_, err = ps.sqlStore.execBuilder(ps.sqlStore.db, ps.sqlStore.builder.
+		Insert("user_plans").
+		SetMap(map[string]interface{}{
+			"user_id":    plan.UserID,
+			"plan":       string(planJSON),
+			"created_at": plan.CreatedAt,
+		}).
+		SuffixExpr(sq.Expr(
+			"ON CONFLICT (user_id) DO UPDATE SET plan = ?, created_at = ?",
+			string(planJSON), plan.CreatedAt),
+		))
+	if err != nil {
+		return errors.Wrapf(err, "can't save plan for user: %s", plan.UserID)
+	}
+	return nil
+}
+
+// Get gets user's plan
+func (ps *SQLPlanStore) Get(userID string) (*model.Plan, error) {
+	var planJSON string
+	query := ps.sqlStore.builder.
+		Select("up.plan").
+		From("user_plans up").
+		Where(sq.Eq{"up.user_id": userID})
+
+	if err := ps.sqlStore.getBuilder(ps.sqlStore.db, &planJSON, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get plan for user: %s", userID)
+	}
+
+	var plan model.Plan
+	if err := json.Unmarshal([]byte(planJSON), &plan); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal plan for user: %s", userID)
+	}
+	return &plan, nil
+}
+
+// Delete removes user's plan
+func (ps *SQLPlanStore) Delete(userID string) error {
+	if _, err := ps.sqlStore.execBuilder(ps.sqlStore.db, ps.sqlStore.builder.
+		Delete("user_plans").
+		Where(sq.Eq{"user_id": userID})); err != nil {
+		return errors.Wrapf(err, "can't delete plan for user: %s", userID)
+	}
+	return nil
+}
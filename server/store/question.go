@@ -12,9 +12,14 @@ type QuestionStore interface {
 	Get(id string) (*model.Question, error)
 	GetIDByName(name string) (string, error)
 	GetQuestions(options *model.QuestionGetOptions) ([]*model.Question, error)
+	Update(question *model.Question) error
+	UpdateStatus(questionID, status string) error
 	Delete(question *model.Question) error
 	GetOnboardingQuestions(courseID string) ([][]*model.Question, error)
 	SaveOnboardingQuestion(courseID, nodeID, questionID string, pos int) error
+	SaveAnswer(answer *model.QuestionAnswer) error
+	GetAnswers(userID string, questionIDs []string) ([]*model.QuestionAnswer, error)
+	GetFailures(userID string, since int64) ([]*model.NodeFailures, error)
 }
 
 // SQLQuestionStore is a struct to store Questions
@@ -33,6 +38,8 @@ func NewQuestionStore(db *SQLStore) QuestionStore {
 			"q.question_type",
 			"q.node_id",
 			"q.explanation",
+			"q.status",
+			"q.exam_only",
 		).
 		From("questions q")
 
@@ -61,22 +68,24 @@ func (qs *SQLQuestionStore) Save(question *model.Question) (*model.Question, err
 			"question_type": question.QuestionType,
 			"node_id":       question.NodeID,
 			"explanation":   question.Explanation,
+			"status":        question.Status,
+			"exam_only":     question.ExamOnly,
 		}))
 	if err != nil {
 		return nil, errors.Wrapf(err, "can't save question with text: %s", question.Question)
 	}
 
 	for _, choice := range question.Choices {
-		if err := qs.saveChoice(choice, question.ID); err != nil {
+		if err := qs.saveChoice(qs.sqlStore.db, choice, question.ID); err != nil {
 			return nil, errors.Wrapf(err, "can't save question(%s) choice: %s", question.ID, choice.Choice)
 		}
 	}
 	return question, nil
 }
 
-func (qs *SQLQuestionStore) saveChoice(choice model.QuestionChoice, questionID string) error {
+func (qs *SQLQuestionStore) saveChoice(e execer, choice model.QuestionChoice, questionID string) error {
 	choice.BeforeSave()
-	_, err := qs.sqlStore.execBuilder(qs.sqlStore.db, qs.sqlStore.builder.
+	_, err := qs.sqlStore.execBuilder(e, qs.sqlStore.builder.
 		Insert("question_choices").
 		SetMap(map[string]interface{}{
 			"id":              choice.ID,
@@ -135,11 +144,19 @@ func (qs *SQLQuestionStore) GetQuestions(options *model.QuestionGetOptions) ([]*
 	if options.NodeID != "" {
 		query = query.Where(sq.Eq{"q.node_id": options.NodeID})
 	}
+	status := options.Status
+	if status == "" {
+		status = model.QuestionStatusApproved
+	}
+	query = query.Where(sq.Eq{"q.status": status})
+	// exam questions are kept apart from the practice ones, so the learners don't see their answers before the exam
+	query = query.Where(sq.Eq{"q.exam_only": options.ExamOnly})
+	// all the questions are returned without the page size, OFFSET without LIMIT isn't valid in SQLite
 	if options.PerPage > 0 {
 		query = query.Limit(uint64(options.PerPage))
-	}
-	if options.Page >= 0 {
-		query = query.Offset(uint64(options.Page * options.PerPage))
+		if options.Page >= 0 {
+			query = query.Offset(uint64(options.Page * options.PerPage))
+		}
 	}
 
 	if err := qs.sqlStore.selectBuilder(qs.sqlStore.db, &questions, query); err != nil {
@@ -196,6 +213,59 @@ func (qs *SQLQuestionStore) populateQuestionsWithChoices(questions []*model.Ques
 	return questions, nil
 }
 
+// Update updates the text, the explanation and the status of the question and replaces its choices
+func (qs *SQLQuestionStore) Update(question *model.Question) error {
+	if err := question.IsValid(); err != nil {
+		return err
+	}
+
+	tx, err := qs.sqlStore.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer qs.sqlStore.finalizeTransaction(tx)
+
+	if _, err := qs.sqlStore.execBuilder(tx, qs.sqlStore.builder.
+		Update("questions").
+		SetMap(map[string]interface{}{
+			"question":    question.Question,
+			"explanation": question.Explanation,
+			"status":      question.Status,
+		}).
+		Where(sq.Eq{"id": question.ID})); err != nil {
+		return errors.Wrapf(err, "can't update question: %s", question.ID)
+	}
+
+	if _, err := qs.sqlStore.execBuilder(tx, qs.sqlStore.builder.
+		Delete("question_choices").
+		Where(sq.Eq{"question_id": question.ID})); err != nil {
+		return errors.Wrapf(err, "can't delete choices of question: %s", question.ID)
+	}
+	for _, choice := range question.Choices {
+		if err := qs.saveChoice(tx, choice, question.ID); err != nil {
+			return errors.Wrapf(err, "can't save question(%s) choice: %s", question.ID, choice.Choice)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+	return nil
+}
+
+// UpdateStatus updates the status of the question, the choices are kept as the learners' answers reference them
+func (qs *SQLQuestionStore) UpdateStatus(questionID, status string) error {
+	if _, err := qs.sqlStore.execBuilder(qs.sqlStore.db, qs.sqlStore.builder.
+		Update("questions").
+		SetMap(map[string]interface{}{
+			"status": status,
+		}).
+		Where(sq.Eq{"id": questionID})); err != nil {
+		return errors.Wrapf(err, "can't update status of question: %s", questionID)
+	}
+	return nil
+}
+
 // Delete removes question
 func (qs *SQLQuestionStore) Delete(question *model.Question) error {
 	curTime := model.GetMillis()
@@ -302,6 +372,75 @@ func (qs *SQLQuestionStore) SaveOnboardingQuestion(courseID, nodeID, questionID
 	return nil
 }
 
+// SaveAnswer saves user's answer on a question
+func (qs *SQLQuestionStore) SaveAnswer(answer *model.QuestionAnswer) error {
+	answer.BeforeSave()
+	if err := answer.IsValid(); err != nil {
+		return err
+	}
+
+	_, err := qs.sqlStore.execBuilder(qs.sqlStore.db, qs.sqlStore.builder.
+		Insert("user_question_answers").
+		SetMap(map[string]interface{}{
+			"user_id":     answer.UserID,
+			"question_id": answer.QuestionID,
+			"choice_id":   answer.ChoiceID,
+			"is_right":    answer.IsRight,
+			"time_spent":  answer.TimeSpent,
+			"created_at":  answer.CreatedAt,
+		}))
+	if err != nil {
+		return errors.Wrapf(err, "can't save answer for question: %s", answer.QuestionID)
+	}
+	return nil
+}
+
+// GetAnswers gets all the answers of the user on the questions, ordered by creation time
+func (qs *SQLQuestionStore) GetAnswers(userID string, questionIDs []string) ([]*model.QuestionAnswer, error) {
+	query := qs.sqlStore.builder.
+		Select(
+			"qa.user_id",
+			"qa.question_id",
+			"qa.choice_id",
+			"qa.is_right",
+			"qa.time_spent",
+			"qa.created_at",
+		).
+		From("user_question_answers qa").
+		Where(sq.Eq{"qa.user_id": userID}).
+		Where(sq.Eq{"qa.question_id": questionIDs}).
+		OrderBy("qa.created_at ASC")
+
+	var answers []*model.QuestionAnswer
+	if err := qs.sqlStore.selectBuilder(qs.sqlStore.db, &answers, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get answers for user: %s", userID)
+	}
+	return answers, nil
+}
+
+// GetFailures gets number of incorrect answers of the user per node since the time
+func (qs *SQLQuestionStore) GetFailures(userID string, since int64) ([]*model.NodeFailures, error) {
+	query := qs.sqlStore.builder.
+		Select(
+			"q.node_id",
+			"COUNT(*) AS failures",
+		).
+		From("user_question_answers qa").
+		Join("questions q ON q.id = qa.question_id").
+		Where(sq.And{
+			sq.Eq{"qa.user_id": userID},
+			sq.Eq{"qa.is_right": false},
+			sq.Gt{"qa.created_at": since},
+		}).
+		GroupBy("q.node_id")
+
+	var failures []*model.NodeFailures
+	if err := qs.sqlStore.selectBuilder(qs.sqlStore.db, &failures, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get failures for user: %s", userID)
+	}
+	return failures, nil
+}
+
 func (qs *SQLQuestionStore) getQuestionsFromIDs(questionIDs []string) ([]*model.Question, error) {
 	questionSelect := qs.sqlStore.builder.
 		Select(
@@ -311,6 +450,8 @@ func (qs *SQLQuestionStore) getQuestionsFromIDs(questionIDs []string) ([]*model.
 			"q.question_type",
 			"q.node_id",
 			"q.explanation",
+			"q.status",
+			"q.exam_only",
 		).
 		From("questions q").
 		Where(sq.Eq{"q.id": questionIDs})
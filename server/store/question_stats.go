@@ -0,0 +1,153 @@
+package store
+
+import (
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+type sqlQuestionStats struct {
+	model.QuestionStats
+	ChoicesJSON string `db:"choices"`
+}
+
+// QuestionStatsStore is an interface to store computed question statistics
+type QuestionStatsStore interface {
+	GetAllAnswers() ([]*model.QuestionAnswer, error)
+	SaveAll(stats []*model.QuestionStats) error
+	Get(questionID string) (*model.QuestionStats, error)
+	GetStats(options *model.QuestionStatsGetOptions) ([]*model.QuestionStats, error)
+}
+
+// SQLQuestionStatsStore is a struct to store question statistics
+type SQLQuestionStatsStore struct {
+	sqlStore    *SQLStore
+	statsSelect sq.SelectBuilder
+}
+
+// NewQuestionStatsStore creates a new store for question statistics.
+func NewQuestionStatsStore(db *SQLStore) QuestionStatsStore {
+	statsSelect := db.builder.
+		Select(
+			"qs.question_id",
+			"qs.node_id",
+			"qs.name",
+			"qs.attempts",
+			"qs.percent_correct",
+			"qs.discrimination",
+			"qs.median_time_spent",
+			"qs.flagged",
+			"qs.choices",
+			"qs.computed_at",
+		).
+		From("question_stats qs")
+
+	return &SQLQuestionStatsStore{
+		sqlStore:    db,
+		statsSelect: statsSelect,
+	}
+}
+
+// GetAllAnswers gets all the answers of all the users, ordered by creation time
+func (qss *SQLQuestionStatsStore) GetAllAnswers() ([]*model.QuestionAnswer, error) {
+	var answers []*model.QuestionAnswer
+	if err := qss.sqlStore.selectBuilder(qss.sqlStore.db, &answers, qss.sqlStore.builder.
+		Select(
+			"qa.user_id",
+			"qa.question_id",
+			"qa.choice_id",
+			"qa.is_right",
+			"qa.time_spent",
+			"qa.created_at",
+		).
+		From("user_question_answers qa").
+		OrderBy("qa.created_at ASC")); err != nil {
+		return nil, errors.Wrap(err, "can't get answers")
+	}
+	return answers, nil
+}
+
+// SaveAll replaces stored statistics with the newly computed ones
+func (qss *SQLQuestionStatsStore) SaveAll(stats []*model.QuestionStats) error {
+	tx, err := qss.sqlStore.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer qss.sqlStore.finalizeTransaction(tx)
+
+	if _, err := qss.sqlStore.execBuilder(tx, qss.sqlStore.builder.Delete("question_stats")); err != nil {
+		return errors.Wrap(err, "can't delete old question stats")
+	}
+
+	for _, s := range stats {
+		choicesJSON, err := json.Marshal(s.Choices)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal choices json: '%v'", s.Choices)
+		}
+		if _, err := qss.sqlStore.execBuilder(tx, qss.sqlStore.builder.
+			Insert("question_stats").
+			SetMap(map[string]interface{}{
+				"question_id":       s.QuestionID,
+				"node_id":           s.NodeID,
+				"name":              s.Name,
+				"attempts":          s.Attempts,
+				"percent_correct":   s.PercentCorrect,
+				"discrimination":    s.Discrimination,
+				"median_time_spent": s.MedianTimeSpent,
+				"flagged":           s.Flagged,
+				"choices":           string(choicesJSON),
+				"computed_at":       s.ComputedAt,
+			})); err != nil {
+			return errors.Wrapf(err, "can't save stats of question: %s", s.QuestionID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+	return nil
+}
+
+// Get gets statistics of the question
+func (qss *SQLQuestionStatsStore) Get(questionID string) (*model.QuestionStats, error) {
+	var s sqlQuestionStats
+	if err := qss.sqlStore.getBuilder(qss.sqlStore.db, &s, qss.statsSelect.Where(sq.Eq{"qs.question_id": questionID})); err != nil {
+		return nil, errors.Wrapf(err, "can't get stats of question: %s", questionID)
+	}
+	if err := json.Unmarshal([]byte(s.ChoicesJSON), &s.Choices); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal choices json: '%s'", s.ChoicesJSON)
+	}
+	return &s.QuestionStats, nil
+}
+
+// GetStats gets statistics with options, flagged questions and the least discriminating ones first
+func (qss *SQLQuestionStatsStore) GetStats(options *model.QuestionStatsGetOptions) ([]*model.QuestionStats, error) {
+	query := qss.statsSelect.OrderBy("qs.flagged DESC", "qs.discrimination ASC", "qs.question_id")
+	if options.NodeID != "" {
+		query = query.Where(sq.Eq{"qs.node_id": options.NodeID})
+	}
+	if options.FlaggedOnly {
+		query = query.Where(sq.Eq{"qs.flagged": true})
+	}
+	if options.PerPage > 0 {
+		query = query.Limit(uint64(options.PerPage))
+	}
+	if options.Page >= 0 {
+		query = query.Offset(uint64(options.Page * options.PerPage))
+	}
+
+	var sqlStats []*sqlQuestionStats
+	if err := qss.sqlStore.selectBuilder(qss.sqlStore.db, &sqlStats, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get question stats with options %v", options)
+	}
+	stats := make([]*model.QuestionStats, 0, len(sqlStats))
+	for _, s := range sqlStats {
+		if err := json.Unmarshal([]byte(s.ChoicesJSON), &s.Choices); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal choices json: '%s'", s.ChoicesJSON)
+		}
+		stats = append(stats, &s.QuestionStats)
+	}
+	return stats, nil
+}
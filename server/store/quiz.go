@@ -0,0 +1,201 @@
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// QuizStore is an interface to crud review quizzes and their results
+type QuizStore interface {
+	Save(quiz *model.ReviewQuiz) (*model.ReviewQuiz, error)
+	Get(id string) (*model.ReviewQuiz, error)
+	GetForUser(userID string, page, perPage int) ([]*model.ReviewQuiz, error)
+	SaveResult(question *model.QuizQuestion) (bool, error)
+	Finish(id string, score int) error
+	GetRecentQuestionIDs(userID string, since int64) ([]string, error)
+}
+
+// SQLQuizStore is a struct to store review quizzes
+type SQLQuizStore struct {
+	sqlStore       *SQLStore
+	quizSelect     sq.SelectBuilder
+	questionSelect sq.SelectBuilder
+}
+
+// NewQuizStore creates a new store for review quizzes.
+func NewQuizStore(db *SQLStore) QuizStore {
+	quizSelect := db.builder.
+		Select(
+			"q.id",
+			"q.user_id",
+			"q.created_at",
+			"q.finished_at",
+			"q.score",
+		).
+		From("review_quizzes q")
+
+	questionSelect := db.builder.
+		Select(
+			"qq.quiz_id",
+			"qq.question_id",
+			"qq.node_id",
+			"qq.position",
+			"qq.choice_id",
+			"qq.is_right",
+			"qq.answered_at",
+		).
+		From("review_quiz_questions qq")
+
+	return &SQLQuizStore{
+		sqlStore:       db,
+		quizSelect:     quizSelect,
+		questionSelect: questionSelect,
+	}
+}
+
+// Save saves the quiz with its questions
+func (qs *SQLQuizStore) Save(quiz *model.ReviewQuiz) (*model.ReviewQuiz, error) {
+	quiz.BeforeSave()
+	if err := quiz.IsValid(); err != nil {
+		return nil, err
+	}
+
+	tx, err := qs.sqlStore.db.Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not begin transaction")
+	}
+	defer qs.sqlStore.finalizeTransaction(tx)
+
+	if _, err := qs.sqlStore.execBuilder(tx, qs.sqlStore.builder.
+		Insert("review_quizzes").
+		SetMap(map[string]interface{}{
+			"id":          quiz.ID,
+			"user_id":     quiz.UserID,
+			"created_at":  quiz.CreatedAt,
+			"finished_at": 0,
+			"score":       0,
+		})); err != nil {
+		return nil, errors.Wrapf(err, "can't save quiz for user: %s", quiz.UserID)
+	}
+
+	for _, question := range quiz.Questions {
+		if _, err := qs.sqlStore.execBuilder(tx, qs.sqlStore.builder.
+			Insert("review_quiz_questions").
+			SetMap(map[string]interface{}{
+				"quiz_id":     quiz.ID,
+				"question_id": question.QuestionID,
+				"node_id":     question.NodeID,
+				"position":    question.Position,
+				"choice_id":   "",
+				"is_right":    false,
+				"answered_at": 0,
+			})); err != nil {
+			return nil, errors.Wrapf(err, "can't save question %s of quiz: %s", question.QuestionID, quiz.ID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "could not commit transaction")
+	}
+	return quiz, nil
+}
+
+// Get gets the quiz with its questions
+func (qs *SQLQuizStore) Get(id string) (*model.ReviewQuiz, error) {
+	var quiz model.ReviewQuiz
+	if err := qs.sqlStore.getBuilder(qs.sqlStore.db, &quiz, qs.quizSelect.Where(sq.Eq{"q.id": id})); err != nil {
+		return nil, errors.Wrapf(err, "can't get quiz by id: %s", id)
+	}
+
+	if err := qs.sqlStore.selectBuilder(qs.sqlStore.db, &quiz.Questions, qs.questionSelect.
+		Where(sq.Eq{"qq.quiz_id": id}).
+		OrderBy("qq.position")); err != nil {
+		return nil, errors.Wrapf(err, "can't get questions of quiz: %s", id)
+	}
+	return &quiz, nil
+}
+
+// GetForUser gets user's quizzes without questions, newest first
+func (qs *SQLQuizStore) GetForUser(userID string, page, perPage int) ([]*model.ReviewQuiz, error) {
+	query := qs.quizSelect.
+		Where(sq.Eq{"q.user_id": userID}).
+		OrderBy("q.created_at DESC")
+	if perPage > 0 {
+		query = query.Limit(uint64(perPage))
+	}
+	if page >= 0 {
+		query = query.Offset(uint64(page * perPage))
+	}
+
+	var quizzes []*model.ReviewQuiz
+	if err := qs.sqlStore.selectBuilder(qs.sqlStore.db, &quizzes, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get quizzes for user: %s", userID)
+	}
+	return quizzes, nil
+}
+
+// SaveResult saves user's answer on the quiz question, returns false if the question was already answered
+func (qs *SQLQuizStore) SaveResult(question *model.QuizQuestion) (bool, error) {
+	result, err := qs.sqlStore.execBuilder(qs.sqlStore.db, qs.sqlStore.builder.
+		Update("review_quiz_questions").
+		SetMap(map[string]interface{}{
+			"choice_id":   question.ChoiceID,
+			"is_right":    question.IsRight,
+			"answered_at": question.AnsweredAt,
+		}).
+		Where(sq.And{
+			sq.Eq{"quiz_id": question.QuizID},
+			sq.Eq{"question_id": question.QuestionID},
+			sq.Eq{"answered_at": 0},
+		}))
+	if err != nil {
+		return false, errors.Wrapf(err, "can't save result of question %s in quiz: %s", question.QuestionID, question.QuizID)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "can't get affected rows")
+	}
+	return rows > 0, nil
+}
+
+// Finish marks the quiz as finished with the score
+func (qs *SQLQuizStore) Finish(id string, score int) error {
+	if _, err := qs.sqlStore.execBuilder(qs.sqlStore.db, qs.sqlStore.builder.
+		Update("review_quizzes").
+		SetMap(map[string]interface{}{
+			"finished_at": model.GetMillis(),
+			"score":       score,
+		}).
+		Where(sq.Eq{"id": id})); err != nil {
+		return errors.Wrapf(err, "can't finish quiz: %s", id)
+	}
+	return nil
+}
+
+// GetRecentQuestionIDs gets ids of the questions user has answered or got in a quiz after the time
+func (qs *SQLQuizStore) GetRecentQuestionIDs(userID string, since int64) ([]string, error) {
+	var answered []string
+	if err := qs.sqlStore.selectBuilder(qs.sqlStore.db, &answered, qs.sqlStore.builder.
+		Select("DISTINCT qa.question_id").
+		From("user_question_answers qa").
+		Where(sq.And{
+			sq.Eq{"qa.user_id": userID},
+			sq.Gt{"qa.created_at": since},
+		})); err != nil {
+		return nil, errors.Wrapf(err, "can't get recently answered questions for user: %s", userID)
+	}
+
+	var quizzed []string
+	if err := qs.sqlStore.selectBuilder(qs.sqlStore.db, &quizzed, qs.sqlStore.builder.
+		Select("DISTINCT qq.question_id").
+		From("review_quiz_questions qq").
+		Join("review_quizzes q ON q.id = qq.quiz_id").
+		Where(sq.And{
+			sq.Eq{"q.user_id": userID},
+			sq.Gt{"q.created_at": since},
+		})); err != nil {
+		return nil, errors.Wrapf(err, "can't get recently quizzed questions for user: %s", userID)
+	}
+	return append(answered, quizzed...), nil
+}
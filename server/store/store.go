@@ -40,6 +40,25 @@ type Store interface {
 	Experiments() ExperimentsStore
 	Customer() CustomerStore
 	NodeNote() NodeNoteStore
+	CompletionPolicy() CompletionPolicyStore
+	Plan() PlanStore
+	Streak() StreakStore
+	Gamification() GamificationStore
+	Certificate() CertificateStore
+	Leaderboard() LeaderboardStore
+	Classroom() ClassroomStore
+	StudyGroup() StudyGroupStore
+	Quiz() QuizStore
+	Exam() ExamStore
+	QuestionStats() QuestionStatsStore
+	Embedding() EmbeddingStore
+	ContentChunk() ContentChunkStore
+	LLMUsage() LLMUsageStore
+	AnswerCache() AnswerCacheStore
+	ConversationSummary() ConversationSummaryStore
+	Moderation() ModerationStore
+	TutorPersonality() TutorPersonalityStore
+	AssignmentHint() AssignmentHintStore
 }
 
 // SQLStore struct represents a DB
@@ -47,25 +66,44 @@ type SQLStore struct {
 	db      *sqlx.DB
 	builder sq.StatementBuilderType
 
-	userStore            UserStore
-	tokenStore           TokenStore
-	nodeStore            NodeStore
-	videoStore           VideoStore
-	textStore            TextStore
-	questionStore        QuestionStore
-	graphStore           GraphStore
-	sessionStore         SessionStore
-	systemStore          SystemStore
-	preferencesStore     PreferencesStore
-	userCodeStore        UserCodeStore
-	goalStore            GoalStore
-	postStore            PostStore
-	userInteractionStore UserInteractionStore
-	experimentsStore     ExperimentsStore
-	customerStore        CustomerStore
-	nodeNoteStore        NodeNoteStore
-	config               *config.DBSettings
-	logger               *log.Logger
+	userStore                UserStore
+	tokenStore               TokenStore
+	nodeStore                NodeStore
+	videoStore               VideoStore
+	textStore                TextStore
+	questionStore            QuestionStore
+	graphStore               GraphStore
+	sessionStore             SessionStore
+	systemStore              SystemStore
+	preferencesStore         PreferencesStore
+	userCodeStore            UserCodeStore
+	goalStore                GoalStore
+	postStore                PostStore
+	userInteractionStore     UserInteractionStore
+	experimentsStore         ExperimentsStore
+	customerStore            CustomerStore
+	nodeNoteStore            NodeNoteStore
+	completionPolicyStore    CompletionPolicyStore
+	planStore                PlanStore
+	streakStore              StreakStore
+	gamificationStore        GamificationStore
+	certificateStore         CertificateStore
+	leaderboardStore         LeaderboardStore
+	classroomStore           ClassroomStore
+	studyGroupStore          StudyGroupStore
+	quizStore                QuizStore
+	examStore                ExamStore
+	questionStatsStore       QuestionStatsStore
+	embeddingStore           EmbeddingStore
+	contentChunkStore        ContentChunkStore
+	llmUsageStore            LLMUsageStore
+	answerCacheStore         AnswerCacheStore
+	conversationSummaryStore ConversationSummaryStore
+	moderationStore          ModerationStore
+	tutorPersonalityStore    TutorPersonalityStore
+	assignmentHintStore      AssignmentHintStore
+	config                   *config.DBSettings
+	logger                   *log.Logger
 }
 
 // queryer is an interface describing a resource that can query.
@@ -138,6 +176,25 @@ func CreateStore(config *config.DBSettings, logger *log.Logger) Store {
 	sqlStore.experimentsStore = NewExperimentsStore(sqlStore)
 	sqlStore.customerStore = NewCustomerStore(sqlStore)
 	sqlStore.nodeNoteStore = NewNodeNoteStore(sqlStore)
+	sqlStore.completionPolicyStore = NewCompletionPolicyStore(sqlStore)
+	sqlStore.planStore = NewPlanStore(sqlStore)
+	sqlStore.streakStore = NewStreakStore(sqlStore)
+	sqlStore.gamificationStore = NewGamificationStore(sqlStore)
+	sqlStore.certificateStore = NewCertificateStore(sqlStore)
+	sqlStore.leaderboardStore = NewLeaderboardStore(sqlStore)
+	sqlStore.classroomStore = NewClassroomStore(sqlStore)
+	sqlStore.studyGroupStore = NewStudyGroupStore(sqlStore)
+	sqlStore.quizStore = NewQuizStore(sqlStore)
+	sqlStore.examStore = NewExamStore(sqlStore)
+	sqlStore.questionStatsStore = NewQuestionStatsStore(sqlStore)
+	sqlStore.embeddingStore = NewEmbeddingStore(sqlStore)
+	sqlStore.contentChunkStore = NewContentChunkStore(sqlStore)
+	sqlStore.llmUsageStore = NewLLMUsageStore(sqlStore)
+	sqlStore.answerCacheStore = NewAnswerCacheStore(sqlStore)
+	sqlStore.conversationSummaryStore = NewConversationSummaryStore(sqlStore)
+	sqlStore.moderationStore = NewModerationStore(sqlStore)
+	sqlStore.tutorPersonalityStore = NewTutorPersonalityStore(sqlStore)
+	sqlStore.assignmentHintStore = NewAssignmentHintStore(sqlStore)
 	if err := sqlStore.RunMigrations(); err != nil {
 		logger.Fatal("can't run migrations", log.Err(err))
 	}
@@ -216,6 +273,130 @@ func (sqlDB *SQLStore) Nuke() error {
 		return errors.Wrap(err, "could not user_node_notes")
 	}
 
+	if _, err := tx.Exec("DROP TABLE IF EXISTS completion_policies"); err != nil {
+		return errors.Wrap(err, "could not completion_policies")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS user_node_status_history"); err != nil {
+		return errors.Wrap(err, "could not user_node_status_history")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS user_plans"); err != nil {
+		return errors.Wrap(err, "could not user_plans")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS user_streaks"); err != nil {
+		return errors.Wrap(err, "could not user_streaks")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS xp_events"); err != nil {
+		return errors.Wrap(err, "could not xp_events")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS user_achievements"); err != nil {
+		return errors.Wrap(err, "could not user_achievements")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS certificates"); err != nil {
+		return errors.Wrap(err, "could not certificates")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS leaderboard_scores"); err != nil {
+		return errors.Wrap(err, "could not leaderboard_scores")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS user_friends"); err != nil {
+		return errors.Wrap(err, "could not user_friends")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS classrooms"); err != nil {
+		return errors.Wrap(err, "could not classrooms")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS classroom_students"); err != nil {
+		return errors.Wrap(err, "could not classroom_students")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS classroom_goals"); err != nil {
+		return errors.Wrap(err, "could not classroom_goals")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS study_groups"); err != nil {
+		return errors.Wrap(err, "could not study_groups")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS study_group_members"); err != nil {
+		return errors.Wrap(err, "could not study_group_members")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS review_quizzes"); err != nil {
+		return errors.Wrap(err, "could not review_quizzes")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS review_quiz_questions"); err != nil {
+		return errors.Wrap(err, "could not review_quiz_questions")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS exams"); err != nil {
+		return errors.Wrap(err, "could not exams")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS exam_attempts"); err != nil {
+		return errors.Wrap(err, "could not exam_attempts")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS exam_attempt_questions"); err != nil {
+		return errors.Wrap(err, "could not exam_attempt_questions")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS question_stats"); err != nil {
+		return errors.Wrap(err, "could not question_stats")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS embeddings"); err != nil {
+		return errors.Wrap(err, "could not embeddings")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS content_chunks"); err != nil {
+		return errors.Wrap(err, "could not content_chunks")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS video_transcripts"); err != nil {
+		return errors.Wrap(err, "could not video_transcripts")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS llm_usage"); err != nil {
+		return errors.Wrap(err, "could not llm_usage")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS answer_cache"); err != nil {
+		return errors.Wrap(err, "could not answer_cache")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS conversation_summaries"); err != nil {
+		return errors.Wrap(err, "could not conversation_summaries")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS moderation_flags"); err != nil {
+		return errors.Wrap(err, "could not moderation_flags")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS tutor_personalities"); err != nil {
+		return errors.Wrap(err, "could not tutor_personalities")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS assignment_hints"); err != nil {
+		return errors.Wrap(err, "could not assignment_hints")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS questions"); err != nil {
+		return errors.Wrap(err, "could not questions")
+	}
+
+	if _, err := tx.Exec("DROP TABLE IF EXISTS question_choices"); err != nil {
+		return errors.Wrap(err, "could not question_choices")
+	}
+
 	if err := tx.Commit(); err != nil {
 		return errors.Wrap(err, "could not commit")
 	}
@@ -281,6 +462,99 @@ func (sqlDB *SQLStore) EmptyAllTables() {
 		if _, err := sqlDB.db.Exec("DELETE FROM user_node_notes"); err != nil {
 			sqlDB.logger.Fatal("can't delete from user_node_notes", log.Err(err))
 		}
+		if _, err := sqlDB.db.Exec("DELETE FROM completion_policies"); err != nil {
+			sqlDB.logger.Fatal("can't delete from completion_policies", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM user_node_status_history"); err != nil {
+			sqlDB.logger.Fatal("can't delete from user_node_status_history", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM user_plans"); err != nil {
+			sqlDB.logger.Fatal("can't delete from user_plans", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM user_streaks"); err != nil {
+			sqlDB.logger.Fatal("can't delete from user_streaks", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM xp_events"); err != nil {
+			sqlDB.logger.Fatal("can't delete from xp_events", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM user_achievements"); err != nil {
+			sqlDB.logger.Fatal("can't delete from user_achievements", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM certificates"); err != nil {
+			sqlDB.logger.Fatal("can't delete from certificates", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM leaderboard_scores"); err != nil {
+			sqlDB.logger.Fatal("can't delete from leaderboard_scores", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM user_friends"); err != nil {
+			sqlDB.logger.Fatal("can't delete from user_friends", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM classrooms"); err != nil {
+			sqlDB.logger.Fatal("can't delete from classrooms", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM classroom_students"); err != nil {
+			sqlDB.logger.Fatal("can't delete from classroom_students", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM classroom_goals"); err != nil {
+			sqlDB.logger.Fatal("can't delete from classroom_goals", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM study_groups"); err != nil {
+			sqlDB.logger.Fatal("can't delete from study_groups", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM study_group_members"); err != nil {
+			sqlDB.logger.Fatal("can't delete from study_group_members", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM review_quizzes"); err != nil {
+			sqlDB.logger.Fatal("can't delete from review_quizzes", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM review_quiz_questions"); err != nil {
+			sqlDB.logger.Fatal("can't delete from review_quiz_questions", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM exams"); err != nil {
+			sqlDB.logger.Fatal("can't delete from exams", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM exam_attempts"); err != nil {
+			sqlDB.logger.Fatal("can't delete from exam_attempts", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM exam_attempt_questions"); err != nil {
+			sqlDB.logger.Fatal("can't delete from exam_attempt_questions", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM question_stats"); err != nil {
+			sqlDB.logger.Fatal("can't delete from question_stats", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM embeddings"); err != nil {
+			sqlDB.logger.Fatal("can't delete from embeddings", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM content_chunks"); err != nil {
+			sqlDB.logger.Fatal("can't delete from content_chunks", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM video_transcripts"); err != nil {
+			sqlDB.logger.Fatal("can't delete from video_transcripts", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM llm_usage"); err != nil {
+			sqlDB.logger.Fatal("can't delete from llm_usage", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM answer_cache"); err != nil {
+			sqlDB.logger.Fatal("can't delete from answer_cache", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM conversation_summaries"); err != nil {
+			sqlDB.logger.Fatal("can't delete from conversation_summaries", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM moderation_flags"); err != nil {
+			sqlDB.logger.Fatal("can't delete from moderation_flags", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM tutor_personalities"); err != nil {
+			sqlDB.logger.Fatal("can't delete from tutor_personalities", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM assignment_hints"); err != nil {
+			sqlDB.logger.Fatal("can't delete from assignment_hints", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM questions"); err != nil {
+			sqlDB.logger.Fatal("can't delete from questions", log.Err(err))
+		}
+		if _, err := sqlDB.db.Exec("DELETE FROM question_choices"); err != nil {
+			sqlDB.logger.Fatal("can't delete from question_choices", log.Err(err))
+		}
 	}
 }
 
@@ -435,3 +709,98 @@ func (sqlDB *SQLStore) Customer() CustomerStore {
 func (sqlDB *SQLStore) NodeNote() NodeNoteStore {
 	return sqlDB.nodeNoteStore
 }
+
+// CompletionPolicy returns an interface to manage completion policies of the nodes in the DB
+func (sqlDB *SQLStore) CompletionPolicy() CompletionPolicyStore {
+	return sqlDB.completionPolicyStore
+}
+
+// Plan returns an interface to manage users' study plans in the DB
+func (sqlDB *SQLStore) Plan() PlanStore {
+	return sqlDB.planStore
+}
+
+// Streak returns an interface to manage users' learning streaks in the DB
+func (sqlDB *SQLStore) Streak() StreakStore {
+	return sqlDB.streakStore
+}
+
+// Gamification returns an interface to manage users' XP and achievements in the DB
+func (sqlDB *SQLStore) Gamification() GamificationStore {
+	return sqlDB.gamificationStore
+}
+
+// Certificate returns an interface to manage users' certificates in the DB
+func (sqlDB *SQLStore) Certificate() CertificateStore {
+	return sqlDB.certificateStore
+}
+
+// Leaderboard returns an interface to manage cached leaderboard scores and users' friends in the DB
+func (sqlDB *SQLStore) Leaderboard() LeaderboardStore {
+	return sqlDB.leaderboardStore
+}
+
+// Classroom returns an interface to manage classrooms in the DB
+func (sqlDB *SQLStore) Classroom() ClassroomStore {
+	return sqlDB.classroomStore
+}
+
+// StudyGroup returns an interface to manage study groups and their members in the DB
+func (sqlDB *SQLStore) StudyGroup() StudyGroupStore {
+	return sqlDB.studyGroupStore
+}
+
+// Quiz returns an interface to manage review quizzes in the DB
+func (sqlDB *SQLStore) Quiz() QuizStore {
+	return sqlDB.quizStore
+}
+
+// Exam returns an interface to manage exams and their attempts in the DB
+func (sqlDB *SQLStore) Exam() ExamStore {
+	return sqlDB.examStore
+}
+
+// QuestionStats returns an interface to manage computed question statistics in the DB
+func (sqlDB *SQLStore) QuestionStats() QuestionStatsStore {
+	return sqlDB.questionStatsStore
+}
+
+// Embedding returns an interface to manage embeddings in the DB
+func (sqlDB *SQLStore) Embedding() EmbeddingStore {
+	return sqlDB.embeddingStore
+}
+
+// ContentChunk returns an interface to manage content chunks in the DB
+func (sqlDB *SQLStore) ContentChunk() ContentChunkStore {
+	return sqlDB.contentChunkStore
+}
+
+// LLMUsage returns an interface to manage the usage ledger of the language model in the DB
+func (sqlDB *SQLStore) LLMUsage() LLMUsageStore {
+	return sqlDB.llmUsageStore
+}
+
+// AnswerCache returns an interface to manage cached tutor answers in the DB
+func (sqlDB *SQLStore) AnswerCache() AnswerCacheStore {
+	return sqlDB.answerCacheStore
+}
+
+// ConversationSummary returns an interface to manage conversation summaries in the DB
+func (sqlDB *SQLStore) ConversationSummary() ConversationSummaryStore {
+	return sqlDB.conversationSummaryStore
+}
+
+// Moderation returns an interface to manage moderation flags in the DB
+func (sqlDB *SQLStore) Moderation() ModerationStore {
+	return sqlDB.moderationStore
+}
+
+// TutorPersonality returns an interface to manage tutor personalities in the DB
+func (sqlDB *SQLStore) TutorPersonality() TutorPersonalityStore {
+	return sqlDB.tutorPersonalityStore
+}
+
+// AssignmentHint returns an interface to manage assignment hints in the DB
+func (sqlDB *SQLStore) AssignmentHint() AssignmentHintStore {
+	return sqlDB.assignmentHintStore
+}
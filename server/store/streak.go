@@ -0,0 +1,74 @@
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// StreakStore is an interface to crud learning streaks
+type StreakStore interface {
+	Save(streak *model.Streak) error
+	Get(userID string) (*model.Streak, error)
+}
+
+// SQLStreakStore is a struct to store learning streaks
+type SQLStreakStore struct {
+	sqlStore     *SQLStore
+	streakSelect sq.SelectBuilder
+}
+
+// NewStreakStore creates a new store for learning streaks.
+func NewStreakStore(db *SQLStore) StreakStore {
+	streakSelect := db.builder.
+		Select(
+			"s.user_id",
+			"s.current_streak",
+			"s.max_streak",
+			"s.last_active_date",
+			"s.freezes",
+			"s.freezes_used",
+			"s.frozen_until",
+			"s.updated_at",
+		).
+		From("user_streaks s")
+
+	return &SQLStreakStore{
+		sqlStore:     db,
+		streakSelect: streakSelect,
+	}
+}
+
+// Save creates or overrides user's streak
+func (ss *SQLStreakStore) Save(streak *model.Streak) error {
+	_, err := ss.sqlStore.execBuilder(ss.sqlStore.db, ss.sqlStore.builder.
+		Insert("user_streaks").
+		SetMap(map[string]interface{}{
+			"user_id":          streak.UserID,
+			"current_streak":   streak.CurrentStreak,
+			"max_streak":       streak.MaxStreak,
+			"last_active_date": streak.LastActiveDate,
+			"freezes":          streak.Freezes,
+			"freezes_used":     streak.FreezesUsed,
+			"frozen_until":     streak.FrozenUntil,
+			"updated_at":       streak.UpdatedAt,
+		}).
+		SuffixExpr(sq.Expr(
+			"ON CONFLICT (user_id) DO UPDATE SET current_streak = ?, max_streak = ?, last_active_date = ?, freezes = ?, freezes_used = ?, frozen_until = ?, updated_at = ?",
+			streak.CurrentStreak, streak.MaxStreak, streak.LastActiveDate, streak.Freezes, streak.FreezesUsed, streak.FrozenUntil, streak.UpdatedAt),
+		))
+	if err != nil {
+		return errors.Wrapf(err, "can't save streak for user: %s", streak.UserID)
+	}
+	return nil
+}
+
+// Get gets user's streak
+func (ss *SQLStreakStore) Get(userID string) (*model.Streak, error) {
+	var streak model.Streak
+	if err := ss.sqlStore.getBuilder(ss.sqlStore.db, &streak,
+		ss.streakSelect.Where(sq.Eq{"s.user_id": userID})); err != nil {
+		return nil, errors.Wrapf(err, "can't get streak for user: %s", userID)
+	}
+	return &streak, nil
+}
@@ -0,0 +1,194 @@
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+// StudyGroupStore is an interface to crud study groups and their members
+type StudyGroupStore interface {
+	Save(group *model.StudyGroup) (*model.StudyGroup, error)
+	Get(id string) (*model.StudyGroup, error)
+	GetGroups(goalNodeID string) ([]*model.StudyGroup, error)
+	GetForUser(userID string) ([]*model.StudyGroup, error)
+	Delete(id string) error
+	AddMember(groupID, userID string) (bool, error)
+	RemoveMember(groupID, userID string) error
+	IsMember(groupID, userID string) (bool, error)
+	GetMembers(groupID string) ([]*model.User, error)
+}
+
+// SQLStudyGroupStore is a struct to store study groups
+type SQLStudyGroupStore struct {
+	sqlStore    *SQLStore
+	groupSelect sq.SelectBuilder
+}
+
+// NewStudyGroupStore creates a new store for study groups.
+func NewStudyGroupStore(db *SQLStore) StudyGroupStore {
+	groupSelect := db.builder.
+		Select(
+			"g.id",
+			"g.creator_id",
+			"g.name",
+			"g.description",
+			"g.goal_node_id",
+			"n.name AS goal_name",
+			"(SELECT COUNT(*) FROM study_group_members m WHERE m.group_id = g.id) AS member_count",
+			"g.created_at",
+			"g.deleted_at",
+		).
+		From("study_groups g").
+		Join("nodes n ON n.id = g.goal_node_id")
+
+	return &SQLStudyGroupStore{
+		sqlStore:    db,
+		groupSelect: groupSelect,
+	}
+}
+
+// Save saves study group in the DB
+func (gs *SQLStudyGroupStore) Save(group *model.StudyGroup) (*model.StudyGroup, error) {
+	group.BeforeSave()
+	if err := group.IsValid(); err != nil {
+		return nil, err
+	}
+
+	_, err := gs.sqlStore.execBuilder(gs.sqlStore.db, gs.sqlStore.builder.
+		Insert("study_groups").
+		SetMap(map[string]interface{}{
+			"id":           group.ID,
+			"creator_id":   group.CreatorID,
+			"name":         group.Name,
+			"description":  group.Description,
+			"goal_node_id": group.GoalNodeID,
+			"created_at":   group.CreatedAt,
+			"deleted_at":   0,
+		}))
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't save study group: %s", group.Name)
+	}
+	return group, nil
+}
+
+// Get gets study group by id
+func (gs *SQLStudyGroupStore) Get(id string) (*model.StudyGroup, error) {
+	var group model.StudyGroup
+	if err := gs.sqlStore.getBuilder(gs.sqlStore.db, &group, gs.groupSelect.
+		Where(sq.And{
+			sq.Eq{"g.id": id},
+			sq.Eq{"g.deleted_at": 0},
+		})); err != nil {
+		return nil, errors.Wrapf(err, "can't get study group by id: %s", id)
+	}
+	return &group, nil
+}
+
+// GetGroups gets all the study groups, filtered by the goal if goalNodeID is set
+func (gs *SQLStudyGroupStore) GetGroups(goalNodeID string) ([]*model.StudyGroup, error) {
+	query := gs.groupSelect.
+		Where(sq.Eq{"g.deleted_at": 0}).
+		OrderBy("g.created_at DESC")
+	if goalNodeID != "" {
+		query = query.Where(sq.Eq{"g.goal_node_id": goalNodeID})
+	}
+
+	var groups []*model.StudyGroup
+	if err := gs.sqlStore.selectBuilder(gs.sqlStore.db, &groups, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get study groups for goal: %s", goalNodeID)
+	}
+	return groups, nil
+}
+
+// GetForUser gets study groups the user is a member of
+func (gs *SQLStudyGroupStore) GetForUser(userID string) ([]*model.StudyGroup, error) {
+	var groups []*model.StudyGroup
+	if err := gs.sqlStore.selectBuilder(gs.sqlStore.db, &groups, gs.groupSelect.
+		Join("study_group_members gm ON gm.group_id = g.id").
+		Where(sq.And{
+			sq.Eq{"gm.user_id": userID},
+			sq.Eq{"g.deleted_at": 0},
+		}).
+		OrderBy("gm.joined_at")); err != nil {
+		return nil, errors.Wrapf(err, "can't get study groups for user: %s", userID)
+	}
+	return groups, nil
+}
+
+// Delete soft deletes the study group
+func (gs *SQLStudyGroupStore) Delete(id string) error {
+	if _, err := gs.sqlStore.execBuilder(gs.sqlStore.db, gs.sqlStore.builder.
+		Update("study_groups").
+		Set("deleted_at", model.GetMillis()).
+		Where(sq.Eq{"id": id})); err != nil {
+		return errors.Wrapf(err, "can't delete study group: %s", id)
+	}
+	return nil
+}
+
+// AddMember adds the user to the study group, returns false if user is already a member
+func (gs *SQLStudyGroupStore) AddMember(groupID, userID string) (bool, error) {
+	result, err := gs.sqlStore.execBuilder(gs.sqlStore.db, gs.sqlStore.builder.
+		Insert("study_group_members").
+		SetMap(map[string]interface{}{
+			"group_id":  groupID,
+			"user_id":   userID,
+			"joined_at": model.GetMillis(),
+		}).
+		Suffix("ON CONFLICT (group_id, user_id) DO NOTHING"))
+	if err != nil {
+		return false, errors.Wrapf(err, "can't add member %s to study group: %s", userID, groupID)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "can't get affected rows")
+	}
+	return rows > 0, nil
+}
+
+// RemoveMember removes the user from the study group
+func (gs *SQLStudyGroupStore) RemoveMember(groupID, userID string) error {
+	if _, err := gs.sqlStore.execBuilder(gs.sqlStore.db, gs.sqlStore.builder.
+		Delete("study_group_members").
+		Where(sq.And{
+			sq.Eq{"group_id": groupID},
+			sq.Eq{"user_id": userID},
+		})); err != nil {
+		return errors.Wrapf(err, "can't remove member %s from study group: %s", userID, groupID)
+	}
+	return nil
+}
+
+// IsMember returns true if the user is a member of the study group
+func (gs *SQLStudyGroupStore) IsMember(groupID, userID string) (bool, error) {
+	var count int
+	if err := gs.sqlStore.getBuilder(gs.sqlStore.db, &count, gs.sqlStore.builder.
+		Select("COUNT(*)").
+		From("study_group_members").
+		Where(sq.And{
+			sq.Eq{"group_id": groupID},
+			sq.Eq{"user_id": userID},
+		})); err != nil {
+		return false, errors.Wrapf(err, "can't check member %s of study group: %s", userID, groupID)
+	}
+	return count > 0, nil
+}
+
+// GetMembers gets members of the study group
+func (gs *SQLStudyGroupStore) GetMembers(groupID string) ([]*model.User, error) {
+	var members []*model.User
+	query := gs.sqlStore.builder.
+		Select("u.id", "u.username", "u.first_name", "u.last_name").
+		From("study_group_members gm").
+		Join("users u ON u.id = gm.user_id").
+		Where(sq.And{
+			sq.Eq{"gm.group_id": groupID},
+			sq.Eq{"u.deleted_at": 0},
+		}).
+		OrderBy("gm.joined_at")
+	if err := gs.sqlStore.selectBuilder(gs.sqlStore.db, &members, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get members of study group: %s", groupID)
+	}
+	return members, nil
+}
@@ -97,6 +97,9 @@ func (ts *SQLTextStore) GetTexts(options *model.TextGetOptions) ([]*model.Text,
 	if options.NodeID != "" {
 		query = query.Where(sq.Eq{"t.node_id": options.NodeID})
 	}
+	if options.Name != "" {
+		query = query.Where(sq.Eq{"t.name": options.Name})
+	}
 	if options.PerPage > 0 {
 		query = query.Limit(uint64(options.PerPage))
 	}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oseducation/knowledge-graph/model"
+	"github.com/pkg/errors"
+)
+
+type sqlTutorPersonality struct {
+	model.TutorPersonality
+	PromptsJSON string `db:"prompts"`
+}
+
+// TutorPersonalityStore is an interface to store tutor personalities
+type TutorPersonalityStore interface {
+	Save(personality *model.TutorPersonality) (*model.TutorPersonality, error)
+	Get(id string) (*model.TutorPersonality, error)
+	GetAll() ([]*model.TutorPersonality, error)
+	Update(personality *model.TutorPersonality) error
+	Delete(id string) error
+}
+
+// SQLTutorPersonalityStore is a struct to store tutor personalities
+type SQLTutorPersonalityStore struct {
+	sqlStore          *SQLStore
+	personalitySelect sq.SelectBuilder
+}
+
+// NewTutorPersonalityStore creates a new store for tutor personalities.
+func NewTutorPersonalityStore(db *SQLStore) TutorPersonalityStore {
+	personalitySelect := db.builder.
+		Select(
+			"tp.id",
+			"tp.name",
+			"tp.avatar",
+			"tp.prompts",
+			"tp.tier",
+			"tp.enabled",
+			"tp.created_at",
+			"tp.updated_at",
+		).
+		From("tutor_personalities tp")
+
+	return &SQLTutorPersonalityStore{
+		sqlStore:          db,
+		personalitySelect: personalitySelect,
+	}
+}
+
+// Save saves the tutor personality
+func (tps *SQLTutorPersonalityStore) Save(personality *model.TutorPersonality) (*model.TutorPersonality, error) {
+	personality.BeforeSave()
+	if err := personality.IsValid(); err != nil {
+		return nil, err
+	}
+	if err := saveTutorPersonality(tps.sqlStore, tps.sqlStore.db, personality); err != nil {
+		return nil, err
+	}
+	return personality, nil
+}
+
+// saveTutorPersonality is shared with the migration adding the default personalities
+func saveTutorPersonality(sqlStore *SQLStore, e execer, personality *model.TutorPersonality) error {
+	promptsJSON, err := json.Marshal(personality.Prompts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal prompts of tutor personality: %s", personality.ID)
+	}
+
+	if _, err := sqlStore.execBuilder(e, sqlStore.builder.
+		Insert("tutor_personalities").
+		SetMap(map[string]interface{}{
+			"id":         personality.ID,
+			"name":       personality.Name,
+			"avatar":     personality.Avatar,
+			"prompts":    string(promptsJSON),
+			"tier":       personality.Tier,
+			"enabled":    personality.Enabled,
+			"created_at": personality.CreatedAt,
+			"updated_at": personality.UpdatedAt,
+		})); err != nil {
+		return errors.Wrapf(err, "can't save tutor personality: %s", personality.ID)
+	}
+	return nil
+}
+
+// Get gets the tutor personality by id
+func (tps *SQLTutorPersonalityStore) Get(id string) (*model.TutorPersonality, error) {
+	var personality sqlTutorPersonality
+	if err := tps.sqlStore.getBuilder(tps.sqlStore.db, &personality, tps.personalitySelect.Where(sq.Eq{"tp.id": id})); err != nil {
+		return nil, errors.Wrapf(err, "can't get tutor personality by id: %s", id)
+	}
+	return personality.toModel()
+}
+
+// GetAll gets all the tutor personalities in the order they were added
+func (tps *SQLTutorPersonalityStore) GetAll() ([]*model.TutorPersonality, error) {
+	var personalities []*sqlTutorPersonality
+	if err := tps.sqlStore.selectBuilder(tps.sqlStore.db, &personalities, tps.personalitySelect.OrderBy("tp.created_at ASC", "tp.id ASC")); err != nil {
+		return nil, errors.Wrap(err, "can't get tutor personalities")
+	}
+	result := make([]*model.TutorPersonality, 0, len(personalities))
+	for _, p := range personalities {
+		personality, err := p.toModel()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, personality)
+	}
+	return result, nil
+}
+
+// Update updates the tutor personality
+func (tps *SQLTutorPersonalityStore) Update(personality *model.TutorPersonality) error {
+	if err := personality.IsValid(); err != nil {
+		return err
+	}
+	promptsJSON, err := json.Marshal(personality.Prompts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal prompts of tutor personality: %s", personality.ID)
+	}
+	personality.UpdatedAt = model.GetMillis()
+
+	if _, err := tps.sqlStore.execBuilder(tps.sqlStore.db, tps.sqlStore.builder.
+		Update("tutor_personalities").
+		SetMap(map[string]interface{}{
+			"name":       personality.Name,
+			"avatar":     personality.Avatar,
+			"prompts":    string(promptsJSON),
+			"tier":       personality.Tier,
+			"enabled":    personality.Enabled,
+			"updated_at": personality.UpdatedAt,
+		}).
+		Where(sq.Eq{"id": personality.ID})); err != nil {
+		return errors.Wrapf(err, "can't update tutor personality: %s", personality.ID)
+	}
+	return nil
+}
+
+// Delete deletes the tutor personality
+func (tps *SQLTutorPersonalityStore) Delete(id string) error {
+	if _, err := tps.sqlStore.execBuilder(tps.sqlStore.db, tps.sqlStore.builder.
+		Delete("tutor_personalities").
+		Where(sq.Eq{"id": id})); err != nil {
+		return errors.Wrapf(err, "can't delete tutor personality: %s", id)
+	}
+	return nil
+}
+
+func (p *sqlTutorPersonality) toModel() (*model.TutorPersonality, error) {
+	personality := p.TutorPersonality
+	personality.Prompts = map[string]string{}
+	if p.PromptsJSON != "" {
+		if err := json.Unmarshal([]byte(p.PromptsJSON), &personality.Prompts); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal prompts of tutor personality: %s", p.ID)
+		}
+	}
+	return &personality, nil
+}
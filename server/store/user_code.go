@@ -12,6 +12,7 @@ type UserCodeStore interface {
 	Update(new *model.UserNodeCode) error
 	GetCodesForUserNode(userID, nodeID string) ([]*model.UserNodeCode, error)
 	GetCodeForUserNode(userID, nodeID, codeName string) (*model.UserNodeCode, error)
+	GetLatestCodeForUserNode(userID, nodeID string) (*model.UserNodeCode, error)
 }
 
 // SQLUserCodeStore is a struct to store users's codes
@@ -28,6 +29,7 @@ func NewUserCodeStore(db *SQLStore) UserCodeStore {
 			"uc.node_id",
 			"uc.code_name",
 			"uc.code",
+			"uc.updated_at",
 		).
 		From("user_node_codes uc")
 
@@ -50,10 +52,11 @@ func (ucs *SQLUserCodeStore) Save(userCode *model.UserNodeCode) error {
 	_, err = ucs.sqlStore.execBuilder(ucs.sqlStore.db, ucs.sqlStore.builder.
 		Insert("user_node_codes").
 		SetMap(map[string]interface{}{
-			"user_id":   userCode.UserID,
-			"node_id":   userCode.NodeID,
-			"code_name": userCode.CodeName,
-			"code":      userCode.Code,
+			"user_id":    userCode.UserID,
+			"node_id":    userCode.NodeID,
+			"code_name":  userCode.CodeName,
+			"code":       userCode.Code,
+			"updated_at": model.GetMillis(),
 		}))
 	if err != nil {
 		return errors.Wrapf(err, "can't save user node code with code name:%s for user:%s", userCode.CodeName, userCode.UserID)
@@ -69,7 +72,8 @@ func (ucs *SQLUserCodeStore) Update(new *model.UserNodeCode) error {
 	_, err := ucs.sqlStore.execBuilder(ucs.sqlStore.db, ucs.sqlStore.builder.
 		Update("user_node_codes").
 		SetMap(map[string]interface{}{
-			"code": new.Code,
+			"code":       new.Code,
+			"updated_at": model.GetMillis(),
 		}).
 		Where(sq.And{
 			sq.Eq{"user_id": new.UserID},
@@ -107,3 +111,18 @@ func (ucs *SQLUserCodeStore) GetCodeForUserNode(userID, nodeID, codeName string)
 	}
 	return &userCode, nil
 }
+
+// GetLatestCodeForUserNode gets the code user has saved last on the node
+func (ucs *SQLUserCodeStore) GetLatestCodeForUserNode(userID, nodeID string) (*model.UserNodeCode, error) {
+	var userCode model.UserNodeCode
+	if err := ucs.sqlStore.getBuilder(ucs.sqlStore.db, &userCode,
+		ucs.userCodesSelect.Where(sq.And{
+			sq.Eq{"user_id": userID},
+			sq.Eq{"node_id": nodeID},
+		}).
+			OrderBy("uc.updated_at DESC").
+			Limit(1)); err != nil {
+		return nil, errors.Wrapf(err, "can't get the latest code of user %s on node %s", userID, nodeID)
+	}
+	return &userCode, nil
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	sq "github.com/Masterminds/squirrel"
 	"github.com/oseducation/knowledge-graph/model"
 	"github.com/pkg/errors"
 )
@@ -8,6 +9,8 @@ import (
 // UserInteractionStore is an interface to crud interactions
 type UserInteractionStore interface {
 	Save(userInteraction *model.UserInteraction) error
+	GetInteractions(userID string, since int64) ([]*model.UserInteraction, error)
+	GetLastActive(userIDs []string, since int64) (map[string]int64, error)
 }
 
 // SQLUserInteractionStore is a struct to store users's interactions
@@ -43,3 +46,59 @@ func (uis *SQLUserInteractionStore) Save(userInteraction *model.UserInteraction)
 	}
 	return nil
 }
+
+// GetInteractions gets user's interactions started after the time
+func (uis *SQLUserInteractionStore) GetInteractions(userID string, since int64) ([]*model.UserInteraction, error) {
+	query := uis.sqlStore.builder.
+		Select(
+			"ui.id",
+			"ui.user_id",
+			"ui.start_date",
+			"ui.end_date",
+			"ui.url",
+			"ui.ui_component_name",
+			"ui.tag",
+		).
+		From("user_interactions ui").
+		Where(sq.And{
+			sq.Eq{"ui.user_id": userID},
+			sq.Gt{"ui.start_date": since},
+		}).
+		OrderBy("ui.start_date ASC")
+
+	var interactions []*model.UserInteraction
+	if err := uis.sqlStore.selectBuilder(uis.sqlStore.db, &interactions, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get interactions for user:%s", userID)
+	}
+	return interactions, nil
+}
+
+// GetLastActive gets the time of the last interaction of each of the users active after the time
+func (uis *SQLUserInteractionStore) GetLastActive(userIDs []string, since int64) (map[string]int64, error) {
+	type LastActivity struct {
+		UserID     string `db:"user_id"`
+		LastActive int64  `db:"last_active"`
+	}
+	query := uis.sqlStore.builder.
+		Select(
+			"ui.user_id",
+			"MAX(ui.end_date) AS last_active",
+		).
+		From("user_interactions ui").
+		Where(sq.And{
+			sq.Eq{"ui.user_id": userIDs},
+			sq.Gt{"ui.end_date": since},
+		}).
+		GroupBy("ui.user_id")
+
+	var rows []*LastActivity
+	if err := uis.sqlStore.selectBuilder(uis.sqlStore.db, &rows, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get last active time for users:%v", userIDs)
+	}
+
+	lastActive := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		lastActive[row.UserID] = row.LastActive
+	}
+	return lastActive, nil
+}
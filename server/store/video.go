@@ -16,6 +16,12 @@ type VideoStore interface {
 	Delete(video *model.Video) error
 	AddUserVideoEngagement(userID, videoID string, userEngagementData *model.UserEngagementData) error
 	GetVideosFromNodeIDs(nodeIDs []string) ([]string, error)
+	GetVideoKeysForUser(userID string, nodeIDs []string) ([]*model.UserVideoKey, error)
+	GetNumberOfFinishedVideos(userID, nodeID string) (int, error)
+	GetVideoLengths(nodeIDs []string) ([]*model.NodeVideoLength, error)
+	GetWatchStats(userID string) (*model.VideoWatchStats, error)
+	SaveTranscript(videoID string, segments []*model.TranscriptSegment) error
+	GetTranscript(videoID string) ([]*model.TranscriptSegment, error)
 }
 
 // SQLVideoStore is a struct to store videos
@@ -232,3 +238,128 @@ func (vs *SQLVideoStore) GetVideosFromNodeIDs(nodeIDs []string) ([]string, error
 
 	return videoKeys, nil
 }
+
+// GetVideoKeysForUser gets keys of the nodes' videos and whether the user has watched them till the end
+func (vs *SQLVideoStore) GetVideoKeysForUser(userID string, nodeIDs []string) ([]*model.UserVideoKey, error) {
+	query := vs.sqlStore.builder.
+		Select(
+			"v.node_id",
+			"v.key",
+			"COALESCE(uv.times_finished, 0) > 0 AS finished",
+		).
+		From("videos v").
+		LeftJoin("user_videos uv ON uv.video_id = v.id AND uv.user_id = ?", userID).
+		Where(sq.And{
+			sq.Eq{"v.node_id": nodeIDs},
+			sq.Eq{"v.deleted_at": 0},
+		})
+
+	var keys []*model.UserVideoKey
+	if err := vs.sqlStore.selectBuilder(vs.sqlStore.db, &keys, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get video keys for user %s and nodes %v", userID, nodeIDs)
+	}
+	return keys, nil
+}
+
+// GetNumberOfFinishedVideos gets number of the node's videos which user has watched till the end at least once
+func (vs *SQLVideoStore) GetNumberOfFinishedVideos(userID, nodeID string) (int, error) {
+	query := vs.sqlStore.builder.
+		Select("COUNT(*)").
+		From("user_videos uv").
+		Join("videos v ON v.id = uv.video_id").
+		Where(sq.And{
+			sq.Eq{"uv.user_id": userID},
+			sq.Eq{"v.node_id": nodeID},
+			sq.Gt{"uv.times_finished": 0},
+		})
+
+	var count int
+	if err := vs.sqlStore.getBuilder(vs.sqlStore.db, &count, query); err != nil {
+		return 0, errors.Wrapf(err, "can't get number of finished videos for user %s and node %s", userID, nodeID)
+	}
+	return count, nil
+}
+
+// GetVideoLengths gets total length of videos for each of the nodes
+func (vs *SQLVideoStore) GetVideoLengths(nodeIDs []string) ([]*model.NodeVideoLength, error) {
+	query := vs.sqlStore.builder.
+		Select(
+			"v.node_id",
+			"SUM(v.length) AS length",
+		).
+		From("videos v").
+		Where(sq.And{
+			sq.Eq{"v.node_id": nodeIDs},
+			sq.Eq{"v.deleted_at": 0},
+		}).
+		GroupBy("v.node_id")
+
+	var lengths []*model.NodeVideoLength
+	if err := vs.sqlStore.selectBuilder(vs.sqlStore.db, &lengths, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get video lengths for nodes %v", nodeIDs)
+	}
+	return lengths, nil
+}
+
+// GetWatchStats gets total number of started and finished videos of the user
+func (vs *SQLVideoStore) GetWatchStats(userID string) (*model.VideoWatchStats, error) {
+	query := vs.sqlStore.builder.
+		Select(
+			"COALESCE(SUM(uv.times_started), 0) AS times_started",
+			"COALESCE(SUM(uv.times_finished), 0) AS times_finished",
+		).
+		From("user_videos uv").
+		Where(sq.Eq{"uv.user_id": userID})
+
+	var stats model.VideoWatchStats
+	if err := vs.sqlStore.getBuilder(vs.sqlStore.db, &stats, query); err != nil {
+		return nil, errors.Wrapf(err, "can't get watch stats for user %s", userID)
+	}
+	return &stats, nil
+}
+
+// SaveTranscript replaces the transcript of the video
+func (vs *SQLVideoStore) SaveTranscript(videoID string, segments []*model.TranscriptSegment) error {
+	tx, err := vs.sqlStore.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer vs.sqlStore.finalizeTransaction(tx)
+
+	if _, err := vs.sqlStore.execBuilder(tx, vs.sqlStore.builder.
+		Delete("video_transcripts").
+		Where(sq.Eq{"video_id": videoID})); err != nil {
+		return errors.Wrapf(err, "can't delete old transcript of video: %s", videoID)
+	}
+
+	for _, segment := range segments {
+		if _, err := vs.sqlStore.execBuilder(tx, vs.sqlStore.builder.
+			Insert("video_transcripts").
+			SetMap(map[string]interface{}{
+				"video_id":      videoID,
+				"start_seconds": segment.StartSeconds,
+				"end_seconds":   segment.EndSeconds,
+				"text":          segment.Text,
+			})); err != nil {
+			return errors.Wrapf(err, "can't save transcript segment of video: %s", videoID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+	return nil
+}
+
+// GetTranscript gets the transcript of the video ordered by time
+func (vs *SQLVideoStore) GetTranscript(videoID string) ([]*model.TranscriptSegment, error) {
+	segments := []*model.TranscriptSegment{}
+	if err := vs.sqlStore.selectBuilder(vs.sqlStore.db, &segments, vs.sqlStore.builder.
+		Select("vt.video_id", "vt.start_seconds", "vt.end_seconds", "vt.text").
+		From("video_transcripts vt").
+		Where(sq.Eq{"vt.video_id": videoID}).
+		OrderBy("vt.start_seconds")); err != nil {
+		return nil, errors.Wrapf(err, "can't get transcript of video: %s", videoID)
+	}
+	return segments, nil
+}
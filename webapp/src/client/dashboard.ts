@@ -34,6 +34,10 @@ export class DashboardClient{
         return `${this.getDashboardRoute()}/steak`;
     }
 
+    getSteakFreezeRoute() {
+        return `${this.getSteakRoute()}/freeze`;
+    }
+
     getAITutorPostsRoute() {
         return `${this.getDashboardRoute()}/number_of_bot_posts_monthly`;
     }
@@ -78,6 +82,11 @@ export class DashboardClient{
         return data;
     };
 
+    spendSteakFreezes = async () => {
+        const data = this.rest.doFetch<Steak>(`${this.getSteakFreezeRoute()}`, {method: 'post'});
+        return data;
+    };
+
     getAITutorPosts = async () => {
         if (!this.rest.me || !this.rest.me.id){
             return null;
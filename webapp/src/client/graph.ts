@@ -1,4 +1,4 @@
-import {Goal, Graph} from "../types/graph";
+import {Goal, Graph, Recommendation} from "../types/graph";
 
 import {Rest} from "./rest";
 
@@ -34,6 +34,15 @@ export class GraphClient{
         return data;
     };
 
+    getNextTopic = async (goalID: string) => {
+        if (!this.rest.me || !this.rest.me.id){
+            return null;
+        }
+        const url = `${this.getGraphRoute()}/next?goal_id=${goalID}`;
+        const data = this.rest.doFetch<Recommendation | null>(url, {method: 'get'});
+        return data;
+    };
+
     getGoals = async() => {
         if (!this.rest.me || !this.rest.me.id){
             return [];
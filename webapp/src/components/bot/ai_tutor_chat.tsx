@@ -5,9 +5,10 @@ import {styled} from '@mui/system';
 import {DashboardColors} from '../../ThemeOptions';
 import useAuth from '../../hooks/useAuth';
 import {Client} from '../../client/client';
-import {Action, Post, PostType, PostTypeChatGPT, PostTypeChatGPTCorrectAnswerExplanation, PostTypeChatGPTIncorrectAnswerExplanation, PostTypeFilledInByAction, PostTypeTestAnswer, PostTypeText, PostTypeTopic, PostTypeVideo} from '../../types/posts';
+import {Action, Citation, Post, PostType, PostTypeChatGPT, PostTypeChatGPTCorrectAnswerExplanation, PostTypeChatGPTIncorrectAnswerExplanation, PostTypeFilledInByAction, PostTypeTestAnswer, PostTypeText, PostTypeTopic, PostTypeVideo} from '../../types/posts';
 import {Analytics} from '../../analytics';
 import useGraph from '../../hooks/useGraph';
+import {NodeTypeAssignment} from '../../types/graph';
 
 import PostComponent from './post_component';
 import {constructBotPost} from './create_post';
@@ -34,6 +35,7 @@ const AITutorChat = () => {
     const [botMessage, setBotMessage] = useState<string>('');
     const [scrollListeners, setScrollListeners] = useState<(() => void)[]>([]);
     const [testCheck, setTestCheck] = useState<TestCheckInterface | null>(null);
+    const [hintNodeID, setHintNodeID] = useState<string | null>(null);
 
     const scrollToBottom = () => {
         messagesEndRef.current?.scrollIntoView();
@@ -88,12 +90,13 @@ const AITutorChat = () => {
                 const reader = response.body.pipeThrough(new TextDecoderStream()).getReader();
 
                 let totalMessage = '';
+                let citations: Citation[] = [];
 
                 // eslint-disable-next-line no-constant-condition
                 while (true) {
                     const {value, done} = await reader.read();
                     if (done) {
-                        saveGPTAnswer(totalMessage);
+                        saveGPTAnswer(totalMessage, citations);
                         setBotMessage('');
                         setUserPostToChat(null);
                         break;
@@ -103,6 +106,10 @@ const AITutorChat = () => {
                         if (event === '[DONE]') {
                             break;
                         }
+                        if (event.startsWith(citationsEventPrefix)) {
+                            citations = JSON.parse(event.substring(citationsEventPrefix.length));
+                            continue;
+                        }
                         if (event.length > 0) {
                             let detectedIntent: PostType|null = null;
                             if (event === '{intent: show_text}') {
@@ -184,8 +191,53 @@ const AITutorChat = () => {
         fetchData();
     }, [testCheck]);
 
-    const saveGPTAnswer = (message: string) => {
-        saveGPTPost(message, PostTypeChatGPT);
+    useEffect(() => {
+        if (!hintNodeID) {
+            return;
+        }
+        const fetchData = async () => {
+            const response = await fetch(`${Client.Bot().getBotsRoute()}/hint`, {
+                method: 'POST',
+                headers: {},
+                body: JSON.stringify({node_id: hintNodeID}),
+            })
+            if (response && response.body) {
+                const reader = response.body.pipeThrough(new TextDecoderStream()).getReader();
+
+                let totalMessage = '';
+                let hintLevel = 0;
+
+                // eslint-disable-next-line no-constant-condition
+                while (true) {
+                    const {value, done} = await reader.read();
+                    if (done) {
+                        saveGPTPost(totalMessage, PostTypeChatGPT, [], {hint_level: hintLevel});
+                        setBotMessage('');
+                        setHintNodeID(null);
+                        break;
+                    }
+                    const events = getData(value)
+                    for (const event of events) {
+                        if (event === '[DONE]') {
+                            break;
+                        }
+                        if (event.startsWith(hintLevelEventPrefix)) {
+                            hintLevel = parseInt(event.substring(hintLevelEventPrefix.length), 10);
+                            continue;
+                        }
+                        if (event.length > 0) {
+                            totalMessage += event;
+                            setBotMessage(totalMessage);
+                        }
+                    }
+                }
+            }
+        }
+        fetchData();
+    }, [hintNodeID]);
+
+    const saveGPTAnswer = (message: string, citations: Citation[]) => {
+        saveGPTPost(message, PostTypeChatGPT, citations);
     }
 
     const saveGPTCorrectAnswerExplanation = (message: string) => {
@@ -196,11 +248,11 @@ const AITutorChat = () => {
         saveGPTPost(message, PostTypeChatGPTIncorrectAnswerExplanation);
     }
 
-    const saveGPTPost = (message: string, postType:PostType) => {
+    const saveGPTPost = (message: string, postType:PostType, citations: Citation[] = [], props: Record<string, any> = {}) => {
         const post = {
             message: message,
             post_type: postType,
-            props: {node_id: nextNodeTowardsGoal?.id || '', tutor_personality: preferences?.tutor_personality || 'standard-tutor-personality'},
+            props: {...props, node_id: nextNodeTowardsGoal?.id || '', tutor_personality: preferences?.tutor_personality || 'standard-tutor-personality', citations: citations},
             user_id: BOT_ID,
             user: null,
             id: '',
@@ -216,6 +268,16 @@ const AITutorChat = () => {
         });
     }
 
+    const requestHint = () => {
+        if (!nextNodeTowardsGoal) {
+            return;
+        }
+        Client.Post().saveUserPost('Can I get a hint on my code?', locationID, PostTypeFilledInByAction).then((userPost) => {
+            setConversationState({...conversationState, posts: [...conversationState.posts, userPost]});
+            setHintNodeID(nextNodeTowardsGoal.id);
+        });
+    }
+
     const handleSend = (input: string): Promise<void> => {
         return Client.Post().saveUserPost(input, locationID, '').then((userPost) => {
             Analytics.messageToAI({user_id: user!.id});
@@ -299,6 +361,22 @@ const AITutorChat = () => {
                         ))}
                     </Box>
                 }
+                {nextNodeTowardsGoal?.node_type === NodeTypeAssignment && !hintNodeID &&
+                    <Box>
+                        <Button
+                            variant='outlined'
+                            sx={{
+                                ml: '40px',
+                                mt: '20px',
+                                color: DashboardColors.primary,
+                                borderColor: DashboardColors.primary,
+                            }}
+                            onClick={requestHint}
+                        >
+                            Get a hint
+                        </Button>
+                    </Box>
+                }
                 <div ref={messagesEndRef}/>
             </MessageList>
             <Box display={'flex'} flexDirection={'row'} alignItems={'center'} sx={{pl: {xs: '2px', sm: '10px', md: '20px', lg: '40px'},}}>
@@ -353,6 +431,9 @@ function getSubstrings(input: string, start: string, end: string): string[] {
     return result;
 }
 
+const citationsEventPrefix = 'citations: ';
+const hintLevelEventPrefix = 'hint_level: ';
+
 function getData(input: string): string[] {
     return getSubstrings(input, 'data: {', '}\n\n');
 }
@@ -2,15 +2,17 @@ import React from 'react';
 import {Box, Typography, MenuItem} from '@mui/material';
 import {styled, alpha} from '@mui/material/styles';
 import Menu, {MenuProps} from '@mui/material/Menu';
-import Icon from '@mui/material/Icon';
 
 import useAuth from '../../hooks/useAuth';
-import {personalities} from '../../types/tutor_personalities';
+import useTutorPersonalities from '../../hooks/use_tutor_personalities';
 import {Client} from '../../client/client';
 
+import TutorPersonalityAvatar from './tutor_personality_avatar';
+
 
 const BotComponent = () => {
     const {user, preferences, setPreferences} = useAuth()
+    const personalities = useTutorPersonalities();
     const [anchorEl, setAnchorEl] = React.useState<HTMLElement | null>(null);
 
     const handleOpen = (event: React.MouseEvent<HTMLElement>) => {
@@ -23,14 +25,6 @@ const BotComponent = () => {
 
     const open = Boolean(anchorEl);
 
-    let currentPersonalitySymbol = '👩‍🏫'
-    for (let i = 0; i < personalities.length; i++) {
-        if (personalities[i].id === preferences?.tutor_personality) {
-            currentPersonalitySymbol = personalities[i].symbol;
-            break
-        }
-    }
-
     return (
         <Box
             aria-owns={open ? 'mouse-over-popover' : undefined}
@@ -38,7 +32,7 @@ const BotComponent = () => {
             onMouseEnter={handleOpen}
             onMouseLeave={handleClose}
         >
-            <Icon sx={{height:'100%'}}>{currentPersonalitySymbol}</Icon>
+            <TutorPersonalityAvatar tutorPersonality={preferences?.tutor_personality}/>
             <StyledMenu
                 // id="demo-customized-menu"
                 // MenuListProps={{
@@ -67,7 +61,7 @@ const BotComponent = () => {
                         }}
                     >
                         <Box display={'flex'} flexDirection={'row'} alignItems={'baseline'}>
-                            <Icon sx={{height:'100%'}}>{personality.symbol}</Icon>
+                            <TutorPersonalityAvatar tutorPersonality={personality.id}/>
                             <Typography variant={'body1'} sx={{ml: 1}}>
                                 {personality.name}
                             </Typography>
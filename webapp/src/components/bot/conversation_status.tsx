@@ -1,4 +1,4 @@
-import {Post, PostTypeChatGPTCorrectAnswerExplanation, PostTypeChatGPTIncorrectAnswerExplanation, PostTypeGoalFinish, PostTypeTest, PostTypeTestAnswer, PostTypeTopic, PostTypeTopicFinish} from "../../types/posts"
+import {Post, PostTypeAchievementUnlocked, PostTypeChatGPTCorrectAnswerExplanation, PostTypeChatGPTIncorrectAnswerExplanation, PostTypeGoalFinish, PostTypeReviewQuiz, PostTypeTest, PostTypeTestAnswer, PostTypeTopic, PostTypeTopicFinish} from "../../types/posts"
 import {NodeWithResources} from "../../types/graph";
 
 import {iKnowThisMessage, anotherVideoMessage, anotherTextMessage, letsStartMessage, anotherTestMessage} from "./messages";
@@ -66,7 +66,9 @@ export const getConversationStatus = (posts: Post[], userID: string, node?: Node
         return UserSwitchedGoal;
     }
 
-    const lastPost = posts[posts.length - 1];
+    // achievement celebrations and review quizzes don't change the state of the conversation
+    const conversationPosts = posts.filter((post) => post.post_type !== PostTypeAchievementUnlocked && post.post_type !== PostTypeReviewQuiz);
+    const lastPost = conversationPosts.length > 0 ? conversationPosts[conversationPosts.length - 1] : posts[posts.length - 1];
     if (lastPost.post_type === "" && lastPost.user_id === userID) {
         return UserWaitingForAnswer;
     }
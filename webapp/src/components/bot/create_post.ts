@@ -41,14 +41,14 @@ const getNodeViewState = (posts: Post[], nodeID: string) => {
     }
 }
 
-export const getNextContent = (posts: Post[], node: NodeWithResources, contentType: PostType):Post => {
+export const getNextContent = (posts: Post[], node: NodeWithResources, contentType: PostType, reason = ''):Post => {
     const nodeViewState = getNodeViewState(posts, node.id);
     if (contentType === PostTypeVideo) {
         return nextVideoMessage(node, nodeViewState);
     } else if (contentType === PostTypeText) {
         return nextTextMessage(node, nodeViewState);
     } else if (contentType === PostTypeTopic) {
-        return nextTopicMessage(node);
+        return nextTopicMessage(node, reason);
     } else if (contentType === PostTypeKarelJS){
         return nextKarelJSMessage(node);
     } else if (contentType === PostTypeTest){
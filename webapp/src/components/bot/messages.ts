@@ -151,11 +151,15 @@ export const nextKarelJSMessage = (node: NodeWithResources): Post => {
     };
 }
 
-export const nextTopicMessage = (node: NodeWithResources): Post => {
+export const nextTopicMessage = (node: NodeWithResources, reason = ''): Post => {
+    let message = `## Topic\n${node.name}\n ### Description\n ${node.description}\n`;
+    if (reason) {
+        message += ` ### Why this topic\n ${reason}\n`;
+    }
     return {
         id: '',
         user_id: BOT_ID,
-        message: `## Topic\n${node.name}\n ### Description\n ${node.description}\n`,
+        message: message,
         post_type: PostTypeTopic,
         user: null,
         props: {
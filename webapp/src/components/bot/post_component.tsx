@@ -1,7 +1,7 @@
 import React from 'react';
 import {Box} from '@mui/material';
 
-import {Post, PostTypeChatGPT, PostTypeChatGPTCorrectAnswerExplanation, PostTypeChatGPTIncorrectAnswerExplanation, PostTypeFilledInByAction, PostTypeGoalFinish, PostTypeKarelJS, PostTypeTest, PostTypeTestAnswer, PostTypeText, PostTypeTopic, PostTypeTopicFinish, PostTypeVideo} from '../../types/posts';
+import {Post, PostTypeAchievementUnlocked, PostTypeChatGPT, PostTypeChatGPTCorrectAnswerExplanation, PostTypeChatGPTIncorrectAnswerExplanation, PostTypeFilledInByAction, PostTypeGoalFinish, PostTypeKarelJS, PostTypeReviewQuiz, PostTypeTest, PostTypeTestAnswer, PostTypeText, PostTypeTopic, PostTypeTopicFinish, PostTypeVideo} from '../../types/posts';
 import IDE from '../karel/ide';
 import LinkFallback from '../link_fallback';
 import VideoFallback from '../video_fallback';
@@ -50,7 +50,9 @@ const PostComponent = (props: Props) => {
         props.post.post_type === PostTypeChatGPTCorrectAnswerExplanation ||
         props.post.post_type === PostTypeChatGPTIncorrectAnswerExplanation ||
         props.post.post_type === PostTypeTestAnswer ||
-        props.post.post_type === PostTypeTopicFinish) {
+        props.post.post_type === PostTypeTopicFinish ||
+        props.post.post_type === PostTypeAchievementUnlocked ||
+        props.post.post_type === PostTypeReviewQuiz) {
         component = (
             <TextMessage
                 shouldAnimate={props.isLast}
@@ -1,10 +1,11 @@
 import React from 'react';
-import {ListItem, ListItemAvatar, Avatar, Box, Icon} from '@mui/material';
+import {ListItem, ListItemAvatar, Avatar, Box} from '@mui/material';
 
 import {DashboardColors} from '../../ThemeOptions';
 import useAuth from '../../hooks/useAuth';
 import {stringAvatar} from '../rhs/rhs';
-import {personalities} from '../../types/tutor_personalities';
+
+import TutorPersonalityAvatar from './tutor_personality_avatar';
 
 interface Props {
     isBot: boolean;
@@ -15,8 +16,6 @@ interface Props {
 const PostContainer = (props: Props) => {
     const {user} = useAuth();
 
-    const icon = getIcon(props.tutorPersonality);
-
     return (
         <ListItem
             sx={{
@@ -29,7 +28,7 @@ const PostContainer = (props: Props) => {
                 <Box display='flex' flexDirection='row' alignItems={'flex-start'} justifyContent={'center'}>
                     <ListItemAvatar sx={{display: 'flex', justifyContent:'center'}}>
                         {props.isBot ?
-                            icon
+                            <TutorPersonalityAvatar tutorPersonality={props.tutorPersonality}/>
                             :
                             <Avatar alt={user!.username} {...stringAvatar(user!)}/>
                         }
@@ -41,16 +40,5 @@ const PostContainer = (props: Props) => {
     );
 }
 
-const getIcon = (tutorPersonality: string | undefined) => {
-    let currentPersonalitySymbol = '👩‍🏫'
-    for (let i = 0; i < personalities.length; i++) {
-        if (personalities[i].id === tutorPersonality) {
-            currentPersonalitySymbol = personalities[i].symbol;
-            break
-        }
-    }
-    return <Icon sx={{height:'100%'}}>{currentPersonalitySymbol}</Icon>
-}
-
 export default PostContainer;
 
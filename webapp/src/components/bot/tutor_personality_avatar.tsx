@@ -0,0 +1,28 @@
+import React from 'react';
+import {Avatar, Icon} from '@mui/material';
+
+import useTutorPersonalities from '../../hooks/use_tutor_personalities';
+import {StandardTutorPersonalityAvatar} from '../../types/tutor_personalities';
+
+interface Props {
+    tutorPersonality?: string;
+}
+
+const TutorPersonalityAvatar = (props: Props) => {
+    const personalities = useTutorPersonalities();
+
+    let avatar = StandardTutorPersonalityAvatar;
+    for (let i = 0; i < personalities.length; i++) {
+        if (personalities[i].id === props.tutorPersonality && personalities[i].avatar) {
+            avatar = personalities[i].avatar;
+            break
+        }
+    }
+
+    if (avatar.startsWith('http')) {
+        return <Avatar src={avatar} sx={{width: 24, height: 24}}/>
+    }
+    return <Icon sx={{height:'100%'}}>{avatar}</Icon>
+}
+
+export default TutorPersonalityAvatar;
@@ -22,7 +22,7 @@ interface ConversationState {
 export default function useConversation() {
     const [conversationState, setConversationState] = useState<ConversationState>({} as ConversationState);
     const {user, preferences} = useAuth();
-    const {nextNodeTowardsGoal, currentGoalID, onReload} = useGraph();
+    const {nextNodeTowardsGoal, nextTopicReason, currentGoalID, onReload} = useGraph();
 
     const locationID = `${user!.id}_${BOT_ID}`
 
@@ -59,14 +59,14 @@ export default function useConversation() {
             post = getNextContent([...posts], nextNodeTowardsGoal, PostTypeText);
             actions = getStandardActions(posts, nextNodeTowardsGoal);
         } else if (status === UserWaitingForTopic) {
-            post = getNextContent([...posts], nextNodeTowardsGoal, PostTypeTopic);
+            post = getNextContent([...posts], nextNodeTowardsGoal, PostTypeTopic, nextTopicReason);
             actions = getStandardActions(posts, nextNodeTowardsGoal);
         } else if (status === UserWaitingForAnswer) {
             setConversationState({...conversationState, userWaitingForAnswer: true});
         } else if (status === WaitingForUser) {
             actions = getStandardActions(posts, nextNodeTowardsGoal);
         } else if (status === UserSwitchedGoal && nextNodeTowardsGoal) {
-            post = nextTopicMessage(nextNodeTowardsGoal);
+            post = nextTopicMessage(nextNodeTowardsGoal, nextTopicReason);
             post.message = 'You have the new goal. The topic towards your goal is the following:\n\n' + post.message;
         } else if (status === UserWaitingForTheFirstTest || status === UserWaitingForAnotherTest) {
             post = getNextContent([...posts], nextNodeTowardsGoal, PostTypeTest);
@@ -1,11 +1,11 @@
 import React, {useEffect, useState} from 'react';
 import Grid2 from '@mui/material/Unstable_Grid2';
-import {Card, Typography, Box, useTheme} from '@mui/material';
+import {Button, Card, Typography, Box, useTheme} from '@mui/material';
 import CheckCircleIcon from '@mui/icons-material/CheckCircle';
 import LocalFireDepartmentOutlinedIcon from '@mui/icons-material/LocalFireDepartmentOutlined';
 import AutoAwesomeOutlinedIcon from '@mui/icons-material/AutoAwesomeOutlined';
 
-import {AITutorNumberOfPosts, FinishedNodes, Steak} from '../../types/dashboard';
+import {AITutorNumberOfPosts, FinishedNodes, LLMBudgetStatus, Steak} from '../../types/dashboard';
 import {Client} from '../../client/client';
 
 interface Props {
@@ -16,6 +16,7 @@ interface Props {
     secondaryText: string;
     iconBackground: string;
     icon: React.ReactNode;
+    action?: React.ReactNode;
 }
 
 const DashboardWidget = (props: Props) => {
@@ -59,6 +60,7 @@ const DashboardWidget = (props: Props) => {
                             {props.secondaryText}
                         </Typography>
                     </Box>
+                    {props.action}
                 </Box>
                 <Box display={'flex'} alignContent={'center'} m={0} p={0}>
                     <Box
@@ -85,8 +87,8 @@ const DashboardWidget = (props: Props) => {
 const Snippets = () => {
     const theme = useTheme();
     const [finishedNodes, setFinishedNodes] = useState<FinishedNodes>({finished_nodes: 0, finished_nodes_this_week: 0});
-    const [steak, setSteak] = useState<Steak>({current_steak: 0, max_steak: 0, today: false});
-    const [postsNum, setPostsNum] = useState<AITutorNumberOfPosts>({bot_posts_month: 0, bot_posts_week: 0, max_posts: 100});
+    const [steak, setSteak] = useState<Steak>({current_steak: 0, max_steak: 0, today: false, freezes: 0, freezes_used: 0, missed_days: 0});
+    const [postsNum, setPostsNum] = useState<AITutorNumberOfPosts>({tokens_month: 0, tokens_week: 0, usage: null});
 
     useEffect(() => {
         Client.Dashboard().getFinishedNodes().then((res) => {
@@ -132,15 +134,30 @@ const Snippets = () => {
                     icon={
                         <LocalFireDepartmentOutlinedIcon fontSize="large" sx={{color: steak.today ? theme.palette.primary.main : theme.palette.grey[600]}}/>
                     }
+                    action={steak.missed_days > 0 && steak.missed_days <= steak.freezes &&
+                        <Button
+                            size='small'
+                            sx={{mt: 1}}
+                            onClick={() => {
+                                Client.Dashboard().spendSteakFreezes().then((res) => {
+                                    if (res) {
+                                        setSteak(res);
+                                    }
+                                });
+                            }}
+                        >
+                            {`Use ${steak.missed_days} of ${steak.freezes} freezes`}
+                        </Button>
+                    }
                 />
             </Grid2>
             <Grid2 xs={12} sm={4}>
                 <DashboardWidget
-                    title="AI Tutor Questions"
-                    value={`${postsNum.bot_posts_month}/${postsNum.max_posts}`}
-                    delta={"" + postsNum.bot_posts_week}
+                    title="AI Tutor Usage"
+                    value={isLimited(postsNum.usage) ? `${Math.round(postsNum.usage!.percent_used)}% of budget` : `${postsNum.tokens_month} tokens`}
+                    delta={"" + postsNum.tokens_week}
                     deltaType="positive"
-                    secondaryText='This Week'
+                    secondaryText='Tokens This Week'
                     iconBackground='#fde8f2'
                     icon={
                         <AutoAwesomeOutlinedIcon fontSize="large" sx={{color: '#ec1b80'}}/>
@@ -151,4 +168,8 @@ const Snippets = () => {
     );
 }
 
+const isLimited = (usage: LLMBudgetStatus | null) => {
+    return usage !== null && (usage.token_limit > 0 || usage.cost_limit > 0);
+}
+
 export default Snippets;
@@ -19,7 +19,7 @@ import {Client} from '../client/client';
 import {ClientError} from "../client/rest";
 import useAuth from '../hooks/useAuth';
 import {Preference, UserPreferences, UserPreferencesDefaultValues } from '../types/users';
-import {personalities} from '../types/tutor_personalities';
+import useTutorPersonalities from '../hooks/use_tutor_personalities';
 
 interface Props {
     onClose: () => void;
@@ -27,6 +27,7 @@ interface Props {
 
 const Preferences = (props: Props) => {
     const {user, preferences, setPreferences} = useAuth();
+    const personalities = useTutorPersonalities();
     const {t} = useTranslation();
 
     const {control, handleSubmit, setError, clearErrors, formState: {errors}} = useForm<UserPreferences>();
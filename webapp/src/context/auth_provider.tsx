@@ -42,6 +42,7 @@ export const AuthProvider = (props: Props) => {
             setUser(data);
             Analytics.identify(data);
             Analytics.getMe();
+            const userID = data.id;
 
             Client.User().getMyPreferences().then((data) => {
                 const prefs = {} as UserPreferences
@@ -59,8 +60,11 @@ export const AuthProvider = (props: Props) => {
                         prefs.legend_on_graph_message = Boolean(data[i].value);
                     } else if (data[i].key === 'legend_on_topic_graph') {
                         prefs.legend_on_topic_graph = Boolean(data[i].value);
+                    } else if (data[i].key === 'timezone') {
+                        prefs.timezone = data[i].value;
                     }
                 }
+                saveBrowserTimezone(prefs, userID);
                 setPreferences(prefs);
                 setLoading(false);
             }).catch((err) => {
@@ -96,4 +100,16 @@ export const AuthProvider = (props: Props) => {
 
 export default AuthContext;
 
+// saveBrowserTimezone stores the browser's timezone, the streak days are counted in it
+const saveBrowserTimezone = (prefs: UserPreferences, userID: string) => {
+    const timezone = Intl.DateTimeFormat().resolvedOptions().timeZone;
+    if (!timezone || prefs.timezone === timezone) {
+        return;
+    }
+    prefs.timezone = timezone;
+    Client.User().saveMyPreferences([{key: 'timezone', user_id: userID, value: timezone}]).catch((err) => {
+        console.log('error while saving timezone', err);
+    });
+}
+
 
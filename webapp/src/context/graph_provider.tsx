@@ -2,7 +2,7 @@
 import React, {createContext, useEffect, useState} from 'react';
 
 import {Client} from "../client/client";
-import {Graph, Link, Node, NodeStatusFinished, NodeStatusNext, NodeStatusStarted, NodeStatusWatched, NodeTypeLecture, NodeTypeExample, NodeTypeAssignment, castToLink, Goal, cloneGraph, NodeStatusUnseen, NodeWithResources} from '../types/graph';
+import {Graph, Link, Node, NodeStatusFinished, NodeStatusNext, NodeStatusStarted, NodeStatusWatched, castToLink, Goal, cloneGraph, NodeStatusUnseen, NodeWithResources} from '../types/graph';
 import useAuth from '../hooks/useAuth';
 import {filterGraphByGoals} from '../components/graph/graph_helpers';
 
@@ -15,6 +15,7 @@ interface GraphContextState {
     currentGoalID: string | null;
     setCurrentGoal: (goal: Goal) => void;
     nextNodeTowardsGoal: NodeWithResources | null;
+    nextTopicReason: string;
     onReload: () => void;
     // addGoal: (goal: Goal) => void;
     removeGoal: (goal: string) => void;
@@ -33,6 +34,7 @@ const GraphContext = createContext<GraphContextState>({
     currentGoalID: null,
     setCurrentGoal: () => {},
     nextNodeTowardsGoal: null,
+    nextTopicReason: '',
     onReload: () => {},
     // addGoal: () => {},
     removeGoal: () => {},
@@ -65,18 +67,17 @@ export const GraphProvider = (props: Props) => {
         }
 
         const computedPathToGoal = computePathToGoal(graphState.globalGraph, newGoal.node_id);
-        const nextNodeID = nextNodeToGoal(graphState.globalGraph, computedPathToGoal, newGoal.node_id);
-        if (!nextNodeID) {
-            return;
-        }
         const exists = graphState.goals.find(goal => goal.node_id === newGoal.node_id);
         let newGoals = graphState.goals;
         if (!exists) {
             newGoals = [newGoal, ...graphState.goals];
         }
 
-        Client.Node().get(nextNodeID).then((node) => {
-            setGraphState({...graphState, pathToGoal: computedPathToGoal, goals: newGoals, nextNodeTowardsGoal: node, currentGoalID: newGoal.node_id});
+        fetchNextTopic(newGoal.node_id).then(([node, reason]) => {
+            if (!node) {
+                return;
+            }
+            setGraphState({...graphState, pathToGoal: computedPathToGoal, goals: newGoals, nextNodeTowardsGoal: node, nextTopicReason: reason, currentGoalID: newGoal.node_id});
         });
     }
 
@@ -87,15 +88,10 @@ export const GraphProvider = (props: Props) => {
         const newGoals = graphState.goals.filter(value => value.node_id !== goal)
         if (graphState.currentGoalID == goal && newGoals.length > 0) {
             const computedPathToGoal = computePathToGoal(graphState.globalGraph, newGoals[0].node_id);
-            const nextNodeID = nextNodeToGoal(graphState.globalGraph, computedPathToGoal, newGoals[0].node_id);
-            if (!nextNodeID) {
-                setGraphState({...graphState, pathToGoal: computedPathToGoal, goals: newGoals, currentGoalID: newGoals[0].node_id});
-                return;
-            }
-            Client.Node().get(nextNodeID).then((node) => {
-                setGraphState({...graphState, pathToGoal: computedPathToGoal, goals: newGoals, nextNodeTowardsGoal: node, currentGoalID: newGoals[0].node_id});
+            fetchNextTopic(newGoals[0].node_id).then(([node, reason]) => {
+                setGraphState({...graphState, pathToGoal: computedPathToGoal, goals: newGoals, nextNodeTowardsGoal: node, nextTopicReason: reason, currentGoalID: newGoals[0].node_id});
             }).catch(() => {
-                setGraphState({...graphState, pathToGoal: computedPathToGoal, goals: newGoals, nextNodeTowardsGoal: null, currentGoalID: newGoals[0].node_id});
+                setGraphState({...graphState, pathToGoal: computedPathToGoal, goals: newGoals, nextNodeTowardsGoal: null, nextTopicReason: '', currentGoalID: newGoals[0].node_id});
             });
         } else {
             setGraphState({...graphState, goals: newGoals});
@@ -114,18 +110,17 @@ export const GraphProvider = (props: Props) => {
                     const filteredGraph = filterGraphByGoals(data, newGoals);
                     const updatedGraph = getGraphWithUpdatedNodeStatuses(filteredGraph)
                     const computedPathToGoal = computePathToGoal(updatedGraph, newGoals[0].node_id);
-                    const nextNodeID = nextNodeToGoal(updatedGraph, computedPathToGoal, newGoals.length > 0 ? newGoals[0].node_id : '');
-                    if (nextNodeID) {
-                        Client.Node().get(nextNodeID).then((node) => {
-                            setGraphState({...graphState, globalGraph: updatedGraph, pathToGoal: computedPathToGoal, goals: newGoals, nextNodeTowardsGoal: node, currentGoalID: newGoals[0].node_id});
-                        }).catch(error => {
-                            console.log('error fetching next node', error)
+                    fetchNextTopic(newGoals[0].node_id).then(([node, reason]) => {
+                        if (!node) {
+                            console.log('no next topic, should not happen', updatedGraph, newGoals[0].node_id);
                             setGraphState({} as GraphContextState);
-                        });
-                    } else {
-                        console.log('no next Node ID, should not happen', updatedGraph, computedPathToGoal, newGoals[0].node_id);
+                            return;
+                        }
+                        setGraphState({...graphState, globalGraph: updatedGraph, pathToGoal: computedPathToGoal, goals: newGoals, nextNodeTowardsGoal: node, nextTopicReason: reason, currentGoalID: newGoals[0].node_id});
+                    }).catch(error => {
+                        console.log('error fetching next node', error)
                         setGraphState({} as GraphContextState);
-                    }
+                    });
                 }
             }).catch(error => {
                 console.log('error fetching goals', error)
@@ -162,6 +157,7 @@ export const GraphProvider = (props: Props) => {
             currentGoalID: graphState.currentGoalID,
             setCurrentGoal,
             nextNodeTowardsGoal: graphState.nextNodeTowardsGoal,
+            nextTopicReason: graphState.nextTopicReason,
             // addGoal,
             removeGoal,
             selectedNode: graphState.selectedNode,
@@ -176,6 +172,16 @@ export const GraphProvider = (props: Props) => {
 
 export default GraphContext;
 
+// fetchNextTopic returns the node the recommender ranks first towards the goal and the reason of the recommendation
+const fetchNextTopic = async (goalID: string): Promise<[NodeWithResources | null, string]> => {
+    const recommendation = await Client.Graph().getNextTopic(goalID);
+    if (!recommendation) {
+        return [null, ''];
+    }
+    const node = await Client.Node().get(recommendation.node.id);
+    return [node, recommendation.reason];
+}
+
 // export const getGraphForParent = (graph: Graph, parentID: string) => {
 //     const nodes = graph.nodes.filter(node => node.parent_id === parentID);
 //     const links = [];
@@ -268,42 +274,6 @@ export const computePathToGoal = (graph: Graph, goalNodeID: string) => {
     return pathToGoal;
 }
 
-export const nextNodeToGoal = (graph: Graph | null, pathToGoal: Map<string, string> | null, goalNodeID: string) => {
-    if (graph === null || pathToGoal === null) {
-        return null;
-    }
-    const [inProgressNodes, nextNodes] = computeNextNodes(graph);
-    if (goalNodeID === "") {
-        if (inProgressNodes && inProgressNodes.length !== 0) {
-            return inProgressNodes[0].nodeID;
-        } else if (nextNodes && nextNodes.length !== 0) {
-            return nextNodes[0].nodeID;
-        }
-        return null;
-    }
-
-    if (inProgressNodes && inProgressNodes.length !== 0) {
-        for (const node of inProgressNodes) {
-            if (pathToGoal.has(node.nodeID)) {
-                return node.nodeID;
-            } else if (node.nodeID === goalNodeID) {
-                return node.nodeID;
-            }
-        }
-    }
-    if (nextNodes && nextNodes.length !== 0) {
-        for (const node of nextNodes) {
-            if (pathToGoal.has(node.nodeID)) {
-                return node.nodeID;
-            } else if (node.nodeID === goalNodeID) {
-                return node.nodeID;
-            }
-        }
-    }
-
-    return null;
-}
-
 export const goalGraph = (graph: Graph | null, pathToGoal: Map<string, string> | null, goalNodeID: string) => {
     const goalNodes = [];
     const goalLinks = [];
@@ -338,24 +308,6 @@ const computeParentMap = (graph: Graph) => {
     return parentMap;
 }
 
-const computeNextNodes = (graph: Graph) => {
-    const inProgressNodes = [];
-    const nextNodes = [];
-    for (const node of graph.nodes) {
-        if(node.parent_id !== "") {
-            if (node.status === NodeStatusStarted || node.status === NodeStatusWatched) {
-                inProgressNodes.push(node);
-            } else if (node.status === NodeStatusNext) {
-                nextNodes.push(node);
-            }
-        }
-    }
-    nextNodes.sort(nodeCmpFn);
-    inProgressNodes.sort(nodeCmpFn);
-
-    return [inProgressNodes, nextNodes];
-}
-
 const getGraphWithUpdatedNodeStatuses = (graph: Graph) => {
     const nodeStatuses = getNodeStatuses(graph);
     const updatedGraph = cloneGraph(graph);
@@ -454,27 +406,6 @@ const getNodeStatuses = (graph: Graph): Map<string, string> => {
     return nodeStatuses;
 }
 
-const nodeCmpFn = (a: Node, b: Node) => {
-    if (a.node_type === b.node_type) {
-        const name1 = a.name.toUpperCase();
-        const name2 = b.name.toUpperCase();
-        if (name1 > name2) {
-            return 1;
-        } else if (name1 < name2) {
-            return -1;
-        } else {
-            return 0;
-        }
-    }
-    const nodeTypeMap = new Map();
-
-    nodeTypeMap
-        .set(NodeTypeLecture, 3)
-        .set(NodeTypeExample, 2)
-        .set(NodeTypeAssignment, 1);
-    return nodeTypeMap.get(a.node_type) - nodeTypeMap.get(b.node_type);
-}
-
 export const generateReverseGraph = (nodes: Node[], links: Link[]): Map<string, string[]> => {
     const graph: Map<string, string[]> = new Map();
 
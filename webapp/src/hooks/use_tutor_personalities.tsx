@@ -0,0 +1,29 @@
+import {useEffect, useState} from 'react';
+
+import {Client} from '../client/client';
+import {TutorPersonality, defaultPersonalities} from '../types/tutor_personalities';
+
+// personalities are managed by admins, they're fetched once per page load
+let loadedPersonalities: TutorPersonality[] | null = null;
+
+const useTutorPersonalities = () => {
+    const [personalities, setPersonalities] = useState<TutorPersonality[]>(loadedPersonalities || defaultPersonalities);
+
+    useEffect(() => {
+        if (loadedPersonalities) {
+            return;
+        }
+        Client.User().getTutorPersonalities()
+            .then((data) => {
+                loadedPersonalities = data;
+                setPersonalities(data);
+            })
+            .catch((err) => {
+                console.log('error', err)
+            });
+    }, []);
+
+    return personalities;
+}
+
+export default useTutorPersonalities;
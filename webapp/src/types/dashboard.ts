@@ -17,10 +17,21 @@ export type Steak = {
     current_steak: number;
     max_steak: number;
     today: boolean
+    freezes: number;
+    freezes_used: number;
+    missed_days: number;
+}
+
+export type LLMBudgetStatus = {
+    plan: string;
+    token_limit: number;
+    cost_limit: number;
+    percent_used: number;
+    exceeded: boolean;
 }
 
 export type AITutorNumberOfPosts = {
-    bot_posts_month: number;
-    bot_posts_week: number;
-    max_posts: number;
+    tokens_month: number;
+    tokens_week: number;
+    usage: LLMBudgetStatus | null;
 }
@@ -84,6 +84,7 @@ export type NodeWithResources = {
     id: string; // ?
     name: string;
     description: string;
+    node_type: string;
     status: string;
     environment: string;
     videos: Video[];
@@ -93,6 +94,17 @@ export type NodeWithResources = {
     prerequisites: Node[];
 }
 
+export type Recommendation = {
+    node: {
+        id: string;
+        name: string;
+        description: string;
+        node_type: string;
+    };
+    score: number;
+    reason: string;
+}
+
 export type NodeViewState = {
     videoIndex: number;
     textIndex: number;
@@ -1,6 +1,6 @@
 import {User} from "./users";
 
-export type PostType = "" | "with_actions" | "video" | "text" | "test" | "topic" | "filled_in_by_action" | "karel_js" | "chat_gpt" | "goal_finished" | "chat_gpt_correct_answer_expl" | "chat_gpt_incorrect_answer_expl" | "answer" | "topic_finished";
+export type PostType = "" | "with_actions" | "video" | "text" | "test" | "topic" | "filled_in_by_action" | "karel_js" | "chat_gpt" | "goal_finished" | "chat_gpt_correct_answer_expl" | "chat_gpt_incorrect_answer_expl" | "answer" | "topic_finished" | "achievement_unlocked" | "review_quiz";
 export const PostTypeWithActions:PostType = "with_actions";
 export const PostTypeVideo:PostType = "video";
 export const PostTypeTopic:PostType = "topic";
@@ -14,6 +14,8 @@ export const PostTypeChatGPT:PostType = "chat_gpt";
 export const PostTypeChatGPTCorrectAnswerExplanation:PostType = "chat_gpt_correct_answer_expl";
 export const PostTypeChatGPTIncorrectAnswerExplanation:PostType = "chat_gpt_incorrect_answer_expl";
 export const PostTypeTestAnswer:PostType = "answer";
+export const PostTypeAchievementUnlocked:PostType = "achievement_unlocked";
+export const PostTypeReviewQuiz:PostType = "review_quiz";
 
 export type Post = {
     id: string;
@@ -24,6 +26,18 @@ export type Post = {
     user: User | null;
 }
 
+// Citation points to the course material the tutor's answer is based on:
+// texts are cited by id and heading anchor, videos by id and timestamp
+export type Citation = {
+    index: number;
+    node_id: string;
+    source_type: 'text' | 'question' | 'video';
+    source_id: string;
+    title: string;
+    anchor?: string;
+    start_seconds?: number;
+}
+
 export type PostWithActions = {
     post: Post;
     actions: Action[];
@@ -1,38 +1,25 @@
 export type TutorPersonality = {
     id: string;
     name: string;
-    prompt: string;
+    // avatar is an emoji or an image URL
+    avatar: string;
+    prompts: {[language: string]: string};
+    tier: 'free' | 'premium';
+    enabled: boolean;
 }
 
-export const personalities = [
+export const StandardTutorPersonalityID = 'standard-tutor-personality';
+
+export const StandardTutorPersonalityAvatar = '👩‍🏫';
+
+// defaultPersonalities are shown until the personalities are loaded from the server
+export const defaultPersonalities: TutorPersonality[] = [
     {
-        id: 'standard-tutor-personality',
+        id: StandardTutorPersonalityID,
         name: 'Standard Tutor',
-        symbol: '👩‍🏫',
-    },
-    {
-        id: 'marvin-tutor-personality',
-        name: 'Marvin the Paranoid Android',
-        symbol: '🤖',
-    },
-    {
-        id: 'steve-jobs-tutor-personality',
-        name: 'Steve Jobs',
-        symbol: '🍎',
+        avatar: StandardTutorPersonalityAvatar,
+        prompts: {},
+        tier: 'free',
+        enabled: true,
     },
-    {
-        id: 'alex-tutor-personality',
-        name: 'Alex DeLarge',
-        symbol: '🎩',
-    },
-    {
-        id: 'yoda-tutor-personality',
-        name: 'Yoda',
-        symbol: '🧘',
-    },
-    {
-        id: 'gollum-tutor-personality',
-        name: 'Gollum',
-        symbol: '🐟',
-    }
 ];
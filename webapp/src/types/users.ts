@@ -42,7 +42,8 @@ export type UserWithNodeCount = {
 export const ROLES = {
     'Admin': 'admin',
     'User': 'user',
-    'Customer': 'customer'
+    'Customer': 'customer',
+    'Teacher': 'teacher'
 };
 
 export const PLANS = {
@@ -59,6 +60,7 @@ export type UserPreferences = {
     legend_on_graph_message: boolean;
     legend_on_topic_graph: boolean;
     tutor_personality: string;
+    timezone: string;
 }
 
 export const UserPreferencesDefaultValues: UserPreferences = {
@@ -67,7 +69,8 @@ export const UserPreferencesDefaultValues: UserPreferences = {
     graph_direction: 'td',
     legend_on_graph_message: true,
     legend_on_topic_graph: true,
-    tutor_personality: 'standard-tutor-personality'
+    tutor_personality: 'standard-tutor-personality',
+    timezone: 'UTC'
 }
 
 export type Preference = {